@@ -0,0 +1,160 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package accumulator
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/awsong/crypto/common"
+)
+
+// RSAAccumulator is an RSA accumulator (Baric and Pfitzmann, Camenisch and Lysyanskaya)
+// acc = g^(x_1 * ... * x_k) mod n where x_i are primes obtained from the accumulated
+// elements by HashToPrime and n is a product of two safe primes (its factorization
+// needs to be unknown to the users of the accumulator). The witness that x is
+// accumulated is w = g^(product of all other primes), the verification is w^x = acc.
+// Non-membership witness for y (Li, Li and Xue) is (a, d = g^b) where
+// a * (x_1 * ... * x_k) + b * y = 1, the verification is acc^a * d^y = g.
+type RSAAccumulator struct {
+	N        *big.Int
+	G        *big.Int
+	Value    *big.Int
+	elements map[string]*big.Int // accumulated elements mapped to primes
+}
+
+// NewRSAAccumulator returns an empty accumulator (acc = g) for the modulus n which
+// needs to be a product of two safe primes. The base g is a random quadratic residue.
+func NewRSAAccumulator(n *big.Int) *RSAAccumulator {
+	var r *big.Int
+	for {
+		r = common.GetRandomInt(n)
+		if r.Sign() > 0 && new(big.Int).GCD(nil, nil, r, n).Cmp(big.NewInt(1)) == 0 {
+			break
+		}
+	}
+	g := new(big.Int).Exp(r, big.NewInt(2), n)
+	return &RSAAccumulator{
+		N:        n,
+		G:        g,
+		Value:    new(big.Int).Set(g),
+		elements: make(map[string]*big.Int),
+	}
+}
+
+// HashToPrime maps elem to a 256-bit prime: it takes the first prime that is greater
+// or equal to the hash of elem (with the highest bit set).
+func HashToPrime(elem *big.Int) *big.Int {
+	x := common.Hash(elem)
+	x.Mod(x, new(big.Int).Lsh(big.NewInt(1), 256))
+	x.SetBit(x, 255, 1)
+	x.SetBit(x, 0, 1)
+	for !x.ProbablyPrime(20) {
+		x.Add(x, big.NewInt(2))
+	}
+	return x
+}
+
+// Add accumulates elem and returns the new accumulator value and the witness for elem.
+// Note that witnesses of the previously accumulated elements need to be updated
+// (w' = w^x where x = HashToPrime(elem)) or obtained by GetMembershipWitness.
+func (a *RSAAccumulator) Add(elem *big.Int) (*big.Int, *big.Int) {
+	if witness, err := a.GetMembershipWitness(elem); err == nil {
+		return a.Value, witness
+	}
+	x := HashToPrime(elem)
+	witness := a.Value
+	a.Value = new(big.Int).Exp(a.Value, x, a.N)
+	a.elements[elem.String()] = x
+	return a.Value, witness
+}
+
+// BatchDelete removes elems from the accumulator and returns the new accumulator value.
+// The value is recomputed from the remaining elements, thus all the witnesses need to
+// be obtained again. It returns an error (and does not remove anything) if some
+// of elems is not accumulated.
+func (a *RSAAccumulator) BatchDelete(elems []*big.Int) (*big.Int, error) {
+	for _, elem := range elems {
+		if _, ok := a.elements[elem.String()]; !ok {
+			return nil, fmt.Errorf("element %v is not accumulated", elem)
+		}
+	}
+	for _, elem := range elems {
+		delete(a.elements, elem.String())
+	}
+	a.Value = new(big.Int).Exp(a.G, a.getProduct(nil), a.N)
+	return a.Value, nil
+}
+
+// getProduct returns the product of the primes of all accumulated elements, except
+// the one given by exclude (it can be nil).
+func (a *RSAAccumulator) getProduct(exclude *big.Int) *big.Int {
+	product := big.NewInt(1)
+	for key, x := range a.elements {
+		if exclude != nil && key == exclude.String() {
+			continue
+		}
+		product.Mul(product, x)
+	}
+	return product
+}
+
+// GetMembershipWitness returns w = g^(product of all other primes). It returns an error
+// if elem is not accumulated.
+func (a *RSAAccumulator) GetMembershipWitness(elem *big.Int) (*big.Int, error) {
+	if _, ok := a.elements[elem.String()]; !ok {
+		return nil, fmt.Errorf("element is not accumulated")
+	}
+	return new(big.Int).Exp(a.G, a.getProduct(elem), a.N), nil
+}
+
+// GetNonMembershipWitness returns (a, d) such that acc^a * d^y = g where y = HashToPrime(elem).
+// It returns an error if elem is accumulated.
+func (a *RSAAccumulator) GetNonMembershipWitness(elem *big.Int) (*big.Int, *big.Int, error) {
+	y := HashToPrime(elem)
+	u := a.getProduct(nil)
+	// alpha * u + beta * y = 1
+	alpha, beta := new(big.Int), new(big.Int)
+	if new(big.Int).GCD(alpha, beta, u, y).Cmp(big.NewInt(1)) != 0 {
+		return nil, nil, fmt.Errorf("element is accumulated")
+	}
+	return alpha, exp(a.G, beta, a.N), nil
+}
+
+// VerifyMembership returns true if witness^HashToPrime(elem) = acc.
+func (a *RSAAccumulator) VerifyMembership(acc, elem, witness *big.Int) bool {
+	left := new(big.Int).Exp(witness, HashToPrime(elem), a.N)
+	return common.ConstantTimeEq(left, acc)
+}
+
+// VerifyNonMembership returns true if acc^alpha * d^HashToPrime(elem) = g.
+func (a *RSAAccumulator) VerifyNonMembership(acc, elem, alpha, d *big.Int) bool {
+	left := exp(acc, alpha, a.N)
+	left.Mul(left, new(big.Int).Exp(d, HashToPrime(elem), a.N))
+	left.Mod(left, a.N)
+	return common.ConstantTimeEq(left, a.G)
+}
+
+// exp returns x^e mod n, e can be negative (x needs to be invertible modulo n).
+func exp(x, e, n *big.Int) *big.Int {
+	if e.Sign() < 0 {
+		inv := new(big.Int).ModInverse(x, n)
+		return inv.Exp(inv, new(big.Int).Neg(e), n)
+	}
+	return new(big.Int).Exp(x, e, n)
+}
@@ -0,0 +1,101 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package accumulator
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/awsong/crypto/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRSAAccumulator(t *testing.T) {
+	p, err := common.GenerateSafePrime(256)
+	if err != nil {
+		t.Errorf("error when generating safe prime: %v", err)
+	}
+	q, err := common.GenerateSafePrime(256)
+	if err != nil {
+		t.Errorf("error when generating safe prime: %v", err)
+	}
+	accumulator := NewRSAAccumulator(new(big.Int).Mul(p, q))
+
+	elements := make([]*big.Int, 100)
+	for i := range elements {
+		elements[i] = big.NewInt(int64(1000 + i))
+		acc, witness := accumulator.Add(elements[i])
+		assert.Equal(t, true, accumulator.VerifyMembership(acc, elements[i], witness),
+			"membership witness returned by Add should be valid")
+	}
+
+	acc := accumulator.Value
+	for _, elem := range elements {
+		witness, err := accumulator.GetMembershipWitness(elem)
+		if err != nil {
+			t.Errorf("error in GetMembershipWitness: %v", err)
+		}
+		assert.Equal(t, true, accumulator.VerifyMembership(acc, elem, witness),
+			"membership proof failed")
+	}
+
+	nonMember := big.NewInt(5)
+	alpha, d, err := accumulator.GetNonMembershipWitness(nonMember)
+	if err != nil {
+		t.Errorf("error in GetNonMembershipWitness: %v", err)
+	}
+	assert.Equal(t, true, accumulator.VerifyNonMembership(acc, nonMember, alpha, d),
+		"non-membership proof failed")
+	_, _, err = accumulator.GetNonMembershipWitness(elements[0])
+	assert.NotNil(t, err, "non-membership witness should not exist for an accumulated element")
+
+	deleted := elements[:10]
+	deletedWitness, _ := accumulator.GetMembershipWitness(deleted[0])
+	acc, err = accumulator.BatchDelete(deleted)
+	if err != nil {
+		t.Errorf("error in BatchDelete: %v", err)
+	}
+	for _, elem := range deleted {
+		_, err := accumulator.GetMembershipWitness(elem)
+		assert.NotNil(t, err, "deleted element should not have a membership witness")
+		alpha, d, err := accumulator.GetNonMembershipWitness(elem)
+		if err != nil {
+			t.Errorf("error in GetNonMembershipWitness: %v", err)
+		}
+		assert.Equal(t, true, accumulator.VerifyNonMembership(acc, elem, alpha, d),
+			"non-membership proof for a deleted element failed")
+	}
+	assert.Equal(t, false, accumulator.VerifyMembership(acc, deleted[0], deletedWitness),
+		"old witness of a deleted element should not be valid")
+
+	for _, elem := range elements[10:] {
+		witness, err := accumulator.GetMembershipWitness(elem)
+		if err != nil {
+			t.Errorf("error in GetMembershipWitness: %v", err)
+		}
+		assert.Equal(t, true, accumulator.VerifyMembership(acc, elem, witness),
+			"membership proof after BatchDelete failed")
+		alpha, d, err := accumulator.GetNonMembershipWitness(elem)
+		assert.NotNil(t, err, "non-membership witness should not exist for a member")
+		assert.Nil(t, alpha)
+		assert.Nil(t, d)
+	}
+
+	_, err = accumulator.BatchDelete(deleted[:1])
+	assert.NotNil(t, err, "deleting an element that is not accumulated should fail")
+}
@@ -19,7 +19,9 @@ package common
 
 import (
 	"crypto/sha512"
+	"crypto/subtle"
 	"math/big"
+	"math/bits"
 )
 
 // It takes big.Int numbers, transform them to bytes, and concatenate the bytes.
@@ -59,6 +61,23 @@ func Exponentiate(x, y, m *big.Int) *big.Int {
 	return r
 }
 
+// ConstantTimeEq returns true if a and b are equal. Absolute values of a and b are
+// padded to the same byte length and compared using subtle.ConstantTimeCompare, thus
+// the execution time depends only on the lengths of a and b, not on their values.
+// It should be used instead of a.Cmp(b) == 0 when comparing values that are checked
+// in verification of proofs.
+func ConstantTimeEq(a, b *big.Int) bool {
+	l := len(a.Bits())
+	if lb := len(b.Bits()); lb > l {
+		l = lb
+	}
+	l *= bits.UintSize / 8
+	aBytes := a.FillBytes(make([]byte, l))
+	bBytes := b.FillBytes(make([]byte, l))
+	return subtle.ConstantTimeCompare(aBytes, bBytes)&
+		subtle.ConstantTimeEq(int32(a.Sign()), int32(b.Sign())) == 1
+}
+
 // Computes least common multiple.
 func LCM(x, y *big.Int) *big.Int {
 	n := new(big.Int)
@@ -30,3 +30,21 @@ func TestLCM(t *testing.T) {
 	lcm := LCM(a, b)
 	assert.Equal(t, lcm, big.NewInt(24), "LCM returned wrong value")
 }
+
+func TestConstantTimeEq(t *testing.T) {
+	a := new(big.Int).Lsh(big.NewInt(12345), 200)
+	b := new(big.Int).Lsh(big.NewInt(12345), 200)
+	assert.True(t, ConstantTimeEq(a, b), "equal values should be equal")
+	assert.True(t, ConstantTimeEq(big.NewInt(0), new(big.Int)), "zeros should be equal")
+	assert.True(t, ConstantTimeEq(big.NewInt(-7), big.NewInt(-7)),
+		"equal negative values should be equal")
+
+	assert.False(t, ConstantTimeEq(a, new(big.Int).Add(b, big.NewInt(1))),
+		"different values should not be equal")
+	assert.False(t, ConstantTimeEq(big.NewInt(7), big.NewInt(-7)),
+		"values with different signs should not be equal")
+	assert.False(t, ConstantTimeEq(a, big.NewInt(12345)),
+		"values of different lengths should not be equal")
+	assert.False(t, ConstantTimeEq(big.NewInt(0), big.NewInt(1)),
+		"zero and one should not be equal")
+}
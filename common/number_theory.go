@@ -0,0 +1,136 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package common
+
+import (
+	"math/big"
+)
+
+// ExtGCD returns gcd(a, b) together with Bezout coefficients s and t such that
+// s * a + t * b = gcd(a, b). The gcd is always non-negative; negative a or b are handled
+// by computing the coefficients for abs(a) and abs(b) and negating them accordingly.
+// For a = b = 0 it returns gcd = s = t = 0.
+func ExtGCD(a, b *big.Int) (gcd, s, t *big.Int) {
+	s, t = new(big.Int), new(big.Int)
+	gcd = new(big.Int).GCD(s, t, new(big.Int).Abs(a), new(big.Int).Abs(b))
+	if a.Sign() < 0 {
+		s.Neg(s)
+	}
+	if b.Sign() < 0 {
+		t.Neg(t)
+	}
+	return gcd, s, t
+}
+
+// Jacobi returns the Jacobi symbol (a/n). It is computed using the law of quadratic
+// reciprocity: factors 2 are removed from a (using (2/n) = -1 iff n = 3, 5 mod 8), then a
+// and n are swapped (changing the sign iff a = n = 3 mod 4) and a is reduced modulo n.
+// Jacobi symbol is defined only for odd positive n, Jacobi panics otherwise.
+// Note that (0/1) = 1 and (a/n) = 0 if gcd(a, n) != 1.
+func Jacobi(a, n *big.Int) int {
+	if n.Sign() <= 0 || n.Bit(0) == 0 {
+		panic("Jacobi symbol is defined only for odd positive n")
+	}
+
+	x := new(big.Int).Mod(a, n)
+	y := new(big.Int).Set(n)
+	j := 1
+	for x.Sign() != 0 {
+		// remove factors 2
+		s := x.TrailingZeroBits()
+		x.Rsh(x, s)
+		if s%2 == 1 {
+			yMod8 := y.Bits()[0] & 7
+			if yMod8 == 3 || yMod8 == 5 {
+				j = -j
+			}
+		}
+
+		// quadratic reciprocity
+		if x.Bits()[0]&3 == 3 && y.Bits()[0]&3 == 3 {
+			j = -j
+		}
+		x, y = y.Mod(y, x), x
+	}
+
+	if y.Cmp(big.NewInt(1)) == 0 {
+		return j
+	}
+	return 0
+}
+
+// ModSqrt returns (root, true) such that root^2 = a mod p if a is a quadratic residue
+// modulo odd prime p and (nil, false) otherwise. For p = 3 mod 4 the root is computed
+// as a^((p+1)/4), otherwise Tonelli-Shanks algorithm is used.
+func ModSqrt(a, p *big.Int) (*big.Int, bool) {
+	a = new(big.Int).Mod(a, p)
+	if a.Sign() == 0 {
+		return big.NewInt(0), true
+	}
+	if Jacobi(a, p) != 1 {
+		return nil, false
+	}
+
+	one := big.NewInt(1)
+	if p.Bit(1) == 1 { // p = 3 mod 4
+		e := new(big.Int).Add(p, one)
+		e.Rsh(e, 2)
+		return new(big.Int).Exp(a, e, p), true
+	}
+
+	// p - 1 = q * 2^s where q is odd
+	pMinusOne := new(big.Int).Sub(p, one)
+	s := pMinusOne.TrailingZeroBits()
+	q := new(big.Int).Rsh(pMinusOne, s)
+
+	// find a quadratic non-residue z
+	z := big.NewInt(2)
+	for Jacobi(z, p) != -1 {
+		z.Add(z, one)
+	}
+
+	m := s
+	c := new(big.Int).Exp(z, q, p)
+	t := new(big.Int).Exp(a, q, p)
+	e := new(big.Int).Add(q, one)
+	r := new(big.Int).Exp(a, e.Rsh(e, 1), p)
+	for t.Cmp(one) != 0 {
+		// find the least i, 0 < i < m, such that t^(2^i) = 1
+		i := uint(0)
+		t2 := new(big.Int).Set(t)
+		for t2.Cmp(one) != 0 {
+			t2.Mul(t2, t2)
+			t2.Mod(t2, p)
+			i++
+		}
+		// b = c^(2^(m-i-1))
+		b := new(big.Int).Set(c)
+		for j := uint(0); j < m-i-1; j++ {
+			b.Mul(b, b)
+			b.Mod(b, p)
+		}
+		m = i
+		c.Mul(b, b)
+		c.Mod(c, p)
+		t.Mul(t, c)
+		t.Mod(t, p)
+		r.Mul(r, b)
+		r.Mod(r, p)
+	}
+	return r, true
+}
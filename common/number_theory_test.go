@@ -0,0 +1,153 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package common
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExtGCD(t *testing.T) {
+	tests := []struct {
+		a   int64
+		b   int64
+		gcd int64
+	}{
+		{12, 18, 6},
+		{-12, 18, 6},
+		{12, -18, 6},
+		{-12, -18, 6},
+		{17, 31, 1},
+		{-17, 31, 1},
+		{1, 1000, 1},
+		{1000, 1, 1},
+		{-1, 5, 1},
+		{0, 7, 7},
+		{7, 0, 7},
+		{0, -7, 7},
+		{0, 0, 0},
+		{240, 46, 2},
+	}
+	for _, test := range tests {
+		a, b := big.NewInt(test.a), big.NewInt(test.b)
+		gcd, s, tt := ExtGCD(a, b)
+		assert.Equal(t, big.NewInt(test.gcd), gcd, "ExtGCD returned wrong gcd for (%d, %d)",
+			test.a, test.b)
+		sum := new(big.Int).Mul(s, a)
+		sum.Add(sum, new(big.Int).Mul(tt, b))
+		assert.Equal(t, gcd, sum, "Bezout identity does not hold for (%d, %d)", test.a, test.b)
+	}
+
+	a := GetRandomIntOfLength(512)
+	b := GetRandomIntOfLength(512)
+	a.Neg(a)
+	gcd, s, tt := ExtGCD(a, b)
+	sum := new(big.Int).Mul(s, a)
+	sum.Add(sum, new(big.Int).Mul(tt, b))
+	assert.Equal(t, gcd, sum, "Bezout identity does not hold for large values")
+}
+
+func TestJacobi(t *testing.T) {
+	tests := []struct {
+		a        int64
+		n        int64
+		expected int
+	}{
+		{0, 1, 1},
+		{5, 1, 1},
+		{0, 3, 0},
+		{1, 3, 1},
+		{2, 3, -1},
+		{2, 7, 1},
+		{3, 7, -1},
+		{6, 9, 0},
+		{2, 15, 1},
+		{7, 15, -1},
+		{30, 59, -1},
+		{1001, 9907, -1},
+		{19, 45, 1},
+		{8, 21, -1},
+		{-1, 7, -1},
+		{-1, 13, 1},
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.expected, Jacobi(big.NewInt(test.a), big.NewInt(test.n)),
+			"Jacobi returned wrong value for (%d/%d)", test.a, test.n)
+	}
+
+	// cross-check with math/big
+	n := GetRandomIntOfLength(256)
+	n.SetBit(n, 0, 1)
+	for i := 0; i < 100; i++ {
+		a := GetRandomInt(n)
+		assert.Equal(t, big.Jacobi(a, n), Jacobi(a, n), "Jacobi does not match big.Jacobi")
+	}
+
+	assert.Panics(t, func() { Jacobi(big.NewInt(3), big.NewInt(8)) },
+		"Jacobi should panic for even n")
+}
+
+func TestModSqrt(t *testing.T) {
+	primes := []*big.Int{
+		big.NewInt(7),  // 3 mod 4
+		big.NewInt(13), // 1 mod 4
+		big.NewInt(17), // 1 mod 16
+		big.NewInt(257),
+		big.NewInt(65537),
+	}
+	// Schnorr group primes of different bit lengths
+	for _, bits := range []int{256, 512} {
+		p, err := GetSafePrime(bits) // 3 mod 4
+		if err != nil {
+			t.Errorf("Error in GetSafePrime: %v", err)
+		}
+		primes = append(primes, p)
+	}
+	// prime p = 1 mod 2^10 for which Tonelli-Shanks needs several iterations
+	for {
+		p := GetRandomIntOfLength(256)
+		p.Lsh(p.Rsh(p, 10), 10)
+		p.Add(p, big.NewInt(1))
+		if p.ProbablyPrime(20) {
+			primes = append(primes, p)
+			break
+		}
+	}
+
+	for _, p := range primes {
+		for i := 0; i < 20; i++ {
+			x := GetRandomInt(p)
+			a := new(big.Int).Exp(x, big.NewInt(2), p)
+			root, ok := ModSqrt(a, p)
+			assert.True(t, ok, "square should have a square root")
+			rootSquared := new(big.Int).Exp(root, big.NewInt(2), p)
+			assert.Equal(t, a, rootSquared, "ModSqrt returned wrong root")
+		}
+
+		// find a non-residue
+		n := big.NewInt(2)
+		for big.Jacobi(n, p) != -1 {
+			n.Add(n, big.NewInt(1))
+		}
+		root, ok := ModSqrt(n, p)
+		assert.False(t, ok, "non-residue should not have a square root")
+		assert.Nil(t, root, "root should be nil for non-residue")
+	}
+}
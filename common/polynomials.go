@@ -48,6 +48,10 @@ func (polynomial *Polynomial) SetCoefficient(coeff_ind int, coefficient *big.Int
 	polynomial.coefficients[coeff_ind] = coefficient
 }
 
+func (polynomial *Polynomial) GetCoefficient(coeff_ind int) *big.Int {
+	return polynomial.coefficients[coeff_ind]
+}
+
 // Computes polynomial values at given points.
 func (polynomial *Polynomial) GetValues(points []*big.Int) map[*big.Int]*big.Int {
 	m := make(map[*big.Int]*big.Int)
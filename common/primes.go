@@ -18,6 +18,7 @@
 package common
 
 import (
+	"context"
 	"crypto/rand"
 	"fmt"
 	"io"
@@ -39,6 +40,102 @@ func GetSafePrime(bits int) (p *big.Int, err error) {
 	}
 }
 
+// GenerateSafePrime returns a safe prime p = 2*q + 1 (q is prime too) of the given bit length.
+// Unlike GetSafePrime it runs in a single goroutine. Candidates are sampled using crypto/rand
+// and the ones divisible by small primes are skipped. Then the Miller-Rabin test with
+// 40 rounds is applied first on q (which is cheaper) and then on p. It returns an error
+// if bits < 64.
+func GenerateSafePrime(bits int) (*big.Int, error) {
+	return GenerateSafePrimeFromReader(bits, rand.Reader)
+}
+
+// GenerateSafePrimeFromReader works as GenerateSafePrime, but candidates are sampled
+// from the given reader. With a deterministic reader the same safe prime is generated
+// each time.
+func GenerateSafePrimeFromReader(bits int, reader io.Reader) (*big.Int, error) {
+	return generateSafePrime(context.Background(), bits, reader)
+}
+
+// GenerateSafePrimeWithContext works as GenerateSafePrime, but the generation can be
+// aborted using ctx - ctx is checked before each candidate and ctx.Err() is returned
+// when ctx is done.
+func GenerateSafePrimeWithContext(ctx context.Context, bits int) (*big.Int, error) {
+	return generateSafePrime(ctx, bits, rand.Reader)
+}
+
+func generateSafePrime(ctx context.Context, bits int, reader io.Reader) (*big.Int, error) {
+	if bits < 64 {
+		return nil, fmt.Errorf("safe prime size must be at least 64-bit")
+	}
+
+	// q has bits-1 bits, the top two bits are set so that p has exactly bits bits
+	bytes := make([]byte, (bits-1+7)/8)
+	b := uint((bits - 1) % 8)
+	if b == 0 {
+		b = 8
+	}
+	q := new(big.Int)
+	p := new(big.Int)
+	bigMod := new(big.Int)
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		_, err := io.ReadFull(reader, bytes)
+		if err != nil {
+			return nil, err
+		}
+		bytes[0] &= uint8(int(1<<b) - 1)
+		if b >= 2 {
+			bytes[0] |= 3 << (b - 2)
+		} else {
+			bytes[0] |= 1
+			bytes[1] |= 0x80
+		}
+		bytes[len(bytes)-1] |= 1
+		q.SetBytes(bytes)
+
+		// neither q nor p = 2*q + 1 can be divisible by small primes
+		if hasSmallPrimeFactor(bigMod.Mod(q, smallPrimesProduct).Uint64()) {
+			continue
+		}
+
+		if !q.ProbablyPrime(40) {
+			continue
+		}
+		p.Lsh(q, 1)
+		p.Add(p, big.NewInt(1))
+		if p.BitLen() == bits && p.ProbablyPrime(40) {
+			return p, nil
+		}
+	}
+}
+
+// hasSmallPrimeFactor returns true if q or 2*q + 1 is divisible by one of smallPrimes,
+// where mod = q mod smallPrimesProduct. As mod can be bigger than 2^63, 2*mod + 1 could
+// overflow uint64 - mod is thus first reduced modulo each prime.
+func hasSmallPrimeFactor(mod uint64) bool {
+	for _, prime := range smallPrimes {
+		m := mod % uint64(prime)
+		if m == 0 || (2*m+1)%uint64(prime) == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// IsSafePrime returns true if n is a safe prime, that is both n and (n-1)/2 pass
+// the Miller-Rabin test with the given number of rounds. It returns false for n <= 2
+// and for even n.
+func IsSafePrime(n *big.Int, rounds int) bool {
+	if n.Cmp(big.NewInt(2)) <= 0 || n.Bit(0) == 0 {
+		return false
+	}
+	q := new(big.Int).Rsh(n, 1) // (n-1)/2 as n is odd
+	return q.ProbablyPrime(rounds) && n.ProbablyPrime(rounds)
+}
+
 // GetGermainPrime returns a prime number p for which 2*p + 1 is also prime. Note that conversely p
 // is called safe prime.
 func GetGermainPrime(bits int) (p *big.Int) {
@@ -18,7 +18,9 @@
 package common
 
 import (
+	"context"
 	"math/big"
+	"math/rand"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -45,3 +47,99 @@ func TestGetSafePrime(t *testing.T) {
 	assert.Equal(t, p.ProbablyPrime(20), true, "p should be prime")
 	assert.Equal(t, p1.ProbablyPrime(20), true, "p1 should be prime")
 }
+
+func TestGenerateSafePrime(t *testing.T) {
+	p, err := GenerateSafePrime(256)
+	if err != nil {
+		t.Errorf("Error in GenerateSafePrime: %v", err)
+	}
+	q := new(big.Int).Sub(p, big.NewInt(1))
+	q.Rsh(q, 1)
+
+	assert.Equal(t, 256, p.BitLen(), "p should have the given bit length")
+	assert.Equal(t, true, p.ProbablyPrime(20), "p should be prime")
+	assert.Equal(t, true, q.ProbablyPrime(20), "q should be prime")
+
+	_, err = GenerateSafePrime(32)
+	assert.NotNil(t, err, "bit length smaller than 64 should not be accepted")
+}
+
+func TestGenerateSafePrimeFromReader(t *testing.T) {
+	p1, err := GenerateSafePrimeFromReader(128, rand.New(rand.NewSource(42)))
+	if err != nil {
+		t.Errorf("Error in GenerateSafePrimeFromReader: %v", err)
+	}
+	p2, err := GenerateSafePrimeFromReader(128, rand.New(rand.NewSource(42)))
+	if err != nil {
+		t.Errorf("Error in GenerateSafePrimeFromReader: %v", err)
+	}
+	assert.Equal(t, true, IsSafePrime(p1, 40), "generated safe prime should pass IsSafePrime")
+	assert.Equal(t, p1, p2, "the same reader should give the same safe prime")
+}
+
+func TestGenerateSafePrimeWithContext(t *testing.T) {
+	p, err := GenerateSafePrimeWithContext(context.Background(), 128)
+	if err != nil {
+		t.Errorf("Error in GenerateSafePrimeWithContext: %v", err)
+	}
+	assert.Equal(t, true, IsSafePrime(p, 40), "generated safe prime should pass IsSafePrime")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = GenerateSafePrimeWithContext(ctx, 2048)
+	assert.Equal(t, context.Canceled, err, "generation should stop when context is canceled")
+}
+
+func BenchmarkGenerateSafePrime512(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		GenerateSafePrime(512)
+	}
+}
+
+// TestHasSmallPrimeFactor checks values above 2^63 for which 2*mod + 1 overflows uint64.
+func TestHasSmallPrimeFactor(t *testing.T) {
+	mod := uint64(1<<63 + 15) // neither mod nor 2*mod + 1 has a factor in smallPrimes
+	assert.Equal(t, false, hasSmallPrimeFactor(mod), "no small prime factor for 2^63 + 15")
+	mod = uint64(1<<63 + 29) // 2*mod + 1 is divisible by 3
+	assert.Equal(t, true, hasSmallPrimeFactor(mod), "small prime factor for 2^63 + 29")
+	assert.Equal(t, true, hasSmallPrimeFactor(9), "small prime factor for 9")
+	assert.Equal(t, false, hasSmallPrimeFactor(83), "no small prime factor for 83")
+}
+
+func TestIsSafePrime(t *testing.T) {
+	tests := []struct {
+		n        int64
+		expected bool
+	}{
+		{5, true},
+		{7, true},
+		{11, true},
+		{23, true},
+		{47, true},
+		{59, true},
+		{83, true},
+		{-7, false},
+		{0, false},
+		{1, false},
+		{2, false},
+		{3, false},  // (3-1)/2 = 1 is not prime
+		{4, false},  // even
+		{13, false}, // prime, but 6 is not
+		{17, false}, // prime, but 8 is not
+		{29, false}, // prime, but 14 is not
+		{31, false}, // prime, but 15 is not
+		{45, false}, // 22 is not prime and 45 is not prime
+		{49, false}, // 24 is not prime and 49 is not prime
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.expected, IsSafePrime(big.NewInt(test.n), 20),
+			"IsSafePrime returned wrong value for %d", test.n)
+	}
+
+	p, err := GenerateSafePrime(128)
+	if err != nil {
+		t.Errorf("Error in GenerateSafePrime: %v", err)
+	}
+	assert.Equal(t, true, IsSafePrime(p, 40), "generated safe prime should pass IsSafePrime")
+}
@@ -20,6 +20,7 @@ package common
 import (
 	"crypto/rand"
 	"fmt"
+	"io"
 	"log"
 	"math/big"
 )
@@ -33,6 +34,49 @@ func GetRandomInt(max *big.Int) *big.Int {
 	return n
 }
 
+// GetRandomInts returns n random integers from [0, max). Random bytes for all the values
+// are read from crypto/rand at once and then split into n values. As in rand.Int, the bits
+// above the bit length of max are masked and the values >= max are rejected
+// (new bytes are read for them), thus the values are uniformly distributed.
+func GetRandomInts(n int, max *big.Int) ([]*big.Int, error) {
+	if max.Sign() <= 0 {
+		return nil, fmt.Errorf("GetRandomInts: max has to be positive")
+	}
+	if n < 0 {
+		return nil, fmt.Errorf("GetRandomInts: n cannot be negative")
+	}
+
+	bitLen := max.BitLen()
+	byteLen := (bitLen + 7) / 8
+	// bits of the first byte which are above bitLen need to be masked
+	mask := byte(int(1<<uint(bitLen-8*(byteLen-1))) - 1)
+
+	values := make([]*big.Int, n)
+	missing := make([]int, n) // indices of values which still need to be generated
+	for i := range missing {
+		missing[i] = i
+	}
+	for len(missing) > 0 {
+		buf := make([]byte, len(missing)*byteLen)
+		if _, err := io.ReadFull(rand.Reader, buf); err != nil {
+			return nil, err
+		}
+		var rejected []int
+		for j, ind := range missing {
+			b := buf[j*byteLen : (j+1)*byteLen]
+			b[0] &= mask
+			v := new(big.Int).SetBytes(b)
+			if v.Cmp(max) >= 0 {
+				rejected = append(rejected, ind)
+				continue
+			}
+			values[ind] = v
+		}
+		missing = rejected
+	}
+	return values, nil
+}
+
 // GetRandomIntAlsoNeg returns random integer from (-max, max).
 func GetRandomIntAlsoNeg(max *big.Int) *big.Int {
 	n := GetRandomInt(max)
@@ -43,30 +87,25 @@ func GetRandomIntAlsoNeg(max *big.Int) *big.Int {
 	return n
 }
 
-// Returns random integer from [min, max).
-func GetRandomIntFromRange(min, max *big.Int) (*big.Int, error) {
+// GetRandomIntInRange returns random integer from [min, max). It returns an error
+// if min >= max.
+func GetRandomIntInRange(min, max *big.Int) (*big.Int, error) {
 	if min.Cmp(max) >= 0 {
-		err := fmt.Errorf("GetRandomIntFromRange: max has to be bigger than min")
-		return nil, err
+		return nil, fmt.Errorf("GetRandomIntInRange: max has to be bigger than min")
 	}
-	if min.Cmp(big.NewInt(0)) < 0 && max.Cmp(big.NewInt(0)) < 0 {
-		d := new(big.Int).Sub(min, max)
-		dAbs := new(big.Int).Abs(d)
-		i := GetRandomInt(dAbs)
-		ic := new(big.Int).Add(min, i)
-		return ic, nil
-	} else if min.Cmp(big.NewInt(0)) < 0 && max.Cmp(big.NewInt(0)) >= 0 {
-		nMin := new(big.Int).Abs(min)
-		d := new(big.Int).Add(nMin, max)
-		i := GetRandomInt(d)
-		ic := new(big.Int).Add(min, i)
-		return ic, nil
-	} else {
-		d := new(big.Int).Sub(max, min)
-		i := GetRandomInt(d)
-		ic := new(big.Int).Add(min, i)
-		return ic, nil
+	d := new(big.Int).Sub(max, min)
+	n, err := rand.Int(rand.Reader, d)
+	if err != nil {
+		return nil, err
 	}
+	return n.Add(min, n), nil
+}
+
+// Returns random integer from [min, max).
+//
+// Deprecated: use GetRandomIntInRange.
+func GetRandomIntFromRange(min, max *big.Int) (*big.Int, error) {
+	return GetRandomIntInRange(min, max)
 }
 
 // GetRandomIntOfLength returns random *big.Int exactly of length bitLengh.
@@ -0,0 +1,92 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package common
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetRandomIntInRange(t *testing.T) {
+	ranges := [][]*big.Int{
+		{big.NewInt(5), big.NewInt(10)},
+		{big.NewInt(-10), big.NewInt(-5)},
+		{big.NewInt(-10), big.NewInt(10)},
+		{big.NewInt(7), big.NewInt(8)},
+	}
+	for _, r := range ranges {
+		for i := 0; i < 50; i++ {
+			n, err := GetRandomIntInRange(r[0], r[1])
+			if err != nil {
+				t.Errorf("error in GetRandomIntInRange: %v", err)
+			}
+			assert.True(t, n.Cmp(r[0]) >= 0 && n.Cmp(r[1]) < 0,
+				"GetRandomIntInRange returned value out of range")
+		}
+	}
+
+	_, err := GetRandomIntInRange(big.NewInt(10), big.NewInt(10))
+	assert.NotNil(t, err, "empty range should return an error")
+	_, err = GetRandomIntInRange(big.NewInt(10), big.NewInt(5))
+	assert.NotNil(t, err, "min > max should return an error")
+}
+
+func TestGetRandomInts(t *testing.T) {
+	maxs := []*big.Int{big.NewInt(1), big.NewInt(7), big.NewInt(256), big.NewInt(257),
+		new(big.Int).Lsh(big.NewInt(1), 1024)}
+	for _, max := range maxs {
+		values, err := GetRandomInts(50, max)
+		if err != nil {
+			t.Errorf("error in GetRandomInts: %v", err)
+		}
+		assert.Equal(t, 50, len(values), "GetRandomInts returned wrong number of values")
+		for _, v := range values {
+			assert.True(t, v.Sign() >= 0 && v.Cmp(max) < 0,
+				"GetRandomInts returned value out of range")
+		}
+	}
+
+	// all values from [0, 7) should appear
+	values, _ := GetRandomInts(1000, big.NewInt(7))
+	counts := make(map[int64]int)
+	for _, v := range values {
+		counts[v.Int64()]++
+	}
+	assert.Equal(t, 7, len(counts), "GetRandomInts does not cover the whole range")
+
+	_, err := GetRandomInts(10, big.NewInt(0))
+	assert.NotNil(t, err, "max 0 should return an error")
+}
+
+func BenchmarkGetRandomInt(b *testing.B) {
+	max := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(189))
+	for i := 0; i < b.N; i++ {
+		for j := 0; j < 10; j++ {
+			GetRandomInt(max)
+		}
+	}
+}
+
+func BenchmarkGetRandomInts(b *testing.B) {
+	max := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(189))
+	for i := 0; i < b.N; i++ {
+		GetRandomInts(10, max)
+	}
+}
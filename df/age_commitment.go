@@ -0,0 +1,117 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package df
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// maxAge is the biggest value accepted as a valid age.
+const maxAge = 150
+
+// AgeProver proves that the commitment c = g^age * h^r hides a valid age: 0 <= age <= 150.
+// It uses CompoundPredicateProver for the predicate age >= 0 AND age <= 150.
+type AgeProver struct {
+	*CompoundPredicateProver
+}
+
+// NewAgeProver returns AgeProver. It returns an error if (age, r) is not the opening of
+// the commitment in committer or if age is not in [0, 150].
+func NewAgeProver(committer *Committer, age, r *big.Int,
+	challengeSpaceSize int) (*AgeProver, error) {
+	prover, err := newAgeRangeProver(committer, age, r, 0, challengeSpaceSize)
+	if err != nil {
+		return nil, err
+	}
+	return &AgeProver{prover}, nil
+}
+
+// AgeOverThresholdProver proves that the commitment c = g^age * h^r hides a valid age
+// which is at least threshold: threshold <= age <= 150.
+type AgeOverThresholdProver struct {
+	*CompoundPredicateProver
+}
+
+// NewAgeOverThresholdProver returns AgeOverThresholdProver. It returns an error if (age, r)
+// is not the opening of the commitment in committer, if threshold is not in [0, 150] or if
+// age is not in [threshold, 150].
+func NewAgeOverThresholdProver(committer *Committer, age, r *big.Int, threshold int,
+	challengeSpaceSize int) (*AgeOverThresholdProver, error) {
+	if threshold < 0 || threshold > maxAge {
+		return nil, fmt.Errorf("threshold needs to be in [0, %d]", maxAge)
+	}
+	prover, err := newAgeRangeProver(committer, age, r, threshold, challengeSpaceSize)
+	if err != nil {
+		return nil, err
+	}
+	return &AgeOverThresholdProver{prover}, nil
+}
+
+// getAgePredicate returns the predicate age >= min AND age <= 150.
+func getAgePredicate(min int) *Predicate {
+	return NewAndPredicate(NewGePredicate(big.NewInt(int64(min))),
+		NewLePredicate(big.NewInt(maxAge)))
+}
+
+func newAgeRangeProver(committer *Committer, age, r *big.Int, min int,
+	challengeSpaceSize int) (*CompoundPredicateProver, error) {
+	committedValue, committedR := committer.GetDecommitMsg()
+	if committedValue == nil || age.Cmp(committedValue) != 0 || r.Cmp(committedR) != 0 {
+		return nil, fmt.Errorf("age and r are not the opening of the commitment")
+	}
+	if age.Cmp(big.NewInt(int64(min))) < 0 || age.Cmp(big.NewInt(maxAge)) > 0 {
+		return nil, fmt.Errorf("age needs to be in [%d, %d]", min, maxAge)
+	}
+	return NewCompoundPredicateProver(committer, getAgePredicate(min), challengeSpaceSize)
+}
+
+type AgeVerifier struct {
+	*CompoundPredicateVerifier
+}
+
+// NewAgeVerifier returns AgeVerifier for the commitment stored in receiver.
+// T is the bound for the committed values (as in Committer).
+func NewAgeVerifier(receiver *Receiver, T *big.Int,
+	challengeSpaceSize int) (*AgeVerifier, error) {
+	verifier, err := NewCompoundPredicateVerifier(receiver, getAgePredicate(0), T,
+		challengeSpaceSize)
+	if err != nil {
+		return nil, err
+	}
+	return &AgeVerifier{verifier}, nil
+}
+
+type AgeOverThresholdVerifier struct {
+	*CompoundPredicateVerifier
+}
+
+// NewAgeOverThresholdVerifier returns AgeOverThresholdVerifier for the commitment stored
+// in receiver. T is the bound for the committed values (as in Committer).
+func NewAgeOverThresholdVerifier(receiver *Receiver, threshold int, T *big.Int,
+	challengeSpaceSize int) (*AgeOverThresholdVerifier, error) {
+	if threshold < 0 || threshold > maxAge {
+		return nil, fmt.Errorf("threshold needs to be in [0, %d]", maxAge)
+	}
+	verifier, err := NewCompoundPredicateVerifier(receiver, getAgePredicate(threshold), T,
+		challengeSpaceSize)
+	if err != nil {
+		return nil, err
+	}
+	return &AgeOverThresholdVerifier{verifier}, nil
+}
@@ -0,0 +1,115 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package df
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// proveAge runs AgeProver (if threshold is negative) or AgeOverThresholdProver for the
+// committed age, where the verifier checks verifierThreshold.
+func proveAge(receiver *Receiver, T *big.Int, age int64, threshold,
+	verifierThreshold int) (bool, error) {
+	x := big.NewInt(age)
+	committer, r, err := getCommitterAndReceiver(receiver, T, x)
+	if err != nil {
+		return false, err
+	}
+	_, rand := committer.GetDecommitMsg()
+
+	var prover *CompoundPredicateProver
+	var verifier *CompoundPredicateVerifier
+	if threshold < 0 {
+		p, err := NewAgeProver(committer, x, rand, 80)
+		if err != nil {
+			return false, err
+		}
+		prover = p.CompoundPredicateProver
+		v, err := NewAgeVerifier(r, T, 80)
+		if err != nil {
+			return false, err
+		}
+		verifier = v.CompoundPredicateVerifier
+	} else {
+		p, err := NewAgeOverThresholdProver(committer, x, rand, threshold, 80)
+		if err != nil {
+			return false, err
+		}
+		prover = p.CompoundPredicateProver
+		v, err := NewAgeOverThresholdVerifier(r, verifierThreshold, T, 80)
+		if err != nil {
+			return false, err
+		}
+		verifier = v.CompoundPredicateVerifier
+	}
+
+	if err := verifier.SetProofRandomData(prover.GetProofRandomData()); err != nil {
+		return false, err
+	}
+	challenges, proofData := prover.GetProofData(verifier.GetChallenge())
+	return verifier.Verify(challenges, proofData), nil
+}
+
+// TestDFCommitmentAge demonstrates how to prove that the committed value is a valid
+// age and that it is at least the given threshold.
+func TestDFCommitmentAge(t *testing.T) {
+	receiver, err := NewReceiver(128, 80)
+	if err != nil {
+		t.Errorf("error in NewReceiver: %v", err)
+	}
+	// n^2 is used for T - but any other value can be used as well
+	T := new(big.Int).Mul(receiver.QRSpecialRSA.N, receiver.QRSpecialRSA.N)
+
+	for _, age := range []int64{0, 1, 42, 149, 150} {
+		proved, err := proveAge(receiver, T, age, -1, -1)
+		if err != nil {
+			t.Errorf("error in age proof: %v", err)
+		}
+		assert.Equal(t, true, proved, "DamgardFujisaki age proof failed for %d.", age)
+	}
+	for _, age := range []int64{-1, 151} {
+		_, err := proveAge(receiver, T, age, -1, -1)
+		assert.NotNil(t, err, "AgeProver should fail for %d", age)
+	}
+
+	for _, age := range []int64{18, 19, 150} {
+		proved, err := proveAge(receiver, T, age, 18, 18)
+		if err != nil {
+			t.Errorf("error in age over threshold proof: %v", err)
+		}
+		assert.Equal(t, true, proved,
+			"DamgardFujisaki age over threshold proof failed for %d.", age)
+	}
+	for _, age := range []int64{17, 151} {
+		_, err := proveAge(receiver, T, age, 18, 18)
+		assert.NotNil(t, err, "AgeOverThresholdProver should fail for %d", age)
+	}
+
+	// the proof of age >= 18 does not verify as a proof of age >= 21
+	proved, err := proveAge(receiver, T, 19, 18, 21)
+	if err != nil {
+		t.Errorf("error in age over threshold proof: %v", err)
+	}
+	assert.Equal(t, false, proved, "age over threshold proof should fail for a different threshold")
+
+	_, err = proveAge(receiver, T, 20, 151, 151)
+	assert.NotNil(t, err, "AgeOverThresholdProver should fail for threshold above 150")
+}
@@ -0,0 +1,294 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package df
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/awsong/crypto/common"
+)
+
+// AlgorithmOp is the operation of an AlgorithmGate.
+type AlgorithmOp int
+
+const (
+	AlgorithmAdd      AlgorithmOp = iota // w = w_In1 + w_In2
+	AlgorithmMul                         // w = w_In1 * w_In2
+	AlgorithmAddConst                    // w = w_In1 + Const
+	AlgorithmMulConst                    // w = w_In1 * Const
+)
+
+// AlgorithmGate is one step of an Algorithm. In1 and In2 are indices of the
+// wires that are the inputs of the gate (In2 is used only by AlgorithmAdd and AlgorithmMul).
+type AlgorithmGate struct {
+	Op    AlgorithmOp
+	In1   int
+	In2   int
+	Const *big.Int
+}
+
+// Algorithm is a public computation that derives a value from a seed. Wire 0 is the seed,
+// gate i computes wire i+1 and the last wire is the output of the algorithm.
+type Algorithm []*AlgorithmGate
+
+// Evaluate returns the values of all wires of the algorithm for a given seed.
+func (alg Algorithm) Evaluate(seed *big.Int) ([]*big.Int, error) {
+	if err := alg.check(); err != nil {
+		return nil, err
+	}
+	wires := []*big.Int{seed}
+	for _, gate := range alg {
+		var w *big.Int
+		switch gate.Op {
+		case AlgorithmAdd:
+			w = new(big.Int).Add(wires[gate.In1], wires[gate.In2])
+		case AlgorithmMul:
+			w = new(big.Int).Mul(wires[gate.In1], wires[gate.In2])
+		case AlgorithmAddConst:
+			w = new(big.Int).Add(wires[gate.In1], gate.Const)
+		case AlgorithmMulConst:
+			w = new(big.Int).Mul(wires[gate.In1], gate.Const)
+		}
+		wires = append(wires, w)
+	}
+	return wires, nil
+}
+
+// check returns an error if some gate has an unknown operation, refers to a wire which
+// is not yet computed, or misses a constant.
+func (alg Algorithm) check() error {
+	if len(alg) == 0 {
+		return fmt.Errorf("algorithm needs to have at least one gate")
+	}
+	for i, gate := range alg {
+		if gate.In1 < 0 || gate.In1 > i {
+			return fmt.Errorf("gate %d refers to an unknown wire", i)
+		}
+		switch gate.Op {
+		case AlgorithmAdd, AlgorithmMul:
+			if gate.In2 < 0 || gate.In2 > i {
+				return fmt.Errorf("gate %d refers to an unknown wire", i)
+			}
+		case AlgorithmAddConst, AlgorithmMulConst:
+			if gate.Const == nil {
+				return fmt.Errorf("gate %d needs a constant", i)
+			}
+		default:
+			return fmt.Errorf("gate %d has unknown operation", i)
+		}
+	}
+	return nil
+}
+
+// AlgorithmProver proves that the committed value x was computed by a given public
+// Algorithm from a committed seed. It can serve as a basis for provably correct
+// generation of parameters: the seed remains hidden, but everybody can check that
+// the parameter was derived from it as specified.
+//
+// Prover commits to all the wires of the algorithm. Commitments to the outputs of
+// addition gates and gates with constants are computed homomorphically
+// (for example c_w = c_1 * c_2 for w = w_1 + w_2, meaning r_w = r_1 + r_2), thus the
+// verifier can check them without any proof. For each multiplication gate a
+// MultiplicationProof is given. All multiplication proofs share the same challenge.
+type AlgorithmProver struct {
+	algorithm   Algorithm
+	committers  []*Committer
+	commitments []*big.Int
+	provers     []*MultiplicationProver
+}
+
+// NewAlgorithmProver commits to all the wires of the algorithm evaluated at seed. The
+// committer defines the parameters of the commitments, its committed value (if any) is ignored.
+func NewAlgorithmProver(committer *Committer, algorithm Algorithm, seed *big.Int,
+	challengeSpaceSize int) (*AlgorithmProver, error) {
+	wires, err := algorithm.Evaluate(seed)
+	if err != nil {
+		return nil, err
+	}
+
+	committers := make([]*Committer, len(wires))
+	commitments := make([]*big.Int, len(wires))
+	var provers []*MultiplicationProver
+	for i, w := range wires {
+		committers[i] = NewCommitter(committer.QRSpecialRSA.N, committer.G, committer.H,
+			committer.T, committer.K)
+		if i == 0 {
+			commitments[i], err = committers[i].GetCommitMsg(w)
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		gate := algorithm[i-1]
+		_, r1 := committers[gate.In1].GetDecommitMsg()
+		switch gate.Op {
+		case AlgorithmAdd:
+			_, r2 := committers[gate.In2].GetDecommitMsg()
+			commitments[i], err = committers[i].GetCommitMsgWithGivenR(w,
+				new(big.Int).Add(r1, r2))
+		case AlgorithmMul:
+			commitments[i], err = committers[i].GetCommitMsg(w)
+			provers = append(provers, NewMultiplicationProver(committers[gate.In1],
+				committers[gate.In2], committers[i], challengeSpaceSize))
+		case AlgorithmAddConst:
+			commitments[i], err = committers[i].GetCommitMsgWithGivenR(w, r1)
+		case AlgorithmMulConst:
+			commitments[i], err = committers[i].GetCommitMsgWithGivenR(w,
+				new(big.Int).Mul(r1, gate.Const))
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &AlgorithmProver{
+		algorithm:   algorithm,
+		committers:  committers,
+		commitments: commitments,
+		provers:     provers,
+	}, nil
+}
+
+// GetVerifierInitializationData returns the commitments to all the wires. The first one
+// is the commitment to the seed and the last one the commitment to the output.
+func (p *AlgorithmProver) GetVerifierInitializationData() []*big.Int {
+	return p.commitments
+}
+
+// GetOutputCommitter returns the committer of the output of the algorithm.
+func (p *AlgorithmProver) GetOutputCommitter() *Committer {
+	return p.committers[len(p.committers)-1]
+}
+
+// GetProofRandomData returns (d1, d2, d3) for each multiplication gate.
+func (p *AlgorithmProver) GetProofRandomData() [][]*big.Int {
+	proofRandomData := make([][]*big.Int, len(p.provers))
+	for i, prover := range p.provers {
+		d1, d2, d3 := prover.GetProofRandomData()
+		proofRandomData[i] = []*big.Int{d1, d2, d3}
+	}
+	return proofRandomData
+}
+
+// GetProofData returns (u1, u, v1, v2, v3) for each multiplication gate.
+func (p *AlgorithmProver) GetProofData(challenge *big.Int) [][]*big.Int {
+	proofData := make([][]*big.Int, len(p.provers))
+	for i, prover := range p.provers {
+		u1, u, v1, v2, v3 := prover.GetProofData(challenge)
+		proofData[i] = []*big.Int{u1, u, v1, v2, v3}
+	}
+	return proofData
+}
+
+type AlgorithmVerifier struct {
+	verifiers []*MultiplicationVerifier
+	challenge *big.Int
+	// challengeSpaceSize is needed only if the verifier generates the challenge
+	challengeSpaceSize int
+}
+
+// NewAlgorithmVerifier checks that the wire commitments (obtained from
+// AlgorithmProver.GetVerifierInitializationData) end with the commitment stored in receiver
+// and that the commitments of addition gates and gates with constants are consistent
+// with the algorithm.
+func NewAlgorithmVerifier(receiver *Receiver, algorithm Algorithm, commitments []*big.Int,
+	challengeSpaceSize int) (*AlgorithmVerifier, error) {
+	if err := algorithm.check(); err != nil {
+		return nil, err
+	}
+	if len(commitments) != len(algorithm)+1 {
+		return nil, fmt.Errorf("the number of commitments does not match the algorithm")
+	}
+	if !common.ConstantTimeEq(commitments[len(algorithm)], receiver.Commitment) {
+		return nil, fmt.Errorf("the output commitment does not match the receiver commitment")
+	}
+
+	group := receiver.QRSpecialRSA
+	receivers := make([]*Receiver, len(commitments))
+	for i, c := range commitments {
+		receivers[i] = &Receiver{df: receiver.df, Commitment: c}
+	}
+
+	var verifiers []*MultiplicationVerifier
+	for i, gate := range algorithm {
+		c1 := commitments[gate.In1]
+		var check *big.Int
+		switch gate.Op {
+		case AlgorithmAdd:
+			check = group.Mul(c1, commitments[gate.In2])
+		case AlgorithmMul:
+			verifiers = append(verifiers, NewMultiplicationVerifier(receivers[gate.In1],
+				receivers[gate.In2], receivers[i+1], challengeSpaceSize))
+			continue
+		case AlgorithmAddConst:
+			check = group.Mul(c1, group.Exp(receiver.G, gate.Const))
+		case AlgorithmMulConst:
+			check = group.Exp(c1, gate.Const)
+		}
+		if !common.ConstantTimeEq(check, commitments[i+1]) {
+			return nil, fmt.Errorf("commitment of gate %d is not consistent with the algorithm", i)
+		}
+	}
+
+	return &AlgorithmVerifier{
+		verifiers:          verifiers,
+		challengeSpaceSize: challengeSpaceSize,
+	}, nil
+}
+
+func (v *AlgorithmVerifier) SetProofRandomData(proofRandomData [][]*big.Int) error {
+	if len(proofRandomData) != len(v.verifiers) {
+		return fmt.Errorf("the length of proofRandomData is not correct")
+	}
+	for i, d := range proofRandomData {
+		if len(d) != 3 {
+			return fmt.Errorf("the length of proofRandomData is not correct")
+		}
+		v.verifiers[i].SetProofRandomData(d[0], d[1], d[2])
+	}
+	return nil
+}
+
+func (v *AlgorithmVerifier) GetChallenge() *big.Int {
+	b := new(big.Int).Exp(big.NewInt(2), big.NewInt(int64(v.challengeSpaceSize)), nil)
+	challenge := common.GetRandomInt(b)
+	v.SetChallenge(challenge)
+	return challenge
+}
+
+// SetChallenge is used when Fiat-Shamir is used - when challenge is generated using hash by the prover.
+func (v *AlgorithmVerifier) SetChallenge(challenge *big.Int) {
+	v.challenge = challenge
+	for _, verifier := range v.verifiers {
+		verifier.SetChallenge(challenge)
+	}
+}
+
+func (v *AlgorithmVerifier) Verify(proofData [][]*big.Int) bool {
+	if len(proofData) != len(v.verifiers) {
+		return false
+	}
+	for i, d := range proofData {
+		if len(d) != 5 || !v.verifiers[i].Verify(d[0], d[1], d[2], d[3], d[4]) {
+			return false
+		}
+	}
+	return true
+}
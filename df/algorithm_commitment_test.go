@@ -0,0 +1,107 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package df
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/awsong/crypto/common"
+	"github.com/stretchr/testify/assert"
+)
+
+// getTestAlgorithm returns an algorithm which computes x = s^2 + 3*s + 7 from seed s.
+func getTestAlgorithm() Algorithm {
+	return Algorithm{
+		{Op: AlgorithmMul, In1: 0, In2: 0},                    // w1 = s^2
+		{Op: AlgorithmMulConst, In1: 0, Const: big.NewInt(3)}, // w2 = 3*s
+		{Op: AlgorithmAdd, In1: 1, In2: 2},                    // w3 = s^2 + 3*s
+		{Op: AlgorithmAddConst, In1: 3, Const: big.NewInt(7)}, // w4 = s^2 + 3*s + 7
+	}
+}
+
+// TestDFCommitmentAlgorithm demonstrates how to prove that the committed value was
+// derived from a committed seed using a given algorithm.
+func TestDFCommitmentAlgorithm(t *testing.T) {
+	receiver, err := NewReceiver(128, 80)
+	if err != nil {
+		t.Errorf("error in NewReceiver: %v", err)
+	}
+	// n^2 is used for T - but any other value can be used as well
+	T := new(big.Int).Mul(receiver.QRSpecialRSA.N, receiver.QRSpecialRSA.N)
+	committer := NewCommitter(receiver.QRSpecialRSA.N,
+		receiver.G, receiver.H, T, receiver.K)
+
+	algorithm := getTestAlgorithm()
+	seed := common.GetRandomInt(receiver.QRSpecialRSA.N)
+	challengeSpaceSize := 80
+	prover, err := NewAlgorithmProver(committer, algorithm, seed, challengeSpaceSize)
+	if err != nil {
+		t.Errorf("error in instantiating AlgorithmProver: %v", err)
+	}
+
+	x, _ := prover.GetOutputCommitter().GetDecommitMsg()
+	expected := new(big.Int).Mul(seed, seed)
+	expected.Add(expected, new(big.Int).Mul(big.NewInt(3), seed))
+	expected.Add(expected, big.NewInt(7))
+	assert.Equal(t, expected, x, "algorithm output is not correct")
+
+	commitments := prover.GetVerifierInitializationData()
+	receiver.SetCommitment(commitments[len(commitments)-1])
+	verifier, err := NewAlgorithmVerifier(receiver, algorithm, commitments, challengeSpaceSize)
+	if err != nil {
+		t.Errorf("error in instantiating AlgorithmVerifier: %v", err)
+	}
+
+	proofRandomData := prover.GetProofRandomData()
+	err = verifier.SetProofRandomData(proofRandomData)
+	if err != nil {
+		t.Errorf("error when calling SetProofRandomData: %v", err)
+	}
+	challenge := verifier.GetChallenge()
+	proved := verifier.Verify(prover.GetProofData(challenge))
+	assert.Equal(t, true, proved, "DamgardFujisaki algorithm proof failed.")
+
+	// commitments do not match a different algorithm
+	otherAlgorithm := getTestAlgorithm()
+	otherAlgorithm[3] = &AlgorithmGate{Op: AlgorithmAddConst, In1: 3, Const: big.NewInt(8)}
+	_, err = NewAlgorithmVerifier(receiver, otherAlgorithm, commitments, challengeSpaceSize)
+	assert.NotNil(t, err, "AlgorithmVerifier should fail for a different algorithm")
+
+	// the proof fails if the square was not computed correctly
+	wrongSeed := new(big.Int).Add(seed, big.NewInt(1))
+	wrongCommitter := NewCommitter(receiver.QRSpecialRSA.N,
+		receiver.G, receiver.H, T, receiver.K)
+	_, err = wrongCommitter.GetCommitMsg(new(big.Int).Mul(wrongSeed, wrongSeed))
+	if err != nil {
+		t.Errorf("error in computing commit msg: %v", err)
+	}
+	prover.provers[0] = NewMultiplicationProver(prover.committers[0], prover.committers[0],
+		wrongCommitter, challengeSpaceSize)
+	verifier, err = NewAlgorithmVerifier(receiver, algorithm, commitments, challengeSpaceSize)
+	if err != nil {
+		t.Errorf("error in instantiating AlgorithmVerifier: %v", err)
+	}
+	err = verifier.SetProofRandomData(prover.GetProofRandomData())
+	if err != nil {
+		t.Errorf("error when calling SetProofRandomData: %v", err)
+	}
+	challenge = verifier.GetChallenge()
+	proved = verifier.Verify(prover.GetProofData(challenge))
+	assert.Equal(t, false, proved, "DamgardFujisaki algorithm proof should fail for wrong square.")
+}
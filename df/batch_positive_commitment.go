@@ -0,0 +1,181 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package df
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/awsong/crypto/common"
+)
+
+// BatchPositiveProver proves that each of the given commitments hides a non-negative value.
+// Instead of running one PositiveProver protocol per value, the proofs are batched into
+// a single non-interactive proof: a single challenge is derived by hashing all
+// commitments and all proof random data together, and is used for all square proofs.
+type BatchPositiveProver struct {
+	positiveProvers    []*PositiveProver
+	commitments        []*big.Int
+	challengeSpaceSize int
+}
+
+// NewBatchPositiveProver returns BatchPositiveProver for the values xs, where xs[i] is
+// the value committed by committers[i].
+func NewBatchPositiveProver(committers []*Committer, xs []*big.Int,
+	challengeSpaceSize int) (*BatchPositiveProver, error) {
+	if len(committers) == 0 || len(committers) != len(xs) {
+		return nil, fmt.Errorf("the number of committers and values needs to be the same and positive")
+	}
+	positiveProvers := make([]*PositiveProver, len(xs))
+	commitments := make([]*big.Int, len(xs))
+	for i, x := range xs {
+		committedValue, r := committers[i].GetDecommitMsg()
+		if committedValue == nil || committedValue.Cmp(x) != 0 {
+			return nil, fmt.Errorf("value %d is not committed by the committer", i)
+		}
+		prover, err := NewPositiveProver(committers[i], x, r, challengeSpaceSize)
+		if err != nil {
+			return nil, fmt.Errorf("error in instantiating PositiveProver for value %d: %v", i, err)
+		}
+		positiveProvers[i] = prover
+		commitments[i] = committers[i].ComputeCommit(x, r)
+	}
+	return &BatchPositiveProver{
+		positiveProvers:    positiveProvers,
+		commitments:        commitments,
+		challengeSpaceSize: challengeSpaceSize,
+	}, nil
+}
+
+// GetVerifierInitializationData returns the small and big commitments of each
+// PositiveProver (see PositiveProver.GetVerifierInitializationData).
+func (p *BatchPositiveProver) GetVerifierInitializationData() ([][]*big.Int, [][]*big.Int) {
+	smallCommitments := make([][]*big.Int, len(p.positiveProvers))
+	bigCommitments := make([][]*big.Int, len(p.positiveProvers))
+	for i, prover := range p.positiveProvers {
+		smallCommitments[i], bigCommitments[i] = prover.GetVerifierInitializationData()
+	}
+	return smallCommitments, bigCommitments
+}
+
+type BatchPositiveProof struct {
+	ProofRandomData [][]*big.Int
+	ProofData       [][]*big.Int
+}
+
+// Prove returns the batched proof. The challenge is not part of the proof as
+// the verifier recomputes it.
+func (p *BatchPositiveProver) Prove() *BatchPositiveProof {
+	proofRandomData := make([][]*big.Int, len(p.positiveProvers))
+	for i, prover := range p.positiveProvers {
+		proofRandomData[i] = prover.GetProofRandomData()
+	}
+	smallCommitments, bigCommitments := p.GetVerifierInitializationData()
+	challenge := getBatchPositiveChallenge(p.commitments, smallCommitments, bigCommitments,
+		proofRandomData, p.challengeSpaceSize)
+
+	proofData := make([][]*big.Int, len(p.positiveProvers))
+	for i, prover := range p.positiveProvers {
+		proofData[i] = prover.GetProofData(getSameChallenges(challenge,
+			len(prover.squareProvers)))
+	}
+	return &BatchPositiveProof{
+		ProofRandomData: proofRandomData,
+		ProofData:       proofData,
+	}
+}
+
+// getBatchPositiveChallenge returns the hash of all commitments and proof random data,
+// reduced to the challenge space.
+func getBatchPositiveChallenge(commitments []*big.Int, smallCommitments, bigCommitments,
+	proofRandomData [][]*big.Int, challengeSpaceSize int) *big.Int {
+	numbers := append([]*big.Int{}, commitments...)
+	for i := range smallCommitments {
+		numbers = append(numbers, smallCommitments[i]...)
+		numbers = append(numbers, bigCommitments[i]...)
+		numbers = append(numbers, proofRandomData[i]...)
+	}
+	challenge := common.Hash(numbers...)
+	b := new(big.Int).Lsh(big.NewInt(1), uint(challengeSpaceSize))
+	return challenge.Mod(challenge, b)
+}
+
+func getSameChallenges(challenge *big.Int, n int) []*big.Int {
+	challenges := make([]*big.Int, n)
+	for i := range challenges {
+		challenges[i] = challenge
+	}
+	return challenges
+}
+
+type BatchPositiveVerifier struct {
+	positiveVerifiers  []*PositiveVerifier
+	commitments        []*big.Int
+	smallCommitments   [][]*big.Int
+	bigCommitments     [][]*big.Int
+	challengeSpaceSize int
+}
+
+// NewBatchPositiveVerifier returns BatchPositiveVerifier for the commitments stored in
+// receivers. smallCommitments and bigCommitments are obtained from
+// BatchPositiveProver.GetVerifierInitializationData.
+func NewBatchPositiveVerifier(receivers []*Receiver, smallCommitments,
+	bigCommitments [][]*big.Int, challengeSpaceSize int) (*BatchPositiveVerifier, error) {
+	if len(receivers) == 0 || len(receivers) != len(smallCommitments) ||
+		len(receivers) != len(bigCommitments) {
+		return nil, fmt.Errorf("the number of receivers and commitments needs to be the same and positive")
+	}
+	positiveVerifiers := make([]*PositiveVerifier, len(receivers))
+	commitments := make([]*big.Int, len(receivers))
+	for i, receiver := range receivers {
+		verifier, err := NewPositiveVerifier(receiver, receiver.Commitment,
+			smallCommitments[i], bigCommitments[i], challengeSpaceSize)
+		if err != nil {
+			return nil, fmt.Errorf("error in instantiating PositiveVerifier for value %d: %v", i, err)
+		}
+		positiveVerifiers[i] = verifier
+		commitments[i] = receiver.Commitment
+	}
+	return &BatchPositiveVerifier{
+		positiveVerifiers:  positiveVerifiers,
+		commitments:        commitments,
+		smallCommitments:   smallCommitments,
+		bigCommitments:     bigCommitments,
+		challengeSpaceSize: challengeSpaceSize,
+	}, nil
+}
+
+// Verify returns true if all the values are proved to be non-negative.
+func (v *BatchPositiveVerifier) Verify(proof *BatchPositiveProof) bool {
+	if len(proof.ProofRandomData) != len(v.positiveVerifiers) ||
+		len(proof.ProofData) != len(v.positiveVerifiers) {
+		return false
+	}
+	challenge := getBatchPositiveChallenge(v.commitments, v.smallCommitments,
+		v.bigCommitments, proof.ProofRandomData, v.challengeSpaceSize)
+	for i, verifier := range v.positiveVerifiers {
+		if err := verifier.SetProofRandomData(proof.ProofRandomData[i]); err != nil {
+			return false
+		}
+		verifier.SetChallenges(getSameChallenges(challenge, len(verifier.squareVerifiers)))
+		if !verifier.Verify(proof.ProofData[i]) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,118 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package df
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/awsong/crypto/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func getBatchPositiveCommitters(receiver *Receiver, T *big.Int,
+	xs []*big.Int) ([]*Committer, []*Receiver, error) {
+	committers := make([]*Committer, len(xs))
+	receivers := make([]*Receiver, len(xs))
+	for i, x := range xs {
+		committers[i] = NewCommitter(receiver.QRSpecialRSA.N, receiver.G, receiver.H, T,
+			receiver.K)
+		c, err := committers[i].GetCommitMsg(x)
+		if err != nil {
+			return nil, nil, err
+		}
+		receivers[i], err = NewReceiverFromParams(receiver.QRSpecialRSA.GetPrimes(),
+			receiver.G, receiver.H, receiver.K)
+		if err != nil {
+			return nil, nil, err
+		}
+		receivers[i].SetCommitment(c)
+	}
+	return committers, receivers, nil
+}
+
+// TestDFCommitmentBatchPositive demonstrates how to prove with a single proof that
+// several commitments hide non-negative numbers.
+func TestDFCommitmentBatchPositive(t *testing.T) {
+	receiver, err := NewReceiver(128, 80)
+	if err != nil {
+		t.Errorf("error in NewReceiver: %v", err)
+	}
+	// n^2 is used for T - but any other value can be used as well
+	T := new(big.Int).Mul(receiver.QRSpecialRSA.N, receiver.QRSpecialRSA.N)
+	xs := make([]*big.Int, 3)
+	for i := range xs {
+		xs[i] = common.GetRandomInt(receiver.QRSpecialRSA.N)
+	}
+	committers, receivers, err := getBatchPositiveCommitters(receiver, T, xs)
+	if err != nil {
+		t.Errorf("error in computing commit msg: %v", err)
+	}
+
+	challengeSpaceSize := 80
+	prover, err := NewBatchPositiveProver(committers, xs, challengeSpaceSize)
+	if err != nil {
+		t.Errorf("error in instantiating BatchPositiveProver: %v", err)
+	}
+	smallCommitments, bigCommitments := prover.GetVerifierInitializationData()
+	verifier, err := NewBatchPositiveVerifier(receivers, smallCommitments, bigCommitments,
+		challengeSpaceSize)
+	if err != nil {
+		t.Errorf("error in instantiating BatchPositiveVerifier: %v", err)
+	}
+	proof := prover.Prove()
+	assert.Equal(t, true, verifier.Verify(proof), "DamgardFujisaki batch positive proof failed.")
+
+	proof.ProofData[1], proof.ProofData[2] = proof.ProofData[2], proof.ProofData[1]
+	assert.Equal(t, false, verifier.Verify(proof),
+		"DamgardFujisaki batch positive proof should fail for the modified proof data")
+}
+
+func TestDFCommitmentBatchPositiveNegativeValue(t *testing.T) {
+	receiver, err := NewReceiver(128, 80)
+	if err != nil {
+		t.Errorf("error in NewReceiver: %v", err)
+	}
+	T := new(big.Int).Mul(receiver.QRSpecialRSA.N, receiver.QRSpecialRSA.N)
+	xs := make([]*big.Int, 3)
+	for i := range xs {
+		xs[i] = common.GetRandomInt(receiver.QRSpecialRSA.N)
+	}
+	negative := new(big.Int).Neg(xs[1])
+	committers, receivers, err := getBatchPositiveCommitters(receiver, T,
+		[]*big.Int{xs[0], negative, xs[2]})
+	if err != nil {
+		t.Errorf("error in computing commit msg: %v", err)
+	}
+
+	_, err = NewBatchPositiveProver(committers, []*big.Int{xs[0], negative, xs[2]}, 80)
+	assert.NotNil(t, err, "BatchPositiveProver should fail for a negative value")
+
+	// the prover proves positive values, but the second commitment hides a negative value
+	honestCommitters, _, err := getBatchPositiveCommitters(receiver, T, xs)
+	if err != nil {
+		t.Errorf("error in computing commit msg: %v", err)
+	}
+	prover, err := NewBatchPositiveProver(honestCommitters, xs, 80)
+	if err != nil {
+		t.Errorf("error in instantiating BatchPositiveProver: %v", err)
+	}
+	smallCommitments, bigCommitments := prover.GetVerifierInitializationData()
+	_, err = NewBatchPositiveVerifier(receivers, smallCommitments, bigCommitments, 80)
+	assert.NotNil(t, err, "BatchPositiveVerifier should fail for a negative value")
+}
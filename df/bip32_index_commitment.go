@@ -0,0 +1,82 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package df
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// BIP32IndexProver proves that the commitment c = g^i * h^r hides a valid BIP32 child key
+// index i: i from [0, 2^31) for non-hardened keys and i from [2^31, 2^32) for hardened keys.
+// The range is proved using CompoundPredicateProver for the predicate i >= a AND i <= b.
+type BIP32IndexProver struct {
+	*CompoundPredicateProver
+}
+
+// NewBIP32IndexProver returns BIP32IndexProver. It returns an error if index is not
+// the value committed in committer or if index is not in the range given by hardened.
+func NewBIP32IndexProver(committer *Committer, index uint32, hardened bool,
+	challengeSpaceSize int) (*BIP32IndexProver, error) {
+	committedValue, _ := committer.GetDecommitMsg()
+	if committedValue == nil || !committedValue.IsUint64() ||
+		committedValue.Uint64() != uint64(index) {
+		return nil, fmt.Errorf("index is not the committed value")
+	}
+	if (index >= 1<<31) != hardened {
+		return nil, fmt.Errorf("index is not in the range for hardened = %v", hardened)
+	}
+	prover, err := NewCompoundPredicateProver(committer, getBIP32IndexPredicate(hardened),
+		challengeSpaceSize)
+	if err != nil {
+		return nil, err
+	}
+	return &BIP32IndexProver{
+		CompoundPredicateProver: prover,
+	}, nil
+}
+
+// getBIP32IndexPredicate returns the predicate i >= 2^31 AND i <= 2^32 - 1 for hardened
+// keys and i >= 0 AND i <= 2^31 - 1 for non-hardened keys.
+func getBIP32IndexPredicate(hardened bool) *Predicate {
+	a := big.NewInt(0)
+	b := big.NewInt(1<<31 - 1)
+	if hardened {
+		a = big.NewInt(1 << 31)
+		b = big.NewInt(1<<32 - 1)
+	}
+	return NewAndPredicate(NewGePredicate(a), NewLePredicate(b))
+}
+
+type BIP32IndexVerifier struct {
+	*CompoundPredicateVerifier
+}
+
+// NewBIP32IndexVerifier returns BIP32IndexVerifier for the commitment stored in receiver.
+// T is the bound for the committed values (as in Committer).
+func NewBIP32IndexVerifier(receiver *Receiver, hardened bool, T *big.Int,
+	challengeSpaceSize int) (*BIP32IndexVerifier, error) {
+	verifier, err := NewCompoundPredicateVerifier(receiver, getBIP32IndexPredicate(hardened), T,
+		challengeSpaceSize)
+	if err != nil {
+		return nil, err
+	}
+	return &BIP32IndexVerifier{
+		CompoundPredicateVerifier: verifier,
+	}, nil
+}
@@ -0,0 +1,85 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package df
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func proveBIP32Index(receiver *Receiver, T *big.Int, index uint32, proverHardened,
+	verifierHardened bool) (bool, error) {
+	committer, r, err := getCommitterAndReceiver(receiver, T, big.NewInt(int64(index)))
+	if err != nil {
+		return false, err
+	}
+	prover, err := NewBIP32IndexProver(committer, index, proverHardened, 80)
+	if err != nil {
+		return false, err
+	}
+	verifier, err := NewBIP32IndexVerifier(r, verifierHardened, T, 80)
+	if err != nil {
+		return false, err
+	}
+	if err := verifier.SetProofRandomData(prover.GetProofRandomData()); err != nil {
+		return false, err
+	}
+	challenges, proofData := prover.GetProofData(verifier.GetChallenge())
+	return verifier.Verify(challenges, proofData), nil
+}
+
+// TestDFCommitmentBIP32Index demonstrates how to prove that the committed value is a valid
+// BIP32 child key index.
+func TestDFCommitmentBIP32Index(t *testing.T) {
+	receiver, err := NewReceiver(128, 80)
+	if err != nil {
+		t.Errorf("error in NewReceiver: %v", err)
+	}
+	// n^2 is used for T - but any other value can be used as well
+	T := new(big.Int).Mul(receiver.QRSpecialRSA.N, receiver.QRSpecialRSA.N)
+
+	for _, test := range []struct {
+		index    uint32
+		hardened bool
+	}{
+		{0, false},
+		{44, false},
+		{1<<31 - 1, false},
+		{1 << 31, true},
+		{1<<31 + 44, true},
+		{1<<32 - 1, true},
+	} {
+		proved, err := proveBIP32Index(receiver, T, test.index, test.hardened, test.hardened)
+		if err != nil {
+			t.Errorf("error in BIP32 index proof: %v", err)
+		}
+		assert.Equal(t, true, proved, "DamgardFujisaki BIP32 index proof failed.")
+	}
+
+	// hardened index 2^31 is not in the non-hardened range
+	_, err = proveBIP32Index(receiver, T, 1<<31, false, false)
+	assert.NotNil(t, err, "BIP32IndexProver should fail for a hardened index")
+	proved, err := proveBIP32Index(receiver, T, 1<<31, true, false)
+	if err != nil {
+		t.Errorf("error in BIP32 index proof: %v", err)
+	}
+	assert.Equal(t, false, proved,
+		"DamgardFujisaki BIP32 index proof should fail for the non-hardened range")
+}
@@ -0,0 +1,140 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package df
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/awsong/crypto/common"
+)
+
+// BloomFilterPositions returns k positions of x in a Bloom filter of m bits:
+// position_j = hash(j, x) mod m for j = 1, ..., k.
+func BloomFilterPositions(x *big.Int, k, m int) []int {
+	positions := make([]int, k)
+	for j := 0; j < k; j++ {
+		h := common.Hash(big.NewInt(int64(j+1)), x)
+		positions[j] = int(h.Mod(h, big.NewInt(int64(m))).Int64())
+	}
+	return positions
+}
+
+// BloomFilterAdd sets the k positions of x in filter (bit i of the filter is
+// bit i%8 of filter[i/8]).
+func BloomFilterAdd(filter []byte, x *big.Int, k int) {
+	for _, pos := range BloomFilterPositions(x, k, 8*len(filter)) {
+		filter[pos/8] |= 1 << uint(pos%8)
+	}
+}
+
+// BloomFilterContains returns true if all k positions of x are set in filter.
+func BloomFilterContains(filter []byte, x *big.Int, k int) bool {
+	for _, pos := range BloomFilterPositions(x, k, 8*len(filter)) {
+		if filter[pos/8]&(1<<uint(pos%8)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// BloomFilterMembershipProver proves that the commitment c = g^x * h^r hides x which is
+// contained in a public Bloom filter (all k positions of x are set). Proving the evaluation
+// of hash functions on the committed value is not possible with the proofs in this package,
+// thus the values are taken from a public universe (for example all the possible identifiers):
+// both parties compute the set S of the values from the universe that are contained in
+// the filter and the prover proves x = s_1 OR ... OR x = s_m for s_i from S (using
+// CompoundPredicateProver). The proof is linear in the size of S.
+type BloomFilterMembershipProver struct {
+	*CompoundPredicateProver
+}
+
+// NewBloomFilterMembershipProver returns BloomFilterMembershipProver for the value committed
+// in committer. It returns an error if the committed value is not in universe or is not
+// contained in the filter.
+func NewBloomFilterMembershipProver(committer *Committer, filter []byte, k int,
+	universe []*big.Int, challengeSpaceSize int) (*BloomFilterMembershipProver, error) {
+	x, _ := committer.GetDecommitMsg()
+	if x == nil {
+		return nil, fmt.Errorf("committer needs to hold a commitment")
+	}
+	inUniverse := false
+	for _, u := range universe {
+		if u.Cmp(x) == 0 {
+			inUniverse = true
+			break
+		}
+	}
+	if !inUniverse {
+		return nil, fmt.Errorf("committed value is not in the universe")
+	}
+	if !BloomFilterContains(filter, x, k) {
+		return nil, fmt.Errorf("committed value is not contained in the filter")
+	}
+
+	predicate, err := getBloomFilterPredicate(filter, k, universe)
+	if err != nil {
+		return nil, err
+	}
+	prover, err := NewCompoundPredicateProver(committer, predicate, challengeSpaceSize)
+	if err != nil {
+		return nil, err
+	}
+	return &BloomFilterMembershipProver{prover}, nil
+}
+
+// getBloomFilterPredicate returns the predicate x = s_1 OR ... OR x = s_m where s_i are
+// the values from universe that are contained in the filter.
+func getBloomFilterPredicate(filter []byte, k int, universe []*big.Int) (*Predicate, error) {
+	if len(filter) == 0 || k < 1 {
+		return nil, fmt.Errorf("filter must not be empty and k must be positive")
+	}
+	var members []*Predicate
+	seen := make(map[string]bool)
+	for _, u := range universe {
+		if seen[u.String()] || !BloomFilterContains(filter, u, k) {
+			continue
+		}
+		seen[u.String()] = true
+		members = append(members, NewEqPredicate(u))
+	}
+	if len(members) == 0 {
+		return nil, fmt.Errorf("no value from the universe is contained in the filter")
+	}
+	return NewOrPredicate(members...), nil
+}
+
+type BloomFilterMembershipVerifier struct {
+	*CompoundPredicateVerifier
+}
+
+// NewBloomFilterMembershipVerifier returns BloomFilterMembershipVerifier for the commitment
+// stored in receiver. T is the bound for the committed values (as in Committer).
+func NewBloomFilterMembershipVerifier(receiver *Receiver, filter []byte, k int,
+	universe []*big.Int, T *big.Int,
+	challengeSpaceSize int) (*BloomFilterMembershipVerifier, error) {
+	predicate, err := getBloomFilterPredicate(filter, k, universe)
+	if err != nil {
+		return nil, err
+	}
+	verifier, err := NewCompoundPredicateVerifier(receiver, predicate, T, challengeSpaceSize)
+	if err != nil {
+		return nil, err
+	}
+	return &BloomFilterMembershipVerifier{verifier}, nil
+}
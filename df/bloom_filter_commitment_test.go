@@ -0,0 +1,88 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package df
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func proveBloomFilterMembership(receiver *Receiver, T, x *big.Int, filter []byte, k int,
+	universe []*big.Int) (bool, error) {
+	committer, r, err := getCommitterAndReceiver(receiver, T, x)
+	if err != nil {
+		return false, err
+	}
+	prover, err := NewBloomFilterMembershipProver(committer, filter, k, universe, 80)
+	if err != nil {
+		return false, err
+	}
+	verifier, err := NewBloomFilterMembershipVerifier(r, filter, k, universe, T, 80)
+	if err != nil {
+		return false, err
+	}
+	if err := verifier.SetProofRandomData(prover.GetProofRandomData()); err != nil {
+		return false, err
+	}
+	challenges, proofData := prover.GetProofData(verifier.GetChallenge())
+	return verifier.Verify(challenges, proofData), nil
+}
+
+// TestDFCommitmentBloomFilter demonstrates how to prove that the committed value
+// is contained in a Bloom filter (8 bits, k = 2) over the universe [0, 20).
+func TestDFCommitmentBloomFilter(t *testing.T) {
+	receiver, err := NewReceiver(128, 80)
+	if err != nil {
+		t.Errorf("error in NewReceiver: %v", err)
+	}
+	// n^2 is used for T - but any other value can be used as well
+	T := new(big.Int).Mul(receiver.QRSpecialRSA.N, receiver.QRSpecialRSA.N)
+	k := 2
+
+	universe := make([]*big.Int, 20)
+	for i := range universe {
+		universe[i] = big.NewInt(int64(i))
+	}
+	filter := make([]byte, 1)
+	BloomFilterAdd(filter, big.NewInt(3), k)
+	BloomFilterAdd(filter, big.NewInt(11), k)
+
+	for _, x := range []int64{3, 11} {
+		proved, err := proveBloomFilterMembership(receiver, T, big.NewInt(x), filter, k,
+			universe)
+		if err != nil {
+			t.Errorf("error in Bloom filter membership proof: %v", err)
+		}
+		assert.Equal(t, true, proved, "DamgardFujisaki Bloom filter proof failed for %d.", x)
+	}
+
+	// a value from the universe that is not contained in the filter
+	for _, u := range universe {
+		if !BloomFilterContains(filter, u, k) {
+			_, err = proveBloomFilterMembership(receiver, T, u, filter, k, universe)
+			assert.NotNil(t, err, "BloomFilterMembershipProver should fail for %v", u)
+			break
+		}
+	}
+
+	// a value that is not in the universe
+	_, err = proveBloomFilterMembership(receiver, T, big.NewInt(3), filter, k, universe[4:])
+	assert.NotNil(t, err, "BloomFilterMembershipProver should fail for a value outside the universe")
+}
@@ -0,0 +1,155 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package df
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/awsong/crypto/common"
+)
+
+// CircularConstraintProver proves that the values x1 and x2 hidden in commitments
+// c1 = g^x1 * h^r1 and c2 = g^x2 * h^r2 satisfy (x1 + x2) mod m = v for public m and v.
+// Prover commits to the quotient q = (x1 + x2 - v) / m, cQ = g^q * h^rQ, and proves
+// x1 + x2 = q*m + v, that is the knowledge of q, rQ and r1 + r2 such that
+// cQ = g^q * h^rQ and c1 * c2 * g^(-v) = (g^m)^q * h^(r1 + r2). The sum is computed
+// homomorphically and the multiplication by the public m is an exponentiation, thus
+// AdditionProver and MultiplicationProver are not needed.
+type CircularConstraintProver struct {
+	statement  *linRep
+	commitment *big.Int // cQ
+}
+
+func NewCircularConstraintProver(committer1, committer2 *Committer, m, v *big.Int,
+	challengeSpaceSize int) (*CircularConstraintProver, error) {
+	if err := checkCircularConstraintParams(m, v); err != nil {
+		return nil, err
+	}
+	x1, r1 := committer1.GetDecommitMsg()
+	x2, r2 := committer2.GetDecommitMsg()
+	sum := new(big.Int).Add(x1, x2)
+	if new(big.Int).Mod(sum, m).Cmp(v) != 0 {
+		return nil, fmt.Errorf("(x1 + x2) mod m is not v")
+	}
+	q := sum.Sub(sum, v)
+	q.Quo(q, m)
+
+	committerQ := NewCommitter(committer1.QRSpecialRSA.N, committer1.G, committer1.H,
+		committer1.T, committer1.K)
+	cQ, err := committerQ.GetCommitMsg(q)
+	if err != nil {
+		return nil, err
+	}
+	_, rQ := committerQ.GetDecommitMsg()
+
+	statement := getCircularConstraintStatement(&committer1.df,
+		committer1.ComputeCommit(x1, r1), committer2.ComputeCommit(x2, r2), m, v, cQ,
+		getPredicateRandomnessBound(&committer1.df, committer1.T, challengeSpaceSize))
+	statement.witnesses = []*big.Int{q, rQ, new(big.Int).Add(r1, r2)}
+	return &CircularConstraintProver{
+		statement:  statement,
+		commitment: cQ,
+	}, nil
+}
+
+// checkCircularConstraintParams returns an error if m is not positive or v is not in [0, m).
+func checkCircularConstraintParams(m, v *big.Int) error {
+	if m.Sign() <= 0 {
+		return fmt.Errorf("m needs to be positive")
+	}
+	if v.Sign() < 0 || v.Cmp(m) >= 0 {
+		return fmt.Errorf("v needs to be in [0, m)")
+	}
+	return nil
+}
+
+// getCircularConstraintStatement returns the statement cQ = g^q * h^rQ and
+// c1 * c2 * g^(-v) = (g^m)^q * h^(r1 + r2) (see linRep).
+func getCircularConstraintStatement(d *df, c1, c2, m, v, cQ, bound *big.Int) *linRep {
+	group := d.QRSpecialRSA
+	y := group.Mul(group.Mul(c1, c2), group.Inv(group.Exp(d.G, v)))
+	return &linRep{
+		group:     group,
+		y:         []*big.Int{cQ, y},
+		equations: [][]linRepTerm{{{d.G, 0}, {d.H, 1}}, {{group.Exp(d.G, m), 0}, {d.H, 2}}},
+		bound:     bound,
+	}
+}
+
+// GetVerifierInitializationData returns data that are needed by CircularConstraintVerifier
+// and are known only after the initialization of CircularConstraintProver: the commitment
+// to the quotient.
+func (p *CircularConstraintProver) GetVerifierInitializationData() *big.Int {
+	return p.commitment
+}
+
+func (p *CircularConstraintProver) GetProofRandomData() []*big.Int {
+	return p.statement.getProofRandomData()
+}
+
+func (p *CircularConstraintProver) GetProofData(challenge *big.Int) []*big.Int {
+	return p.statement.getProofData(challenge)
+}
+
+type CircularConstraintVerifier struct {
+	statement          *linRep
+	challengeSpaceSize int
+	proofRandomData    []*big.Int
+	challenge          *big.Int
+}
+
+// NewCircularConstraintVerifier returns CircularConstraintVerifier for the commitments stored
+// in receiver1 and receiver2. The commitment cQ is obtained from
+// CircularConstraintProver.GetVerifierInitializationData and T is the bound for
+// the committed values (as in Committer).
+func NewCircularConstraintVerifier(receiver1, receiver2 *Receiver, m, v, cQ, T *big.Int,
+	challengeSpaceSize int) (*CircularConstraintVerifier, error) {
+	if err := checkCircularConstraintParams(m, v); err != nil {
+		return nil, err
+	}
+	return &CircularConstraintVerifier{
+		statement: getCircularConstraintStatement(&receiver1.df, receiver1.Commitment,
+			receiver2.Commitment, m, v, cQ,
+			getPredicateRandomnessBound(&receiver1.df, T, challengeSpaceSize)),
+		challengeSpaceSize: challengeSpaceSize,
+	}, nil
+}
+
+func (v *CircularConstraintVerifier) SetProofRandomData(proofRandomData []*big.Int) error {
+	if len(proofRandomData) != 2 {
+		return fmt.Errorf("the length of proofRandomData is not correct")
+	}
+	v.proofRandomData = proofRandomData
+	return nil
+}
+
+func (v *CircularConstraintVerifier) GetChallenge() *big.Int {
+	b := new(big.Int).Lsh(big.NewInt(1), uint(v.challengeSpaceSize))
+	v.challenge = common.GetRandomInt(b)
+	return v.challenge
+}
+
+// SetChallenge is used when Fiat-Shamir is used - when challenge is generated using hash by the prover.
+func (v *CircularConstraintVerifier) SetChallenge(challenge *big.Int) {
+	v.challenge = challenge
+}
+
+func (v *CircularConstraintVerifier) Verify(proofData []*big.Int) bool {
+	return v.statement.verify(v.challenge, v.proofRandomData, proofData)
+}
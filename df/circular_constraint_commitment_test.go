@@ -0,0 +1,105 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package df
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func proveCircularConstraint(receiver *Receiver, T *big.Int, x1, x2, m, v,
+	verifierV *big.Int) (bool, error) {
+	challengeSpaceSize := 80
+	committers := make([]*Committer, 2)
+	receivers := make([]*Receiver, 2)
+	for i, x := range []*big.Int{x1, x2} {
+		committers[i] = NewCommitter(receiver.QRSpecialRSA.N, receiver.G, receiver.H, T,
+			receiver.K)
+		c, err := committers[i].GetCommitMsg(x)
+		if err != nil {
+			return false, err
+		}
+		receivers[i], err = NewReceiverFromParams(receiver.QRSpecialRSA.GetPrimes(),
+			receiver.G, receiver.H, receiver.K)
+		if err != nil {
+			return false, err
+		}
+		receivers[i].SetCommitment(c)
+	}
+
+	prover, err := NewCircularConstraintProver(committers[0], committers[1], m, v,
+		challengeSpaceSize)
+	if err != nil {
+		return false, err
+	}
+	verifier, err := NewCircularConstraintVerifier(receivers[0], receivers[1], m, verifierV,
+		prover.GetVerifierInitializationData(), T, challengeSpaceSize)
+	if err != nil {
+		return false, err
+	}
+	err = verifier.SetProofRandomData(prover.GetProofRandomData())
+	if err != nil {
+		return false, err
+	}
+	challenge := verifier.GetChallenge()
+	return verifier.Verify(prover.GetProofData(challenge)), nil
+}
+
+// TestDFCommitmentCircularConstraint demonstrates how to prove that for the committed
+// x1 and x2 it holds (x1 + x2) mod m = v.
+func TestDFCommitmentCircularConstraint(t *testing.T) {
+	receiver, err := NewReceiver(128, 80)
+	if err != nil {
+		t.Errorf("error in NewReceiver: %v", err)
+	}
+	// n^2 is used for T - but any other value can be used as well
+	T := new(big.Int).Mul(receiver.QRSpecialRSA.N, receiver.QRSpecialRSA.N)
+
+	tests := []struct {
+		x1, x2, m, v int64
+	}{
+		{5, 9, 12, 2},           // 14 mod 12
+		{22, 2, 24, 0},          // 24 mod 24
+		{3, 4, 10, 7},           // 7 mod 10 (q = 0)
+		{-5, 1, 7, 3},           // -4 mod 7 (negative quotient)
+		{1000003, 77, 365, 345}, // 1000080 mod 365
+	}
+	for _, test := range tests {
+		m, v := big.NewInt(test.m), big.NewInt(test.v)
+		proved, err := proveCircularConstraint(receiver, T, big.NewInt(test.x1),
+			big.NewInt(test.x2), m, v, v)
+		if err != nil {
+			t.Errorf("error in circular constraint proof: %v", err)
+		}
+		assert.Equal(t, true, proved, "DamgardFujisaki circular constraint proof failed.")
+	}
+
+	_, err = proveCircularConstraint(receiver, T, big.NewInt(5), big.NewInt(9), big.NewInt(12),
+		big.NewInt(3), big.NewInt(3))
+	assert.NotNil(t, err, "CircularConstraintProver should fail for a wrong v")
+
+	proved, err := proveCircularConstraint(receiver, T, big.NewInt(5), big.NewInt(9),
+		big.NewInt(12), big.NewInt(2), big.NewInt(3))
+	if err != nil {
+		t.Errorf("error in circular constraint proof: %v", err)
+	}
+	assert.Equal(t, false, proved,
+		"DamgardFujisaki circular constraint proof should fail for a different v")
+}
@@ -18,6 +18,8 @@
 package df
 
 import (
+	"bytes"
+	"context"
 	"fmt"
 	"math/big"
 
@@ -52,12 +54,28 @@ func (df *df) ComputeCommit(a, r *big.Int) *big.Int {
 	return c
 }
 
+// ParamsFingerprint returns a hash of the commitment parameters (N, G, H). It is used to
+// check that a commitment was computed with the same parameters as the ones it is verified with.
+func (df *df) ParamsFingerprint() []byte {
+	return common.HashIntoBytes(df.QRSpecialRSA.N, df.G, df.H)
+}
+
+// Commitment is a commitment value bound to the parameters it was computed with. Using
+// Commitment instead of *big.Int prevents passing a commitment to a Receiver with
+// different parameters.
+type Commitment struct {
+	Value             *big.Int
+	ParamsFingerprint []byte
+}
+
 type Committer struct {
 	df
 	B              int      // 2^B is upper bound estimation for group order, it can be len(RSASpecial.N) - 2
 	T              *big.Int // we can commit to values between -T and T
 	committedValue *big.Int
 	r              *big.Int
+	gTable         *fixedBaseTable // see PrecomputeFixedBase
+	hTable         *fixedBaseTable
 }
 
 // TODO: switch h and g
@@ -72,6 +90,95 @@ func NewCommitter(n, g, h, t *big.Int, k int) *Committer {
 		T: t}
 }
 
+// minModulusBitLen is the minimal bit length of the RSA special modulus accepted
+// by NewCommitterWithBitLen.
+const minModulusBitLen = 1024
+
+// NewCommitterWithBitLen generates new parameters (RSA special modulus of bitLen bits and
+// generators G, H) and returns a Committer which can commit to values in (-2^T, 2^T). K is security
+// parameter as in NewReceiver. It returns an error if bitLen < 1024 (for 128-bit security
+// bitLen should be at least 3072). The primes are not stored - note that the binding property
+// relies on the committer not knowing the factorization of the modulus, thus the parameters need
+// to be generated by a trusted party. The receiver is obtained from the public parameters
+// (committer.QRSpecialRSA.N, committer.G, committer.H) by NewReceiverFromPublicParams.
+// When the receiver generates the parameters, NewReceiver and NewCommitter should be used instead.
+func NewCommitterWithBitLen(bitLen, T, K int) (*Committer, error) {
+	if bitLen < minModulusBitLen {
+		return nil, fmt.Errorf("modulus bit length needs to be at least %d", minModulusBitLen)
+	}
+	if T < 1 {
+		return nil, fmt.Errorf("T needs to be positive")
+	}
+	receiver, err := NewReceiver(bitLen/2, K)
+	if err != nil {
+		return nil, err
+	}
+	t := new(big.Int).Exp(big.NewInt(2), big.NewInt(int64(T)), nil)
+
+	return NewCommitter(receiver.QRSpecialRSA.N, receiver.G, receiver.H, t, K), nil
+}
+
+// NewCommitterWithContext works as NewCommitterWithBitLen, but the generation of the
+// safe primes can be aborted using ctx (ctx.Err() is returned in this case). If progress
+// is not nil, the messages "generating p", "generating q" and "computing n" are sent to it
+// as the generation proceeds. Sending blocks until the message is received or ctx is done,
+// thus the caller needs to read from progress (or use a buffered channel). As for
+// NewCommitterWithBitLen, the receiver is obtained by NewReceiverFromPublicParams.
+func NewCommitterWithContext(ctx context.Context, bitLen, T, K int,
+	progress chan<- string) (*Committer, error) {
+	if bitLen < minModulusBitLen {
+		return nil, fmt.Errorf("modulus bit length needs to be at least %d", minModulusBitLen)
+	}
+	if T < 1 {
+		return nil, fmt.Errorf("T needs to be positive")
+	}
+	report := func(msg string) error {
+		if progress == nil {
+			return ctx.Err()
+		}
+		select {
+		case progress <- msg:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	if err := report("generating p"); err != nil {
+		return nil, err
+	}
+	p, err := common.GenerateSafePrimeWithContext(ctx, bitLen/2)
+	if err != nil {
+		return nil, err
+	}
+	if err := report("generating q"); err != nil {
+		return nil, err
+	}
+	var q *big.Int
+	for q == nil || q.Cmp(p) == 0 {
+		q, err = common.GenerateSafePrimeWithContext(ctx, bitLen/2)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err := report("computing n"); err != nil {
+		return nil, err
+	}
+	p1 := new(big.Int).Rsh(p, 1) // (p-1)/2
+	q1 := new(big.Int).Rsh(q, 1)
+	group, err := qr.NewRSASpecialFromParams(qr.NewRSASpecialPrimes(p, q, p1, q1))
+	if err != nil {
+		return nil, err
+	}
+	receiver, err := newReceiverFromGroup(group, K)
+	if err != nil {
+		return nil, err
+	}
+	t := new(big.Int).Exp(big.NewInt(2), big.NewInt(int64(T)), nil)
+
+	return NewCommitter(receiver.QRSpecialRSA.N, receiver.G, receiver.H, t, K), nil
+}
+
 // TODO: the naming is not OK because it also sets committer.committedValue and committer.r
 func (c *Committer) GetCommitMsg(a *big.Int) (*big.Int, error) {
 	abs := new(big.Int).Abs(a)
@@ -83,7 +190,7 @@ func (c *Committer) GetCommitMsg(a *big.Int) (*big.Int, error) {
 	exp := big.NewInt(int64(c.B + c.K))
 	boundary := new(big.Int).Exp(big.NewInt(2), exp, nil)
 	r := common.GetRandomInt(boundary)
-	commitment := c.ComputeCommit(a, r)
+	commitment := c.ComputeCommitFast(a, r)
 
 	c.committedValue = a
 	c.r = r
@@ -96,7 +203,7 @@ func (c *Committer) GetCommitMsgWithGivenR(a, r *big.Int) (*big.Int, error) {
 		return nil, fmt.Errorf("committed value needs to be in (-T, T)")
 	}
 	// c = G^a * H^r % group.N
-	commitment := c.ComputeCommit(a, r)
+	commitment := c.ComputeCommitFast(a, r)
 	c.committedValue = a
 	c.r = r
 	return commitment, nil
@@ -106,6 +213,52 @@ func (c *Committer) GetDecommitMsg() (*big.Int, *big.Int) {
 	return c.committedValue, c.r
 }
 
+// Commit returns the commitment G^x * H^r % group.N together with the fingerprint
+// of the committer's parameters. Like GetCommitMsgWithGivenR it sets the committed value and r.
+func (c *Committer) Commit(x, r *big.Int) (*Commitment, error) {
+	commitment, err := c.GetCommitMsgWithGivenR(x, r)
+	if err != nil {
+		return nil, err
+	}
+	return &Commitment{
+		Value:             commitment,
+		ParamsFingerprint: c.ParamsFingerprint(),
+	}, nil
+}
+
+// AddCommitments returns a new Committer which holds a commitment to a1 + a2 with
+// randomness r1 + r2, where (a1, r1) and (a2, r2) are the values committed by c1 and c2.
+// DF commitments are additively homomorphic, thus the commitment of the returned Committer
+// is the same as the one receiver obtains by CombineCommitments. The returned Committer can
+// be passed (together with its committed value and randomness) to NewPositiveProver to prove
+// that the sum is positive without revealing a1 and a2.
+func AddCommitments(c1, c2 *Committer) (*Committer, error) {
+	if c1.QRSpecialRSA.N.Cmp(c2.QRSpecialRSA.N) != 0 || c1.G.Cmp(c2.G) != 0 ||
+		c1.H.Cmp(c2.H) != 0 {
+		return nil, fmt.Errorf("committers need to use the same parameters")
+	}
+	if c1.committedValue == nil || c2.committedValue == nil {
+		return nil, fmt.Errorf("committers need to hold a commitment")
+	}
+
+	a := new(big.Int).Add(c1.committedValue, c2.committedValue)
+	r := new(big.Int).Add(c1.r, c2.r)
+	committer := NewCommitter(c1.QRSpecialRSA.N, c1.G, c1.H, c1.T, c1.K)
+	_, err := committer.GetCommitMsgWithGivenR(a, r)
+	if err != nil {
+		return nil, err
+	}
+
+	return committer, nil
+}
+
+// CombineCommitments returns c1Commitment * c2Commitment % n. This is a commitment to
+// a1 + a2 (with randomness r1 + r2) where c1Commitment = G^a1 * H^r1 and c2Commitment = G^a2 * H^r2.
+func CombineCommitments(c1Commitment, c2Commitment *big.Int, n *big.Int) *big.Int {
+	c := new(big.Int).Mul(c1Commitment, c2Commitment)
+	return c.Mod(c, n)
+}
+
 type Receiver struct {
 	df
 	Commitment *big.Int
@@ -120,7 +273,12 @@ func NewReceiver(safePrimeBitLength, k int) (*Receiver, error) {
 	if err != nil {
 		return nil, err
 	}
+	return newReceiverFromGroup(qr, k)
+}
 
+// newReceiverFromGroup chooses generators H and G = H^alpha of the given group and
+// returns a Receiver.
+func newReceiverFromGroup(qr *qr.RSASpecial, k int) (*Receiver, error) {
 	h, err := qr.GetRandomGenerator()
 	if err != nil {
 		return nil, err
@@ -142,7 +300,8 @@ func NewReceiver(safePrimeBitLength, k int) (*Receiver, error) {
 
 // NewReceiverFromParams returns an instance of a receiver with the
 // parameters as given by input. Different instances are needed because
-// each sets its own Commitment value.
+// each sets its own Commitment value. It returns an error if g or h is not
+// in QR_N or is 1 (see checkGroupElement).
 func NewReceiverFromParams(specialRSAPrimes *qr.RSASpecialPrimes, g, h *big.Int,
 	k int) (
 	*Receiver, error) {
@@ -150,6 +309,12 @@ func NewReceiverFromParams(specialRSAPrimes *qr.RSASpecialPrimes, g, h *big.Int,
 	if err != nil {
 		return nil, err
 	}
+	if err := checkGroupElement(group, g); err != nil {
+		return nil, fmt.Errorf("G is not valid: %v", err)
+	}
+	if err := checkGroupElement(group, h); err != nil {
+		return nil, fmt.Errorf("H is not valid: %v", err)
+	}
 
 	return &Receiver{df: df{
 		QRSpecialRSA: group,
@@ -159,15 +324,69 @@ func NewReceiverFromParams(specialRSAPrimes *qr.RSASpecialPrimes, g, h *big.Int,
 	}, nil
 }
 
+// NewReceiverFromPublicParams returns a receiver for the public parameters (N, G, H), for
+// example the ones of a Committer returned by NewCommitterWithBitLen. As the factorization
+// of N is not known, it cannot be checked that G and H are in QR_N - only that they are
+// units in (1, N). It returns an error otherwise.
+func NewReceiverFromPublicParams(n, g, h *big.Int, k int) (*Receiver, error) {
+	if err := checkPublicGroupElement(n, g); err != nil {
+		return nil, fmt.Errorf("G is not valid: %v", err)
+	}
+	if err := checkPublicGroupElement(n, h); err != nil {
+		return nil, fmt.Errorf("H is not valid: %v", err)
+	}
+
+	return &Receiver{df: df{
+		QRSpecialRSA: qr.NewRSApecialPublic(n),
+		G:            g,
+		H:            h,
+		K:            k},
+	}, nil
+}
+
+// checkPublicGroupElement returns an error if x is not in (1, N) or if gcd(x, N) != 1.
+func checkPublicGroupElement(n, x *big.Int) error {
+	if x == nil || x.Cmp(big.NewInt(1)) <= 0 || x.Cmp(n) >= 0 {
+		return fmt.Errorf("element needs to be in (1, N)")
+	}
+	if new(big.Int).GCD(nil, nil, x, n).Cmp(big.NewInt(1)) != 0 {
+		return fmt.Errorf("element is not in Z_N*")
+	}
+	return nil
+}
+
+// checkGroupElement returns an error if x is not in (1, N) or if x^order != 1 (mod N)
+// where order = P1 * Q1 is the order of QR_N (computed from the prime factors of N).
+// Since N is a product of safe primes, the elements of Z_N* whose order divides P1 * Q1
+// are exactly the elements of QR_N.
+func checkGroupElement(group *qr.RSASpecial, x *big.Int) error {
+	if x == nil || x.Cmp(big.NewInt(1)) <= 0 || x.Cmp(group.N) >= 0 {
+		return fmt.Errorf("element needs to be in (1, N)")
+	}
+	if group.Exp(x, group.Order).Cmp(big.NewInt(1)) != 0 {
+		return fmt.Errorf("element is not in QR_N")
+	}
+	return nil
+}
+
 // When receiver receives a commitment, it stores the value using SetCommitment method.
 func (r *Receiver) SetCommitment(c *big.Int) {
 	r.Commitment = c
 }
 
+// Verify returns true if c was computed with the receiver's parameters and
+// c = G^x * H^r % group.N.
+func (rc *Receiver) Verify(c *Commitment, x, r *big.Int) bool {
+	if !bytes.Equal(c.ParamsFingerprint, rc.ParamsFingerprint()) {
+		return false
+	}
+	return common.ConstantTimeEq(rc.ComputeCommit(x, r), c.Value)
+}
+
 func (r *Receiver) CheckDecommitment(R, a *big.Int) bool {
 	tmp1 := r.QRSpecialRSA.Exp(r.G, a)
 	tmp2 := r.QRSpecialRSA.Exp(r.H, R)
 	c := r.QRSpecialRSA.Mul(tmp1, tmp2)
 
-	return c.Cmp(r.Commitment) == 0
+	return common.ConstantTimeEq(c, r.Commitment)
 }
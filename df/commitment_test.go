@@ -18,7 +18,10 @@
 package df
 
 import (
+	"context"
+	"math/big"
 	"testing"
+	"time"
 
 	"github.com/awsong/crypto/common"
 	"github.com/stretchr/testify/assert"
@@ -47,3 +50,308 @@ func TestDFCommitment(t *testing.T) {
 
 	assert.Equal(t, true, success, "DamgardFujisaki commitment failed.")
 }
+
+// TestDFCommitmentAddition demonstrates how two commitments can be combined into
+// a commitment to the sum of committed values and how it can be then proved
+// that the sum is positive.
+func TestDFCommitmentAddition(t *testing.T) {
+	receiver, err := NewReceiver(128, 80)
+	if err != nil {
+		t.Errorf("Error in NewReceiver: %v", err)
+	}
+
+	// n^2 is used for T - but any other value can be used as well
+	T := new(big.Int).Mul(receiver.QRSpecialRSA.N, receiver.QRSpecialRSA.N)
+	committer1 := NewCommitter(receiver.QRSpecialRSA.N, receiver.G, receiver.H,
+		T, receiver.K)
+	committer2 := NewCommitter(receiver.QRSpecialRSA.N, receiver.G, receiver.H,
+		T, receiver.K)
+
+	a1 := common.GetRandomInt(receiver.QRSpecialRSA.N)
+	a2 := common.GetRandomInt(receiver.QRSpecialRSA.N)
+	c1, err := committer1.GetCommitMsg(a1)
+	if err != nil {
+		t.Errorf("Error in GetCommitMsg: %v", err)
+	}
+	c2, err := committer2.GetCommitMsg(a2)
+	if err != nil {
+		t.Errorf("Error in GetCommitMsg: %v", err)
+	}
+
+	committer, err := AddCommitments(committer1, committer2)
+	if err != nil {
+		t.Errorf("Error in AddCommitments: %v", err)
+	}
+	receiver.SetCommitment(CombineCommitments(c1, c2, receiver.QRSpecialRSA.N))
+
+	a, r := committer.GetDecommitMsg()
+	assert.Equal(t, new(big.Int).Add(a1, a2), a, "committed value is not the sum")
+	success := receiver.CheckDecommitment(r, a)
+	assert.Equal(t, true, success, "DamgardFujisaki commitment addition failed.")
+
+	challengeSpaceSize := 80
+	prover, err := NewPositiveProver(committer, a, r, challengeSpaceSize)
+	if err != nil {
+		t.Errorf("Error in instantiating PositiveProver: %v", err)
+	}
+	smallCommitments, bigCommitments := prover.GetVerifierInitializationData()
+	verifier, err := NewPositiveVerifier(receiver, receiver.Commitment,
+		smallCommitments, bigCommitments, challengeSpaceSize)
+	if err != nil {
+		t.Errorf("Error in instantiating PositiveVerifier: %v", err)
+	}
+
+	proofRandomData := prover.GetProofRandomData()
+	challenges := verifier.GetChallenges()
+	err = verifier.SetProofRandomData(proofRandomData)
+	if err != nil {
+		t.Errorf("Error when calling SetProofRandomData: %v", err)
+	}
+	proofData := prover.GetProofData(challenges)
+	proved := verifier.Verify(proofData)
+	assert.Equal(t, true, proved, "DamgardFujisaki positive proof of the sum failed.")
+}
+
+// TestDFCommitmentFingerprint demonstrates that commitments computed with one set of
+// parameters are not accepted by a receiver with different parameters.
+func TestDFCommitmentFingerprint(t *testing.T) {
+	receiver, err := NewReceiver(128, 80)
+	if err != nil {
+		t.Errorf("Error in NewReceiver: %v", err)
+	}
+	otherReceiver, err := NewReceiver(128, 80)
+	if err != nil {
+		t.Errorf("Error in NewReceiver: %v", err)
+	}
+
+	committer := NewCommitter(receiver.QRSpecialRSA.N, receiver.G, receiver.H,
+		receiver.QRSpecialRSA.N, receiver.K)
+	x := common.GetRandomInt(receiver.QRSpecialRSA.N)
+	r := common.GetRandomInt(receiver.QRSpecialRSA.N)
+	c, err := committer.Commit(x, r)
+	if err != nil {
+		t.Errorf("Error in Commit: %v", err)
+	}
+
+	assert.Equal(t, true, receiver.Verify(c, x, r), "commitment should be verified")
+	assert.Equal(t, false, receiver.Verify(c, new(big.Int).Add(x, big.NewInt(1)), r),
+		"commitment should not be verified for a different value")
+	assert.Equal(t, false, otherReceiver.Verify(c, x, r),
+		"commitment should not be verified by a receiver with different parameters")
+}
+
+func TestNewCommitterWithBitLen(t *testing.T) {
+	_, err := NewCommitterWithBitLen(512, 256, 80)
+	assert.NotNil(t, err, "NewCommitterWithBitLen should fail for a modulus shorter than 1024 bits")
+
+	committer, err := NewCommitterWithBitLen(1024, 256, 80)
+	if err != nil {
+		t.Errorf("Error in NewCommitterWithBitLen: %v", err)
+	}
+	assert.True(t, committer.QRSpecialRSA.N.BitLen() >= 1023, "modulus is too short")
+	assert.Equal(t, new(big.Int).Lsh(big.NewInt(1), 256), committer.T, "T is not 2^256")
+
+	// receiver needs only the public parameters
+	receiver, err := NewReceiverFromPublicParams(committer.QRSpecialRSA.N, committer.G,
+		committer.H, committer.K)
+	if err != nil {
+		t.Errorf("Error in NewReceiverFromPublicParams: %v", err)
+	}
+	a := common.GetRandomInt(committer.T)
+	c, err := committer.GetCommitMsg(a)
+	if err != nil {
+		t.Errorf("Error in GetCommitMsg: %v", err)
+	}
+	receiver.SetCommitment(c)
+	committedVal, r := committer.GetDecommitMsg()
+	assert.Equal(t, true, receiver.CheckDecommitment(r, committedVal),
+		"DamgardFujisaki commitment failed.")
+}
+
+func TestNewReceiverFromParams(t *testing.T) {
+	receiver, err := NewReceiver(128, 80)
+	if err != nil {
+		t.Errorf("error in NewReceiver: %v", err)
+	}
+	primes := receiver.QRSpecialRSA.GetPrimes()
+	n := receiver.QRSpecialRSA.N
+
+	_, err = NewReceiverFromParams(primes, receiver.G, receiver.H, receiver.K)
+	if err != nil {
+		t.Errorf("error in NewReceiverFromParams: %v", err)
+	}
+
+	// -1 is not a quadratic residue modulo N (it has order 2)
+	minusOne := new(big.Int).Sub(n, big.NewInt(1))
+	notQR := receiver.QRSpecialRSA.Mul(receiver.G, minusOne)
+	for _, g := range []*big.Int{big.NewInt(0), big.NewInt(1), minusOne, n, notQR} {
+		_, err = NewReceiverFromParams(primes, g, receiver.H, receiver.K)
+		assert.NotNil(t, err, "NewReceiverFromParams should fail for G = %v", g)
+		_, err = NewReceiverFromParams(primes, receiver.G, g, receiver.K)
+		assert.NotNil(t, err, "NewReceiverFromParams should fail for H = %v", g)
+	}
+}
+
+func TestNewReceiverFromPublicParams(t *testing.T) {
+	receiver, err := NewReceiver(128, 80)
+	if err != nil {
+		t.Errorf("error in NewReceiver: %v", err)
+	}
+	n := receiver.QRSpecialRSA.N
+
+	_, err = NewReceiverFromPublicParams(n, receiver.G, receiver.H, receiver.K)
+	if err != nil {
+		t.Errorf("error in NewReceiverFromPublicParams: %v", err)
+	}
+
+	for _, g := range []*big.Int{nil, big.NewInt(0), big.NewInt(1), n,
+		receiver.QRSpecialRSA.P} {
+		_, err = NewReceiverFromPublicParams(n, g, receiver.H, receiver.K)
+		assert.NotNil(t, err, "NewReceiverFromPublicParams should fail for G = %v", g)
+		_, err = NewReceiverFromPublicParams(n, receiver.G, g, receiver.K)
+		assert.NotNil(t, err, "NewReceiverFromPublicParams should fail for H = %v", g)
+	}
+}
+
+func TestNewCommitterWithContext(t *testing.T) {
+	progress := make(chan string, 3)
+	committer, err := NewCommitterWithContext(context.Background(), 1024, 256, 80, progress)
+	if err != nil {
+		t.Errorf("Error in NewCommitterWithContext: %v", err)
+	}
+	close(progress)
+	var msgs []string
+	for msg := range progress {
+		msgs = append(msgs, msg)
+	}
+	assert.Equal(t, []string{"generating p", "generating q", "computing n"}, msgs,
+		"progress messages are not correct")
+	assert.True(t, committer.QRSpecialRSA.N.BitLen() >= 1023, "modulus is too short")
+
+	receiver, err := NewReceiverFromPublicParams(committer.QRSpecialRSA.N, committer.G,
+		committer.H, committer.K)
+	if err != nil {
+		t.Errorf("Error in NewReceiverFromPublicParams: %v", err)
+	}
+	a := common.GetRandomInt(committer.T)
+	c, err := committer.GetCommitMsg(a)
+	if err != nil {
+		t.Errorf("Error in GetCommitMsg: %v", err)
+	}
+	receiver.SetCommitment(c)
+	committedVal, r := committer.GetDecommitMsg()
+	assert.Equal(t, true, receiver.CheckDecommitment(r, committedVal),
+		"DamgardFujisaki commitment failed.")
+
+	// nobody reads from progress, canceled context needs to unblock the generation
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = NewCommitterWithContext(ctx, 3072, 256, 80, make(chan string))
+	assert.Equal(t, context.Canceled, err, "NewCommitterWithContext should stop on cancel")
+
+	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = NewCommitterWithContext(ctx, 3072, 256, 80, nil)
+	assert.Equal(t, context.DeadlineExceeded, err,
+		"NewCommitterWithContext should stop when deadline is exceeded")
+
+	_, err = NewCommitterWithContext(context.Background(), 512, 256, 80, nil)
+	assert.NotNil(t, err, "NewCommitterWithContext should fail for a modulus shorter than 1024 bits")
+}
+
+// TestDFAtLargeSizes runs PositiveProver and MultiplicationProver with 1024, 2048 and
+// 3072-bit moduli. Moduli larger than 1024 bits are skipped in short mode as the generation
+// of safe primes takes long.
+func TestDFAtLargeSizes(t *testing.T) {
+	for _, bitLen := range []int{1024, 2048, 3072} {
+		if bitLen > 1024 && testing.Short() {
+			t.Logf("skipping %d-bit modulus in short mode", bitLen)
+			continue
+		}
+		receiver, err := NewReceiver(bitLen/2, 80)
+		if err != nil {
+			t.Errorf("Error in NewReceiver: %v", err)
+			continue
+		}
+		assert.True(t, receiver.QRSpecialRSA.N.BitLen() >= bitLen-1,
+			"modulus is shorter than %d bits", bitLen)
+
+		proved, err := proveDFPositive(receiver)
+		if err != nil {
+			t.Errorf("Error in positive proof (%d bits): %v", bitLen, err)
+		}
+		assert.Equal(t, true, proved, "positive proof failed for %d-bit modulus", bitLen)
+
+		proved, err = proveDFMultiplication(receiver)
+		if err != nil {
+			t.Errorf("Error in multiplication proof (%d bits): %v", bitLen, err)
+		}
+		assert.Equal(t, true, proved, "multiplication proof failed for %d-bit modulus", bitLen)
+	}
+}
+
+func proveDFPositive(receiver *Receiver) (bool, error) {
+	T := new(big.Int).Mul(receiver.QRSpecialRSA.N, receiver.QRSpecialRSA.N)
+	committer := NewCommitter(receiver.QRSpecialRSA.N, receiver.G, receiver.H, T, receiver.K)
+	x := common.GetRandomInt(receiver.QRSpecialRSA.N)
+	c, err := committer.GetCommitMsg(x)
+	if err != nil {
+		return false, err
+	}
+	receiver.SetCommitment(c)
+	_, r := committer.GetDecommitMsg()
+
+	challengeSpaceSize := 80
+	prover, err := NewPositiveProver(committer, x, r, challengeSpaceSize)
+	if err != nil {
+		return false, err
+	}
+	smallCommitments, bigCommitments := prover.GetVerifierInitializationData()
+	verifier, err := NewPositiveVerifier(receiver, receiver.Commitment,
+		smallCommitments, bigCommitments, challengeSpaceSize)
+	if err != nil {
+		return false, err
+	}
+	proofRandomData := prover.GetProofRandomData()
+	challenges := verifier.GetChallenges()
+	if err := verifier.SetProofRandomData(proofRandomData); err != nil {
+		return false, err
+	}
+	return verifier.Verify(prover.GetProofData(challenges)), nil
+}
+
+func proveDFMultiplication(receiver *Receiver) (bool, error) {
+	T := new(big.Int).Mul(receiver.QRSpecialRSA.N, receiver.QRSpecialRSA.N)
+	primes := receiver.QRSpecialRSA.GetPrimes()
+	receivers := make([]*Receiver, 3)
+	committers := make([]*Committer, 3)
+	for i := range receivers {
+		rec, err := NewReceiverFromParams(primes, receiver.G, receiver.H, receiver.K)
+		if err != nil {
+			return false, err
+		}
+		receivers[i] = rec
+		committers[i] = NewCommitter(rec.QRSpecialRSA.N, rec.G, rec.H, T, rec.K)
+	}
+
+	x1 := common.GetRandomInt(receiver.QRSpecialRSA.N)
+	x2 := common.GetRandomInt(receiver.QRSpecialRSA.N)
+	for i, x := range []*big.Int{x1, x2, new(big.Int).Mul(x1, x2)} {
+		c, err := committers[i].GetCommitMsg(x)
+		if err != nil {
+			return false, err
+		}
+		receivers[i].SetCommitment(c)
+	}
+
+	challengeSpaceSize := 80
+	prover := NewMultiplicationProver(committers[0], committers[1], committers[2],
+		challengeSpaceSize)
+	verifier := NewMultiplicationVerifier(receivers[0], receivers[1], receivers[2],
+		challengeSpaceSize)
+	d1, d2, d3 := prover.GetProofRandomData()
+	verifier.SetProofRandomData(d1, d2, d3)
+	challenge := verifier.GetChallenge()
+	u1, u, v1, v2, v3 := prover.GetProofData(challenge)
+	return verifier.Verify(u1, u, v1, v2, v3), nil
+}
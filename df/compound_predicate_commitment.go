@@ -0,0 +1,630 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package df
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/awsong/crypto/common"
+	"github.com/awsong/crypto/qr"
+)
+
+// PredicateOp is the type of a Predicate node.
+type PredicateOp int
+
+const (
+	PredicateEq  PredicateOp = iota // x = Value
+	PredicateGe                     // x >= Value
+	PredicateLe                     // x <= Value
+	PredicateAnd                    // all the Children hold
+	PredicateOr                     // at least one of the Children holds
+)
+
+// Predicate is a node of the abstract syntax tree of a predicate about the committed
+// value x. Leaves (PredicateEq, PredicateGe, PredicateLe) compare x with a public Value,
+// inner nodes (PredicateAnd, PredicateOr) combine their Children.
+// For example x = v1 OR (x >= v2 AND x <= v3) is:
+//
+//	NewOrPredicate(NewEqPredicate(v1),
+//		NewAndPredicate(NewGePredicate(v2), NewLePredicate(v3)))
+type Predicate struct {
+	Op       PredicateOp
+	Value    *big.Int
+	Children []*Predicate
+}
+
+func NewEqPredicate(v *big.Int) *Predicate {
+	return &Predicate{Op: PredicateEq, Value: v}
+}
+
+func NewGePredicate(v *big.Int) *Predicate {
+	return &Predicate{Op: PredicateGe, Value: v}
+}
+
+func NewLePredicate(v *big.Int) *Predicate {
+	return &Predicate{Op: PredicateLe, Value: v}
+}
+
+func NewAndPredicate(children ...*Predicate) *Predicate {
+	return &Predicate{Op: PredicateAnd, Children: children}
+}
+
+func NewOrPredicate(children ...*Predicate) *Predicate {
+	return &Predicate{Op: PredicateOr, Children: children}
+}
+
+// Holds returns true if the predicate holds for x.
+func (p *Predicate) Holds(x *big.Int) bool {
+	switch p.Op {
+	case PredicateEq:
+		return x.Cmp(p.Value) == 0
+	case PredicateGe:
+		return x.Cmp(p.Value) >= 0
+	case PredicateLe:
+		return x.Cmp(p.Value) <= 0
+	case PredicateAnd:
+		for _, child := range p.Children {
+			if !child.Holds(x) {
+				return false
+			}
+		}
+		return true
+	case PredicateOr:
+		for _, child := range p.Children {
+			if child.Holds(x) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// check returns an error if some leaf misses a value or some inner node has no children.
+func (p *Predicate) check() error {
+	switch p.Op {
+	case PredicateEq, PredicateGe, PredicateLe:
+		if p.Value == nil {
+			return fmt.Errorf("predicate leaf needs a value")
+		}
+	case PredicateAnd, PredicateOr:
+		if len(p.Children) == 0 {
+			return fmt.Errorf("AND and OR predicates need at least one child")
+		}
+		for _, child := range p.Children {
+			if err := child.check(); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("unknown predicate operation")
+	}
+	return nil
+}
+
+func (p *Predicate) isLeaf() bool {
+	return p.Op != PredicateAnd && p.Op != PredicateOr
+}
+
+// linRepTerm presents base^w where w is the witness with index witness.
+type linRepTerm struct {
+	base    *big.Int
+	witness int
+}
+
+// linRep is a sigma protocol for the knowledge of witnesses w_0, ..., w_(m-1) such that
+// y_j = prod_k base_jk^w_jk for each equation j. The responses z_i = r_i + e * w_i are
+// computed in Z. Proof can be simulated for any challenge e by choosing z_i randomly and
+// computing t_j = prod_k base_jk^z_jk * y_j^(-e), which is needed for OR-composition.
+type linRep struct {
+	group      *qr.RSASpecial
+	y          []*big.Int
+	equations  [][]linRepTerm
+	witnesses  []*big.Int // not known to the verifier and when the proof is simulated
+	randomness []*big.Int
+	bound      *big.Int // randomness and simulated responses are chosen from [0, bound)
+}
+
+// eval returns prod_k base_jk^exps[w_jk] for the equation j.
+func (l *linRep) eval(j int, exps []*big.Int) *big.Int {
+	res := big.NewInt(1)
+	for _, term := range l.equations[j] {
+		res = l.group.Mul(res, l.group.Exp(term.base, exps[term.witness]))
+	}
+	return res
+}
+
+func (l *linRep) nWitnesses() int {
+	n := 0
+	for _, equation := range l.equations {
+		for _, term := range equation {
+			if term.witness+1 > n {
+				n = term.witness + 1
+			}
+		}
+	}
+	return n
+}
+
+func (l *linRep) getProofRandomData() []*big.Int {
+	l.randomness = make([]*big.Int, l.nWitnesses())
+	for i := range l.randomness {
+		l.randomness[i] = common.GetRandomInt(l.bound)
+	}
+	t := make([]*big.Int, len(l.equations))
+	for j := range l.equations {
+		t[j] = l.eval(j, l.randomness)
+	}
+	return t
+}
+
+func (l *linRep) getProofData(challenge *big.Int) []*big.Int {
+	z := make([]*big.Int, len(l.witnesses))
+	for i, w := range l.witnesses {
+		z[i] = new(big.Int).Mul(challenge, w)
+		z[i].Add(z[i], l.randomness[i])
+	}
+	return z
+}
+
+// simulate returns proof random data and proof data which are accepted for the given challenge.
+func (l *linRep) simulate(challenge *big.Int) ([]*big.Int, []*big.Int) {
+	z := make([]*big.Int, l.nWitnesses())
+	for i := range z {
+		z[i] = common.GetRandomInt(l.bound)
+	}
+	t := make([]*big.Int, len(l.equations))
+	minusChallenge := new(big.Int).Neg(challenge)
+	for j := range l.equations {
+		t[j] = l.group.Mul(l.eval(j, z), l.group.Exp(l.y[j], minusChallenge))
+	}
+	return t, z
+}
+
+func (l *linRep) verify(challenge *big.Int, t, z []*big.Int) bool {
+	if len(t) != len(l.equations) || len(z) != l.nWitnesses() {
+		return false
+	}
+	for j := range l.equations {
+		left := l.eval(j, z)
+		right := l.group.Mul(t[j], l.group.Exp(l.y[j], challenge))
+		if !common.ConstantTimeEq(left, right) {
+			return false
+		}
+	}
+	return true
+}
+
+// getLeafStatement returns the statement which is proved for a leaf predicate about the
+// value x committed in c = g^x * h^r.
+// For PredicateEq it is the knowledge of r such that c * g^(-v) = h^r.
+// For PredicateGe (PredicateLe) the difference d = x - v (d = v - x) is decomposed into four
+// squares d = a_0^2 + ... + a_3^2 and aux holds commitments c_i = g^a_i * h^r_i. The statement
+// is then the knowledge of a_i, r_i and rho such that c_i = g^a_i * h^r_i and
+// c * g^(-v) = c_0^a_0 * ... * c_3^a_3 * h^rho (g^v * c^(-1) = ... for PredicateLe).
+func getLeafStatement(d *df, c *big.Int, p *Predicate, aux []*big.Int,
+	bound *big.Int) *linRep {
+	group := d.QRSpecialRSA
+	gv := group.Exp(d.G, p.Value)
+	l := &linRep{
+		group: group,
+		bound: bound,
+	}
+	switch p.Op {
+	case PredicateEq:
+		l.y = []*big.Int{group.Mul(c, group.Inv(gv))}
+		l.equations = [][]linRepTerm{{{d.H, 0}}}
+	case PredicateGe, PredicateLe:
+		y := group.Mul(c, group.Inv(gv))
+		if p.Op == PredicateLe {
+			y = group.Inv(y)
+		}
+		last := []linRepTerm{}
+		for i, ci := range aux {
+			l.y = append(l.y, ci)
+			l.equations = append(l.equations, []linRepTerm{{d.G, i}, {d.H, 4 + i}})
+			last = append(last, linRepTerm{ci, i})
+		}
+		l.y = append(l.y, y)
+		l.equations = append(l.equations, append(last, linRepTerm{d.H, 8}))
+	}
+	return l
+}
+
+// getLeafAuxLength returns the number of commitments needed for a leaf predicate.
+func getLeafAuxLength(p *Predicate) int {
+	if p.Op == PredicateEq {
+		return 0
+	}
+	return 4
+}
+
+// predicateNode holds the state of the proof for a node of the predicate.
+type predicateNode struct {
+	predicate *Predicate
+	children  []*predicateNode
+	holds     bool
+	simulated bool
+	challenge *big.Int
+	aux       []*big.Int // commitments to the roots of decomposition for PredicateGe and PredicateLe
+	statement *linRep
+	t         []*big.Int
+	z         []*big.Int
+}
+
+func newPredicateNode(p *Predicate, x *big.Int) *predicateNode {
+	n := &predicateNode{
+		predicate: p,
+		holds:     x != nil && p.Holds(x),
+	}
+	for _, child := range p.Children {
+		n.children = append(n.children, newPredicateNode(child, x))
+	}
+	return n
+}
+
+// walk calls f for the node and all its descendants in pre-order.
+func (n *predicateNode) walk(f func(*predicateNode)) {
+	f(n)
+	for _, child := range n.children {
+		child.walk(f)
+	}
+}
+
+// CompoundPredicateProver proves that the value x committed in c = g^x * h^r satisfies
+// a predicate given as an abstract syntax tree of AND and OR nodes over comparisons of x
+// with public values (see Predicate).
+// Each leaf is proved using a sigma protocol for the knowledge of a representation
+// (see getLeafStatement). AND nodes are composed by using the same challenge for
+// all the children. OR nodes are composed as in [Cramer, Damgard, Schoenmakers]: the
+// prover simulates the proofs for the children which do not hold (choosing their challenges)
+// and the challenges of the children need to sum up to the challenge of the OR node
+// (modulo 2^challengeSpaceSize).
+type CompoundPredicateProver struct {
+	df                 *df
+	root               *predicateNode
+	challengeSpaceSize int
+}
+
+func NewCompoundPredicateProver(committer *Committer, predicate *Predicate,
+	challengeSpaceSize int) (*CompoundPredicateProver, error) {
+	if err := predicate.check(); err != nil {
+		return nil, err
+	}
+	x, _ := committer.GetDecommitMsg()
+	if !predicate.Holds(x) {
+		return nil, fmt.Errorf("the committed value does not satisfy the predicate")
+	}
+	return newCompoundPredicateProver(committer, predicate, challengeSpaceSize)
+}
+
+// newCompoundPredicateProver works as NewCompoundPredicateProver, but it does not check
+// that the predicate holds - if it does not, the proof can only be simulated (see
+// getSimulatedProofRandomData). This is needed when the proof is a part of OR-composition
+// over several commitments.
+func newCompoundPredicateProver(committer *Committer, predicate *Predicate,
+	challengeSpaceSize int) (*CompoundPredicateProver, error) {
+	x, r := committer.GetDecommitMsg()
+	c := committer.ComputeCommit(x, r)
+	bound := getPredicateRandomnessBound(&committer.df, committer.T, challengeSpaceSize)
+
+	root := newPredicateNode(predicate, x)
+	var err error
+	root.walk(func(n *predicateNode) {
+		if err != nil || !n.predicate.isLeaf() {
+			return
+		}
+		var witnesses []*big.Int
+		witnesses, n.aux, err = getLeafWitnesses(committer, n.predicate, n.holds)
+		n.statement = getLeafStatement(&committer.df, c, n.predicate, n.aux, bound)
+		n.statement.witnesses = witnesses
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &CompoundPredicateProver{
+		df:                 &committer.df,
+		root:               root,
+		challengeSpaceSize: challengeSpaceSize,
+	}, nil
+}
+
+// getPredicateRandomnessBound returns 2^(l + challengeSpaceSize + K) where 2^l is
+// the upper bound for the witnesses of leaf statements.
+func getPredicateRandomnessBound(d *df, T *big.Int, challengeSpaceSize int) *big.Int {
+	l := d.QRSpecialRSA.N.BitLen() + d.K + T.BitLen()
+	return new(big.Int).Lsh(big.NewInt(1), uint(l+challengeSpaceSize+d.K))
+}
+
+// getLeafWitnesses returns the witnesses for the leaf statement (see getLeafStatement) and
+// the commitments c_i for PredicateGe and PredicateLe. If the leaf does not hold, the
+// commitments are commitments to zero and the witnesses are not needed (the proof is simulated).
+func getLeafWitnesses(committer *Committer, p *Predicate, holds bool) ([]*big.Int,
+	[]*big.Int, error) {
+	x, r := committer.GetDecommitMsg()
+	if p.Op == PredicateEq {
+		return []*big.Int{r}, nil, nil
+	}
+
+	d := new(big.Int).Sub(x, p.Value)
+	rho := new(big.Int).Set(r)
+	if p.Op == PredicateLe {
+		d.Neg(d)
+		rho.Neg(rho)
+	}
+	roots := NewLagrange()
+	if holds {
+		var err error
+		roots, err = lipmaaDecompose(d)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	b := new(big.Int).Lsh(big.NewInt(1), uint(committer.B+committer.K))
+	witnesses := make([]*big.Int, 9)
+	aux := make([]*big.Int, 4)
+	for i, a := range roots {
+		ri := common.GetRandomInt(b)
+		aux[i] = committer.ComputeCommit(a, ri)
+		witnesses[i] = a
+		witnesses[4+i] = ri
+		rho.Sub(rho, new(big.Int).Mul(a, ri))
+	}
+	witnesses[8] = rho
+	return witnesses, aux, nil
+}
+
+// GetProofRandomData returns, for each leaf in pre-order, the commitments c_i
+// (only for PredicateGe and PredicateLe) followed by the proof random data of the leaf statement.
+func (p *CompoundPredicateProver) GetProofRandomData() []*big.Int {
+	p.prepare(p.root, false, nil)
+	return p.collectProofRandomData()
+}
+
+// getSimulatedProofRandomData returns proof random data of the simulated proof for
+// the given challenge. GetProofData then returns the simulated proof (the challenge
+// passed to it is ignored).
+func (p *CompoundPredicateProver) getSimulatedProofRandomData(challenge *big.Int) []*big.Int {
+	p.prepare(p.root, true, challenge)
+	return p.collectProofRandomData()
+}
+
+func (p *CompoundPredicateProver) collectProofRandomData() []*big.Int {
+	var proofRandomData []*big.Int
+	p.root.walk(func(n *predicateNode) {
+		if n.predicate.isLeaf() {
+			proofRandomData = append(proofRandomData, n.aux...)
+			proofRandomData = append(proofRandomData, n.t...)
+		}
+	})
+	return proofRandomData
+}
+
+// prepare computes proof random data for the leaves of the subtree. If the subtree is
+// to be simulated, its challenges are chosen here.
+func (p *CompoundPredicateProver) prepare(n *predicateNode, simulated bool,
+	challenge *big.Int) {
+	n.simulated = simulated
+	n.challenge = challenge
+	switch {
+	case n.predicate.isLeaf() && simulated:
+		n.t, n.z = n.statement.simulate(challenge)
+	case n.predicate.isLeaf():
+		n.t = n.statement.getProofRandomData()
+	case n.predicate.Op == PredicateAnd:
+		for _, child := range n.children {
+			p.prepare(child, simulated, challenge)
+		}
+	case simulated: // OR node
+		challenges := p.splitChallenge(challenge, len(n.children))
+		for i, child := range n.children {
+			p.prepare(child, true, challenges[i])
+		}
+	default: // OR node which holds - the first child which holds is proved
+		chosen := false
+		for _, child := range n.children {
+			if child.holds && !chosen {
+				chosen = true
+				p.prepare(child, false, nil)
+			} else {
+				p.prepare(child, true, p.getRandomChallenge())
+			}
+		}
+	}
+}
+
+func (p *CompoundPredicateProver) getRandomChallenge() *big.Int {
+	b := new(big.Int).Lsh(big.NewInt(1), uint(p.challengeSpaceSize))
+	return common.GetRandomInt(b)
+}
+
+// splitChallenge returns n random challenges which sum up to challenge
+// modulo 2^challengeSpaceSize.
+func (p *CompoundPredicateProver) splitChallenge(challenge *big.Int, n int) []*big.Int {
+	challenges := make([]*big.Int, n)
+	last := new(big.Int).Set(challenge)
+	for i := 0; i < n-1; i++ {
+		challenges[i] = p.getRandomChallenge()
+		last.Sub(last, challenges[i])
+	}
+	challenges[n-1] = last.Mod(last, new(big.Int).Lsh(big.NewInt(1),
+		uint(p.challengeSpaceSize)))
+	return challenges
+}
+
+// GetProofData returns the challenges of all the nodes (in pre-order) and the proof
+// data of all the leaves (in pre-order).
+func (p *CompoundPredicateProver) GetProofData(challenge *big.Int) ([]*big.Int, []*big.Int) {
+	if !p.root.simulated {
+		p.respond(p.root, challenge)
+	}
+	var challenges, proofData []*big.Int
+	p.root.walk(func(n *predicateNode) {
+		challenges = append(challenges, n.challenge)
+		if n.predicate.isLeaf() {
+			proofData = append(proofData, n.z...)
+		}
+	})
+	return challenges, proofData
+}
+
+// respond computes the proof data for the subtree which is not simulated.
+func (p *CompoundPredicateProver) respond(n *predicateNode, challenge *big.Int) {
+	n.challenge = challenge
+	switch {
+	case n.predicate.isLeaf():
+		n.z = n.statement.getProofData(challenge)
+	case n.predicate.Op == PredicateAnd:
+		for _, child := range n.children {
+			p.respond(child, challenge)
+		}
+	default: // OR node
+		var proved *predicateNode
+		c := new(big.Int).Set(challenge)
+		for _, child := range n.children {
+			if child.simulated {
+				c.Sub(c, child.challenge)
+			} else {
+				proved = child
+			}
+		}
+		c.Mod(c, new(big.Int).Lsh(big.NewInt(1), uint(p.challengeSpaceSize)))
+		p.respond(proved, c)
+	}
+}
+
+type CompoundPredicateVerifier struct {
+	df                 *df
+	commitment         *big.Int
+	root               *predicateNode
+	bound              *big.Int
+	challenge          *big.Int
+	challengeSpaceSize int
+}
+
+// NewCompoundPredicateVerifier returns CompoundPredicateVerifier for the commitment
+// stored in receiver. T is the bound for the committed values (as in Committer).
+func NewCompoundPredicateVerifier(receiver *Receiver, predicate *Predicate, T *big.Int,
+	challengeSpaceSize int) (*CompoundPredicateVerifier, error) {
+	if err := predicate.check(); err != nil {
+		return nil, err
+	}
+	return &CompoundPredicateVerifier{
+		df:                 &receiver.df,
+		commitment:         receiver.Commitment,
+		root:               newPredicateNode(predicate, nil),
+		bound:              getPredicateRandomnessBound(&receiver.df, T, challengeSpaceSize),
+		challengeSpaceSize: challengeSpaceSize,
+	}, nil
+}
+
+func (v *CompoundPredicateVerifier) SetProofRandomData(proofRandomData []*big.Int) error {
+	var err error
+	v.root.walk(func(n *predicateNode) {
+		if err != nil || !n.predicate.isLeaf() {
+			return
+		}
+		nAux := getLeafAuxLength(n.predicate)
+		if len(proofRandomData) < nAux {
+			err = fmt.Errorf("the length of proofRandomData is not correct")
+			return
+		}
+		n.aux = proofRandomData[:nAux]
+		n.statement = getLeafStatement(v.df, v.commitment, n.predicate, n.aux, v.bound)
+		nT := len(n.statement.equations)
+		if len(proofRandomData) < nAux+nT {
+			err = fmt.Errorf("the length of proofRandomData is not correct")
+			return
+		}
+		n.t = proofRandomData[nAux : nAux+nT]
+		proofRandomData = proofRandomData[nAux+nT:]
+	})
+	if err == nil && len(proofRandomData) != 0 {
+		err = fmt.Errorf("the length of proofRandomData is not correct")
+	}
+	return err
+}
+
+func (v *CompoundPredicateVerifier) GetChallenge() *big.Int {
+	b := new(big.Int).Lsh(big.NewInt(1), uint(v.challengeSpaceSize))
+	v.challenge = common.GetRandomInt(b)
+	return v.challenge
+}
+
+// SetChallenge is used when Fiat-Shamir is used - when challenge is generated using hash by the prover.
+func (v *CompoundPredicateVerifier) SetChallenge(challenge *big.Int) {
+	v.challenge = challenge
+}
+
+// Verify checks that the challenges are consistent with the predicate (AND children have
+// the challenge of the parent, OR children challenges sum up to the challenge of the parent)
+// and that the proofs of all the leaves are valid.
+func (v *CompoundPredicateVerifier) Verify(challenges, proofData []*big.Int) bool {
+	// assign challenges and proof data to the nodes
+	ok := true
+	v.root.walk(func(n *predicateNode) {
+		if !ok || len(challenges) == 0 || n.statement == nil && n.predicate.isLeaf() {
+			ok = false
+			return
+		}
+		n.challenge, challenges = challenges[0], challenges[1:]
+		if n.predicate.isLeaf() {
+			nZ := n.statement.nWitnesses()
+			if len(proofData) < nZ {
+				ok = false
+				return
+			}
+			n.z, proofData = proofData[:nZ], proofData[nZ:]
+		}
+	})
+	if !ok || len(challenges) != 0 || len(proofData) != 0 {
+		return false
+	}
+	if !common.ConstantTimeEq(v.root.challenge, v.challenge) {
+		return false
+	}
+	return v.verifyNode(v.root)
+}
+
+func (v *CompoundPredicateVerifier) verifyNode(n *predicateNode) bool {
+	b := new(big.Int).Lsh(big.NewInt(1), uint(v.challengeSpaceSize))
+	if n.challenge.Sign() < 0 || n.challenge.Cmp(b) >= 0 {
+		return false
+	}
+	if n.predicate.isLeaf() {
+		return n.statement.verify(n.challenge, n.t, n.z)
+	}
+
+	sum := big.NewInt(0)
+	for _, child := range n.children {
+		if n.predicate.Op == PredicateAnd &&
+			!common.ConstantTimeEq(child.challenge, n.challenge) {
+			return false
+		}
+		sum.Add(sum, child.challenge)
+		if !v.verifyNode(child) {
+			return false
+		}
+	}
+	if n.predicate.Op == PredicateOr {
+		return common.ConstantTimeEq(sum.Mod(sum, b), n.challenge)
+	}
+	return true
+}
@@ -0,0 +1,114 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package df
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func proveCompoundPredicate(receiver *Receiver, committer *Committer, T *big.Int,
+	predicate *Predicate) (bool, error) {
+	challengeSpaceSize := 80
+	prover, err := NewCompoundPredicateProver(committer, predicate, challengeSpaceSize)
+	if err != nil {
+		return false, err
+	}
+	verifier, err := NewCompoundPredicateVerifier(receiver, predicate, T, challengeSpaceSize)
+	if err != nil {
+		return false, err
+	}
+
+	proofRandomData := prover.GetProofRandomData()
+	err = verifier.SetProofRandomData(proofRandomData)
+	if err != nil {
+		return false, err
+	}
+	challenge := verifier.GetChallenge()
+	challenges, proofData := prover.GetProofData(challenge)
+	return verifier.Verify(challenges, proofData), nil
+}
+
+// TestDFCommitmentCompoundPredicate demonstrates how to prove that the committed value x
+// satisfies x = v1 OR (x >= v2 AND x <= v3).
+func TestDFCommitmentCompoundPredicate(t *testing.T) {
+	receiver, err := NewReceiver(128, 80)
+	if err != nil {
+		t.Errorf("error in NewReceiver: %v", err)
+	}
+	// n^2 is used for T - but any other value can be used as well
+	T := new(big.Int).Mul(receiver.QRSpecialRSA.N, receiver.QRSpecialRSA.N)
+
+	v1, v2, v3 := big.NewInt(7), big.NewInt(100), big.NewInt(1000)
+	predicate := NewOrPredicate(NewEqPredicate(v1),
+		NewAndPredicate(NewGePredicate(v2), NewLePredicate(v3)))
+
+	// x = v1, x in [v2, v3] (also both boundaries), x which does not satisfy the predicate
+	values := []int64{7, 100, 543, 1000, -7, 8, 99, 1001}
+	for _, value := range values {
+		x := big.NewInt(value)
+		committer := NewCommitter(receiver.QRSpecialRSA.N,
+			receiver.G, receiver.H, T, receiver.K)
+		c, err := committer.GetCommitMsg(x)
+		if err != nil {
+			t.Errorf("error in computing commit msg: %v", err)
+		}
+		receiver.SetCommitment(c)
+
+		proved, err := proveCompoundPredicate(receiver, committer, T, predicate)
+		if predicate.Holds(x) {
+			if err != nil {
+				t.Errorf("error in compound predicate proof: %v", err)
+			}
+			assert.Equal(t, true, proved,
+				"DamgardFujisaki compound predicate proof failed for x = %d", value)
+		} else {
+			assert.NotNil(t, err,
+				"CompoundPredicateProver should fail for x = %d", value)
+		}
+	}
+}
+
+func TestDFCommitmentCompoundPredicateWrongCommitment(t *testing.T) {
+	receiver, err := NewReceiver(128, 80)
+	if err != nil {
+		t.Errorf("error in NewReceiver: %v", err)
+	}
+	T := new(big.Int).Mul(receiver.QRSpecialRSA.N, receiver.QRSpecialRSA.N)
+	predicate := NewOrPredicate(NewEqPredicate(big.NewInt(7)),
+		NewAndPredicate(NewGePredicate(big.NewInt(100)), NewLePredicate(big.NewInt(1000))))
+
+	committer := NewCommitter(receiver.QRSpecialRSA.N,
+		receiver.G, receiver.H, T, receiver.K)
+	_, err = committer.GetCommitMsg(big.NewInt(543))
+	if err != nil {
+		t.Errorf("error in computing commit msg: %v", err)
+	}
+	// the verifier has a commitment to a value which does not satisfy the predicate
+	c := receiver.ComputeCommit(big.NewInt(5000), big.NewInt(12345))
+	receiver.SetCommitment(c)
+
+	proved, err := proveCompoundPredicate(receiver, committer, T, predicate)
+	if err != nil {
+		t.Errorf("error in compound predicate proof: %v", err)
+	}
+	assert.Equal(t, false, proved,
+		"DamgardFujisaki compound predicate proof should fail for a different commitment")
+}
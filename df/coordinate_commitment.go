@@ -0,0 +1,99 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package df
+
+import (
+	"math/big"
+)
+
+// LatitudeProver and LongitudeProver prove that the commitment c = g^x * h^r hides a valid
+// GPS coordinate in fixed-point representation with six decimal places (degrees * 10^6):
+// -90000000 <= x <= 90000000 for latitude and -180000000 <= x <= 180000000 for longitude.
+// As for the integer types, CompoundPredicateProver is used.
+
+// coordinateScale is the number of fixed-point units in one degree.
+const coordinateScale = 1000000
+
+var (
+	latitudeRange = &integerTypeRange{"latitude", big.NewInt(-90 * coordinateScale),
+		big.NewInt(90 * coordinateScale)}
+	longitudeRange = &integerTypeRange{"longitude", big.NewInt(-180 * coordinateScale),
+		big.NewInt(180 * coordinateScale)}
+)
+
+type LatitudeProver struct {
+	*CompoundPredicateProver
+}
+
+// NewLatitudeProver returns LatitudeProver. It returns an error if (x, r) is not the opening
+// of the commitment in committer or if x is not in [-90000000, 90000000].
+func NewLatitudeProver(committer *Committer, x, r *big.Int,
+	challengeSpaceSize int) (*LatitudeProver, error) {
+	prover, err := newIntegerTypeRangeProver(committer, x, r, latitudeRange, challengeSpaceSize)
+	if err != nil {
+		return nil, err
+	}
+	return &LatitudeProver{prover}, nil
+}
+
+type LongitudeProver struct {
+	*CompoundPredicateProver
+}
+
+// NewLongitudeProver returns LongitudeProver. It returns an error if (x, r) is not the opening
+// of the commitment in committer or if x is not in [-180000000, 180000000].
+func NewLongitudeProver(committer *Committer, x, r *big.Int,
+	challengeSpaceSize int) (*LongitudeProver, error) {
+	prover, err := newIntegerTypeRangeProver(committer, x, r, longitudeRange, challengeSpaceSize)
+	if err != nil {
+		return nil, err
+	}
+	return &LongitudeProver{prover}, nil
+}
+
+type LatitudeVerifier struct {
+	*CompoundPredicateVerifier
+}
+
+// NewLatitudeVerifier returns LatitudeVerifier for the commitment stored in receiver.
+// T is the bound for the committed values (as in Committer).
+func NewLatitudeVerifier(receiver *Receiver, T *big.Int,
+	challengeSpaceSize int) (*LatitudeVerifier, error) {
+	verifier, err := NewCompoundPredicateVerifier(receiver, latitudeRange.predicate(), T,
+		challengeSpaceSize)
+	if err != nil {
+		return nil, err
+	}
+	return &LatitudeVerifier{verifier}, nil
+}
+
+type LongitudeVerifier struct {
+	*CompoundPredicateVerifier
+}
+
+// NewLongitudeVerifier returns LongitudeVerifier for the commitment stored in receiver.
+// T is the bound for the committed values (as in Committer).
+func NewLongitudeVerifier(receiver *Receiver, T *big.Int,
+	challengeSpaceSize int) (*LongitudeVerifier, error) {
+	verifier, err := NewCompoundPredicateVerifier(receiver, longitudeRange.predicate(), T,
+		challengeSpaceSize)
+	if err != nil {
+		return nil, err
+	}
+	return &LongitudeVerifier{verifier}, nil
+}
@@ -0,0 +1,102 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package df
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// proveCoordinate runs LatitudeProver (if latitude is true) or LongitudeProver for
+// the committed value x.
+func proveCoordinate(receiver *Receiver, T *big.Int, x int64, latitude bool) (bool, error) {
+	committer, r, err := getCommitterAndReceiver(receiver, T, big.NewInt(x))
+	if err != nil {
+		return false, err
+	}
+	_, rand := committer.GetDecommitMsg()
+
+	var prover *CompoundPredicateProver
+	var verifier *CompoundPredicateVerifier
+	if latitude {
+		p, err := NewLatitudeProver(committer, big.NewInt(x), rand, 80)
+		if err != nil {
+			return false, err
+		}
+		prover = p.CompoundPredicateProver
+		v, err := NewLatitudeVerifier(r, T, 80)
+		if err != nil {
+			return false, err
+		}
+		verifier = v.CompoundPredicateVerifier
+	} else {
+		p, err := NewLongitudeProver(committer, big.NewInt(x), rand, 80)
+		if err != nil {
+			return false, err
+		}
+		prover = p.CompoundPredicateProver
+		v, err := NewLongitudeVerifier(r, T, 80)
+		if err != nil {
+			return false, err
+		}
+		verifier = v.CompoundPredicateVerifier
+	}
+
+	if err := verifier.SetProofRandomData(prover.GetProofRandomData()); err != nil {
+		return false, err
+	}
+	challenges, proofData := prover.GetProofData(verifier.GetChallenge())
+	return verifier.Verify(challenges, proofData), nil
+}
+
+// TestDFCommitmentCoordinate demonstrates how to prove that the committed value is
+// a valid latitude or longitude (in degrees * 10^6).
+func TestDFCommitmentCoordinate(t *testing.T) {
+	receiver, err := NewReceiver(128, 80)
+	if err != nil {
+		t.Errorf("error in NewReceiver: %v", err)
+	}
+	// n^2 is used for T - but any other value can be used as well
+	T := new(big.Int).Mul(receiver.QRSpecialRSA.N, receiver.QRSpecialRSA.N)
+
+	for _, x := range []int64{-90000000, 0, 46056946, 90000000} {
+		proved, err := proveCoordinate(receiver, T, x, true)
+		if err != nil {
+			t.Errorf("error in latitude proof: %v", err)
+		}
+		assert.Equal(t, true, proved, "DamgardFujisaki latitude proof failed for %d.", x)
+	}
+	for _, x := range []int64{-180000000, 14505751, 180000000} {
+		proved, err := proveCoordinate(receiver, T, x, false)
+		if err != nil {
+			t.Errorf("error in longitude proof: %v", err)
+		}
+		assert.Equal(t, true, proved, "DamgardFujisaki longitude proof failed for %d.", x)
+	}
+
+	for _, x := range []int64{-90000001, 90000001, 180000000} {
+		_, err := proveCoordinate(receiver, T, x, true)
+		assert.NotNil(t, err, "LatitudeProver should fail for %d", x)
+	}
+	for _, x := range []int64{-180000001, 180000001} {
+		_, err := proveCoordinate(receiver, T, x, false)
+		assert.NotNil(t, err, "LongitudeProver should fail for %d", x)
+	}
+}
@@ -0,0 +1,173 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package df
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/awsong/crypto/common"
+)
+
+// CoprimeProver proves that the values x and y hidden in commitments cX = g^x * h^rX and
+// cY = g^y * h^rY are coprime. Prover computes Bezout coefficients s, t such that
+// s*x + t*y = 1 and commits to s, t, s*x and t*y. It then proves (using the same challenge):
+// (1) that cSX = g^(s*x) * h^rSX hides the product of s and x, where s is hidden in cS,
+// (2) that cTY = g^(t*y) * h^rTY hides the product of t and y, where t is hidden in cT.
+// Prover also reveals r = rSX + rTY and verifier checks cSX * cTY = g * h^r, that means
+// s*x + t*y = 1. Bezout coefficients can be negative - this is not a problem as DF
+// commitments and MultiplicationProver work with any integers (in (-T, T)).
+// This is the same as GCDProver for d = 1, but the divisibility proofs are not needed.
+type CoprimeProver struct {
+	mulProverS  *MultiplicationProver
+	mulProverT  *MultiplicationProver
+	commitments []*big.Int // cS, cT, cSX, cTY
+	r           *big.Int   // rSX + rTY
+}
+
+// NewCoprimeProver returns CoprimeProver for x committed by cx and y committed by cy.
+// It returns an error if gcd(x, y) != 1.
+func NewCoprimeProver(cx *Committer, cy *Committer, x, y *big.Int,
+	challengeSpaceSize int) (*CoprimeProver, error) {
+	committedX, _ := cx.GetDecommitMsg()
+	committedY, _ := cy.GetDecommitMsg()
+	if committedX == nil || committedY == nil ||
+		committedX.Cmp(x) != 0 || committedY.Cmp(y) != 0 {
+		return nil, fmt.Errorf("committers do not hold x and y")
+	}
+
+	// s * x + t * y = gcd(x, y)
+	gcd, s, t := common.ExtGCD(x, y)
+	if gcd.Cmp(big.NewInt(1)) != 0 {
+		return nil, fmt.Errorf("x and y are not coprime")
+	}
+
+	values := []*big.Int{s, t, new(big.Int).Mul(s, x), new(big.Int).Mul(t, y)}
+	committers := make([]*Committer, len(values))
+	commitments := make([]*big.Int, len(values))
+	for i, val := range values {
+		committer := NewCommitter(cx.QRSpecialRSA.N, cx.G, cx.H, cx.T, cx.K)
+		commitment, err := committer.GetCommitMsg(val)
+		if err != nil {
+			return nil, fmt.Errorf("error when creating commit msg")
+		}
+		committers[i] = committer
+		commitments[i] = commitment
+	}
+
+	_, rSX := committers[2].GetDecommitMsg()
+	_, rTY := committers[3].GetDecommitMsg()
+
+	return &CoprimeProver{
+		mulProverS: NewMultiplicationProver(committers[0], cx, committers[2],
+			challengeSpaceSize),
+		mulProverT: NewMultiplicationProver(committers[1], cy, committers[3],
+			challengeSpaceSize),
+		commitments: commitments,
+		r:           new(big.Int).Add(rSX, rTY),
+	}, nil
+}
+
+// GetVerifierInitializationData returns data that are needed by CoprimeVerifier
+// and are known only after the initialization of CoprimeProver: commitments
+// to s, t, s*x, t*y and r = rSX + rTY.
+func (p *CoprimeProver) GetVerifierInitializationData() ([]*big.Int, *big.Int) {
+	return p.commitments, p.r
+}
+
+func (p *CoprimeProver) GetProofRandomData() []*big.Int {
+	d1S, d2S, d3S := p.mulProverS.GetProofRandomData()
+	d1T, d2T, d3T := p.mulProverT.GetProofRandomData()
+	return []*big.Int{d1S, d2S, d3S, d1T, d2T, d3T}
+}
+
+func (p *CoprimeProver) GetProofData(challenge *big.Int) []*big.Int {
+	u1S, uS, v1S, v2S, v3S := p.mulProverS.GetProofData(challenge)
+	u1T, uT, v1T, v2T, v3T := p.mulProverT.GetProofData(challenge)
+	return []*big.Int{u1S, uS, v1S, v2S, v3S, u1T, uT, v1T, v2T, v3T}
+}
+
+type CoprimeVerifier struct {
+	mulVerifierS *MultiplicationVerifier
+	mulVerifierT *MultiplicationVerifier
+}
+
+func NewCoprimeVerifier(receiverX, receiverY *Receiver, commitments []*big.Int, r *big.Int,
+	challengeSpaceSize int) (*CoprimeVerifier, error) {
+	if len(commitments) != 4 {
+		return nil, fmt.Errorf("the length of commitments is not correct")
+	}
+
+	// check: cSX * cTY = g * h^r
+	check := receiverX.QRSpecialRSA.Mul(commitments[2], commitments[3])
+	if !common.ConstantTimeEq(check, receiverX.ComputeCommit(big.NewInt(1), r)) {
+		return nil, fmt.Errorf("commitments to products do not sum up to 1")
+	}
+
+	primes := receiverX.QRSpecialRSA.GetPrimes()
+	receivers := make([]*Receiver, len(commitments))
+	for i, comm := range commitments {
+		receiver, err := NewReceiverFromParams(primes, receiverX.G, receiverX.H, receiverX.K)
+		if err != nil {
+			return nil, fmt.Errorf("error when calling NewReceiverFromParams")
+		}
+		receiver.SetCommitment(comm)
+		receivers[i] = receiver
+	}
+
+	return &CoprimeVerifier{
+		mulVerifierS: NewMultiplicationVerifier(receivers[0], receiverX, receivers[2],
+			challengeSpaceSize),
+		mulVerifierT: NewMultiplicationVerifier(receivers[1], receiverY, receivers[3],
+			challengeSpaceSize),
+	}, nil
+}
+
+func (v *CoprimeVerifier) SetProofRandomData(proofRandomData []*big.Int) error {
+	if len(proofRandomData) != 6 {
+		return fmt.Errorf("the length of proofRandomData is not correct")
+	}
+	v.mulVerifierS.SetProofRandomData(proofRandomData[0], proofRandomData[1],
+		proofRandomData[2])
+	v.mulVerifierT.SetProofRandomData(proofRandomData[3], proofRandomData[4],
+		proofRandomData[5])
+	return nil
+}
+
+// GetChallenge returns a challenge which is used in both sub-proofs.
+func (v *CoprimeVerifier) GetChallenge() *big.Int {
+	challenge := v.mulVerifierS.GetChallenge()
+	v.SetChallenge(challenge)
+	return challenge
+}
+
+// SetChallenge is used when Fiat-Shamir is used - when challenge is generated using hash by the prover.
+func (v *CoprimeVerifier) SetChallenge(challenge *big.Int) {
+	v.mulVerifierS.SetChallenge(challenge)
+	v.mulVerifierT.SetChallenge(challenge)
+}
+
+func (v *CoprimeVerifier) Verify(proofData []*big.Int) bool {
+	if len(proofData) != 10 {
+		return false
+	}
+	return v.mulVerifierS.Verify(proofData[0], proofData[1], proofData[2], proofData[3],
+		proofData[4]) &&
+		v.mulVerifierT.Verify(proofData[5], proofData[6], proofData[7], proofData[8],
+			proofData[9])
+}
@@ -0,0 +1,111 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package df
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestDFCommitmentCoprime demonstrates how to prove that for given commitments
+// cX = g^x * h^rX, cY = g^y * h^rY it holds gcd(x, y) = 1.
+func TestDFCommitmentCoprime(t *testing.T) {
+	receiverX, err := NewReceiver(128, 80)
+	if err != nil {
+		t.Errorf("error in NewReceiver: %v", err)
+	}
+	receiverY, err := NewReceiverFromParams(receiverX.QRSpecialRSA.GetPrimes(),
+		receiverX.G, receiverX.H, receiverX.K)
+	if err != nil {
+		t.Errorf("error in NewReceiverFromParams: %v", err)
+	}
+
+	// n^2 is used for T - but any other value can be used as well
+	T := new(big.Int).Mul(receiverX.QRSpecialRSA.N, receiverX.QRSpecialRSA.N)
+	challengeSpaceSize := 80
+
+	// the second pair has a negative value (and thus negated Bezout coefficient)
+	pairs := [][]*big.Int{
+		{big.NewInt(35), big.NewInt(18)},
+		{big.NewInt(-35), big.NewInt(18)},
+		{big.NewInt(12), big.NewInt(18)},
+	}
+	for i, pair := range pairs {
+		x, y := pair[0], pair[1]
+		committerX := NewCommitter(receiverX.QRSpecialRSA.N,
+			receiverX.G, receiverX.H, T, receiverX.K)
+		committerY := NewCommitter(receiverX.QRSpecialRSA.N,
+			receiverX.G, receiverX.H, T, receiverX.K)
+		cX, err := committerX.GetCommitMsg(x)
+		if err != nil {
+			t.Errorf("error in computing commit msg: %v", err)
+		}
+		cY, err := committerY.GetCommitMsg(y)
+		if err != nil {
+			t.Errorf("error in computing commit msg: %v", err)
+		}
+		receiverX.SetCommitment(cX)
+		receiverY.SetCommitment(cY)
+
+		prover, err := NewCoprimeProver(committerX, committerY, x, y, challengeSpaceSize)
+		if i == 2 {
+			// gcd(12, 18) = 6
+			assert.NotNil(t, err, "CoprimeProver should fail for values which are not coprime")
+			continue
+		}
+		if err != nil {
+			t.Errorf("error in instantiating CoprimeProver: %v", err)
+		}
+
+		commitments, r := prover.GetVerifierInitializationData()
+		verifier, err := NewCoprimeVerifier(receiverX, receiverY, commitments, r,
+			challengeSpaceSize)
+		if err != nil {
+			t.Errorf("error in instantiating CoprimeVerifier: %v", err)
+		}
+
+		err = verifier.SetProofRandomData(prover.GetProofRandomData())
+		if err != nil {
+			t.Errorf("error when calling SetProofRandomData: %v", err)
+		}
+		challenge := verifier.GetChallenge()
+		proved := verifier.Verify(prover.GetProofData(challenge))
+		assert.Equal(t, true, proved, "DamgardFujisaki coprime proof failed.")
+
+		// the proof does not verify for a different y
+		cY2, err := committerY.GetCommitMsg(big.NewInt(21))
+		if err != nil {
+			t.Errorf("error in computing commit msg: %v", err)
+		}
+		receiverY.SetCommitment(cY2)
+		verifier, err = NewCoprimeVerifier(receiverX, receiverY, commitments, r,
+			challengeSpaceSize)
+		if err != nil {
+			t.Errorf("error in instantiating CoprimeVerifier: %v", err)
+		}
+		err = verifier.SetProofRandomData(prover.GetProofRandomData())
+		if err != nil {
+			t.Errorf("error when calling SetProofRandomData: %v", err)
+		}
+		challenge = verifier.GetChallenge()
+		proved = verifier.Verify(prover.GetProofData(challenge))
+		assert.Equal(t, false, proved, "DamgardFujisaki coprime proof should fail for different y.")
+	}
+}
@@ -0,0 +1,219 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package df
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/awsong/crypto/common"
+)
+
+// DecimalRepresentationProver proves that the commitment c = g^x * h^r hides x such that
+// x = d_0 + 10*d_1 + ... + 10^(n-1)*d_(n-1) with digits d_i in [0, 9], that is 0 <= x < 10^n.
+// Prover commits to each digit c_i = g^d_i * h^r_i where randomnesses are chosen such that
+// r = r_0 + 10*r_1 + ... + 10^(n-1)*r_(n-1). Verifier checks that
+// c = c_0 * c_1^10 * ... * c_(n-1)^(10^(n-1)), and for each digit prover proves
+// (using CompoundPredicateProver) that d_i = 0 OR ... OR d_i = 9.
+// All digit proofs use the same challenge.
+type DecimalRepresentationProver struct {
+	digitProvers     []*CompoundPredicateProver
+	digitCommitments []*big.Int
+}
+
+func NewDecimalRepresentationProver(committer *Committer, x *big.Int, n int,
+	challengeSpaceSize int) (*DecimalRepresentationProver, error) {
+	if n < 1 {
+		return nil, fmt.Errorf("n needs to be positive")
+	}
+	digits, err := getDecimalDigits(x, n)
+	if err != nil {
+		return nil, err
+	}
+	_, r := committer.GetDecommitMsg()
+
+	// r_1, ..., r_(n-1) are chosen randomly, r_0 = r - (10*r_1 + ... + 10^(n-1)*r_(n-1))
+	rBound := new(big.Int).Lsh(big.NewInt(1), uint(committer.B+committer.K))
+	rs := make([]*big.Int, n)
+	r0 := new(big.Int).Set(r)
+	for i := 1; i < n; i++ {
+		rs[i] = common.GetRandomInt(rBound)
+		r0.Sub(r0, new(big.Int).Mul(rs[i], getPowerOfTen(i)))
+	}
+	rs[0] = r0
+
+	predicate := getDigitPredicate()
+	digitProvers := make([]*CompoundPredicateProver, n)
+	digitCommitments := make([]*big.Int, n)
+	for i := 0; i < n; i++ {
+		digitCommitter := NewCommitter(committer.QRSpecialRSA.N,
+			committer.G, committer.H, committer.T, committer.K)
+		commitment, err := digitCommitter.GetCommitMsgWithGivenR(digits[i], rs[i])
+		if err != nil {
+			return nil, fmt.Errorf("error when creating commit msg with given r")
+		}
+		digitCommitments[i] = commitment
+		digitProvers[i], err = NewCompoundPredicateProver(digitCommitter, predicate,
+			challengeSpaceSize)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &DecimalRepresentationProver{
+		digitProvers:     digitProvers,
+		digitCommitments: digitCommitments,
+	}, nil
+}
+
+// getDecimalDigits returns the n least significant decimal digits of x. It returns
+// an error if x is not in [0, 10^n).
+func getDecimalDigits(x *big.Int, n int) ([]*big.Int, error) {
+	if x.Sign() < 0 || x.Cmp(getPowerOfTen(n)) != -1 {
+		return nil, fmt.Errorf("x needs to be in [0, 10^n)")
+	}
+	digits := make([]*big.Int, n)
+	rest := new(big.Int).Set(x)
+	for i := 0; i < n; i++ {
+		digits[i] = new(big.Int)
+		rest.DivMod(rest, big.NewInt(10), digits[i])
+	}
+	return digits, nil
+}
+
+// getPowerOfTen returns 10^i.
+func getPowerOfTen(i int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(i)), nil)
+}
+
+// getDigitPredicate returns the predicate d = 0 OR ... OR d = 9.
+func getDigitPredicate() *Predicate {
+	digits := make([]*Predicate, 10)
+	for d := range digits {
+		digits[d] = NewEqPredicate(big.NewInt(int64(d)))
+	}
+	return NewOrPredicate(digits...)
+}
+
+// GetVerifierInitializationData returns data that are needed by DecimalRepresentationVerifier
+// and are known only after the initialization of DecimalRepresentationProver:
+// commitments to digits.
+func (p *DecimalRepresentationProver) GetVerifierInitializationData() []*big.Int {
+	return p.digitCommitments
+}
+
+// GetProofRandomData returns the proof random data of the digit proofs.
+func (p *DecimalRepresentationProver) GetProofRandomData() [][]*big.Int {
+	proofRandomData := make([][]*big.Int, len(p.digitProvers))
+	for i, prover := range p.digitProvers {
+		proofRandomData[i] = prover.GetProofRandomData()
+	}
+	return proofRandomData
+}
+
+// GetProofData returns the challenges and the proof data of the digit proofs.
+func (p *DecimalRepresentationProver) GetProofData(challenge *big.Int) ([][]*big.Int,
+	[][]*big.Int) {
+	challenges := make([][]*big.Int, len(p.digitProvers))
+	proofData := make([][]*big.Int, len(p.digitProvers))
+	for i, prover := range p.digitProvers {
+		challenges[i], proofData[i] = prover.GetProofData(challenge)
+	}
+	return challenges, proofData
+}
+
+type DecimalRepresentationVerifier struct {
+	digitVerifiers []*CompoundPredicateVerifier
+}
+
+// NewDecimalRepresentationVerifier returns DecimalRepresentationVerifier for the commitment
+// stored in receiver. T is the bound for the committed values (as in Committer).
+func NewDecimalRepresentationVerifier(receiver *Receiver, n int, digitCommitments []*big.Int,
+	T *big.Int, challengeSpaceSize int) (*DecimalRepresentationVerifier, error) {
+	if n < 1 || len(digitCommitments) != n {
+		return nil, fmt.Errorf("the length of digitCommitments is not correct")
+	}
+
+	// check: c = c_0 * c_1^10 * ... * c_(n-1)^(10^(n-1))
+	check := big.NewInt(1)
+	for i, comm := range digitCommitments {
+		check = receiver.QRSpecialRSA.Mul(check,
+			receiver.QRSpecialRSA.Exp(comm, getPowerOfTen(i)))
+	}
+	if !common.ConstantTimeEq(check, receiver.Commitment) {
+		return nil, fmt.Errorf("digit commitments do not compose the commitment")
+	}
+
+	predicate := getDigitPredicate()
+	digitVerifiers := make([]*CompoundPredicateVerifier, n)
+	for i, comm := range digitCommitments {
+		digitReceiver, err := NewReceiverFromParams(receiver.QRSpecialRSA.GetPrimes(),
+			receiver.G, receiver.H, receiver.K)
+		if err != nil {
+			return nil, fmt.Errorf("error when calling NewReceiverFromParams")
+		}
+		digitReceiver.SetCommitment(comm)
+		digitVerifiers[i], err = NewCompoundPredicateVerifier(digitReceiver, predicate, T,
+			challengeSpaceSize)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &DecimalRepresentationVerifier{
+		digitVerifiers: digitVerifiers,
+	}, nil
+}
+
+func (v *DecimalRepresentationVerifier) SetProofRandomData(proofRandomData [][]*big.Int) error {
+	if len(proofRandomData) != len(v.digitVerifiers) {
+		return fmt.Errorf("the length of proofRandomData is not correct")
+	}
+	for i, verifier := range v.digitVerifiers {
+		if err := verifier.SetProofRandomData(proofRandomData[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetChallenge returns a challenge which is used in all digit proofs.
+func (v *DecimalRepresentationVerifier) GetChallenge() *big.Int {
+	challenge := v.digitVerifiers[0].GetChallenge()
+	v.SetChallenge(challenge)
+	return challenge
+}
+
+// SetChallenge is used when Fiat-Shamir is used - when challenge is generated using hash by the prover.
+func (v *DecimalRepresentationVerifier) SetChallenge(challenge *big.Int) {
+	for _, verifier := range v.digitVerifiers {
+		verifier.SetChallenge(challenge)
+	}
+}
+
+func (v *DecimalRepresentationVerifier) Verify(challenges, proofData [][]*big.Int) bool {
+	if len(challenges) != len(v.digitVerifiers) || len(proofData) != len(v.digitVerifiers) {
+		return false
+	}
+	for i, verifier := range v.digitVerifiers {
+		if !verifier.Verify(challenges[i], proofData[i]) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,78 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package df
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func proveDecimalRepresentation(receiver *Receiver, committer *Committer, x *big.Int, n,
+	challengeSpaceSize int) (bool, error) {
+	prover, err := NewDecimalRepresentationProver(committer, x, n, challengeSpaceSize)
+	if err != nil {
+		return false, err
+	}
+	verifier, err := NewDecimalRepresentationVerifier(receiver, n,
+		prover.GetVerifierInitializationData(), committer.T, challengeSpaceSize)
+	if err != nil {
+		return false, err
+	}
+
+	err = verifier.SetProofRandomData(prover.GetProofRandomData())
+	if err != nil {
+		return false, err
+	}
+	challenge := verifier.GetChallenge()
+	return verifier.Verify(prover.GetProofData(challenge)), nil
+}
+
+// TestDFCommitmentDecimalRepresentation demonstrates how to prove that the commitment
+// hides a number x with n decimal digits, that is 0 <= x < 10^n.
+func TestDFCommitmentDecimalRepresentation(t *testing.T) {
+	x := big.NewInt(907135)
+	receiver, committer, err := getRangeTestParams(x)
+	if err != nil {
+		t.Errorf("error when creating committer: %v", err)
+	}
+
+	proved, err := proveDecimalRepresentation(receiver, committer, x, 6, 80)
+	if err != nil {
+		t.Errorf("error in decimal representation proof: %v", err)
+	}
+	assert.Equal(t, true, proved, "DamgardFujisaki decimal representation proof failed.")
+
+	// leading zero digits
+	proved, err = proveDecimalRepresentation(receiver, committer, x, 8, 80)
+	if err != nil {
+		t.Errorf("error in decimal representation proof: %v", err)
+	}
+	assert.Equal(t, true, proved, "DamgardFujisaki decimal representation proof failed.")
+
+	_, err = NewDecimalRepresentationProver(committer, x, 5, 80)
+	assert.NotNil(t, err, "x should not be representable with 5 digits")
+
+	// digit commitments do not compose the commitment to a different value
+	prover, _ := NewDecimalRepresentationProver(committer, x, 6, 80)
+	receiver.SetCommitment(committer.ComputeCommit(big.NewInt(1), big.NewInt(1)))
+	_, err = NewDecimalRepresentationVerifier(receiver, 6, prover.GetVerifierInitializationData(),
+		committer.T, 80)
+	assert.NotNil(t, err, "verifier should reject digit commitments for a different commitment")
+}
@@ -0,0 +1,99 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package df
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/awsong/crypto/common"
+)
+
+// Decomposition writes a non-negative integer x as a sum of squares: Decompose returns
+// roots such that x = roots[0]^2 + ... + roots[k-1]^2. It is used by
+// NewPositiveProverWithDecomposition - PositiveProver proves that each of the commitments
+// to roots[i]^2 hides a square.
+type Decomposition interface {
+	Decompose(x *big.Int) ([]*big.Int, error)
+}
+
+// LipmaaDecomposition writes x as a sum of four squares (Lagrange's four-square
+// theorem), as used by NewPositiveProver. Zero roots are kept, thus there are always
+// four roots.
+type LipmaaDecomposition struct{}
+
+func (d LipmaaDecomposition) Decompose(x *big.Int) ([]*big.Int, error) {
+	roots, err := lipmaaDecompose(x)
+	if err != nil {
+		return nil, err
+	}
+	return roots[:], nil
+}
+
+// TwoSquareDecomposition writes a prime p = 1 (mod 4) as a sum of two squares (Fermat's
+// theorem on sums of two squares). The roots are computed using Hermite-Serret algorithm:
+// the Euclidean algorithm is applied on p and r, where r^2 = -1 (mod p), and it is stopped
+// at the first remainder a < sqrt(p), then p = a^2 + b^2. It returns an error if x is not
+// a prime of the form 4k + 1.
+type TwoSquareDecomposition struct{}
+
+func (d TwoSquareDecomposition) Decompose(x *big.Int) ([]*big.Int, error) {
+	if x.Sign() <= 0 || x.Bit(0) == 0 || x.Bit(1) != 0 || !x.ProbablyPrime(20) {
+		return nil, fmt.Errorf("x needs to be a prime of the form 4k + 1")
+	}
+	r, ok := common.ModSqrt(new(big.Int).Sub(x, big.NewInt(1)), x)
+	if !ok {
+		return nil, fmt.Errorf("square root of -1 modulo x does not exist")
+	}
+
+	a, b := new(big.Int).Set(x), r
+	for new(big.Int).Mul(b, b).Cmp(x) > 0 {
+		a, b = b, a.Mod(a, b)
+	}
+	c := new(big.Int).Sub(x, new(big.Int).Mul(b, b))
+	c.Sqrt(c)
+	return []*big.Int{b, c}, nil
+}
+
+// BitDecomposition writes x from [0, 2^BitLen) as a sum of squares using the binary
+// representation x = sum b_i * 2^i: for even i it holds b_i * 2^i = (b_i * 2^(i/2))^2 and
+// for odd i it holds b_i * 2^i = (b_i * 2^((i-1)/2))^2 + (b_i * 2^((i-1)/2))^2.
+// Roots for zero bits are zero, thus the number of roots depends only on BitLen
+// (and does not reveal anything about x). Note that for larger BitLen the proof is much
+// bigger than with LipmaaDecomposition (one square proof for each root).
+type BitDecomposition struct {
+	BitLen int
+}
+
+func (d BitDecomposition) Decompose(x *big.Int) ([]*big.Int, error) {
+	if d.BitLen < 1 {
+		return nil, fmt.Errorf("BitLen needs to be positive")
+	}
+	if x.Sign() < 0 || x.BitLen() > d.BitLen {
+		return nil, fmt.Errorf("x needs to be in [0, 2^%d)", d.BitLen)
+	}
+	roots := make([]*big.Int, 0, d.BitLen+d.BitLen/2)
+	for i := 0; i < d.BitLen; i++ {
+		root := new(big.Int).Lsh(big.NewInt(int64(x.Bit(i))), uint(i/2))
+		roots = append(roots, root)
+		if i%2 == 1 {
+			roots = append(roots, new(big.Int).Set(root))
+		}
+	}
+	return roots, nil
+}
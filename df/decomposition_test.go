@@ -0,0 +1,145 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package df
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/awsong/crypto/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func sumOfSquares(roots []*big.Int) *big.Int {
+	sum := big.NewInt(0)
+	for _, root := range roots {
+		sum.Add(sum, new(big.Int).Mul(root, root))
+	}
+	return sum
+}
+
+func TestTwoSquareDecomposition(t *testing.T) {
+	decomp := TwoSquareDecomposition{}
+	for _, p := range []int64{5, 13, 17, 97, 1000000009} {
+		x := big.NewInt(p)
+		roots, err := decomp.Decompose(x)
+		if err != nil {
+			t.Errorf("error in TwoSquareDecomposition: %v", err)
+		}
+		assert.Equal(t, 2, len(roots), "two roots are expected")
+		assert.Equal(t, x, sumOfSquares(roots), "roots do not sum up to %d", p)
+	}
+
+	p := common.GetRandomIntOfLength(256)
+	for p.Bit(1) != 0 || !p.ProbablyPrime(20) {
+		p = common.GetRandomIntOfLength(256)
+		p.SetBit(p, 0, 1)
+	}
+	roots, err := decomp.Decompose(p)
+	if err != nil {
+		t.Errorf("error in TwoSquareDecomposition: %v", err)
+	}
+	assert.Equal(t, p, sumOfSquares(roots), "roots do not sum up to a large prime")
+
+	for _, x := range []int64{-5, 0, 2, 7, 21, 25} {
+		_, err := decomp.Decompose(big.NewInt(x))
+		assert.NotNil(t, err, "TwoSquareDecomposition should fail for %d", x)
+	}
+}
+
+func TestBitDecomposition(t *testing.T) {
+	decomp := BitDecomposition{BitLen: 16}
+	for _, val := range []int64{0, 1, 2, 3, 12345, 65535} {
+		x := big.NewInt(val)
+		roots, err := decomp.Decompose(x)
+		if err != nil {
+			t.Errorf("error in BitDecomposition: %v", err)
+		}
+		assert.Equal(t, 24, len(roots), "the number of roots should depend only on BitLen")
+		assert.Equal(t, x, sumOfSquares(roots), "roots do not sum up to %d", val)
+	}
+
+	_, err := decomp.Decompose(big.NewInt(65536))
+	assert.NotNil(t, err, "BitDecomposition should fail for x >= 2^BitLen")
+	_, err = decomp.Decompose(big.NewInt(-1))
+	assert.NotNil(t, err, "BitDecomposition should fail for negative x")
+}
+
+func provePositiveWithDecomposition(receiver *Receiver, T *big.Int, decomp Decomposition,
+	x *big.Int, nRoots int) (bool, error) {
+	committer, r, err := getCommitterAndReceiver(receiver, T, x)
+	if err != nil {
+		return false, err
+	}
+	_, rand := committer.GetDecommitMsg()
+	prover, err := NewPositiveProverWithDecomposition(committer, decomp, x, rand, 80)
+	if err != nil {
+		return false, err
+	}
+	smallCommitments, bigCommitments := prover.GetVerifierInitializationData()
+	verifier, err := NewPositiveVerifierWithDecomposition(r, r.Commitment, smallCommitments,
+		bigCommitments, nRoots, 80)
+	if err != nil {
+		return false, err
+	}
+	if err := verifier.SetProofRandomData(prover.GetProofRandomData()); err != nil {
+		return false, err
+	}
+	return verifier.Verify(prover.GetProofData(verifier.GetChallenges())), nil
+}
+
+// TestDFCommitmentPositiveWithDecomposition demonstrates how a different decomposition
+// can be used in the positive proof.
+func TestDFCommitmentPositiveWithDecomposition(t *testing.T) {
+	receiver, err := NewReceiver(128, 80)
+	if err != nil {
+		t.Errorf("error in NewReceiver: %v", err)
+	}
+	// n^2 is used for T - but any other value can be used as well
+	T := new(big.Int).Mul(receiver.QRSpecialRSA.N, receiver.QRSpecialRSA.N)
+
+	tests := []struct {
+		decomp Decomposition
+		x      *big.Int
+		nRoots int
+	}{
+		{LipmaaDecomposition{}, common.GetRandomInt(receiver.QRSpecialRSA.N), 4},
+		{LipmaaDecomposition{}, big.NewInt(9), 4},
+		{TwoSquareDecomposition{}, big.NewInt(1000000009), 2},
+		{BitDecomposition{BitLen: 16}, big.NewInt(40000), 24},
+		{BitDecomposition{BitLen: 16}, big.NewInt(0), 24},
+	}
+	for _, test := range tests {
+		proved, err := provePositiveWithDecomposition(receiver, T, test.decomp, test.x,
+			test.nRoots)
+		if err != nil {
+			t.Errorf("error in positive proof with decomposition: %v", err)
+		}
+		assert.Equal(t, true, proved, "DamgardFujisaki positive proof failed for %T.",
+			test.decomp)
+	}
+
+	_, err = provePositiveWithDecomposition(receiver, T, TwoSquareDecomposition{},
+		big.NewInt(1000000007), 2)
+	assert.NotNil(t, err, "two-square decomposition should fail for a prime 3 mod 4")
+
+	// the verifier does not accept a different number of roots than agreed
+	_, err = provePositiveWithDecomposition(receiver, T, TwoSquareDecomposition{},
+		big.NewInt(1000000009), 4)
+	assert.NotNil(t, err, "PositiveVerifier should fail for an unexpected number of roots")
+}
@@ -0,0 +1,69 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package df
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// DivisibilityProver proves that for commitments cA = g^a * h^rA and cB = g^b * h^rB
+// it holds a | b. Prover commits to the quotient q = b / a (cQ = g^q * h^rQ) and proves
+// a * q = b using MultiplicationProver.
+type DivisibilityProver struct {
+	*MultiplicationProver
+}
+
+// NewDivisibilityProver returns DivisibilityProver for the values committed by committerA,
+// committerB and committerQ (which needs to hold a commitment to b / a). It returns an
+// error if a does not divide b.
+func NewDivisibilityProver(committerA, committerB, committerQ *Committer,
+	challengeSpaceSize int) (*DivisibilityProver, error) {
+	a, _ := committerA.GetDecommitMsg()
+	b, _ := committerB.GetDecommitMsg()
+	q, _ := committerQ.GetDecommitMsg()
+	if a.Sign() == 0 {
+		return nil, fmt.Errorf("a needs to be non-zero")
+	}
+	quotient, m := new(big.Int).QuoRem(b, a, new(big.Int))
+	if m.Sign() != 0 {
+		return nil, fmt.Errorf("a does not divide b")
+	}
+	if quotient.Cmp(q) != 0 {
+		return nil, fmt.Errorf("committerQ does not hold the quotient b / a")
+	}
+
+	return &DivisibilityProver{
+		MultiplicationProver: NewMultiplicationProver(committerA, committerQ, committerB,
+			challengeSpaceSize),
+	}, nil
+}
+
+type DivisibilityVerifier struct {
+	*MultiplicationVerifier
+}
+
+// NewDivisibilityVerifier returns DivisibilityVerifier for the commitments stored in
+// receiverA, receiverB and receiverQ (commitment to the quotient).
+func NewDivisibilityVerifier(receiverA, receiverB, receiverQ *Receiver,
+	challengeSpaceSize int) *DivisibilityVerifier {
+	return &DivisibilityVerifier{
+		MultiplicationVerifier: NewMultiplicationVerifier(receiverA, receiverQ, receiverB,
+			challengeSpaceSize),
+	}
+}
@@ -0,0 +1,98 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package df
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/awsong/crypto/common"
+	"github.com/stretchr/testify/assert"
+)
+
+// proveDivisibility commits to a, b and b / a (or to q if given) and runs the divisibility proof.
+func proveDivisibility(receiver *Receiver, a, b, q *big.Int) (bool, error) {
+	T := new(big.Int).Mul(receiver.QRSpecialRSA.N, receiver.QRSpecialRSA.N)
+	if q == nil {
+		q = new(big.Int).Quo(b, a)
+	}
+	values := []*big.Int{a, b, q}
+	committers := make([]*Committer, 3)
+	receivers := make([]*Receiver, 3)
+	for i, val := range values {
+		committers[i] = NewCommitter(receiver.QRSpecialRSA.N,
+			receiver.G, receiver.H, T, receiver.K)
+		c, err := committers[i].GetCommitMsg(val)
+		if err != nil {
+			return false, err
+		}
+		receivers[i], err = NewReceiverFromParams(receiver.QRSpecialRSA.GetPrimes(),
+			receiver.G, receiver.H, receiver.K)
+		if err != nil {
+			return false, err
+		}
+		receivers[i].SetCommitment(c)
+	}
+
+	challengeSpaceSize := 80
+	prover, err := NewDivisibilityProver(committers[0], committers[1], committers[2],
+		challengeSpaceSize)
+	if err != nil {
+		return false, err
+	}
+	verifier := NewDivisibilityVerifier(receivers[0], receivers[1], receivers[2],
+		challengeSpaceSize)
+
+	d1, d2, d3 := prover.GetProofRandomData()
+	verifier.SetProofRandomData(d1, d2, d3)
+	challenge := verifier.GetChallenge()
+	return verifier.Verify(prover.GetProofData(challenge)), nil
+}
+
+// TestDFCommitmentDivisibility demonstrates how to prove that for given commitments
+// cA = g^a * h^rA and cB = g^b * h^rB it holds a | b.
+func TestDFCommitmentDivisibility(t *testing.T) {
+	receiver, err := NewReceiver(128, 80)
+	if err != nil {
+		t.Errorf("error in NewReceiver: %v", err)
+	}
+
+	a := common.GetRandomInt(receiver.QRSpecialRSA.N)
+	b := new(big.Int).Mul(a, common.GetRandomInt(receiver.QRSpecialRSA.N))
+	cases := [][]*big.Int{
+		{a, b},             // a | b
+		{big.NewInt(1), b}, // a = 1
+		{b, b},             // a = b
+	}
+	for _, c := range cases {
+		proved, err := proveDivisibility(receiver, c[0], c[1], nil)
+		if err != nil {
+			t.Errorf("error in divisibility proof: %v", err)
+		}
+		assert.Equal(t, true, proved, "DamgardFujisaki divisibility proof failed.")
+	}
+
+	// a does not divide b + 1
+	bPlusOne := new(big.Int).Add(b, big.NewInt(1))
+	_, err = proveDivisibility(receiver, a, bPlusOne, nil)
+	assert.NotNil(t, err, "DivisibilityProver should fail when a does not divide b")
+
+	// wrong quotient
+	_, err = proveDivisibility(receiver, a, b, big.NewInt(2))
+	assert.NotNil(t, err, "DivisibilityProver should fail for a wrong quotient")
+}
@@ -153,5 +153,5 @@ func (v *EqualityVerifier) Verify(s1, s21, s22 *big.Int) bool {
 	left2 := v.receiver2.QRSpecialRSA.Exp(v.receiver2.Commitment, v.challenge)
 	left2 = v.receiver2.QRSpecialRSA.Mul(v.proofRandomData2, left2)
 	right2 := v.receiver2.ComputeCommit(s1, s22)
-	return left1.Cmp(right1) == 0 && left2.Cmp(right2) == 0
+	return common.ConstantTimeEq(left1, right1) && common.ConstantTimeEq(left2, right2)
 }
@@ -0,0 +1,162 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package df
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/awsong/crypto/common"
+)
+
+// EulerTotientRelationProver proves that the value committed in c_phi = g^phi * h^r_phi is
+// the Euler totient of the RSA modulus n = p*q committed in c_n = g^n * h^r_n, that is
+// phi = (p-1)*(q-1) = n - p - q + 1. Prover commits to the factors, c_p = g^p * h^r_p
+// and c_q = g^q * h^r_q, and proves the knowledge of p, r_p, q, r_q, rho1 and rho2 such that:
+// c_p = g^p * h^r_p
+// c_q = g^q * h^r_q
+// c_n = c_p^q * h^rho1 (n = p*q, as in MultiplicationProver)
+// c_phi * g^(-1) * c_n^(-1) * c_p * c_q = h^rho2 (phi = n - p - q + 1).
+// Note that the proof does not show that p and q are primes (or different from 1).
+type EulerTotientRelationProver struct {
+	statement *linRep
+	cP        *big.Int
+	cQ        *big.Int
+}
+
+func NewEulerTotientRelationProver(committerN, committerPhi *Committer, p, q *big.Int,
+	challengeSpaceSize int) (*EulerTotientRelationProver, error) {
+	n, rN := committerN.GetDecommitMsg()
+	phi, rPhi := committerPhi.GetDecommitMsg()
+	if new(big.Int).Mul(p, q).Cmp(n) != 0 {
+		return nil, fmt.Errorf("p * q is not the committed modulus")
+	}
+	phiCheck := new(big.Int).Mul(new(big.Int).Sub(p, one), new(big.Int).Sub(q, one))
+	if phiCheck.Cmp(phi) != 0 {
+		return nil, fmt.Errorf("the committed value is not (p-1)*(q-1)")
+	}
+
+	committerP := NewCommitter(committerN.QRSpecialRSA.N, committerN.G, committerN.H,
+		committerN.T, committerN.K)
+	cP, err := committerP.GetCommitMsg(p)
+	if err != nil {
+		return nil, err
+	}
+	_, rP := committerP.GetDecommitMsg()
+	committerQ := NewCommitter(committerN.QRSpecialRSA.N, committerN.G, committerN.H,
+		committerN.T, committerN.K)
+	cQ, err := committerQ.GetCommitMsg(q)
+	if err != nil {
+		return nil, err
+	}
+	_, rQ := committerQ.GetDecommitMsg()
+
+	// rho1 = r_n - q*r_p, rho2 = r_phi - r_n + r_p + r_q
+	rho1 := new(big.Int).Sub(rN, new(big.Int).Mul(q, rP))
+	rho2 := new(big.Int).Sub(rPhi, rN)
+	rho2.Add(rho2, rP)
+	rho2.Add(rho2, rQ)
+
+	statement := getEulerTotientStatement(&committerN.df, committerN.ComputeCommit(n, rN),
+		committerPhi.ComputeCommit(phi, rPhi), cP, cQ,
+		getPredicateRandomnessBound(&committerN.df, committerN.T, challengeSpaceSize))
+	statement.witnesses = []*big.Int{p, rP, q, rQ, rho1, rho2}
+	return &EulerTotientRelationProver{
+		statement: statement,
+		cP:        cP,
+		cQ:        cQ,
+	}, nil
+}
+
+// getEulerTotientStatement returns the statement proved by EulerTotientRelationProver
+// (see linRep).
+func getEulerTotientStatement(d *df, cN, cPhi, cP, cQ, bound *big.Int) *linRep {
+	group := d.QRSpecialRSA
+	y := group.Mul(cPhi, group.Inv(group.Mul(d.G, cN)))
+	y = group.Mul(y, group.Mul(cP, cQ))
+	return &linRep{
+		group: group,
+		y:     []*big.Int{cP, cQ, cN, y},
+		equations: [][]linRepTerm{
+			{{d.G, 0}, {d.H, 1}},
+			{{d.G, 2}, {d.H, 3}},
+			{{cP, 2}, {d.H, 4}},
+			{{d.H, 5}},
+		},
+		bound: bound,
+	}
+}
+
+// GetVerifierInitializationData returns data that are needed by EulerTotientRelationVerifier
+// and are known only after the initialization of EulerTotientRelationProver: the commitments
+// to p and q.
+func (p *EulerTotientRelationProver) GetVerifierInitializationData() (*big.Int, *big.Int) {
+	return p.cP, p.cQ
+}
+
+func (p *EulerTotientRelationProver) GetProofRandomData() []*big.Int {
+	return p.statement.getProofRandomData()
+}
+
+func (p *EulerTotientRelationProver) GetProofData(challenge *big.Int) []*big.Int {
+	return p.statement.getProofData(challenge)
+}
+
+type EulerTotientRelationVerifier struct {
+	statement          *linRep
+	challengeSpaceSize int
+	proofRandomData    []*big.Int
+	challenge          *big.Int
+}
+
+// NewEulerTotientRelationVerifier returns EulerTotientRelationVerifier for the modulus
+// committed in receiverN and the totient committed in receiverPhi. The commitments cP and cQ
+// are obtained from EulerTotientRelationProver.GetVerifierInitializationData and T is
+// the bound for the committed values (as in Committer).
+func NewEulerTotientRelationVerifier(receiverN, receiverPhi *Receiver, cP, cQ, T *big.Int,
+	challengeSpaceSize int) *EulerTotientRelationVerifier {
+	return &EulerTotientRelationVerifier{
+		statement: getEulerTotientStatement(&receiverN.df, receiverN.Commitment,
+			receiverPhi.Commitment, cP, cQ,
+			getPredicateRandomnessBound(&receiverN.df, T, challengeSpaceSize)),
+		challengeSpaceSize: challengeSpaceSize,
+	}
+}
+
+func (v *EulerTotientRelationVerifier) SetProofRandomData(proofRandomData []*big.Int) error {
+	if len(proofRandomData) != len(v.statement.equations) {
+		return fmt.Errorf("the length of proofRandomData is not correct")
+	}
+	v.proofRandomData = proofRandomData
+	return nil
+}
+
+func (v *EulerTotientRelationVerifier) GetChallenge() *big.Int {
+	b := new(big.Int).Lsh(big.NewInt(1), uint(v.challengeSpaceSize))
+	v.challenge = common.GetRandomInt(b)
+	return v.challenge
+}
+
+// SetChallenge is used when Fiat-Shamir is used - when challenge is generated using hash by the prover.
+func (v *EulerTotientRelationVerifier) SetChallenge(challenge *big.Int) {
+	v.challenge = challenge
+}
+
+func (v *EulerTotientRelationVerifier) Verify(proofData []*big.Int) bool {
+	return v.statement.verify(v.challenge, v.proofRandomData, proofData)
+}
@@ -0,0 +1,95 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package df
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestDFCommitmentEulerTotient demonstrates how to prove that the committed value is
+// the Euler totient of the committed RSA modulus.
+func TestDFCommitmentEulerTotient(t *testing.T) {
+	receiver, err := NewReceiver(128, 80)
+	if err != nil {
+		t.Errorf("error in NewReceiver: %v", err)
+	}
+	// n^2 is used for T - but any other value can be used as well
+	T := new(big.Int).Mul(receiver.QRSpecialRSA.N, receiver.QRSpecialRSA.N)
+
+	p, err := rand.Prime(rand.Reader, 64)
+	if err != nil {
+		t.Errorf("error when generating prime: %v", err)
+	}
+	q, err := rand.Prime(rand.Reader, 64)
+	if err != nil {
+		t.Errorf("error when generating prime: %v", err)
+	}
+	n := new(big.Int).Mul(p, q)
+	phi := new(big.Int).Mul(new(big.Int).Sub(p, one), new(big.Int).Sub(q, one))
+
+	committerN, receiverN, err := getCommitterAndReceiver(receiver, T, n)
+	if err != nil {
+		t.Errorf("error in computing commit msg: %v", err)
+	}
+	committerPhi, receiverPhi, err := getCommitterAndReceiver(receiver, T, phi)
+	if err != nil {
+		t.Errorf("error in computing commit msg: %v", err)
+	}
+
+	challengeSpaceSize := 80
+	prover, err := NewEulerTotientRelationProver(committerN, committerPhi, p, q,
+		challengeSpaceSize)
+	if err != nil {
+		t.Errorf("error in instantiating EulerTotientRelationProver: %v", err)
+	}
+	cP, cQ := prover.GetVerifierInitializationData()
+	verifier := NewEulerTotientRelationVerifier(receiverN, receiverPhi, cP, cQ, T,
+		challengeSpaceSize)
+	err = verifier.SetProofRandomData(prover.GetProofRandomData())
+	if err != nil {
+		t.Errorf("error when calling SetProofRandomData: %v", err)
+	}
+	proved := verifier.Verify(prover.GetProofData(verifier.GetChallenge()))
+	assert.Equal(t, true, proved, "DamgardFujisaki Euler totient proof failed.")
+
+	// the proof is not accepted for a commitment to another value
+	_, receiverOther, err := getCommitterAndReceiver(receiver, T, new(big.Int).Add(phi, one))
+	if err != nil {
+		t.Errorf("error in computing commit msg: %v", err)
+	}
+	verifier = NewEulerTotientRelationVerifier(receiverN, receiverOther, cP, cQ, T,
+		challengeSpaceSize)
+	err = verifier.SetProofRandomData(prover.GetProofRandomData())
+	if err != nil {
+		t.Errorf("error when calling SetProofRandomData: %v", err)
+	}
+	proved = verifier.Verify(prover.GetProofData(verifier.GetChallenge()))
+	assert.Equal(t, false, proved,
+		"DamgardFujisaki Euler totient proof should fail for a wrong totient")
+
+	committerOther, _, err := getCommitterAndReceiver(receiver, T, new(big.Int).Add(phi, one))
+	if err != nil {
+		t.Errorf("error in computing commit msg: %v", err)
+	}
+	_, err = NewEulerTotientRelationProver(committerN, committerOther, p, q, challengeSpaceSize)
+	assert.NotNil(t, err, "EulerTotientRelationProver should fail for a wrong totient")
+}
@@ -0,0 +1,83 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package df
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// FermatPrimalityWitnessProver proves that the value x hidden in the commitment
+// c = g^x * h^r satisfies x^(p-1) = 1 mod p for a public prime p, that is x is
+// a Fermat witness for p (x is not divisible by p). By Fermat's little theorem this
+// is equivalent to gcd(x, p) = 1, which is proved using CoprimeProver - p is
+// committed with zero randomness (g^p) so that verifier can compute the commitment itself.
+type FermatPrimalityWitnessProver struct {
+	*CoprimeProver
+}
+
+// NewFermatPrimalityWitnessProver returns FermatPrimalityWitnessProver. It returns an error
+// if p is not a prime or if x is divisible by p.
+func NewFermatPrimalityWitnessProver(committer *Committer, x, p *big.Int,
+	challengeSpaceSize int) (*FermatPrimalityWitnessProver, error) {
+	if !p.ProbablyPrime(20) {
+		return nil, fmt.Errorf("p is not a prime")
+	}
+	if new(big.Int).Exp(x, new(big.Int).Sub(p, big.NewInt(1)), p).Cmp(big.NewInt(1)) != 0 {
+		return nil, fmt.Errorf("x^(p-1) is not 1 mod p")
+	}
+	committerP := NewCommitter(committer.QRSpecialRSA.N, committer.G, committer.H,
+		committer.T, committer.K)
+	if _, err := committerP.GetCommitMsgWithGivenR(p, big.NewInt(0)); err != nil {
+		return nil, err
+	}
+	prover, err := NewCoprimeProver(committer, committerP, x, p, challengeSpaceSize)
+	if err != nil {
+		return nil, err
+	}
+	return &FermatPrimalityWitnessProver{
+		CoprimeProver: prover,
+	}, nil
+}
+
+type FermatPrimalityWitnessVerifier struct {
+	*CoprimeVerifier
+}
+
+// NewFermatPrimalityWitnessVerifier returns FermatPrimalityWitnessVerifier for the commitment
+// stored in receiver. The commitments and r are obtained from
+// FermatPrimalityWitnessProver.GetVerifierInitializationData.
+func NewFermatPrimalityWitnessVerifier(receiver *Receiver, p *big.Int, commitments []*big.Int,
+	r *big.Int, challengeSpaceSize int) (*FermatPrimalityWitnessVerifier, error) {
+	if !p.ProbablyPrime(20) {
+		return nil, fmt.Errorf("p is not a prime")
+	}
+	receiverP, err := NewReceiverFromParams(receiver.QRSpecialRSA.GetPrimes(),
+		receiver.G, receiver.H, receiver.K)
+	if err != nil {
+		return nil, err
+	}
+	receiverP.SetCommitment(receiverP.ComputeCommit(p, big.NewInt(0)))
+	verifier, err := NewCoprimeVerifier(receiver, receiverP, commitments, r, challengeSpaceSize)
+	if err != nil {
+		return nil, err
+	}
+	return &FermatPrimalityWitnessVerifier{
+		CoprimeVerifier: verifier,
+	}, nil
+}
@@ -0,0 +1,78 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package df
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func proveFermatPrimalityWitness(receiver *Receiver, committer *Committer, x, proverP,
+	verifierP *big.Int) (bool, error) {
+	challengeSpaceSize := 80
+	prover, err := NewFermatPrimalityWitnessProver(committer, x, proverP, challengeSpaceSize)
+	if err != nil {
+		return false, err
+	}
+	commitments, r := prover.GetVerifierInitializationData()
+	verifier, err := NewFermatPrimalityWitnessVerifier(receiver, verifierP, commitments, r,
+		challengeSpaceSize)
+	if err != nil {
+		return false, err
+	}
+	err = verifier.SetProofRandomData(prover.GetProofRandomData())
+	if err != nil {
+		return false, err
+	}
+	challenge := verifier.GetChallenge()
+	return verifier.Verify(prover.GetProofData(challenge)), nil
+}
+
+// TestDFCommitmentFermatPrimalityWitness demonstrates how to prove that the committed x
+// satisfies x^(p-1) = 1 mod p for a public prime p.
+func TestDFCommitmentFermatPrimalityWitness(t *testing.T) {
+	x := big.NewInt(123456789)
+	receiver, committer, err := getRangeTestParams(x)
+	if err != nil {
+		t.Errorf("error when creating committer: %v", err)
+	}
+	p := big.NewInt(1000003)
+
+	proved, err := proveFermatPrimalityWitness(receiver, committer, x, p, p)
+	if err != nil {
+		t.Errorf("error in Fermat primality witness proof: %v", err)
+	}
+	assert.Equal(t, true, proved, "DamgardFujisaki Fermat primality witness proof failed.")
+
+	// 123456789 = 3^2 * 3607 * 3803
+	_, err = NewFermatPrimalityWitnessProver(committer, x, big.NewInt(3607), 80)
+	assert.NotNil(t, err, "FermatPrimalityWitnessProver should fail when p divides x")
+
+	_, err = NewFermatPrimalityWitnessProver(committer, x, big.NewInt(1000001), 80)
+	assert.NotNil(t, err, "FermatPrimalityWitnessProver should fail for composite p")
+
+	// the proof for p does not verify for a different prime
+	proved, err = proveFermatPrimalityWitness(receiver, committer, x, p, big.NewInt(1000033))
+	if err != nil {
+		t.Errorf("error in Fermat primality witness proof: %v", err)
+	}
+	assert.Equal(t, false, proved,
+		"DamgardFujisaki Fermat primality witness proof should fail for a different prime")
+}
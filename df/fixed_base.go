@@ -0,0 +1,111 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package df
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// fixedBaseTable holds the powers base^(j * 2^(windowBits*i)) mod n for all windows i
+// and digits j in [0, 2^windowBits). Exponentiation of the fixed base then needs only one
+// multiplication per window of the exponent (and no squarings).
+type fixedBaseTable struct {
+	n          *big.Int
+	windowBits int
+	table      [][]*big.Int
+}
+
+// newFixedBaseTable returns fixedBaseTable for exponents of at most maxBits bits.
+func newFixedBaseTable(base, n *big.Int, windowBits, maxBits int) *fixedBaseTable {
+	nWindows := (maxBits + windowBits - 1) / windowBits
+	table := make([][]*big.Int, nWindows)
+	windowBase := new(big.Int).Set(base) // base^(2^(windowBits*i))
+	for i := range table {
+		table[i] = make([]*big.Int, 1<<uint(windowBits))
+		table[i][0] = big.NewInt(1)
+		for j := 1; j < len(table[i]); j++ {
+			table[i][j] = new(big.Int).Mul(table[i][j-1], windowBase)
+			table[i][j].Mod(table[i][j], n)
+		}
+		windowBase = new(big.Int).Mul(table[i][len(table[i])-1], windowBase)
+		windowBase.Mod(windowBase, n)
+	}
+	return &fixedBaseTable{
+		n:          n,
+		windowBits: windowBits,
+		table:      table,
+	}
+}
+
+// exp returns base^exponent mod n. The second return value is false if the exponent
+// is too big for the table.
+func (t *fixedBaseTable) exp(exponent *big.Int) (*big.Int, bool) {
+	expAbs := new(big.Int).Abs(exponent)
+	if expAbs.BitLen() > len(t.table)*t.windowBits {
+		return nil, false
+	}
+	res := big.NewInt(1)
+	for i := range t.table {
+		digit := 0
+		for k := t.windowBits - 1; k >= 0; k-- {
+			digit = digit<<1 | int(expAbs.Bit(i*t.windowBits+k))
+		}
+		if digit != 0 {
+			res.Mul(res, t.table[i][digit])
+			res.Mod(res, t.n)
+		}
+	}
+	if exponent.Sign() < 0 {
+		res.ModInverse(res, t.n)
+	}
+	return res, true
+}
+
+// PrecomputeFixedBase precomputes tables of powers of G and H which are then used by
+// ComputeCommitFast (and by GetCommitMsg, GetCommitMsgWithGivenR). The tables cover exponents
+// up to the bit length of T for G and B + K for H - for bigger exponents the usual
+// exponentiation is used. The memory needed is about 2^windowBits * (B + K) / windowBits
+// integers modulo N per base. For a 2048-bit modulus a window of 6 bits makes commitments
+// about three times faster (see BenchmarkDFCommit100FixedBase6bit).
+func (c *Committer) PrecomputeFixedBase(windowBits int) error {
+	if windowBits < 1 || windowBits > 16 {
+		return fmt.Errorf("windowBits needs to be in [1, 16]")
+	}
+	n := c.QRSpecialRSA.N
+	c.gTable = newFixedBaseTable(c.G, n, windowBits, c.T.BitLen())
+	c.hTable = newFixedBaseTable(c.H, n, windowBits, c.B+c.K)
+	return nil
+}
+
+// ComputeCommitFast returns G^a * H^r % group.N (as ComputeCommit), using the tables
+// computed by PrecomputeFixedBase.
+func (c *Committer) ComputeCommitFast(a, r *big.Int) *big.Int {
+	if c.gTable == nil {
+		return c.ComputeCommit(a, r)
+	}
+	tmp1, ok := c.gTable.exp(a)
+	if !ok {
+		tmp1 = c.QRSpecialRSA.Exp(c.G, a)
+	}
+	tmp2, ok := c.hTable.exp(r)
+	if !ok {
+		tmp2 = c.QRSpecialRSA.Exp(c.H, r)
+	}
+	return c.QRSpecialRSA.Mul(tmp1, tmp2)
+}
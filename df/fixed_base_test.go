@@ -0,0 +1,110 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package df
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/awsong/crypto/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDFCommitmentFixedBase(t *testing.T) {
+	receiver, err := NewReceiver(128, 80)
+	if err != nil {
+		t.Errorf("error in NewReceiver: %v", err)
+	}
+	T := new(big.Int).Mul(receiver.QRSpecialRSA.N, receiver.QRSpecialRSA.N)
+	committer := NewCommitter(receiver.QRSpecialRSA.N, receiver.G, receiver.H, T, receiver.K)
+	err = committer.PrecomputeFixedBase(0)
+	assert.NotNil(t, err, "window of 0 bits should not be accepted")
+	err = committer.PrecomputeFixedBase(4)
+	if err != nil {
+		t.Errorf("error in PrecomputeFixedBase: %v", err)
+	}
+
+	rBound := new(big.Int).Lsh(big.NewInt(1), uint(committer.B+committer.K))
+	tests := [][2]*big.Int{
+		{big.NewInt(0), big.NewInt(0)},
+		{common.GetRandomInt(T), common.GetRandomInt(rBound)},
+		{new(big.Int).Neg(common.GetRandomInt(T)), new(big.Int).Neg(common.GetRandomInt(rBound))},
+		// exponents too big for the tables
+		{new(big.Int).Mul(T, T), new(big.Int).Mul(rBound, rBound)},
+	}
+	for _, test := range tests {
+		assert.Equal(t, committer.ComputeCommit(test[0], test[1]),
+			committer.ComputeCommitFast(test[0], test[1]),
+			"ComputeCommitFast does not match ComputeCommit")
+	}
+
+	x := common.GetRandomInt(T)
+	c, err := committer.GetCommitMsg(x)
+	if err != nil {
+		t.Errorf("error in computing commit msg: %v", err)
+	}
+	receiver.SetCommitment(c)
+	_, r := committer.GetDecommitMsg()
+	assert.Equal(t, true, receiver.CheckDecommitment(r, x),
+		"commitment computed with precomputed tables does not open")
+}
+
+// getFixedBaseBenchmarkCommitter returns a committer for a 2048-bit modulus. The primes
+// are not safe primes (generating them takes too long), which does not matter for
+// the performance of the commitment.
+func getFixedBaseBenchmarkCommitter(b *testing.B) *Committer {
+	p, err := rand.Prime(rand.Reader, 1024)
+	if err != nil {
+		b.Fatalf("error when generating prime: %v", err)
+	}
+	q, err := rand.Prime(rand.Reader, 1024)
+	if err != nil {
+		b.Fatalf("error when generating prime: %v", err)
+	}
+	n := new(big.Int).Mul(p, q)
+	h := common.GetRandomInt(n)
+	h.Exp(h, big.NewInt(2), n)
+	g := new(big.Int).Exp(h, common.GetRandomInt(n), n)
+	return NewCommitter(n, g, h, n, 80)
+}
+
+func benchmarkDFCommit100(b *testing.B, windowBits int) {
+	committer := getFixedBaseBenchmarkCommitter(b)
+	if windowBits > 0 {
+		if err := committer.PrecomputeFixedBase(windowBits); err != nil {
+			b.Fatalf("error in PrecomputeFixedBase: %v", err)
+		}
+	}
+	xs := make([]*big.Int, 100)
+	for i := range xs {
+		xs[i] = common.GetRandomInt(committer.T)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, x := range xs {
+			if _, err := committer.GetCommitMsg(x); err != nil {
+				b.Fatalf("error in computing commit msg: %v", err)
+			}
+		}
+	}
+}
+
+func BenchmarkDFCommit100(b *testing.B)              { benchmarkDFCommit100(b, 0) }
+func BenchmarkDFCommit100FixedBase4bit(b *testing.B) { benchmarkDFCommit100(b, 4) }
+func BenchmarkDFCommit100FixedBase6bit(b *testing.B) { benchmarkDFCommit100(b, 6) }
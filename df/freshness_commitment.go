@@ -0,0 +1,89 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package df
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// FreshnessProver proves that the commitment c = g^t * h^r hides a fresh Unix timestamp:
+// now - maxAgeSeconds <= t <= now + tolerance. The tolerance allows for small clock
+// differences between the prover and the verifier. The current time now is a parameter
+// (it is not read from the clock) and the prover and the verifier need to agree on it,
+// for example the verifier sends it together with the nonce of the session.
+// It uses CompoundPredicateProver for the predicate t >= now - maxAgeSeconds AND
+// t <= now + tolerance.
+type FreshnessProver struct {
+	*CompoundPredicateProver
+}
+
+// NewFreshnessProver returns FreshnessProver. It returns an error if (t, r) is not the
+// opening of the commitment in committer, if maxAgeSeconds or tolerance is negative or if
+// t is not in [now - maxAgeSeconds, now + tolerance].
+func NewFreshnessProver(committer *Committer, t, r *big.Int, now, maxAgeSeconds,
+	tolerance int64, challengeSpaceSize int) (*FreshnessProver, error) {
+	pred, err := getFreshnessPredicate(now, maxAgeSeconds, tolerance)
+	if err != nil {
+		return nil, err
+	}
+	committedValue, committedR := committer.GetDecommitMsg()
+	if committedValue == nil || t.Cmp(committedValue) != 0 || r.Cmp(committedR) != 0 {
+		return nil, fmt.Errorf("t and r are not the opening of the commitment")
+	}
+	if !pred.Holds(t) {
+		return nil, fmt.Errorf("timestamp needs to be in [now - %d, now + %d]",
+			maxAgeSeconds, tolerance)
+	}
+	prover, err := NewCompoundPredicateProver(committer, pred, challengeSpaceSize)
+	if err != nil {
+		return nil, err
+	}
+	return &FreshnessProver{prover}, nil
+}
+
+// getFreshnessPredicate returns the predicate t >= now - maxAgeSeconds AND
+// t <= now + tolerance.
+func getFreshnessPredicate(now, maxAgeSeconds, tolerance int64) (*Predicate, error) {
+	if maxAgeSeconds < 0 || tolerance < 0 {
+		return nil, fmt.Errorf("maxAgeSeconds and tolerance need to be non-negative")
+	}
+	n := big.NewInt(now)
+	min := new(big.Int).Sub(n, big.NewInt(maxAgeSeconds))
+	max := new(big.Int).Add(n, big.NewInt(tolerance))
+	return NewAndPredicate(NewGePredicate(min), NewLePredicate(max)), nil
+}
+
+type FreshnessVerifier struct {
+	*CompoundPredicateVerifier
+}
+
+// NewFreshnessVerifier returns FreshnessVerifier for the commitment stored in receiver.
+// T is the bound for the committed values (as in Committer).
+func NewFreshnessVerifier(receiver *Receiver, now, maxAgeSeconds, tolerance int64,
+	T *big.Int, challengeSpaceSize int) (*FreshnessVerifier, error) {
+	pred, err := getFreshnessPredicate(now, maxAgeSeconds, tolerance)
+	if err != nil {
+		return nil, err
+	}
+	verifier, err := NewCompoundPredicateVerifier(receiver, pred, T, challengeSpaceSize)
+	if err != nil {
+		return nil, err
+	}
+	return &FreshnessVerifier{verifier}, nil
+}
@@ -0,0 +1,83 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package df
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func proveFreshness(receiver *Receiver, T *big.Int, timestamp, now,
+	verifierNow int64) (bool, error) {
+	x := big.NewInt(timestamp)
+	committer, r, err := getCommitterAndReceiver(receiver, T, x)
+	if err != nil {
+		return false, err
+	}
+	_, rand := committer.GetDecommitMsg()
+
+	prover, err := NewFreshnessProver(committer, x, rand, now, 300, 30, 80)
+	if err != nil {
+		return false, err
+	}
+	verifier, err := NewFreshnessVerifier(r, verifierNow, 300, 30, T, 80)
+	if err != nil {
+		return false, err
+	}
+	if err := verifier.SetProofRandomData(prover.GetProofRandomData()); err != nil {
+		return false, err
+	}
+	challenges, proofData := prover.GetProofData(verifier.GetChallenge())
+	return verifier.Verify(challenges, proofData), nil
+}
+
+// TestDFCommitmentFreshness demonstrates how to prove that the committed timestamp is
+// at most 300 seconds old (with 30 seconds of tolerance for clock differences).
+func TestDFCommitmentFreshness(t *testing.T) {
+	receiver, err := NewReceiver(128, 80)
+	if err != nil {
+		t.Errorf("error in NewReceiver: %v", err)
+	}
+	// n^2 is used for T - but any other value can be used as well
+	T := new(big.Int).Mul(receiver.QRSpecialRSA.N, receiver.QRSpecialRSA.N)
+	now := int64(1700000000)
+
+	for _, ts := range []int64{now, now - 300, now - 1, now + 30} {
+		proved, err := proveFreshness(receiver, T, ts, now, now)
+		if err != nil {
+			t.Errorf("error in freshness proof: %v", err)
+		}
+		assert.Equal(t, true, proved, "DamgardFujisaki freshness proof failed for %d.", ts)
+	}
+	for _, ts := range []int64{now - 301, now + 31} {
+		_, err := proveFreshness(receiver, T, ts, now, now)
+		assert.NotNil(t, err, "FreshnessProver should fail for %d", ts)
+	}
+
+	// the proof for now does not verify for the verifier's later now
+	proved, err := proveFreshness(receiver, T, now-290, now, now+60)
+	if err != nil {
+		t.Errorf("error in freshness proof: %v", err)
+	}
+	assert.Equal(t, false, proved, "freshness proof should fail for a different now")
+
+	_, err = NewFreshnessVerifier(receiver, now, -1, 30, T, 80)
+	assert.NotNil(t, err, "NewFreshnessVerifier should fail for negative maxAgeSeconds")
+}
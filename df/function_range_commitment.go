@@ -0,0 +1,100 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package df
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// FunctionRangeProver proves that the commitment c = g^y * h^r hides a value y from the range
+// of a public table-defined function f: [n] -> [m], that is y = f(i) for some i. functionRange
+// is the table f(0), ..., f(n-1). This is a set membership proof for
+// S = {f(0), ..., f(n-1)}: y = s_1 OR ... OR y = s_k where s_1, ..., s_k are the distinct
+// values in S, and is proved using CompoundPredicateProver.
+type FunctionRangeProver struct {
+	*CompoundPredicateProver
+}
+
+// NewFunctionRangeProver returns FunctionRangeProver. It returns an error if (y, r) is not
+// the opening of the commitment in committer or if y is not in the range of the function.
+func NewFunctionRangeProver(committer *Committer, y, r *big.Int, functionRange []*big.Int,
+	challengeSpaceSize int) (*FunctionRangeProver, error) {
+	committedValue, committedR := committer.GetDecommitMsg()
+	if committedValue == nil || y.Cmp(committedValue) != 0 || r.Cmp(committedR) != 0 {
+		return nil, fmt.Errorf("y and r are not the opening of the commitment")
+	}
+	inRange := false
+	for _, v := range functionRange {
+		if v.Cmp(y) == 0 {
+			inRange = true
+			break
+		}
+	}
+	if !inRange {
+		return nil, fmt.Errorf("y is not in the range of the function")
+	}
+
+	predicate, err := getFunctionRangePredicate(functionRange)
+	if err != nil {
+		return nil, err
+	}
+	prover, err := NewCompoundPredicateProver(committer, predicate, challengeSpaceSize)
+	if err != nil {
+		return nil, err
+	}
+	return &FunctionRangeProver{prover}, nil
+}
+
+// getFunctionRangePredicate returns the predicate y = s_1 OR ... OR y = s_k where
+// s_1, ..., s_k are the distinct values in functionRange (in the order of their first
+// appearance, so that prover and verifier obtain the same predicate).
+func getFunctionRangePredicate(functionRange []*big.Int) (*Predicate, error) {
+	if len(functionRange) == 0 {
+		return nil, fmt.Errorf("function range is empty")
+	}
+	var values []*Predicate
+	seen := make(map[string]bool)
+	for _, v := range functionRange {
+		if seen[v.String()] {
+			continue
+		}
+		seen[v.String()] = true
+		values = append(values, NewEqPredicate(v))
+	}
+	return NewOrPredicate(values...), nil
+}
+
+type FunctionRangeVerifier struct {
+	*CompoundPredicateVerifier
+}
+
+// NewFunctionRangeVerifier returns FunctionRangeVerifier for the commitment stored in receiver.
+// T is the bound for the committed values (as in Committer).
+func NewFunctionRangeVerifier(receiver *Receiver, functionRange []*big.Int, T *big.Int,
+	challengeSpaceSize int) (*FunctionRangeVerifier, error) {
+	predicate, err := getFunctionRangePredicate(functionRange)
+	if err != nil {
+		return nil, err
+	}
+	verifier, err := NewCompoundPredicateVerifier(receiver, predicate, T, challengeSpaceSize)
+	if err != nil {
+		return nil, err
+	}
+	return &FunctionRangeVerifier{verifier}, nil
+}
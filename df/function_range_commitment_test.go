@@ -0,0 +1,82 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package df
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestDFCommitmentFunctionRange demonstrates how to prove that the committed value y
+// is in the range of the squaring function f(i) = i^2 defined on [-3, 3].
+func TestDFCommitmentFunctionRange(t *testing.T) {
+	receiver, err := NewReceiver(128, 80)
+	if err != nil {
+		t.Errorf("error in NewReceiver: %v", err)
+	}
+	// n^2 is used for T - but any other value can be used as well
+	T := new(big.Int).Mul(receiver.QRSpecialRSA.N, receiver.QRSpecialRSA.N)
+	challengeSpaceSize := 80
+
+	var functionRange []*big.Int
+	for i := int64(-3); i <= 3; i++ {
+		functionRange = append(functionRange, big.NewInt(i*i))
+	}
+
+	committer := NewCommitter(receiver.QRSpecialRSA.N,
+		receiver.G, receiver.H, T, receiver.K)
+	y := big.NewInt(4)
+	c, err := committer.GetCommitMsg(y)
+	if err != nil {
+		t.Errorf("error in computing commit msg: %v", err)
+	}
+	receiver.SetCommitment(c)
+	_, r := committer.GetDecommitMsg()
+
+	prover, err := NewFunctionRangeProver(committer, y, r, functionRange, challengeSpaceSize)
+	if err != nil {
+		t.Errorf("error in instantiating FunctionRangeProver: %v", err)
+	}
+	verifier, err := NewFunctionRangeVerifier(receiver, functionRange, T, challengeSpaceSize)
+	if err != nil {
+		t.Errorf("error in instantiating FunctionRangeVerifier: %v", err)
+	}
+	err = verifier.SetProofRandomData(prover.GetProofRandomData())
+	if err != nil {
+		t.Errorf("error when calling SetProofRandomData: %v", err)
+	}
+	challenge := verifier.GetChallenge()
+	proved := verifier.Verify(prover.GetProofData(challenge))
+	assert.Equal(t, true, proved, "DamgardFujisaki function range proof failed.")
+
+	// 5 is not a square
+	_, err = committer.GetCommitMsg(big.NewInt(5))
+	if err != nil {
+		t.Errorf("error in computing commit msg: %v", err)
+	}
+	_, r = committer.GetDecommitMsg()
+	_, err = NewFunctionRangeProver(committer, big.NewInt(5), r, functionRange,
+		challengeSpaceSize)
+	assert.NotNil(t, err, "FunctionRangeProver should fail for a value not in the range")
+
+	// y and r are not the opening of the commitment
+	_, err = NewFunctionRangeProver(committer, y, r, functionRange, challengeSpaceSize)
+	assert.NotNil(t, err, "FunctionRangeProver should fail for a wrong opening")
+}
@@ -0,0 +1,233 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package df
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/awsong/crypto/common"
+)
+
+// GCDProver proves that the values a and b hidden in commitments cA = g^a * h^rA and
+// cB = g^b * h^rB have gcd(a, b) = d for a public d.
+// Proof consists of four parallel proofs (all using the same challenge):
+// (1) proof that d divides a - cA is seen as a commitment (g^d)^(a/d) * h^rA and
+// prover proves that it can open it,
+// (2) the same for b,
+// (3) proof that cUA = g^(u*a) * h^rUA hides the product of u and a, where u is hidden in cU,
+// (4) proof that cVB = g^(v*b) * h^rVB hides the product of v and b, where v is hidden in cV.
+// Here u and v are Bezout coefficients (u*a + v*b = d). Prover also reveals
+// s = rUA + rVB and verifier checks cUA * cVB = g^d * h^s, that means u*a + v*b = d.
+// Note that the two divisibility proofs and the Bezout equation imply gcd(a, b) = d.
+type GCDProver struct {
+	openingProverA *OpeningProver
+	openingProverB *OpeningProver
+	mulProverU     *MultiplicationProver
+	mulProverV     *MultiplicationProver
+	commitments    []*big.Int // cU, cV, cUA, cVB
+	s              *big.Int   // rUA + rVB
+}
+
+// NewGCDProver returns GCDProver. It returns an error if d is not positive (gcd(0, 0) = 0
+// cannot be proved as 0 does not divide 0) or if gcd(a, b) != d.
+func NewGCDProver(committerA, committerB *Committer, d *big.Int,
+	challengeSpaceSize int) (*GCDProver, error) {
+	if d.Sign() <= 0 {
+		return nil, fmt.Errorf("d needs to be positive")
+	}
+	a, rA := committerA.GetDecommitMsg()
+	b, rB := committerB.GetDecommitMsg()
+
+	// u * a + v * b = gcd(a, b)
+	gcd, u, v := common.ExtGCD(a, b)
+	if gcd.Cmp(d) != 0 {
+		return nil, fmt.Errorf("gcd of committed values is not d")
+	}
+
+	gToD := committerA.QRSpecialRSA.Exp(committerA.G, d)
+	openingProverA, err := newDivisibilityOpeningProver(committerA, gToD, a, rA, d,
+		challengeSpaceSize)
+	if err != nil {
+		return nil, err
+	}
+	openingProverB, err := newDivisibilityOpeningProver(committerB, gToD, b, rB, d,
+		challengeSpaceSize)
+	if err != nil {
+		return nil, err
+	}
+
+	values := []*big.Int{u, v, new(big.Int).Mul(u, a), new(big.Int).Mul(v, b)}
+	committers := make([]*Committer, len(values))
+	commitments := make([]*big.Int, len(values))
+	for i, val := range values {
+		committer := NewCommitter(committerA.QRSpecialRSA.N,
+			committerA.G, committerA.H, committerA.T, committerA.K)
+		commitment, err := committer.GetCommitMsg(val)
+		if err != nil {
+			return nil, fmt.Errorf("error when creating commit msg")
+		}
+		committers[i] = committer
+		commitments[i] = commitment
+	}
+
+	_, rUA := committers[2].GetDecommitMsg()
+	_, rVB := committers[3].GetDecommitMsg()
+
+	return &GCDProver{
+		openingProverA: openingProverA,
+		openingProverB: openingProverB,
+		mulProverU: NewMultiplicationProver(committers[0], committerA, committers[2],
+			challengeSpaceSize),
+		mulProverV: NewMultiplicationProver(committers[1], committerB, committers[3],
+			challengeSpaceSize),
+		commitments: commitments,
+		s:           new(big.Int).Add(rUA, rVB),
+	}, nil
+}
+
+// newDivisibilityOpeningProver returns OpeningProver which proves that the commitment
+// g^x * h^r can be opened as (g^d)^(x/d) * h^r.
+func newDivisibilityOpeningProver(committer *Committer, gToD, x, r, d *big.Int,
+	challengeSpaceSize int) (*OpeningProver, error) {
+	committerD := NewCommitter(committer.QRSpecialRSA.N, gToD, committer.H, committer.T,
+		committer.K)
+	_, err := committerD.GetCommitMsgWithGivenR(new(big.Int).Div(x, d), r)
+	if err != nil {
+		return nil, fmt.Errorf("error when creating commit msg with given r")
+	}
+	return NewOpeningProver(committerD, challengeSpaceSize), nil
+}
+
+// GetVerifierInitializationData returns data that are needed by GCDVerifier
+// and are known only after the initialization of GCDProver: commitments
+// to u, v, u*a, v*b and s = rUA + rVB.
+func (p *GCDProver) GetVerifierInitializationData() ([]*big.Int, *big.Int) {
+	return p.commitments, p.s
+}
+
+func (p *GCDProver) GetProofRandomData() []*big.Int {
+	tA := p.openingProverA.GetProofRandomData()
+	tB := p.openingProverB.GetProofRandomData()
+	d1U, d2U, d3U := p.mulProverU.GetProofRandomData()
+	d1V, d2V, d3V := p.mulProverV.GetProofRandomData()
+	return []*big.Int{tA, tB, d1U, d2U, d3U, d1V, d2V, d3V}
+}
+
+func (p *GCDProver) GetProofData(challenge *big.Int) []*big.Int {
+	s1A, s2A := p.openingProverA.GetProofData(challenge)
+	s1B, s2B := p.openingProverB.GetProofData(challenge)
+	u1U, uU, v1U, v2U, v3U := p.mulProverU.GetProofData(challenge)
+	u1V, uV, v1V, v2V, v3V := p.mulProverV.GetProofData(challenge)
+	return []*big.Int{s1A, s2A, s1B, s2B, u1U, uU, v1U, v2U, v3U, u1V, uV, v1V, v2V, v3V}
+}
+
+type GCDVerifier struct {
+	openingVerifierA *OpeningVerifier
+	openingVerifierB *OpeningVerifier
+	mulVerifierU     *MultiplicationVerifier
+	mulVerifierV     *MultiplicationVerifier
+}
+
+func NewGCDVerifier(receiverA, receiverB *Receiver, d *big.Int,
+	commitments []*big.Int, s *big.Int, challengeSpaceSize int) (*GCDVerifier, error) {
+	if d.Sign() <= 0 {
+		return nil, fmt.Errorf("d needs to be positive")
+	}
+	if len(commitments) != 4 {
+		return nil, fmt.Errorf("the length of commitments is not correct")
+	}
+
+	// check: cUA * cVB = g^d * h^s
+	check := receiverA.QRSpecialRSA.Mul(commitments[2], commitments[3])
+	if !common.ConstantTimeEq(check, receiverA.ComputeCommit(d, s)) {
+		return nil, fmt.Errorf("commitments to products do not sum up to d")
+	}
+
+	primes := receiverA.QRSpecialRSA.GetPrimes()
+	gToD := receiverA.QRSpecialRSA.Exp(receiverA.G, d)
+	receiverDA, err := NewReceiverFromParams(primes, gToD, receiverA.H, receiverA.K)
+	if err != nil {
+		return nil, fmt.Errorf("error when calling NewReceiverFromParams")
+	}
+	receiverDA.SetCommitment(receiverA.Commitment)
+	receiverDB, err := NewReceiverFromParams(primes, gToD, receiverA.H, receiverA.K)
+	if err != nil {
+		return nil, fmt.Errorf("error when calling NewReceiverFromParams")
+	}
+	receiverDB.SetCommitment(receiverB.Commitment)
+
+	receivers := make([]*Receiver, len(commitments))
+	for i, comm := range commitments {
+		receiver, err := NewReceiverFromParams(primes, receiverA.G, receiverA.H, receiverA.K)
+		if err != nil {
+			return nil, fmt.Errorf("error when calling NewReceiverFromParams")
+		}
+		receiver.SetCommitment(comm)
+		receivers[i] = receiver
+	}
+
+	return &GCDVerifier{
+		openingVerifierA: NewOpeningVerifier(receiverDA, challengeSpaceSize),
+		openingVerifierB: NewOpeningVerifier(receiverDB, challengeSpaceSize),
+		mulVerifierU: NewMultiplicationVerifier(receivers[0], receiverA, receivers[2],
+			challengeSpaceSize),
+		mulVerifierV: NewMultiplicationVerifier(receivers[1], receiverB, receivers[3],
+			challengeSpaceSize),
+	}, nil
+}
+
+func (v *GCDVerifier) SetProofRandomData(proofRandomData []*big.Int) error {
+	if len(proofRandomData) != 8 {
+		return fmt.Errorf("the length of proofRandomData is not correct")
+	}
+	v.openingVerifierA.SetProofRandomData(proofRandomData[0])
+	v.openingVerifierB.SetProofRandomData(proofRandomData[1])
+	v.mulVerifierU.SetProofRandomData(proofRandomData[2], proofRandomData[3],
+		proofRandomData[4])
+	v.mulVerifierV.SetProofRandomData(proofRandomData[5], proofRandomData[6],
+		proofRandomData[7])
+	return nil
+}
+
+// GetChallenge returns a challenge which is used in all four sub-proofs.
+func (v *GCDVerifier) GetChallenge() *big.Int {
+	challenge := v.openingVerifierA.GetChallenge()
+	v.SetChallenge(challenge)
+	return challenge
+}
+
+// SetChallenge is used when Fiat-Shamir is used - when challenge is generated using hash by the prover.
+func (v *GCDVerifier) SetChallenge(challenge *big.Int) {
+	v.openingVerifierA.SetChallenge(challenge)
+	v.openingVerifierB.SetChallenge(challenge)
+	v.mulVerifierU.SetChallenge(challenge)
+	v.mulVerifierV.SetChallenge(challenge)
+}
+
+func (v *GCDVerifier) Verify(proofData []*big.Int) bool {
+	if len(proofData) != 14 {
+		return false
+	}
+	return v.openingVerifierA.Verify(proofData[0], proofData[1]) &&
+		v.openingVerifierB.Verify(proofData[2], proofData[3]) &&
+		v.mulVerifierU.Verify(proofData[4], proofData[5], proofData[6], proofData[7],
+			proofData[8]) &&
+		v.mulVerifierV.Verify(proofData[9], proofData[10], proofData[11], proofData[12],
+			proofData[13])
+}
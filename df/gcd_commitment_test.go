@@ -0,0 +1,106 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package df
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestDFCommitmentGCD demonstrates how to prove that for given commitments
+// cA = g^a * h^rA, cB = g^b * h^rB it holds gcd(a, b) = d for a public d.
+func TestDFCommitmentGCD(t *testing.T) {
+	receiverA, err := NewReceiver(128, 80)
+	if err != nil {
+		t.Errorf("Error in NewReceiver: %v", err)
+	}
+	receiverB, err := NewReceiverFromParams(receiverA.QRSpecialRSA.GetPrimes(),
+		receiverA.G, receiverA.H, receiverA.K)
+	if err != nil {
+		t.Errorf("Error in NewReceiverFromParams: %v", err)
+	}
+
+	// n^2 is used for T - but any other value can be used as well
+	T := new(big.Int).Mul(receiverA.QRSpecialRSA.N, receiverA.QRSpecialRSA.N)
+	committerA := NewCommitter(receiverA.QRSpecialRSA.N,
+		receiverA.G, receiverA.H, T, receiverA.K)
+	committerB := NewCommitter(receiverA.QRSpecialRSA.N,
+		receiverA.G, receiverA.H, T, receiverA.K)
+
+	cA, err := committerA.GetCommitMsg(big.NewInt(12))
+	if err != nil {
+		t.Errorf("Error in computing commit msg: %v", err)
+	}
+	cB, err := committerB.GetCommitMsg(big.NewInt(18))
+	if err != nil {
+		t.Errorf("Error in computing commit msg: %v", err)
+	}
+	receiverA.SetCommitment(cA)
+	receiverB.SetCommitment(cB)
+
+	challengeSpaceSize := 80
+	d := big.NewInt(6)
+	prover, err := NewGCDProver(committerA, committerB, d, challengeSpaceSize)
+	if err != nil {
+		t.Errorf("Error in instantiating GCDProver: %v", err)
+	}
+
+	commitments, s := prover.GetVerifierInitializationData()
+	verifier, err := NewGCDVerifier(receiverA, receiverB, d, commitments, s,
+		challengeSpaceSize)
+	if err != nil {
+		t.Errorf("Error in instantiating GCDVerifier: %v", err)
+	}
+
+	proofRandomData := prover.GetProofRandomData()
+	err = verifier.SetProofRandomData(proofRandomData)
+	if err != nil {
+		t.Errorf("Error when calling SetProofRandomData: %v", err)
+	}
+	challenge := verifier.GetChallenge()
+	proofData := prover.GetProofData(challenge)
+	proved := verifier.Verify(proofData)
+	assert.Equal(t, true, proved, "DamgardFujisaki GCD proof failed.")
+
+	// gcd(12, 18) is not 4
+	_, err = NewGCDProver(committerA, committerB, big.NewInt(4), challengeSpaceSize)
+	assert.NotNil(t, err, "GCDProver should not be instantiated for a wrong gcd")
+
+	// verifier for a wrong gcd must not accept the commitments of a proof for the correct one
+	_, err = NewGCDVerifier(receiverA, receiverB, big.NewInt(4), commitments, s,
+		challengeSpaceSize)
+	assert.NotNil(t, err, "GCDVerifier should not accept the proof for a wrong gcd")
+
+	// gcd(0, 0) = 0 is rejected (0 does not divide 0)
+	_, err = committerA.GetCommitMsg(big.NewInt(0))
+	if err != nil {
+		t.Errorf("Error in computing commit msg: %v", err)
+	}
+	_, err = committerB.GetCommitMsg(big.NewInt(0))
+	if err != nil {
+		t.Errorf("Error in computing commit msg: %v", err)
+	}
+	for _, d := range []*big.Int{big.NewInt(0), big.NewInt(-6)} {
+		_, err = NewGCDProver(committerA, committerB, d, challengeSpaceSize)
+		assert.NotNil(t, err, "GCDProver should fail for d = %v", d)
+		_, err = NewGCDVerifier(receiverA, receiverB, d, commitments, s, challengeSpaceSize)
+		assert.NotNil(t, err, "GCDVerifier should fail for d = %v", d)
+	}
+}
@@ -0,0 +1,184 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package df
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/awsong/crypto/common"
+	"github.com/awsong/crypto/schnorr"
+)
+
+// GEEProver (group exponent equality) proves that the exponent x in y = g^x mod p
+// (g is a generator of the Schnorr group) is the same as the value x committed
+// in the DF commitment c = G^x * H^r mod N. This links proofs in Schnorr groups (where
+// exponents are computed modulo q) with proofs about DF commitments (where values are integers).
+// Prover chooses rhoX from [0, q * 2^(challengeSpaceSize + K)) and rhoR
+// from [0, 2^(B + 2*NLength + challengeSpaceSize)) and sends t1 = g^rhoX mod p and
+// t2 = G^rhoX * H^rhoR mod N. For challenge c it responds with sX = rhoX + c*x and
+// sR = rhoR + c*r (in Z, not modulo). Verifier checks g^sX = t1 * y^c mod p,
+// G^sX * H^sR = t2 * c^challenge mod N and that sX is smaller than
+// q * 2^(challengeSpaceSize + K + 1).
+// This alone proves only that the DF value is congruent to the exponent modulo q (the DF
+// value could be x + k*q, the bound on sX does not prevent it), thus prover additionally
+// proves (using CompoundPredicateProver with the same challenge) that the DF value is
+// in [0, q), which together with the congruence means that the DF value is the exponent.
+// GEEProver is not composed of a schnorr.Prover and a DF prover, because these choose their
+// random values independently (schnorr.Prover modulo q), while both t1 and t2 need to use
+// the same integer rhoX.
+type GEEProver struct {
+	group              *schnorr.Group
+	committer          *Committer
+	rangeProver        *CompoundPredicateProver
+	challengeSpaceSize int
+	rhoX               *big.Int
+	rhoR               *big.Int
+}
+
+// getExponentRangePredicate returns the predicate x >= 0 AND x <= q - 1.
+func getExponentRangePredicate(q *big.Int) *Predicate {
+	return NewAndPredicate(NewGePredicate(big.NewInt(0)),
+		NewLePredicate(new(big.Int).Sub(q, big.NewInt(1))))
+}
+
+// NewGEEProver returns GEEProver for y = group.G^x mod group.P where x is the value committed
+// in committer. It returns an error if x is not from [0, group.Q).
+func NewGEEProver(group *schnorr.Group, committer *Committer,
+	challengeSpaceSize int) (*GEEProver, error) {
+	x, _ := committer.GetDecommitMsg()
+	if x == nil || x.Sign() < 0 || x.Cmp(group.Q) >= 0 {
+		return nil, fmt.Errorf("committed value needs to be in [0, q)")
+	}
+	rangeProver, err := NewCompoundPredicateProver(committer,
+		getExponentRangePredicate(group.Q), challengeSpaceSize)
+	if err != nil {
+		return nil, err
+	}
+
+	return &GEEProver{
+		group:              group,
+		committer:          committer,
+		rangeProver:        rangeProver,
+		challengeSpaceSize: challengeSpaceSize,
+	}, nil
+}
+
+// GetProofRandomData returns t1 = g^rhoX mod p, t2 = G^rhoX * H^rhoR mod N and the proof
+// random data of the range proof.
+func (p *GEEProver) GetProofRandomData() (*big.Int, *big.Int, []*big.Int) {
+	b1 := new(big.Int).Lsh(p.group.Q, uint(p.challengeSpaceSize+p.committer.K))
+	p.rhoX = common.GetRandomInt(b1)
+	nLen := p.committer.QRSpecialRSA.N.BitLen()
+	b2 := new(big.Int).Lsh(big.NewInt(1), uint(p.committer.B+2*nLen+p.challengeSpaceSize))
+	p.rhoR = common.GetRandomInt(b2)
+
+	t1 := p.group.Exp(p.group.G, p.rhoX)
+	t2 := p.committer.ComputeCommit(p.rhoX, p.rhoR)
+	return t1, t2, p.rangeProver.GetProofRandomData()
+}
+
+// GetProofData returns sX = rhoX + challenge*x and sR = rhoR + challenge*r (in Z, not modulo)
+// and the challenges and the proof data of the range proof.
+func (p *GEEProver) GetProofData(challenge *big.Int) (*big.Int, *big.Int, []*big.Int,
+	[]*big.Int) {
+	x, r := p.committer.GetDecommitMsg()
+	sX := new(big.Int).Mul(challenge, x)
+	sX.Add(sX, p.rhoX)
+	sR := new(big.Int).Mul(challenge, r)
+	sR.Add(sR, p.rhoR)
+	rangeChallenges, rangeProofData := p.rangeProver.GetProofData(challenge)
+	return sX, sR, rangeChallenges, rangeProofData
+}
+
+type GEEVerifier struct {
+	group              *schnorr.Group
+	y                  *big.Int
+	receiver           *Receiver
+	rangeVerifier      *CompoundPredicateVerifier
+	challengeSpaceSize int
+	challenge          *big.Int
+	t1                 *big.Int
+	t2                 *big.Int
+}
+
+// NewGEEVerifier returns GEEVerifier which verifies that y = group.G^x mod group.P where x
+// is committed in receiver.Commitment. T is the bound for the values committed in DF
+// commitments (as in Committer).
+func NewGEEVerifier(group *schnorr.Group, y *big.Int, receiver *Receiver, T *big.Int,
+	challengeSpaceSize int) (*GEEVerifier, error) {
+	if group.Q.BitLen() <= challengeSpaceSize {
+		return nil, fmt.Errorf("challenge space needs to be smaller than q")
+	}
+	rangeVerifier, err := NewCompoundPredicateVerifier(receiver,
+		getExponentRangePredicate(group.Q), T, challengeSpaceSize)
+	if err != nil {
+		return nil, err
+	}
+
+	return &GEEVerifier{
+		group:              group,
+		y:                  y,
+		receiver:           receiver,
+		rangeVerifier:      rangeVerifier,
+		challengeSpaceSize: challengeSpaceSize,
+	}, nil
+}
+
+func (v *GEEVerifier) SetProofRandomData(t1, t2 *big.Int,
+	rangeProofRandomData []*big.Int) error {
+	v.t1 = t1
+	v.t2 = t2
+	return v.rangeVerifier.SetProofRandomData(rangeProofRandomData)
+}
+
+func (v *GEEVerifier) GetChallenge() *big.Int {
+	b := new(big.Int).Lsh(big.NewInt(1), uint(v.challengeSpaceSize))
+	challenge := common.GetRandomInt(b)
+	v.SetChallenge(challenge)
+	return challenge
+}
+
+// SetChallenge is used when Fiat-Shamir is used - when challenge is generated using hash by the prover.
+func (v *GEEVerifier) SetChallenge(challenge *big.Int) {
+	v.challenge = challenge
+	v.rangeVerifier.SetChallenge(challenge)
+}
+
+// Verify checks the equality modulo q and the range proof that the DF value is in [0, q).
+func (v *GEEVerifier) Verify(sX, sR *big.Int, rangeChallenges,
+	rangeProofData []*big.Int) bool {
+	if !v.rangeVerifier.Verify(rangeChallenges, rangeProofData) {
+		return false
+	}
+	bound := new(big.Int).Lsh(v.group.Q, uint(v.challengeSpaceSize+v.receiver.K+1))
+	if sX.Sign() < 0 || sX.Cmp(bound) >= 0 {
+		return false
+	}
+
+	// check g^sX = t1 * y^challenge mod p
+	left1 := v.group.Exp(v.group.G, sX)
+	right1 := v.group.Mul(v.t1, v.group.Exp(v.y, v.challenge))
+
+	// check G^sX * H^sR = t2 * c^challenge mod N
+	left2 := v.receiver.ComputeCommit(sX, sR)
+	right2 := v.receiver.QRSpecialRSA.Exp(v.receiver.Commitment, v.challenge)
+	right2 = v.receiver.QRSpecialRSA.Mul(v.t2, right2)
+
+	return common.ConstantTimeEq(left1, right1) && common.ConstantTimeEq(left2, right2)
+}
@@ -0,0 +1,129 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package df
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/awsong/crypto/common"
+	"github.com/awsong/crypto/schnorr"
+	"github.com/stretchr/testify/assert"
+)
+
+func proveGroupExponentEquality(prover *GEEProver, verifier *GEEVerifier) (bool, error) {
+	t1, t2, rangeProofRandomData := prover.GetProofRandomData()
+	if err := verifier.SetProofRandomData(t1, t2, rangeProofRandomData); err != nil {
+		return false, err
+	}
+	return verifier.Verify(prover.GetProofData(verifier.GetChallenge())), nil
+}
+
+// TestGroupExponentEquality demonstrates how to prove that the exponent x in y = g^x mod p
+// is the same as the value committed in DF commitment c = G^x * H^r mod N.
+func TestGroupExponentEquality(t *testing.T) {
+	group, err := schnorr.NewGroup(160)
+	if err != nil {
+		t.Errorf("error when creating Schnorr group: %v", err)
+	}
+	receiver, err := NewReceiver(128, 80)
+	if err != nil {
+		t.Errorf("error in NewReceiver: %v", err)
+	}
+	// n^2 is used for T - but any other value can be used as well
+	T := new(big.Int).Mul(receiver.QRSpecialRSA.N, receiver.QRSpecialRSA.N)
+	committer := NewCommitter(receiver.QRSpecialRSA.N,
+		receiver.G, receiver.H, T, receiver.K)
+
+	x := common.GetRandomInt(group.Q)
+	y := group.Exp(group.G, x)
+	c, err := committer.GetCommitMsg(x)
+	if err != nil {
+		t.Errorf("error in computing commit msg: %v", err)
+	}
+	receiver.SetCommitment(c)
+
+	challengeSpaceSize := 80
+	prover, err := NewGEEProver(group, committer, challengeSpaceSize)
+	if err != nil {
+		t.Errorf("error in NewGEEProver: %v", err)
+	}
+	verifier, err := NewGEEVerifier(group, y, receiver, T, challengeSpaceSize)
+	if err != nil {
+		t.Errorf("error in NewGEEVerifier: %v", err)
+	}
+	proved, err := proveGroupExponentEquality(prover, verifier)
+	if err != nil {
+		t.Errorf("error in group exponent equality proof: %v", err)
+	}
+	assert.Equal(t, true, proved, "group exponent equality proof failed")
+
+	// y for a different exponent
+	yOther := group.Exp(group.G, new(big.Int).Add(x, big.NewInt(1)))
+	verifier, _ = NewGEEVerifier(group, yOther, receiver, T, challengeSpaceSize)
+	proved, err = proveGroupExponentEquality(prover, verifier)
+	if err != nil {
+		t.Errorf("error in group exponent equality proof: %v", err)
+	}
+	assert.Equal(t, false, proved, "group exponent equality proof should fail for different x")
+}
+
+// TestGroupExponentEqualityModQ checks that the proof fails when the DF commitment hides
+// x + q and y = g^x (the values are equal only modulo q). The prover is built directly
+// as NewGEEProver rejects such values.
+func TestGroupExponentEqualityModQ(t *testing.T) {
+	group, err := schnorr.NewGroup(160)
+	if err != nil {
+		t.Errorf("error when creating Schnorr group: %v", err)
+	}
+	receiver, err := NewReceiver(128, 80)
+	if err != nil {
+		t.Errorf("error in NewReceiver: %v", err)
+	}
+	T := new(big.Int).Mul(receiver.QRSpecialRSA.N, receiver.QRSpecialRSA.N)
+
+	for i := 0; i < 5; i++ {
+		x := common.GetRandomInt(group.Q)
+		y := group.Exp(group.G, x)
+		committer, r, err := getCommitterAndReceiver(receiver, T, new(big.Int).Add(x, group.Q))
+		if err != nil {
+			t.Errorf("error when committing: %v", err)
+		}
+
+		rangeProver, err := newCompoundPredicateProver(committer,
+			getExponentRangePredicate(group.Q), 80)
+		if err != nil {
+			t.Errorf("error in newCompoundPredicateProver: %v", err)
+		}
+		prover := &GEEProver{
+			group:              group,
+			committer:          committer,
+			rangeProver:        rangeProver,
+			challengeSpaceSize: 80,
+		}
+		verifier, err := NewGEEVerifier(group, y, r, T, 80)
+		if err != nil {
+			t.Errorf("error in NewGEEVerifier: %v", err)
+		}
+		proved, err := proveGroupExponentEquality(prover, verifier)
+		if err != nil {
+			t.Errorf("error in group exponent equality proof: %v", err)
+		}
+		assert.Equal(t, false, proved, "group exponent equality proof should fail for x + q")
+	}
+}
@@ -0,0 +1,40 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package df
+
+import (
+	"math/big"
+)
+
+// getCommitterAndReceiver returns a Committer with a commitment to x and a Receiver (with
+// the same parameters as receiver) which holds this commitment.
+func getCommitterAndReceiver(receiver *Receiver, T, x *big.Int) (*Committer, *Receiver,
+	error) {
+	committer := NewCommitter(receiver.QRSpecialRSA.N, receiver.G, receiver.H, T, receiver.K)
+	c, err := committer.GetCommitMsg(x)
+	if err != nil {
+		return nil, nil, err
+	}
+	r, err := NewReceiverFromParams(receiver.QRSpecialRSA.GetPrimes(), receiver.G, receiver.H,
+		receiver.K)
+	if err != nil {
+		return nil, nil, err
+	}
+	r.SetCommitment(c)
+	return committer, r, nil
+}
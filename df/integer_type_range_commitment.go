@@ -0,0 +1,151 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package df
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+)
+
+// Int32RangeProver, Uint64RangeProver and Int64RangeProver prove that the commitment
+// c = g^x * h^r hides a value of the given integer type, for example -2^31 <= x <= 2^31 - 1
+// for int32. The range is proved using CompoundPredicateProver for the predicate
+// x >= min AND x <= max.
+
+type Int32RangeProver struct {
+	*CompoundPredicateProver
+}
+
+// NewInt32RangeProver returns Int32RangeProver. It returns an error if (x, r) is not
+// the opening of the commitment in committer or if x is not an int32 value.
+func NewInt32RangeProver(committer *Committer, x, r *big.Int,
+	challengeSpaceSize int) (*Int32RangeProver, error) {
+	prover, err := newIntegerTypeRangeProver(committer, x, r, int32Range, challengeSpaceSize)
+	if err != nil {
+		return nil, err
+	}
+	return &Int32RangeProver{prover}, nil
+}
+
+type Uint64RangeProver struct {
+	*CompoundPredicateProver
+}
+
+// NewUint64RangeProver returns Uint64RangeProver. It returns an error if (x, r) is not
+// the opening of the commitment in committer or if x is not an uint64 value.
+func NewUint64RangeProver(committer *Committer, x, r *big.Int,
+	challengeSpaceSize int) (*Uint64RangeProver, error) {
+	prover, err := newIntegerTypeRangeProver(committer, x, r, uint64Range, challengeSpaceSize)
+	if err != nil {
+		return nil, err
+	}
+	return &Uint64RangeProver{prover}, nil
+}
+
+type Int64RangeProver struct {
+	*CompoundPredicateProver
+}
+
+// NewInt64RangeProver returns Int64RangeProver. It returns an error if (x, r) is not
+// the opening of the commitment in committer or if x is not an int64 value.
+func NewInt64RangeProver(committer *Committer, x, r *big.Int,
+	challengeSpaceSize int) (*Int64RangeProver, error) {
+	prover, err := newIntegerTypeRangeProver(committer, x, r, int64Range, challengeSpaceSize)
+	if err != nil {
+		return nil, err
+	}
+	return &Int64RangeProver{prover}, nil
+}
+
+// integerTypeRange holds the smallest and the biggest value of an integer type.
+type integerTypeRange struct {
+	name string
+	min  *big.Int
+	max  *big.Int
+}
+
+var (
+	int32Range  = &integerTypeRange{"int32", big.NewInt(math.MinInt32), big.NewInt(math.MaxInt32)}
+	uint64Range = &integerTypeRange{"uint64", big.NewInt(0), new(big.Int).SetUint64(math.MaxUint64)}
+	int64Range  = &integerTypeRange{"int64", big.NewInt(math.MinInt64), big.NewInt(math.MaxInt64)}
+)
+
+func (t *integerTypeRange) predicate() *Predicate {
+	return NewAndPredicate(NewGePredicate(t.min), NewLePredicate(t.max))
+}
+
+func newIntegerTypeRangeProver(committer *Committer, x, r *big.Int, t *integerTypeRange,
+	challengeSpaceSize int) (*CompoundPredicateProver, error) {
+	committedValue, committedR := committer.GetDecommitMsg()
+	if committedValue == nil || x.Cmp(committedValue) != 0 || r.Cmp(committedR) != 0 {
+		return nil, fmt.Errorf("x and r are not the opening of the commitment")
+	}
+	if x.Cmp(t.min) < 0 || x.Cmp(t.max) > 0 {
+		return nil, fmt.Errorf("x is not a valid %s value", t.name)
+	}
+	return NewCompoundPredicateProver(committer, t.predicate(), challengeSpaceSize)
+}
+
+type Int32RangeVerifier struct {
+	*CompoundPredicateVerifier
+}
+
+// NewInt32RangeVerifier returns Int32RangeVerifier for the commitment stored in receiver.
+// T is the bound for the committed values (as in Committer).
+func NewInt32RangeVerifier(receiver *Receiver, T *big.Int,
+	challengeSpaceSize int) (*Int32RangeVerifier, error) {
+	verifier, err := NewCompoundPredicateVerifier(receiver, int32Range.predicate(), T,
+		challengeSpaceSize)
+	if err != nil {
+		return nil, err
+	}
+	return &Int32RangeVerifier{verifier}, nil
+}
+
+type Uint64RangeVerifier struct {
+	*CompoundPredicateVerifier
+}
+
+// NewUint64RangeVerifier returns Uint64RangeVerifier for the commitment stored in receiver.
+// T is the bound for the committed values (as in Committer).
+func NewUint64RangeVerifier(receiver *Receiver, T *big.Int,
+	challengeSpaceSize int) (*Uint64RangeVerifier, error) {
+	verifier, err := NewCompoundPredicateVerifier(receiver, uint64Range.predicate(), T,
+		challengeSpaceSize)
+	if err != nil {
+		return nil, err
+	}
+	return &Uint64RangeVerifier{verifier}, nil
+}
+
+type Int64RangeVerifier struct {
+	*CompoundPredicateVerifier
+}
+
+// NewInt64RangeVerifier returns Int64RangeVerifier for the commitment stored in receiver.
+// T is the bound for the committed values (as in Committer).
+func NewInt64RangeVerifier(receiver *Receiver, T *big.Int,
+	challengeSpaceSize int) (*Int64RangeVerifier, error) {
+	verifier, err := NewCompoundPredicateVerifier(receiver, int64Range.predicate(), T,
+		challengeSpaceSize)
+	if err != nil {
+		return nil, err
+	}
+	return &Int64RangeVerifier{verifier}, nil
+}
@@ -0,0 +1,141 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package df
+
+import (
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// proveIntegerType runs the range proof for the given integer type name ("int32",
+// "uint64" or "int64") for the committed value x, where the verifier checks verifierType.
+func proveIntegerType(receiver *Receiver, T, x *big.Int, proverType,
+	verifierType string) (bool, error) {
+	committer, r, err := getCommitterAndReceiver(receiver, T, x)
+	if err != nil {
+		return false, err
+	}
+	_, rand := committer.GetDecommitMsg()
+
+	var prover *CompoundPredicateProver
+	switch proverType {
+	case "int32":
+		p, err := NewInt32RangeProver(committer, x, rand, 80)
+		if err != nil {
+			return false, err
+		}
+		prover = p.CompoundPredicateProver
+	case "uint64":
+		p, err := NewUint64RangeProver(committer, x, rand, 80)
+		if err != nil {
+			return false, err
+		}
+		prover = p.CompoundPredicateProver
+	case "int64":
+		p, err := NewInt64RangeProver(committer, x, rand, 80)
+		if err != nil {
+			return false, err
+		}
+		prover = p.CompoundPredicateProver
+	}
+
+	var verifier *CompoundPredicateVerifier
+	switch verifierType {
+	case "int32":
+		v, err := NewInt32RangeVerifier(r, T, 80)
+		if err != nil {
+			return false, err
+		}
+		verifier = v.CompoundPredicateVerifier
+	case "uint64":
+		v, err := NewUint64RangeVerifier(r, T, 80)
+		if err != nil {
+			return false, err
+		}
+		verifier = v.CompoundPredicateVerifier
+	case "int64":
+		v, err := NewInt64RangeVerifier(r, T, 80)
+		if err != nil {
+			return false, err
+		}
+		verifier = v.CompoundPredicateVerifier
+	}
+
+	if err := verifier.SetProofRandomData(prover.GetProofRandomData()); err != nil {
+		return false, err
+	}
+	challenges, proofData := prover.GetProofData(verifier.GetChallenge())
+	return verifier.Verify(challenges, proofData), nil
+}
+
+// TestDFCommitmentIntegerTypeRange demonstrates how to prove that the committed value
+// is a valid value of an integer type.
+func TestDFCommitmentIntegerTypeRange(t *testing.T) {
+	receiver, err := NewReceiver(128, 80)
+	if err != nil {
+		t.Errorf("error in NewReceiver: %v", err)
+	}
+	// n^2 is used for T - but any other value can be used as well
+	T := new(big.Int).Mul(receiver.QRSpecialRSA.N, receiver.QRSpecialRSA.N)
+
+	valid := []struct {
+		x   *big.Int
+		typ string
+	}{
+		{big.NewInt(math.MinInt32), "int32"},
+		{big.NewInt(-5), "int32"},
+		{big.NewInt(math.MaxInt32), "int32"},
+		{big.NewInt(0), "uint64"},
+		{new(big.Int).SetUint64(math.MaxUint64), "uint64"},
+		{big.NewInt(math.MinInt64), "int64"},
+		{big.NewInt(math.MaxInt64), "int64"},
+	}
+	for _, test := range valid {
+		proved, err := proveIntegerType(receiver, T, test.x, test.typ, test.typ)
+		if err != nil {
+			t.Errorf("error in %s range proof: %v", test.typ, err)
+		}
+		assert.Equal(t, true, proved, "DamgardFujisaki %s range proof failed for %v.",
+			test.typ, test.x)
+	}
+
+	invalid := []struct {
+		x   *big.Int
+		typ string
+	}{
+		{big.NewInt(math.MinInt32 - 1), "int32"},
+		{big.NewInt(math.MaxInt32 + 1), "int32"},
+		{big.NewInt(-1), "uint64"},
+		{new(big.Int).Lsh(big.NewInt(1), 64), "uint64"},
+		{new(big.Int).Lsh(big.NewInt(1), 63), "int64"},
+	}
+	for _, test := range invalid {
+		_, err := proveIntegerType(receiver, T, test.x, test.typ, test.typ)
+		assert.NotNil(t, err, "%s range prover should fail for %v", test.typ, test.x)
+	}
+
+	// a valid int64 value which is not an int32 value is not accepted by int32 verifier
+	proved, err := proveIntegerType(receiver, T, big.NewInt(math.MaxInt32+1), "int64", "int32")
+	if err != nil {
+		t.Errorf("error in range proof: %v", err)
+	}
+	assert.Equal(t, false, proved, "int32 range proof should fail for an int64 value")
+}
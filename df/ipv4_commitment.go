@@ -0,0 +1,167 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package df
+
+import (
+	"fmt"
+	"math/big"
+	"net"
+)
+
+// ipv4BitLen is the bit length of IPv4 addresses.
+const ipv4BitLen = 32
+
+// IPv4ToInt returns the IPv4 address ip as an integer from [0, 2^32). It returns an
+// error if ip is not an IPv4 address.
+func IPv4ToInt(ip net.IP) (*big.Int, error) {
+	ip4 := ip.To4()
+	if ip4 == nil {
+		return nil, fmt.Errorf("not an IPv4 address")
+	}
+	return new(big.Int).SetBytes(ip4), nil
+}
+
+// IPv4Prover proves that the commitment c = g^addr * h^r hides a valid IPv4 address,
+// that is addr from [0, 2^32). It uses NBitRangeProver for n = 32.
+type IPv4Prover struct {
+	*NBitRangeProver
+}
+
+// NewIPv4Prover returns IPv4Prover. It returns an error if (addr, r) is not the opening
+// of the commitment in committer or if addr is not from [0, 2^32).
+func NewIPv4Prover(committer *Committer, addr, r *big.Int,
+	challengeSpaceSize int) (*IPv4Prover, error) {
+	if err := checkOpening(committer, addr, r); err != nil {
+		return nil, err
+	}
+	prover, err := NewNBitRangeProver(committer, addr, ipv4BitLen, challengeSpaceSize)
+	if err != nil {
+		return nil, err
+	}
+	return &IPv4Prover{prover}, nil
+}
+
+// checkOpening returns an error if (x, r) is not the opening of the commitment in committer.
+func checkOpening(committer *Committer, x, r *big.Int) error {
+	committedValue, committedR := committer.GetDecommitMsg()
+	if committedValue == nil || x.Cmp(committedValue) != 0 || r.Cmp(committedR) != 0 {
+		return fmt.Errorf("x and r are not the opening of the commitment")
+	}
+	return nil
+}
+
+type IPv4Verifier struct {
+	*NBitRangeVerifier
+}
+
+// NewIPv4Verifier returns IPv4Verifier for the commitment stored in receiver, where
+// bitCommitments are obtained by IPv4Prover.GetVerifierInitializationData.
+func NewIPv4Verifier(receiver *Receiver, bitCommitments []*big.Int,
+	challengeSpaceSize int) (*IPv4Verifier, error) {
+	verifier, err := NewNBitRangeVerifier(receiver, ipv4BitLen, bitCommitments,
+		challengeSpaceSize)
+	if err != nil {
+		return nil, err
+	}
+	return &IPv4Verifier{verifier}, nil
+}
+
+// IPv4SubnetProver proves that the commitment c = g^addr * h^r hides an IPv4 address
+// from the subnet network/prefix (CIDR notation) without revealing the address. It holds
+// c * g^(-network) = g^(addr - network) * h^r, thus prover proves (using NBitRangeProver
+// for n = 32 - prefix) that this commitment (which can be computed by the verifier) hides
+// a value from [0, 2^(32 - prefix)). The prefix needs to be in [0, 31] - for a /32 subnet
+// the address is public anyway.
+type IPv4SubnetProver struct {
+	*NBitRangeProver
+}
+
+// NewIPv4SubnetProver returns IPv4SubnetProver. It returns an error if (addr, r) is not
+// the opening of the commitment in committer, if network/prefix is not a valid subnet
+// or if addr is not in the subnet.
+func NewIPv4SubnetProver(committer *Committer, addr, r, network *big.Int, prefix int,
+	challengeSpaceSize int) (*IPv4SubnetProver, error) {
+	if err := checkOpening(committer, addr, r); err != nil {
+		return nil, err
+	}
+	if err := checkIPv4Subnet(network, prefix); err != nil {
+		return nil, err
+	}
+	host := new(big.Int).Sub(addr, network)
+	hostBound := new(big.Int).Lsh(big.NewInt(1), uint(ipv4BitLen-prefix))
+	if host.Sign() < 0 || host.Cmp(hostBound) >= 0 {
+		return nil, fmt.Errorf("address is not in the subnet")
+	}
+
+	hostCommitter := NewCommitter(committer.QRSpecialRSA.N, committer.G, committer.H,
+		committer.T, committer.K)
+	if _, err := hostCommitter.GetCommitMsgWithGivenR(host, r); err != nil {
+		return nil, err
+	}
+	prover, err := NewNBitRangeProver(hostCommitter, host, ipv4BitLen-prefix,
+		challengeSpaceSize)
+	if err != nil {
+		return nil, err
+	}
+	return &IPv4SubnetProver{prover}, nil
+}
+
+// checkIPv4Subnet returns an error if prefix is not in [0, 31] or if network is not
+// an IPv4 address with the last 32 - prefix bits zero.
+func checkIPv4Subnet(network *big.Int, prefix int) error {
+	if prefix < 0 || prefix >= ipv4BitLen {
+		return fmt.Errorf("prefix needs to be in [0, %d]", ipv4BitLen-1)
+	}
+	if network.Sign() < 0 || network.BitLen() > ipv4BitLen {
+		return fmt.Errorf("network needs to be an IPv4 address")
+	}
+	hostMask := new(big.Int).Lsh(big.NewInt(1), uint(ipv4BitLen-prefix))
+	hostMask.Sub(hostMask, big.NewInt(1))
+	if new(big.Int).And(network, hostMask).Sign() != 0 {
+		return fmt.Errorf("host bits of the network address need to be zero")
+	}
+	return nil
+}
+
+type IPv4SubnetVerifier struct {
+	*NBitRangeVerifier
+}
+
+// NewIPv4SubnetVerifier returns IPv4SubnetVerifier for the commitment stored in receiver,
+// where bitCommitments are obtained by IPv4SubnetProver.GetVerifierInitializationData.
+func NewIPv4SubnetVerifier(receiver *Receiver, network *big.Int, prefix int,
+	bitCommitments []*big.Int, challengeSpaceSize int) (*IPv4SubnetVerifier, error) {
+	if err := checkIPv4Subnet(network, prefix); err != nil {
+		return nil, err
+	}
+	hostReceiver, err := NewReceiverFromParams(receiver.QRSpecialRSA.GetPrimes(),
+		receiver.G, receiver.H, receiver.K)
+	if err != nil {
+		return nil, err
+	}
+	group := receiver.QRSpecialRSA
+	hostReceiver.SetCommitment(group.Mul(receiver.Commitment,
+		group.Exp(receiver.G, new(big.Int).Neg(network))))
+
+	verifier, err := NewNBitRangeVerifier(hostReceiver, ipv4BitLen-prefix, bitCommitments,
+		challengeSpaceSize)
+	if err != nil {
+		return nil, err
+	}
+	return &IPv4SubnetVerifier{verifier}, nil
+}
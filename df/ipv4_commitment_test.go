@@ -0,0 +1,123 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package df
+
+import (
+	"math/big"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func getIPv4(t *testing.T, s string) *big.Int {
+	addr, err := IPv4ToInt(net.ParseIP(s))
+	if err != nil {
+		t.Errorf("error in IPv4ToInt: %v", err)
+	}
+	return addr
+}
+
+func proveIPv4(receiver *Receiver, T, addr *big.Int) (bool, error) {
+	committer, r, err := getCommitterAndReceiver(receiver, T, addr)
+	if err != nil {
+		return false, err
+	}
+	_, rand := committer.GetDecommitMsg()
+	prover, err := NewIPv4Prover(committer, addr, rand, 80)
+	if err != nil {
+		return false, err
+	}
+	verifier, err := NewIPv4Verifier(r, prover.GetVerifierInitializationData(), 80)
+	if err != nil {
+		return false, err
+	}
+	if err := verifier.SetProofRandomData(prover.GetProofRandomData()); err != nil {
+		return false, err
+	}
+	return verifier.Verify(prover.GetProofData(verifier.GetChallenge())), nil
+}
+
+func proveIPv4Subnet(receiver *Receiver, T, addr, network *big.Int, prefix int,
+	verifierNetwork *big.Int, verifierPrefix int) (bool, error) {
+	committer, r, err := getCommitterAndReceiver(receiver, T, addr)
+	if err != nil {
+		return false, err
+	}
+	_, rand := committer.GetDecommitMsg()
+	prover, err := NewIPv4SubnetProver(committer, addr, rand, network, prefix, 80)
+	if err != nil {
+		return false, err
+	}
+	verifier, err := NewIPv4SubnetVerifier(r, verifierNetwork, verifierPrefix,
+		prover.GetVerifierInitializationData(), 80)
+	if err != nil {
+		return false, err
+	}
+	if err := verifier.SetProofRandomData(prover.GetProofRandomData()); err != nil {
+		return false, err
+	}
+	return verifier.Verify(prover.GetProofData(verifier.GetChallenge())), nil
+}
+
+// TestDFCommitmentIPv4 demonstrates how to prove that the committed value is a valid
+// IPv4 address and that it is in the given subnet.
+func TestDFCommitmentIPv4(t *testing.T) {
+	receiver, err := NewReceiver(128, 80)
+	if err != nil {
+		t.Errorf("error in NewReceiver: %v", err)
+	}
+	// n^2 is used for T - but any other value can be used as well
+	T := new(big.Int).Mul(receiver.QRSpecialRSA.N, receiver.QRSpecialRSA.N)
+
+	for _, s := range []string{"0.0.0.0", "192.168.1.42", "255.255.255.255"} {
+		proved, err := proveIPv4(receiver, T, getIPv4(t, s))
+		if err != nil {
+			t.Errorf("error in IPv4 proof: %v", err)
+		}
+		assert.Equal(t, true, proved, "DamgardFujisaki IPv4 proof failed for %s.", s)
+	}
+	_, err = proveIPv4(receiver, T, new(big.Int).Lsh(big.NewInt(1), 32))
+	assert.NotNil(t, err, "IPv4Prover should fail for 2^32")
+	_, err = IPv4ToInt(net.ParseIP("2001:db8::1"))
+	assert.NotNil(t, err, "IPv4ToInt should fail for IPv6 address")
+
+	network := getIPv4(t, "192.168.1.0")
+	for _, s := range []string{"192.168.1.0", "192.168.1.42", "192.168.1.255"} {
+		proved, err := proveIPv4Subnet(receiver, T, getIPv4(t, s), network, 24, network, 24)
+		if err != nil {
+			t.Errorf("error in IPv4 subnet proof: %v", err)
+		}
+		assert.Equal(t, true, proved, "DamgardFujisaki IPv4 subnet proof failed for %s.", s)
+	}
+	for _, s := range []string{"192.168.2.1", "192.168.0.255", "10.0.0.1"} {
+		_, err := proveIPv4Subnet(receiver, T, getIPv4(t, s), network, 24, network, 24)
+		assert.NotNil(t, err, "IPv4SubnetProver should fail for %s", s)
+	}
+
+	// the proof for 192.168.1.0/24 does not verify for 192.168.2.0/24
+	proved, err := proveIPv4Subnet(receiver, T, getIPv4(t, "192.168.1.42"), network, 24,
+		getIPv4(t, "192.168.2.0"), 24)
+	assert.False(t, err == nil && proved, "IPv4 subnet proof should fail for another subnet")
+
+	_, err = proveIPv4Subnet(receiver, T, getIPv4(t, "192.168.1.42"),
+		getIPv4(t, "192.168.1.1"), 24, network, 24)
+	assert.NotNil(t, err, "IPv4SubnetProver should fail for network with host bits set")
+	_, err = proveIPv4Subnet(receiver, T, network, network, 32, network, 32)
+	assert.NotNil(t, err, "IPv4SubnetProver should fail for prefix 32")
+}
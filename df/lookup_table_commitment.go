@@ -0,0 +1,90 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package df
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// LookupTableProver proves that the commitment c = g^i * h^r hides an index i into
+// a public lookup table such that table[i] = v for a public v. This is a set membership
+// proof for the indices at which v appears in the table, i = j_1 OR ... OR i = j_m,
+// and is proved using CompoundPredicateProver.
+type LookupTableProver struct {
+	*CompoundPredicateProver
+}
+
+func NewLookupTableProver(committer *Committer, table []*big.Int, v *big.Int,
+	challengeSpaceSize int) (*LookupTableProver, error) {
+	predicate, err := getLookupTablePredicate(table, v)
+	if err != nil {
+		return nil, err
+	}
+	i, _ := committer.GetDecommitMsg()
+	if i.Sign() < 0 || i.Cmp(big.NewInt(int64(len(table)))) >= 0 {
+		return nil, fmt.Errorf("index is out of bounds")
+	}
+	if table[i.Int64()].Cmp(v) != 0 {
+		return nil, fmt.Errorf("table value at the committed index is not v")
+	}
+
+	prover, err := NewCompoundPredicateProver(committer, predicate, challengeSpaceSize)
+	if err != nil {
+		return nil, err
+	}
+	return &LookupTableProver{
+		CompoundPredicateProver: prover,
+	}, nil
+}
+
+// getLookupTablePredicate returns the predicate i = j_1 OR ... OR i = j_m where
+// j_1, ..., j_m are the indices at which v appears in the table.
+func getLookupTablePredicate(table []*big.Int, v *big.Int) (*Predicate, error) {
+	var indices []*Predicate
+	for j, t := range table {
+		if t.Cmp(v) == 0 {
+			indices = append(indices, NewEqPredicate(big.NewInt(int64(j))))
+		}
+	}
+	if len(indices) == 0 {
+		return nil, fmt.Errorf("v does not appear in the table")
+	}
+	return NewOrPredicate(indices...), nil
+}
+
+type LookupTableVerifier struct {
+	*CompoundPredicateVerifier
+}
+
+// NewLookupTableVerifier returns LookupTableVerifier for the commitment stored in receiver.
+// T is the bound for the committed values (as in Committer).
+func NewLookupTableVerifier(receiver *Receiver, table []*big.Int, v *big.Int, T *big.Int,
+	challengeSpaceSize int) (*LookupTableVerifier, error) {
+	predicate, err := getLookupTablePredicate(table, v)
+	if err != nil {
+		return nil, err
+	}
+	verifier, err := NewCompoundPredicateVerifier(receiver, predicate, T, challengeSpaceSize)
+	if err != nil {
+		return nil, err
+	}
+	return &LookupTableVerifier{
+		CompoundPredicateVerifier: verifier,
+	}, nil
+}
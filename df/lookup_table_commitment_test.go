@@ -0,0 +1,88 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package df
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestDFCommitmentLookupTable demonstrates how to prove that the committed index i
+// points to the value v in a public lookup table.
+func TestDFCommitmentLookupTable(t *testing.T) {
+	receiver, err := NewReceiver(128, 80)
+	if err != nil {
+		t.Errorf("error in NewReceiver: %v", err)
+	}
+	// n^2 is used for T - but any other value can be used as well
+	T := new(big.Int).Mul(receiver.QRSpecialRSA.N, receiver.QRSpecialRSA.N)
+	challengeSpaceSize := 80
+
+	table := []*big.Int{big.NewInt(10), big.NewInt(20), big.NewInt(30), big.NewInt(20),
+		big.NewInt(50)}
+	v := big.NewInt(20)
+
+	committer := NewCommitter(receiver.QRSpecialRSA.N,
+		receiver.G, receiver.H, T, receiver.K)
+	c, err := committer.GetCommitMsg(big.NewInt(3))
+	if err != nil {
+		t.Errorf("error in computing commit msg: %v", err)
+	}
+	receiver.SetCommitment(c)
+
+	prover, err := NewLookupTableProver(committer, table, v, challengeSpaceSize)
+	if err != nil {
+		t.Errorf("error in instantiating LookupTableProver: %v", err)
+	}
+	verifier, err := NewLookupTableVerifier(receiver, table, v, T, challengeSpaceSize)
+	if err != nil {
+		t.Errorf("error in instantiating LookupTableVerifier: %v", err)
+	}
+	err = verifier.SetProofRandomData(prover.GetProofRandomData())
+	if err != nil {
+		t.Errorf("error when calling SetProofRandomData: %v", err)
+	}
+	challenge := verifier.GetChallenge()
+	proved := verifier.Verify(prover.GetProofData(challenge))
+	assert.Equal(t, true, proved, "DamgardFujisaki lookup table proof failed.")
+
+	// wrong value for the committed index
+	_, err = NewLookupTableProver(committer, table, big.NewInt(30), challengeSpaceSize)
+	assert.NotNil(t, err, "LookupTableProver should fail for a wrong value")
+
+	// the proof for v = 20 does not verify as a proof for v = 30
+	prover, _ = NewLookupTableProver(committer, table, v, challengeSpaceSize)
+	verifier, err = NewLookupTableVerifier(receiver, table, big.NewInt(30), T,
+		challengeSpaceSize)
+	if err != nil {
+		t.Errorf("error in instantiating LookupTableVerifier: %v", err)
+	}
+	// the proof for v = 20 has two branches, the verifier for v = 30 expects only one
+	err = verifier.SetProofRandomData(prover.GetProofRandomData())
+	assert.NotNil(t, err, "LookupTableVerifier should reject the proof for a different value")
+
+	// index out of bounds
+	_, err = committer.GetCommitMsg(big.NewInt(5))
+	if err != nil {
+		t.Errorf("error in computing commit msg: %v", err)
+	}
+	_, err = NewLookupTableProver(committer, table, v, challengeSpaceSize)
+	assert.NotNil(t, err, "LookupTableProver should fail for an index out of bounds")
+}
@@ -0,0 +1,185 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package df
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/awsong/crypto/qr"
+)
+
+// MatVecProver proves that commitments c_y_1, ..., c_y_m hide the vector y = M * x where M
+// is a public m x k matrix and x is the vector hidden in commitments c_x_1, ..., c_x_k.
+// For each row j it holds y_j = M_j1 * x_1 + ... + M_jk * x_k, thus
+// D_j = c_y_j * c_x_1^(-M_j1) * ... * c_x_k^(-M_jk) = h^rho_j where
+// rho_j = r_y_j - (M_j1 * r_x_1 + ... + M_jk * r_x_k). Prover proves the knowledge
+// of rho_j (that D_j is a commitment to 0) for each row - using the same challenge for all rows.
+type MatVecProver struct {
+	reprProvers []*qr.RepresentationProver
+	randomBits  int // bit length of random values used in proofs
+}
+
+func NewMatVecProver(committersX, committersY []*Committer, m [][]*big.Int,
+	challengeSpaceSize int) (*MatVecProver, error) {
+	if err := checkMatVecDimensions(len(committersX), len(committersY), m); err != nil {
+		return nil, err
+	}
+
+	c := committersX[0]
+	reprProvers := make([]*qr.RepresentationProver, len(m))
+	for j, row := range m {
+		y, rho := committersY[j].GetDecommitMsg()
+		rho = new(big.Int).Set(rho)
+		yCheck := big.NewInt(0)
+		for i, mji := range row {
+			x, rX := committersX[i].GetDecommitMsg()
+			yCheck.Add(yCheck, new(big.Int).Mul(mji, x))
+			rho.Sub(rho, new(big.Int).Mul(mji, rX))
+		}
+		if yCheck.Cmp(y) != 0 {
+			return nil, fmt.Errorf("committed y is not M * x")
+		}
+
+		d := c.QRSpecialRSA.Exp(c.H, rho)
+		reprProvers[j] = qr.NewRepresentationProver(c.QRSpecialRSA, challengeSpaceSize,
+			[]*big.Int{rho}, []*big.Int{c.H}, d)
+	}
+
+	// rho_j is smaller than 2^(B+K) * (|M_j1| + ... + |M_jk| + 1), random values need to
+	// be bigger than challenge * rho_j by factor 2^K to statistically hide rho_j
+	return &MatVecProver{
+		reprProvers: reprProvers,
+		randomBits:  c.B + 2*c.K + getMaxRowNormBitLen(m) + challengeSpaceSize,
+	}, nil
+}
+
+// checkMatVecDimensions returns an error if m is not nY x nX matrix.
+func checkMatVecDimensions(nX, nY int, m [][]*big.Int) error {
+	if nX == 0 || len(m) != nY {
+		return fmt.Errorf("the number of rows of M does not match the length of y")
+	}
+	for _, row := range m {
+		if len(row) != nX {
+			return fmt.Errorf("the number of columns of M does not match the length of x")
+		}
+	}
+	return nil
+}
+
+// getMaxRowNormBitLen returns the bit length of max_j (|M_j1| + ... + |M_jk| + 1).
+func getMaxRowNormBitLen(m [][]*big.Int) int {
+	maxBitLen := 0
+	for _, row := range m {
+		sum := big.NewInt(1)
+		for _, mji := range row {
+			sum.Add(sum, new(big.Int).Abs(mji))
+		}
+		if sum.BitLen() > maxBitLen {
+			maxBitLen = sum.BitLen()
+		}
+	}
+	return maxBitLen
+}
+
+func (p *MatVecProver) GetProofRandomData() []*big.Int {
+	proofRandomData := make([]*big.Int, len(p.reprProvers))
+	for j, prover := range p.reprProvers {
+		// the length of boundaries matches the number of bases, so no error is returned
+		proofRandomData[j], _ = prover.GetProofRandomDataGivenBoundaries(
+			[]int{p.randomBits}, true)
+	}
+	return proofRandomData
+}
+
+func (p *MatVecProver) GetProofData(challenge *big.Int) []*big.Int {
+	proofData := make([]*big.Int, len(p.reprProvers))
+	for j, prover := range p.reprProvers {
+		proofData[j] = prover.GetProofData(challenge)[0]
+	}
+	return proofData
+}
+
+type MatVecVerifier struct {
+	reprVerifiers []*qr.RepresentationVerifier
+	h             *big.Int
+	ds            []*big.Int
+}
+
+func NewMatVecVerifier(receiversX, receiversY []*Receiver, m [][]*big.Int,
+	challengeSpaceSize int) (*MatVecVerifier, error) {
+	if err := checkMatVecDimensions(len(receiversX), len(receiversY), m); err != nil {
+		return nil, err
+	}
+
+	group := receiversX[0].QRSpecialRSA
+	reprVerifiers := make([]*qr.RepresentationVerifier, len(m))
+	ds := make([]*big.Int, len(m))
+	for j, row := range m {
+		// D_j = c_y_j * c_x_1^(-M_j1) * ... * c_x_k^(-M_jk)
+		d := receiversY[j].Commitment
+		for i, mji := range row {
+			t := group.Exp(receiversX[i].Commitment, new(big.Int).Neg(mji))
+			d = group.Mul(d, t)
+		}
+		ds[j] = d
+		reprVerifiers[j] = qr.NewRepresentationVerifier(group, challengeSpaceSize)
+	}
+
+	return &MatVecVerifier{
+		reprVerifiers: reprVerifiers,
+		h:             receiversX[0].H,
+		ds:            ds,
+	}, nil
+}
+
+func (v *MatVecVerifier) SetProofRandomData(proofRandomData []*big.Int) error {
+	if len(proofRandomData) != len(v.reprVerifiers) {
+		return fmt.Errorf("the length of proofRandomData is not correct")
+	}
+	for j, verifier := range v.reprVerifiers {
+		verifier.SetProofRandomData(proofRandomData[j], []*big.Int{v.h}, v.ds[j])
+	}
+	return nil
+}
+
+// GetChallenge returns a challenge which is used for all rows.
+func (v *MatVecVerifier) GetChallenge() *big.Int {
+	challenge := v.reprVerifiers[0].GetChallenge()
+	v.SetChallenge(challenge)
+	return challenge
+}
+
+// SetChallenge is used when Fiat-Shamir is used - when challenge is generated using hash by the prover.
+func (v *MatVecVerifier) SetChallenge(challenge *big.Int) {
+	for _, verifier := range v.reprVerifiers {
+		verifier.SetChallenge(challenge)
+	}
+}
+
+func (v *MatVecVerifier) Verify(proofData []*big.Int) bool {
+	if len(proofData) != len(v.reprVerifiers) {
+		return false
+	}
+	for j, verifier := range v.reprVerifiers {
+		if !verifier.Verify([]*big.Int{proofData[j]}) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,115 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package df
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func getMatVecCommitments(receiver *Receiver, T *big.Int, values []*big.Int) ([]*Committer,
+	[]*Receiver, error) {
+	committers := make([]*Committer, len(values))
+	receivers := make([]*Receiver, len(values))
+	for i, val := range values {
+		committer := NewCommitter(receiver.QRSpecialRSA.N,
+			receiver.G, receiver.H, T, receiver.K)
+		c, err := committer.GetCommitMsg(val)
+		if err != nil {
+			return nil, nil, err
+		}
+		r, err := NewReceiverFromParams(receiver.QRSpecialRSA.GetPrimes(),
+			receiver.G, receiver.H, receiver.K)
+		if err != nil {
+			return nil, nil, err
+		}
+		r.SetCommitment(c)
+		committers[i] = committer
+		receivers[i] = r
+	}
+	return committers, receivers, nil
+}
+
+// TestDFCommitmentMatVec demonstrates how to prove that commitments to y hide M * x
+// where M is a public matrix and x is hidden in commitments.
+func TestDFCommitmentMatVec(t *testing.T) {
+	receiver, err := NewReceiver(128, 80)
+	if err != nil {
+		t.Errorf("error in NewReceiver: %v", err)
+	}
+	// n^2 is used for T - but any other value can be used as well
+	T := new(big.Int).Mul(receiver.QRSpecialRSA.N, receiver.QRSpecialRSA.N)
+
+	m := [][]*big.Int{
+		{big.NewInt(2), big.NewInt(-3)},
+		{big.NewInt(5), big.NewInt(7)},
+	}
+	x := []*big.Int{big.NewInt(11), big.NewInt(4)}
+	y := []*big.Int{big.NewInt(10), big.NewInt(83)}
+
+	committersX, receiversX, err := getMatVecCommitments(receiver, T, x)
+	if err != nil {
+		t.Errorf("error when creating commitments: %v", err)
+	}
+	committersY, receiversY, err := getMatVecCommitments(receiver, T, y)
+	if err != nil {
+		t.Errorf("error when creating commitments: %v", err)
+	}
+
+	challengeSpaceSize := 80
+	prover, err := NewMatVecProver(committersX, committersY, m, challengeSpaceSize)
+	if err != nil {
+		t.Errorf("error in instantiating MatVecProver: %v", err)
+	}
+	verifier, err := NewMatVecVerifier(receiversX, receiversY, m, challengeSpaceSize)
+	if err != nil {
+		t.Errorf("error in instantiating MatVecVerifier: %v", err)
+	}
+
+	err = verifier.SetProofRandomData(prover.GetProofRandomData())
+	if err != nil {
+		t.Errorf("error when calling SetProofRandomData: %v", err)
+	}
+	challenge := verifier.GetChallenge()
+	proved := verifier.Verify(prover.GetProofData(challenge))
+	assert.Equal(t, true, proved, "DamgardFujisaki matrix-vector proof failed.")
+
+	// wrong result commitment
+	yWrong := []*big.Int{big.NewInt(10), big.NewInt(84)}
+	committersYWrong, receiversYWrong, err := getMatVecCommitments(receiver, T, yWrong)
+	if err != nil {
+		t.Errorf("error when creating commitments: %v", err)
+	}
+	_, err = NewMatVecProver(committersX, committersYWrong, m, challengeSpaceSize)
+	assert.NotNil(t, err, "MatVecProver should not be created for wrong y")
+
+	// prover for correct y against the verifier with wrong commitments
+	verifier, err = NewMatVecVerifier(receiversX, receiversYWrong, m, challengeSpaceSize)
+	if err != nil {
+		t.Errorf("error in instantiating MatVecVerifier: %v", err)
+	}
+	err = verifier.SetProofRandomData(prover.GetProofRandomData())
+	if err != nil {
+		t.Errorf("error when calling SetProofRandomData: %v", err)
+	}
+	challenge = verifier.GetChallenge()
+	proved = verifier.Verify(prover.GetProofData(challenge))
+	assert.Equal(t, false, proved, "DamgardFujisaki matrix-vector proof should fail for wrong y")
+}
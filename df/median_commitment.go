@@ -0,0 +1,181 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package df
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// MedianProver proves that the commitments c_1, ..., c_n (n odd) hide a sorted list
+// x_1 <= x_2 <= ... <= x_n and that the commitment c_m hides its median x_((n+1)/2).
+// For each i < n it holds c_(i+1) * c_i^(-1) = g^(x_(i+1) - x_i) * h^(r_(i+1) - r_i), thus
+// prover proves (using CompoundPredicateProver) that these n-1 commitments hide
+// non-negative values. The median is proved using EqualityProver for c_m and c_((n+1)/2).
+// All proofs use the same challenge.
+type MedianProver struct {
+	orderProvers   []*CompoundPredicateProver
+	equalityProver *EqualityProver
+}
+
+func NewMedianProver(committers []*Committer, medianCommitter *Committer,
+	challengeSpaceSize int) (*MedianProver, error) {
+	n := len(committers)
+	if n%2 == 0 {
+		return nil, fmt.Errorf("the number of commitments needs to be odd")
+	}
+	median, _ := medianCommitter.GetDecommitMsg()
+	x, _ := committers[n/2].GetDecommitMsg()
+	if median.Cmp(x) != 0 {
+		return nil, fmt.Errorf("the committed value is not the median")
+	}
+
+	predicate := NewGePredicate(big.NewInt(0))
+	orderProvers := make([]*CompoundPredicateProver, n-1)
+	for i := 0; i < n-1; i++ {
+		x1, r1 := committers[i].GetDecommitMsg()
+		x2, r2 := committers[i+1].GetDecommitMsg()
+		d := new(big.Int).Sub(x2, x1)
+		if d.Sign() < 0 {
+			return nil, fmt.Errorf("the committed values are not sorted")
+		}
+		committer := NewCommitter(committers[i].QRSpecialRSA.N, committers[i].G,
+			committers[i].H, committers[i].T, committers[i].K)
+		if _, err := committer.GetCommitMsgWithGivenR(d, new(big.Int).Sub(r2, r1)); err != nil {
+			return nil, err
+		}
+		prover, err := NewCompoundPredicateProver(committer, predicate, challengeSpaceSize)
+		if err != nil {
+			return nil, err
+		}
+		orderProvers[i] = prover
+	}
+
+	return &MedianProver{
+		orderProvers: orderProvers,
+		equalityProver: NewEqualityProver(medianCommitter, committers[n/2],
+			challengeSpaceSize),
+	}, nil
+}
+
+// GetProofRandomData returns the proof random data of the order proofs and of the
+// equality proof.
+func (p *MedianProver) GetProofRandomData() ([][]*big.Int, []*big.Int) {
+	orderData := make([][]*big.Int, len(p.orderProvers))
+	for i, prover := range p.orderProvers {
+		orderData[i] = prover.GetProofRandomData()
+	}
+	d1, d2 := p.equalityProver.GetProofRandomData()
+	return orderData, []*big.Int{d1, d2}
+}
+
+// GetProofData returns the challenges and the proof data of the order proofs and
+// the proof data of the equality proof.
+func (p *MedianProver) GetProofData(challenge *big.Int) ([][]*big.Int, [][]*big.Int,
+	[]*big.Int) {
+	challenges := make([][]*big.Int, len(p.orderProvers))
+	proofData := make([][]*big.Int, len(p.orderProvers))
+	for i, prover := range p.orderProvers {
+		challenges[i], proofData[i] = prover.GetProofData(challenge)
+	}
+	s1, s21, s22 := p.equalityProver.GetProofData(challenge)
+	return challenges, proofData, []*big.Int{s1, s21, s22}
+}
+
+type MedianVerifier struct {
+	orderVerifiers   []*CompoundPredicateVerifier
+	equalityVerifier *EqualityVerifier
+}
+
+// NewMedianVerifier returns MedianVerifier for the commitments stored in receivers
+// and medianReceiver. T is the bound for the committed values (as in Committer).
+func NewMedianVerifier(receivers []*Receiver, medianReceiver *Receiver, T *big.Int,
+	challengeSpaceSize int) (*MedianVerifier, error) {
+	n := len(receivers)
+	if n%2 == 0 {
+		return nil, fmt.Errorf("the number of commitments needs to be odd")
+	}
+
+	predicate := NewGePredicate(big.NewInt(0))
+	orderVerifiers := make([]*CompoundPredicateVerifier, n-1)
+	for i := 0; i < n-1; i++ {
+		receiver, err := NewReceiverFromParams(receivers[i].QRSpecialRSA.GetPrimes(),
+			receivers[i].G, receivers[i].H, receivers[i].K)
+		if err != nil {
+			return nil, fmt.Errorf("error when calling NewReceiverFromParams")
+		}
+		group := receivers[i].QRSpecialRSA
+		receiver.SetCommitment(group.Mul(receivers[i+1].Commitment,
+			group.Inv(receivers[i].Commitment)))
+		verifier, err := NewCompoundPredicateVerifier(receiver, predicate, T, challengeSpaceSize)
+		if err != nil {
+			return nil, err
+		}
+		orderVerifiers[i] = verifier
+	}
+
+	return &MedianVerifier{
+		orderVerifiers: orderVerifiers,
+		equalityVerifier: NewEqualityVerifier(medianReceiver, receivers[n/2],
+			challengeSpaceSize),
+	}, nil
+}
+
+func (v *MedianVerifier) SetProofRandomData(orderData [][]*big.Int,
+	equalityData []*big.Int) error {
+	if len(orderData) != len(v.orderVerifiers) || len(equalityData) != 2 {
+		return fmt.Errorf("the length of proofRandomData is not correct")
+	}
+	for i, verifier := range v.orderVerifiers {
+		if err := verifier.SetProofRandomData(orderData[i]); err != nil {
+			return err
+		}
+	}
+	v.equalityVerifier.SetProofRandomData(equalityData[0], equalityData[1])
+	return nil
+}
+
+// GetChallenge returns a challenge which is used in all proofs.
+func (v *MedianVerifier) GetChallenge() *big.Int {
+	challenge := v.equalityVerifier.GetChallenge()
+	v.SetChallenge(challenge)
+	return challenge
+}
+
+// SetChallenge is used when Fiat-Shamir is used - when challenge is generated using hash by the prover.
+func (v *MedianVerifier) SetChallenge(challenge *big.Int) {
+	for _, verifier := range v.orderVerifiers {
+		verifier.SetChallenge(challenge)
+	}
+	v.equalityVerifier.SetChallenge(challenge)
+}
+
+func (v *MedianVerifier) Verify(challenges, proofData [][]*big.Int,
+	equalityProofData []*big.Int) bool {
+	if len(challenges) != len(v.orderVerifiers) || len(proofData) != len(v.orderVerifiers) ||
+		len(equalityProofData) != 3 {
+		return false
+	}
+	for i, verifier := range v.orderVerifiers {
+		if !verifier.Verify(challenges[i], proofData[i]) {
+			return false
+		}
+	}
+	return v.equalityVerifier.Verify(equalityProofData[0], equalityProofData[1],
+		equalityProofData[2])
+}
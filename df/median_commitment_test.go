@@ -0,0 +1,114 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package df
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func proveMedian(committers []*Committer, medianCommitter *Committer, receivers []*Receiver,
+	medianReceiver *Receiver, T *big.Int) (bool, error) {
+	challengeSpaceSize := 80
+	prover, err := NewMedianProver(committers, medianCommitter, challengeSpaceSize)
+	if err != nil {
+		return false, err
+	}
+	verifier, err := NewMedianVerifier(receivers, medianReceiver, T, challengeSpaceSize)
+	if err != nil {
+		return false, err
+	}
+	err = verifier.SetProofRandomData(prover.GetProofRandomData())
+	if err != nil {
+		return false, err
+	}
+	challenge := verifier.GetChallenge()
+	return verifier.Verify(prover.GetProofData(challenge)), nil
+}
+
+func getMedianTestCommitments(receiver *Receiver, T *big.Int, values []*big.Int) ([]*Committer,
+	[]*Receiver, error) {
+	committers := make([]*Committer, len(values))
+	receivers := make([]*Receiver, len(values))
+	for i, x := range values {
+		committers[i] = NewCommitter(receiver.QRSpecialRSA.N, receiver.G, receiver.H, T,
+			receiver.K)
+		c, err := committers[i].GetCommitMsg(x)
+		if err != nil {
+			return nil, nil, err
+		}
+		receivers[i], err = NewReceiverFromParams(receiver.QRSpecialRSA.GetPrimes(),
+			receiver.G, receiver.H, receiver.K)
+		if err != nil {
+			return nil, nil, err
+		}
+		receivers[i].SetCommitment(c)
+	}
+	return committers, receivers, nil
+}
+
+// TestDFCommitmentMedian demonstrates how to prove that the commitments hide a sorted
+// list and that c_m hides its median.
+func TestDFCommitmentMedian(t *testing.T) {
+	receiver, err := NewReceiver(128, 80)
+	if err != nil {
+		t.Errorf("error in NewReceiver: %v", err)
+	}
+	// n^2 is used for T - but any other value can be used as well
+	T := new(big.Int).Mul(receiver.QRSpecialRSA.N, receiver.QRSpecialRSA.N)
+
+	values := []*big.Int{big.NewInt(3), big.NewInt(17), big.NewInt(17), big.NewInt(250),
+		big.NewInt(1021)}
+	committers, receivers, err := getMedianTestCommitments(receiver, T, values)
+	if err != nil {
+		t.Errorf("error when creating commitments: %v", err)
+	}
+	medianCommitters, medianReceivers, err := getMedianTestCommitments(receiver, T,
+		[]*big.Int{big.NewInt(17), big.NewInt(250)})
+	if err != nil {
+		t.Errorf("error when creating commitments: %v", err)
+	}
+
+	proved, err := proveMedian(committers, medianCommitters[0], receivers, medianReceivers[0], T)
+	if err != nil {
+		t.Errorf("error in median proof: %v", err)
+	}
+	assert.Equal(t, true, proved, "DamgardFujisaki median proof failed.")
+
+	// wrong median
+	_, err = NewMedianProver(committers, medianCommitters[1], 80)
+	assert.NotNil(t, err, "MedianProver should fail for a wrong median")
+
+	// unsorted set
+	unsorted := []*Committer{committers[0], committers[3], committers[2], committers[1],
+		committers[4]}
+	_, err = NewMedianProver(unsorted, medianCommitters[0], 80)
+	assert.NotNil(t, err, "MedianProver should fail for an unsorted set")
+
+	// the verifier receives the commitments in unsorted order
+	unsortedReceivers := []*Receiver{receivers[0], receivers[3], receivers[2], receivers[1],
+		receivers[4]}
+	proved, err = proveMedian(committers, medianCommitters[0], unsortedReceivers,
+		medianReceivers[0], T)
+	if err != nil {
+		t.Errorf("error in median proof: %v", err)
+	}
+	assert.Equal(t, false, proved, "DamgardFujisaki median proof should fail for an unsorted set")
+}
@@ -36,6 +36,12 @@ type MultiplicationProver struct {
 	committer2         *Committer
 	committer3         *Committer
 	challengeSpaceSize int
+	c1                 *big.Int
+	a1                 *big.Int
+	r1                 *big.Int
+	a2                 *big.Int
+	r2                 *big.Int
+	r3                 *big.Int
 	y1                 *big.Int
 	s1                 *big.Int
 	y                  *big.Int
@@ -43,14 +49,27 @@ type MultiplicationProver struct {
 	s3                 *big.Int
 }
 
+// NewMultiplicationProver returns MultiplicationProver for the commitments held by
+// committer1, committer2 and committer3. The committed values and randomness (and c1) are
+// taken from committers at construction, thus committers can be reused (for example
+// to commit to other values) while the proof is running.
 func NewMultiplicationProver(committer1, committer2,
 	committer3 *Committer,
 	challengeSpaceSize int) *MultiplicationProver {
+	a1, r1 := committer1.GetDecommitMsg()
+	a2, r2 := committer2.GetDecommitMsg()
+	_, r3 := committer3.GetDecommitMsg()
 	return &MultiplicationProver{
 		committer1:         committer1,
 		committer2:         committer2,
 		committer3:         committer3,
 		challengeSpaceSize: challengeSpaceSize,
+		c1:                 committer1.ComputeCommit(a1, r1),
+		a1:                 a1,
+		r1:                 r1,
+		a2:                 a2,
+		r2:                 r2,
+		r3:                 r3,
 	}
 }
 
@@ -81,10 +100,8 @@ func (p *MultiplicationProver) GetProofRandomData() (*big.Int, *big.Int, *big.In
 	// d3 = c1^y * H^s3
 	d1 := p.committer1.ComputeCommit(y1, s1) // ComputeCommit can be called on any of the committers
 	d2 := p.committer1.ComputeCommit(y, s2)
-	a1, r1 := p.committer1.GetDecommitMsg()
-	c1 := p.committer1.ComputeCommit(a1, r1)
 
-	l := p.committer1.QRSpecialRSA.Exp(c1, y)
+	l := p.committer1.QRSpecialRSA.Exp(p.c1, y)
 	r := p.committer1.QRSpecialRSA.Exp(p.committer1.H, s3)
 	d3 := p.committer1.QRSpecialRSA.Mul(l, r)
 	return d1, d2, d3
@@ -97,9 +114,7 @@ func (p *MultiplicationProver) GetProofData(challenge *big.Int) (*big.Int, *big.
 	// v1 = s1 + challenge*r1 (in Z, not modulo)
 	// v2 = s2 + challenge*r2 (in Z, not modulo)
 	// v3 = s3 + challenge*(r3 - a2 * r1) (in Z, not modulo)
-	a1, r1 := p.committer1.GetDecommitMsg()
-	a2, r2 := p.committer2.GetDecommitMsg()
-	_, r3 := p.committer3.GetDecommitMsg()
+	a1, r1, a2, r2, r3 := p.a1, p.r1, p.a2, p.r2, p.r3
 
 	u1 := new(big.Int).Mul(challenge, a1)
 	u1.Add(u1, p.y1)
@@ -217,5 +232,6 @@ func (v *MultiplicationVerifier) Verify(u1, u, v1, v2, v3 *big.Int) bool {
 	left3 := v.receiver3.QRSpecialRSA.Mul(tmp1, tmp2)
 	right3 := v.receiver1.QRSpecialRSA.Exp(v.receiver3.Commitment, v.challenge)
 	right3 = v.receiver1.QRSpecialRSA.Mul(v.d3, right3)
-	return left1.Cmp(right1) == 0 && left2.Cmp(right2) == 0 && left3.Cmp(right3) == 0
+	return common.ConstantTimeEq(left1, right1) && common.ConstantTimeEq(left2, right2) &&
+		common.ConstantTimeEq(left3, right3)
 }
@@ -91,3 +91,45 @@ func TestDFCommitmentMultiplication(t *testing.T) {
 
 	assert.Equal(t, true, proved, "DamgardFujisaki multiplication proof failed.")
 }
+
+// TestDFCommitmentMultiplicationReusedCommitter checks that the proof is complete when
+// GetProofRandomData is called more than once and when committer1 is reused for
+// another commitment after the prover is created.
+func TestDFCommitmentMultiplicationReusedCommitter(t *testing.T) {
+	receiver, err := NewReceiver(128, 80)
+	if err != nil {
+		t.Errorf("Error in NewReceiver: %v", err)
+	}
+	// n^2 is used for T - but any other value can be used as well
+	T := new(big.Int).Mul(receiver.QRSpecialRSA.N, receiver.QRSpecialRSA.N)
+
+	x1 := common.GetRandomInt(receiver.QRSpecialRSA.N)
+	x2 := common.GetRandomInt(receiver.QRSpecialRSA.N)
+	committers := make([]*Committer, 3)
+	receivers := make([]*Receiver, 3)
+	for i, x := range []*big.Int{x1, x2, new(big.Int).Mul(x1, x2)} {
+		committers[i], receivers[i], err = getCommitterAndReceiver(receiver, T, x)
+		if err != nil {
+			t.Errorf("Error in computing commit msg: %v", err)
+		}
+	}
+
+	challengeSpaceSize := 80
+	prover := NewMultiplicationProver(committers[0], committers[1], committers[2],
+		challengeSpaceSize)
+
+	// committer1 is reused for a commitment to another value
+	_, err = committers[0].GetCommitMsg(big.NewInt(7))
+	if err != nil {
+		t.Errorf("Error in computing commit msg: %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		verifier := NewMultiplicationVerifier(receivers[0], receivers[1], receivers[2],
+			challengeSpaceSize)
+		verifier.SetProofRandomData(prover.GetProofRandomData())
+		challenge := verifier.GetChallenge()
+		proved := verifier.Verify(prover.GetProofData(challenge))
+		assert.Equal(t, true, proved, "DamgardFujisaki multiplication proof failed in run %d.", i)
+	}
+}
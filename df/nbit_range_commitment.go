@@ -0,0 +1,185 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package df
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/awsong/crypto/common"
+)
+
+// NBitRangeProver proves that the commitment c = g^x * h^r hides x such that 0 <= x < 2^n.
+// Unlike RangeProver (which is based on Lipmaa decomposition into four squares),
+// NBitRangeProver uses binary decomposition x = b_0 + 2*b_1 + ... + 2^(n-1)*b_(n-1):
+// prover commits to each bit c_i = g^b_i * h^r_i where randomnesses are chosen such that
+// r = r_0 + 2*r_1 + ... + 2^(n-1)*r_(n-1). Verifier checks that
+// c = c_0 * c_1^2 * ... * c_(n-1)^(2^(n-1)), and for each bit prover proves (using
+// MultiplicationProver for the commitments c_i, c_i, c_i) that b_i * b_i = b_i,
+// which holds in Z only for b_i in {0, 1}. All multiplication proofs use the same challenge.
+type NBitRangeProver struct {
+	mulProvers     []*MultiplicationProver
+	bitCommitments []*big.Int
+}
+
+func NewNBitRangeProver(committer *Committer, x *big.Int, n int,
+	challengeSpaceSize int) (*NBitRangeProver, error) {
+	if n < 1 {
+		return nil, fmt.Errorf("n needs to be positive")
+	}
+	bound := new(big.Int).Lsh(big.NewInt(1), uint(n))
+	if x.Sign() < 0 || x.Cmp(bound) != -1 {
+		return nil, fmt.Errorf("x needs to be in [0, 2^n)")
+	}
+	_, r := committer.GetDecommitMsg()
+
+	// r_1, ..., r_(n-1) are chosen randomly, r_0 = r - (2*r_1 + ... + 2^(n-1)*r_(n-1))
+	rBound := new(big.Int).Lsh(big.NewInt(1), uint(committer.B+committer.K))
+	rs := make([]*big.Int, n)
+	r0 := new(big.Int).Set(r)
+	for i := 1; i < n; i++ {
+		rs[i] = common.GetRandomInt(rBound)
+		r0.Sub(r0, new(big.Int).Lsh(rs[i], uint(i)))
+	}
+	rs[0] = r0
+
+	mulProvers := make([]*MultiplicationProver, n)
+	bitCommitments := make([]*big.Int, n)
+	for i := 0; i < n; i++ {
+		bitCommitter := NewCommitter(committer.QRSpecialRSA.N,
+			committer.G, committer.H, committer.T, committer.K)
+		bit := big.NewInt(int64(x.Bit(i)))
+		commitment, err := bitCommitter.GetCommitMsgWithGivenR(bit, rs[i])
+		if err != nil {
+			return nil, fmt.Errorf("error when creating commit msg with given r")
+		}
+		bitCommitments[i] = commitment
+		mulProvers[i] = NewMultiplicationProver(bitCommitter, bitCommitter, bitCommitter,
+			challengeSpaceSize)
+	}
+
+	return &NBitRangeProver{
+		mulProvers:     mulProvers,
+		bitCommitments: bitCommitments,
+	}, nil
+}
+
+// GetVerifierInitializationData returns data that are needed by NBitRangeVerifier
+// and are known only after the initialization of NBitRangeProver: commitments to bits.
+func (p *NBitRangeProver) GetVerifierInitializationData() []*big.Int {
+	return p.bitCommitments
+}
+
+func (p *NBitRangeProver) GetProofRandomData() []*big.Int {
+	proofRandomData := make([]*big.Int, 3*len(p.mulProvers))
+	for i, prover := range p.mulProvers {
+		d1, d2, d3 := prover.GetProofRandomData()
+		proofRandomData[3*i] = d1
+		proofRandomData[3*i+1] = d2
+		proofRandomData[3*i+2] = d3
+	}
+	return proofRandomData
+}
+
+func (p *NBitRangeProver) GetProofData(challenge *big.Int) []*big.Int {
+	proofData := make([]*big.Int, 5*len(p.mulProvers))
+	for i, prover := range p.mulProvers {
+		u1, u, v1, v2, v3 := prover.GetProofData(challenge)
+		proofData[5*i] = u1
+		proofData[5*i+1] = u
+		proofData[5*i+2] = v1
+		proofData[5*i+3] = v2
+		proofData[5*i+4] = v3
+	}
+	return proofData
+}
+
+type NBitRangeVerifier struct {
+	mulVerifiers []*MultiplicationVerifier
+}
+
+func NewNBitRangeVerifier(receiver *Receiver, n int, bitCommitments []*big.Int,
+	challengeSpaceSize int) (*NBitRangeVerifier, error) {
+	if n < 1 || len(bitCommitments) != n {
+		return nil, fmt.Errorf("the length of bitCommitments is not correct")
+	}
+
+	// check: c = c_0 * c_1^2 * ... * c_(n-1)^(2^(n-1))
+	check := big.NewInt(1)
+	for i, comm := range bitCommitments {
+		pow := new(big.Int).Lsh(big.NewInt(1), uint(i))
+		check = receiver.QRSpecialRSA.Mul(check, receiver.QRSpecialRSA.Exp(comm, pow))
+	}
+	if !common.ConstantTimeEq(check, receiver.Commitment) {
+		return nil, fmt.Errorf("bit commitments do not compose the commitment")
+	}
+
+	mulVerifiers := make([]*MultiplicationVerifier, n)
+	for i, comm := range bitCommitments {
+		bitReceiver, err := NewReceiverFromParams(receiver.QRSpecialRSA.GetPrimes(),
+			receiver.G, receiver.H, receiver.K)
+		if err != nil {
+			return nil, fmt.Errorf("error when calling NewReceiverFromParams")
+		}
+		bitReceiver.SetCommitment(comm)
+		mulVerifiers[i] = NewMultiplicationVerifier(bitReceiver, bitReceiver, bitReceiver,
+			challengeSpaceSize)
+	}
+
+	return &NBitRangeVerifier{
+		mulVerifiers: mulVerifiers,
+	}, nil
+}
+
+func (v *NBitRangeVerifier) SetProofRandomData(proofRandomData []*big.Int) error {
+	if len(proofRandomData) != 3*len(v.mulVerifiers) {
+		return fmt.Errorf("the length of proofRandomData is not correct")
+	}
+	for i, verifier := range v.mulVerifiers {
+		verifier.SetProofRandomData(proofRandomData[3*i], proofRandomData[3*i+1],
+			proofRandomData[3*i+2])
+	}
+	return nil
+}
+
+// GetChallenge returns a challenge which is used in all bit proofs.
+func (v *NBitRangeVerifier) GetChallenge() *big.Int {
+	challenge := v.mulVerifiers[0].GetChallenge()
+	v.SetChallenge(challenge)
+	return challenge
+}
+
+// SetChallenge is used when Fiat-Shamir is used - when challenge is generated using hash by the prover.
+func (v *NBitRangeVerifier) SetChallenge(challenge *big.Int) {
+	for _, verifier := range v.mulVerifiers {
+		verifier.SetChallenge(challenge)
+	}
+}
+
+func (v *NBitRangeVerifier) Verify(proofData []*big.Int) bool {
+	if len(proofData) != 5*len(v.mulVerifiers) {
+		return false
+	}
+	for i, verifier := range v.mulVerifiers {
+		if !verifier.Verify(proofData[5*i], proofData[5*i+1], proofData[5*i+2],
+			proofData[5*i+3], proofData[5*i+4]) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,146 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package df
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/awsong/crypto/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func proveNBitRange(receiver *Receiver, committer *Committer, x *big.Int, n,
+	challengeSpaceSize int) (bool, error) {
+	prover, err := NewNBitRangeProver(committer, x, n, challengeSpaceSize)
+	if err != nil {
+		return false, err
+	}
+	verifier, err := NewNBitRangeVerifier(receiver, n,
+		prover.GetVerifierInitializationData(), challengeSpaceSize)
+	if err != nil {
+		return false, err
+	}
+
+	proofRandomData := prover.GetProofRandomData()
+	err = verifier.SetProofRandomData(proofRandomData)
+	if err != nil {
+		return false, err
+	}
+	challenge := verifier.GetChallenge()
+	proofData := prover.GetProofData(challenge)
+	return verifier.Verify(proofData), nil
+}
+
+func proveFourSquareRange(receiver *Receiver, committer *Committer, x, a, b *big.Int,
+	challengeSpaceSize int) (bool, error) {
+	prover, err := NewRangeProver(committer, x, a, b, challengeSpaceSize)
+	if err != nil {
+		return false, err
+	}
+	smallCommitments1, bigCommitments1, smallCommitments2, bigCommitments2 :=
+		prover.GetVerifierInitializationData()
+	verifier, err := NewRangeVerifier(receiver, a, b, smallCommitments1,
+		bigCommitments1, smallCommitments2, bigCommitments2, challengeSpaceSize)
+	if err != nil {
+		return false, err
+	}
+
+	proofRandomData1, proofRandomData2 := prover.GetProofRandomData()
+	challenges1, challenges2 := verifier.GetChallenges()
+	err = verifier.SetProofRandomData(proofRandomData1, proofRandomData2)
+	if err != nil {
+		return false, err
+	}
+	proofData1, proofData2, err := prover.GetProofData(challenges1, challenges2)
+	if err != nil {
+		return false, err
+	}
+	return verifier.Verify(proofData1, proofData2)
+}
+
+func getRangeTestParams(x *big.Int) (*Receiver, *Committer, error) {
+	receiver, err := NewReceiver(128, 80)
+	if err != nil {
+		return nil, nil, err
+	}
+	// n^2 is used for T - but any other value can be used as well
+	T := new(big.Int).Mul(receiver.QRSpecialRSA.N, receiver.QRSpecialRSA.N)
+	committer := NewCommitter(receiver.QRSpecialRSA.N,
+		receiver.G, receiver.H, T, receiver.K)
+	c, err := committer.GetCommitMsg(x)
+	if err != nil {
+		return nil, nil, err
+	}
+	receiver.SetCommitment(c)
+	return receiver, committer, nil
+}
+
+// TestDFCommitmentNBitRange demonstrates how to prove that the commitment
+// hides a number x such that 0 <= x < 2^n.
+func TestDFCommitmentNBitRange(t *testing.T) {
+	n := 32
+	x := common.GetRandomInt(new(big.Int).Lsh(big.NewInt(1), uint(n)))
+	receiver, committer, err := getRangeTestParams(x)
+	if err != nil {
+		t.Errorf("error when creating committer: %v", err)
+	}
+
+	proved, err := proveNBitRange(receiver, committer, x, n, 80)
+	if err != nil {
+		t.Errorf("error in n-bit range proof: %v", err)
+	}
+	assert.Equal(t, true, proved, "DamgardFujisaki n-bit range proof failed.")
+
+	_, err = NewNBitRangeProver(committer, x, 8, 80)
+	assert.NotNil(t, err, "x should not be representable in 8 bits")
+
+	// bit commitments do not compose the commitment to a different value
+	prover, _ := NewNBitRangeProver(committer, x, n, 80)
+	receiver.SetCommitment(committer.ComputeCommit(big.NewInt(1), big.NewInt(1)))
+	_, err = NewNBitRangeVerifier(receiver, n, prover.GetVerifierInitializationData(), 80)
+	assert.NotNil(t, err, "verifier should reject bit commitments for a different commitment")
+}
+
+func BenchmarkDFCommitmentNBitRange(b *testing.B) {
+	n := 32
+	x := common.GetRandomInt(new(big.Int).Lsh(big.NewInt(1), uint(n)))
+	receiver, committer, err := getRangeTestParams(x)
+	if err != nil {
+		b.Fatalf("error when creating committer: %v", err)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		proveNBitRange(receiver, committer, x, n, 80)
+	}
+}
+
+func BenchmarkDFCommitmentFourSquareRange(b *testing.B) {
+	n := 32
+	x := common.GetRandomInt(new(big.Int).Lsh(big.NewInt(1), uint(n)))
+	upper := new(big.Int).Lsh(big.NewInt(1), uint(n))
+	upper.Sub(upper, big.NewInt(1))
+	receiver, committer, err := getRangeTestParams(x)
+	if err != nil {
+		b.Fatalf("error when creating committer: %v", err)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		proveFourSquareRange(receiver, committer, x, big.NewInt(0), upper, 80)
+	}
+}
@@ -0,0 +1,100 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package df
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// NonNegLinCombProver proves that for commitments c_1, ..., c_n (c_i = g^x_i * h^r_i) and
+// public coefficients a_1, ..., a_n >= 0 it holds a_1 * x_1 + ... + a_n * x_n >= 0.
+// DF commitments are additively homomorphic, thus the commitment to the linear combination
+// is c = c_1^a_1 * ... * c_n^a_n = g^(a_1*x_1 + ... + a_n*x_n) * h^(a_1*r_1 + ... + a_n*r_n)
+// and can be computed by the verifier itself (no separate proofs for scalar multiplication
+// and addition are needed). Prover then proves that c hides a positive number using PositiveProver.
+type NonNegLinCombProver struct {
+	*PositiveProver
+}
+
+func NewNonNegLinCombProver(committers []*Committer, coefficients []*big.Int,
+	challengeSpaceSize int) (*NonNegLinCombProver, error) {
+	if err := checkLinCombCoefficients(len(committers), coefficients); err != nil {
+		return nil, err
+	}
+
+	x := big.NewInt(0)
+	r := big.NewInt(0)
+	for i, committer := range committers {
+		xi, ri := committer.GetDecommitMsg()
+		x.Add(x, new(big.Int).Mul(coefficients[i], xi))
+		r.Add(r, new(big.Int).Mul(coefficients[i], ri))
+	}
+
+	prover, err := NewPositiveProver(committers[0], x, r, challengeSpaceSize)
+	if err != nil {
+		return nil, err
+	}
+	return &NonNegLinCombProver{
+		PositiveProver: prover,
+	}, nil
+}
+
+// checkLinCombCoefficients returns an error if the number of coefficients is not n or if
+// any of the coefficients is negative.
+func checkLinCombCoefficients(n int, coefficients []*big.Int) error {
+	if n == 0 || len(coefficients) != n {
+		return fmt.Errorf("the number of coefficients does not match the number of commitments")
+	}
+	for _, a := range coefficients {
+		if a.Sign() < 0 {
+			return fmt.Errorf("coefficients need to be non-negative")
+		}
+	}
+	return nil
+}
+
+type NonNegLinCombVerifier struct {
+	*PositiveVerifier
+}
+
+// NewNonNegLinCombVerifier returns NonNegLinCombVerifier for commitments c_1, ..., c_n and
+// coefficients a_1, ..., a_n. The values smallCommitments and bigCommitments are obtained
+// from NonNegLinCombProver.GetVerifierInitializationData.
+func NewNonNegLinCombVerifier(receiver *Receiver, commitments, coefficients []*big.Int,
+	smallCommitments, bigCommitments []*big.Int,
+	challengeSpaceSize int) (*NonNegLinCombVerifier, error) {
+	if err := checkLinCombCoefficients(len(commitments), coefficients); err != nil {
+		return nil, err
+	}
+
+	// c = c_1^a_1 * ... * c_n^a_n
+	c := big.NewInt(1)
+	for i, ci := range commitments {
+		c = receiver.QRSpecialRSA.Mul(c, receiver.QRSpecialRSA.Exp(ci, coefficients[i]))
+	}
+
+	verifier, err := NewPositiveVerifier(receiver, c, smallCommitments, bigCommitments,
+		challengeSpaceSize)
+	if err != nil {
+		return nil, err
+	}
+	return &NonNegLinCombVerifier{
+		PositiveVerifier: verifier,
+	}, nil
+}
@@ -0,0 +1,85 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package df
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/awsong/crypto/common"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestDFCommitmentNonNegLinComb demonstrates how to prove that for commitments c_1, ..., c_n
+// and public coefficients a_1, ..., a_n it holds a_1 * x_1 + ... + a_n * x_n >= 0.
+func TestDFCommitmentNonNegLinComb(t *testing.T) {
+	receiver, err := NewReceiver(128, 80)
+	if err != nil {
+		t.Errorf("error in NewReceiver: %v", err)
+	}
+	// n^2 is used for T - but any other value can be used as well
+	T := new(big.Int).Mul(receiver.QRSpecialRSA.N, receiver.QRSpecialRSA.N)
+
+	x1 := common.GetRandomInt(receiver.QRSpecialRSA.N)
+	x2 := new(big.Int).Neg(common.GetRandomInt(x1)) // x2 < 0, 3*x1 + 2*x2 > 0
+	x3 := common.GetRandomInt(receiver.QRSpecialRSA.N)
+	xs := []*big.Int{x1, x2, x3}
+	coefficients := []*big.Int{big.NewInt(3), big.NewInt(2), big.NewInt(5)}
+
+	committers := make([]*Committer, len(xs))
+	commitments := make([]*big.Int, len(xs))
+	for i, x := range xs {
+		committers[i] = NewCommitter(receiver.QRSpecialRSA.N,
+			receiver.G, receiver.H, T, receiver.K)
+		commitments[i], err = committers[i].GetCommitMsg(x)
+		if err != nil {
+			t.Errorf("error in computing commit msg: %v", err)
+		}
+	}
+
+	challengeSpaceSize := 80
+	prover, err := NewNonNegLinCombProver(committers, coefficients, challengeSpaceSize)
+	if err != nil {
+		t.Errorf("error in instantiating NonNegLinCombProver: %v", err)
+	}
+	smallCommitments, bigCommitments := prover.GetVerifierInitializationData()
+	verifier, err := NewNonNegLinCombVerifier(receiver, commitments, coefficients,
+		smallCommitments, bigCommitments, challengeSpaceSize)
+	if err != nil {
+		t.Errorf("error in instantiating NonNegLinCombVerifier: %v", err)
+	}
+
+	proofRandomData := prover.GetProofRandomData()
+	challenges := verifier.GetChallenges()
+	err = verifier.SetProofRandomData(proofRandomData)
+	if err != nil {
+		t.Errorf("error when calling SetProofRandomData: %v", err)
+	}
+	proved := verifier.Verify(prover.GetProofData(challenges))
+	assert.Equal(t, true, proved, "DamgardFujisaki non-negative linear combination proof failed.")
+
+	// 0*x1 + 1*x2 + 0*x3 < 0
+	negCoefficients := []*big.Int{big.NewInt(0), big.NewInt(1), big.NewInt(0)}
+	_, err = NewNonNegLinCombProver(committers, negCoefficients, challengeSpaceSize)
+	assert.NotNil(t, err, "NonNegLinCombProver should fail for negative combination")
+
+	// proof for the positive combination does not verify for a different combination
+	_, err = NewNonNegLinCombVerifier(receiver, commitments, negCoefficients,
+		smallCommitments, bigCommitments, challengeSpaceSize)
+	assert.NotNil(t, err, "NonNegLinCombVerifier should fail for a different combination")
+}
@@ -122,5 +122,5 @@ func (v *OpeningVerifier) Verify(s1, s2 *big.Int) bool {
 	left := v.receiver.QRSpecialRSA.Exp(v.receiver.Commitment, v.challenge)
 	left = v.receiver.QRSpecialRSA.Mul(v.proofRandomData, left)
 	right := v.receiver.ComputeCommit(s1, s2)
-	return left.Cmp(right) == 0
+	return common.ConstantTimeEq(left, right)
 }
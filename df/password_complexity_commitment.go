@@ -0,0 +1,312 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package df
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/awsong/crypto/common"
+)
+
+// passwordMinLength is the minimal number of characters of a password.
+const passwordMinLength = 8
+
+// getCharPredicate returns the predicate x >= min AND x <= max.
+func getCharPredicate(min, max byte) *Predicate {
+	return NewAndPredicate(NewGePredicate(big.NewInt(int64(min))),
+		NewLePredicate(big.NewInt(int64(max))))
+}
+
+var (
+	printablePredicate = getCharPredicate(' ', '~')
+	digitPredicate     = getCharPredicate('0', '9')
+	upperPredicate     = getCharPredicate('A', 'Z')
+)
+
+// PasswordComplexityProver proves that the commitments c_0, ..., c_(n-1) to the bytes
+// of a password (n is public, shorter passwords are padded with zero bytes) satisfy:
+// (1) the password has at least 8 characters: c_0, ..., c_7 hide printable ASCII
+// characters (the padding bytes are zero),
+// (2) the password contains a digit: c_0 hides a digit OR ... OR c_(n-1) hides a digit,
+// (3) the password contains an uppercase letter (as for digits).
+// Each of the statements about a single commitment is proved using CompoundPredicateProver.
+// The proofs for (1) use the same challenge (AND-composition), while the challenges of
+// the proofs for (2) sum up to the challenge (OR-composition over commitments, as in
+// CompoundPredicateProver), the same for (3). The proofs for commitments which do not hide
+// a digit (uppercase letter) are simulated.
+type PasswordComplexityProver struct {
+	lengthProvers      []*CompoundPredicateProver
+	digitProvers       []*CompoundPredicateProver
+	upperProvers       []*CompoundPredicateProver
+	digitIndex         int // index of the commitment with a digit which is actually proved
+	upperIndex         int
+	challengeSpaceSize int
+}
+
+// NewPasswordComplexityProver returns PasswordComplexityProver for the committers which
+// hold the commitments to the password bytes. It returns an error if the password does
+// not satisfy the requirements.
+func NewPasswordComplexityProver(committers []*Committer,
+	challengeSpaceSize int) (*PasswordComplexityProver, error) {
+	if len(committers) < passwordMinLength {
+		return nil, fmt.Errorf("at least %d commitments are needed", passwordMinLength)
+	}
+	lengthProvers := make([]*CompoundPredicateProver, passwordMinLength)
+	for i := range lengthProvers {
+		x, _ := committers[i].GetDecommitMsg()
+		if !printablePredicate.Holds(x) {
+			return nil, fmt.Errorf("password needs at least %d characters", passwordMinLength)
+		}
+		prover, err := NewCompoundPredicateProver(committers[i], printablePredicate,
+			challengeSpaceSize)
+		if err != nil {
+			return nil, err
+		}
+		lengthProvers[i] = prover
+	}
+
+	digitProvers, digitIndex, err := newCharClassProvers(committers, digitPredicate,
+		challengeSpaceSize)
+	if err != nil {
+		return nil, fmt.Errorf("password needs to contain a digit")
+	}
+	upperProvers, upperIndex, err := newCharClassProvers(committers, upperPredicate,
+		challengeSpaceSize)
+	if err != nil {
+		return nil, fmt.Errorf("password needs to contain an uppercase letter")
+	}
+
+	return &PasswordComplexityProver{
+		lengthProvers:      lengthProvers,
+		digitProvers:       digitProvers,
+		upperProvers:       upperProvers,
+		digitIndex:         digitIndex,
+		upperIndex:         upperIndex,
+		challengeSpaceSize: challengeSpaceSize,
+	}, nil
+}
+
+// newCharClassProvers returns a prover of the predicate for each of the committers and
+// the index of the first committed value which satisfies the predicate.
+func newCharClassProvers(committers []*Committer, predicate *Predicate,
+	challengeSpaceSize int) ([]*CompoundPredicateProver, int, error) {
+	index := -1
+	provers := make([]*CompoundPredicateProver, len(committers))
+	for i, committer := range committers {
+		x, _ := committer.GetDecommitMsg()
+		if index == -1 && predicate.Holds(x) {
+			index = i
+		}
+		prover, err := newCompoundPredicateProver(committer, predicate, challengeSpaceSize)
+		if err != nil {
+			return nil, 0, err
+		}
+		provers[i] = prover
+	}
+	if index == -1 {
+		return nil, 0, fmt.Errorf("no committed value satisfies the predicate")
+	}
+	return provers, index, nil
+}
+
+// GetProofRandomData returns the proof random data of the length proofs (for c_0, ..., c_7),
+// of the digit proofs and of the uppercase letter proofs (for c_0, ..., c_(n-1)).
+func (p *PasswordComplexityProver) GetProofRandomData() [][]*big.Int {
+	var proofRandomData [][]*big.Int
+	for _, prover := range p.lengthProvers {
+		proofRandomData = append(proofRandomData, prover.GetProofRandomData())
+	}
+	proofRandomData = append(proofRandomData,
+		p.getOrProofRandomData(p.digitProvers, p.digitIndex)...)
+	return append(proofRandomData, p.getOrProofRandomData(p.upperProvers, p.upperIndex)...)
+}
+
+func (p *PasswordComplexityProver) getOrProofRandomData(provers []*CompoundPredicateProver,
+	index int) [][]*big.Int {
+	b := new(big.Int).Lsh(big.NewInt(1), uint(p.challengeSpaceSize))
+	proofRandomData := make([][]*big.Int, len(provers))
+	for i, prover := range provers {
+		if i == index {
+			proofRandomData[i] = prover.GetProofRandomData()
+		} else {
+			proofRandomData[i] = prover.getSimulatedProofRandomData(common.GetRandomInt(b))
+		}
+	}
+	return proofRandomData
+}
+
+// GetProofData returns the challenges and the proof data of all the proofs (in the same
+// order as GetProofRandomData).
+func (p *PasswordComplexityProver) GetProofData(challenge *big.Int) ([][]*big.Int,
+	[][]*big.Int) {
+	var challenges, proofData [][]*big.Int
+	for _, prover := range p.lengthProvers {
+		c, z := prover.GetProofData(challenge)
+		challenges = append(challenges, c)
+		proofData = append(proofData, z)
+	}
+	for _, or := range []struct {
+		provers []*CompoundPredicateProver
+		index   int
+	}{{p.digitProvers, p.digitIndex}, {p.upperProvers, p.upperIndex}} {
+		c, z := p.getOrProofData(or.provers, or.index, challenge)
+		challenges = append(challenges, c...)
+		proofData = append(proofData, z...)
+	}
+	return challenges, proofData
+}
+
+// getOrProofData computes the challenge of the proved commitment as the challenge minus
+// the challenges of the simulated proofs (modulo 2^challengeSpaceSize).
+func (p *PasswordComplexityProver) getOrProofData(provers []*CompoundPredicateProver,
+	index int, challenge *big.Int) ([][]*big.Int, [][]*big.Int) {
+	c := new(big.Int).Set(challenge)
+	for i, prover := range provers {
+		if i != index {
+			c.Sub(c, prover.root.challenge)
+		}
+	}
+	c.Mod(c, new(big.Int).Lsh(big.NewInt(1), uint(p.challengeSpaceSize)))
+
+	challenges := make([][]*big.Int, len(provers))
+	proofData := make([][]*big.Int, len(provers))
+	for i, prover := range provers {
+		challenges[i], proofData[i] = prover.GetProofData(c)
+	}
+	return challenges, proofData
+}
+
+type PasswordComplexityVerifier struct {
+	lengthVerifiers    []*CompoundPredicateVerifier
+	digitVerifiers     []*CompoundPredicateVerifier
+	upperVerifiers     []*CompoundPredicateVerifier
+	challenge          *big.Int
+	challengeSpaceSize int
+}
+
+// NewPasswordComplexityVerifier returns PasswordComplexityVerifier for the commitments
+// to the password bytes stored in receivers. T is the bound for the committed values
+// (as in Committer).
+func NewPasswordComplexityVerifier(receivers []*Receiver, T *big.Int,
+	challengeSpaceSize int) (*PasswordComplexityVerifier, error) {
+	if len(receivers) < passwordMinLength {
+		return nil, fmt.Errorf("at least %d commitments are needed", passwordMinLength)
+	}
+	newVerifiers := func(receivers []*Receiver,
+		predicate *Predicate) ([]*CompoundPredicateVerifier, error) {
+		verifiers := make([]*CompoundPredicateVerifier, len(receivers))
+		for i, receiver := range receivers {
+			verifier, err := NewCompoundPredicateVerifier(receiver, predicate, T,
+				challengeSpaceSize)
+			if err != nil {
+				return nil, err
+			}
+			verifiers[i] = verifier
+		}
+		return verifiers, nil
+	}
+
+	lengthVerifiers, err := newVerifiers(receivers[:passwordMinLength], printablePredicate)
+	if err != nil {
+		return nil, err
+	}
+	digitVerifiers, err := newVerifiers(receivers, digitPredicate)
+	if err != nil {
+		return nil, err
+	}
+	upperVerifiers, err := newVerifiers(receivers, upperPredicate)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PasswordComplexityVerifier{
+		lengthVerifiers:    lengthVerifiers,
+		digitVerifiers:     digitVerifiers,
+		upperVerifiers:     upperVerifiers,
+		challengeSpaceSize: challengeSpaceSize,
+	}, nil
+}
+
+// getVerifiers returns all the verifiers in the order of the proofs.
+func (v *PasswordComplexityVerifier) getVerifiers() []*CompoundPredicateVerifier {
+	verifiers := append([]*CompoundPredicateVerifier{}, v.lengthVerifiers...)
+	verifiers = append(verifiers, v.digitVerifiers...)
+	return append(verifiers, v.upperVerifiers...)
+}
+
+func (v *PasswordComplexityVerifier) SetProofRandomData(proofRandomData [][]*big.Int) error {
+	verifiers := v.getVerifiers()
+	if len(proofRandomData) != len(verifiers) {
+		return fmt.Errorf("the length of proofRandomData is not correct")
+	}
+	for i, verifier := range verifiers {
+		if err := verifier.SetProofRandomData(proofRandomData[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (v *PasswordComplexityVerifier) GetChallenge() *big.Int {
+	b := new(big.Int).Lsh(big.NewInt(1), uint(v.challengeSpaceSize))
+	v.challenge = common.GetRandomInt(b)
+	return v.challenge
+}
+
+// SetChallenge is used when Fiat-Shamir is used - when challenge is generated using hash by the prover.
+func (v *PasswordComplexityVerifier) SetChallenge(challenge *big.Int) {
+	v.challenge = challenge
+}
+
+// Verify checks that the length proofs use the challenge, that the challenges of the
+// digit (uppercase letter) proofs sum up to the challenge and that all the proofs are valid.
+func (v *PasswordComplexityVerifier) Verify(challenges, proofData [][]*big.Int) bool {
+	verifiers := v.getVerifiers()
+	if len(challenges) != len(verifiers) || len(proofData) != len(verifiers) {
+		return false
+	}
+	for i, c := range challenges {
+		if len(c) == 0 {
+			return false
+		}
+		if i < len(v.lengthVerifiers) {
+			verifiers[i].SetChallenge(v.challenge)
+		} else {
+			verifiers[i].SetChallenge(c[0])
+		}
+		if !verifiers[i].Verify(c, proofData[i]) {
+			return false
+		}
+	}
+
+	b := new(big.Int).Lsh(big.NewInt(1), uint(v.challengeSpaceSize))
+	n := len(v.digitVerifiers)
+	for _, or := range [][][]*big.Int{
+		challenges[passwordMinLength : passwordMinLength+n],
+		challenges[passwordMinLength+n:]} {
+		sum := big.NewInt(0)
+		for _, c := range or {
+			sum.Add(sum, c[0])
+		}
+		if !common.ConstantTimeEq(sum.Mod(sum, b), v.challenge) {
+			return false
+		}
+	}
+	return true
+}
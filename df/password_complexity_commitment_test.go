@@ -0,0 +1,108 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package df
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// commitPassword commits to the bytes of the password padded with zeros to n bytes.
+func commitPassword(receiver *Receiver, T *big.Int, password string,
+	n int) ([]*Committer, []*Receiver, error) {
+	committers := make([]*Committer, n)
+	receivers := make([]*Receiver, n)
+	for i := 0; i < n; i++ {
+		b := int64(0)
+		if i < len(password) {
+			b = int64(password[i])
+		}
+		committer, r, err := getCommitterAndReceiver(receiver, T, big.NewInt(b))
+		if err != nil {
+			return nil, nil, err
+		}
+		committers[i], receivers[i] = committer, r
+	}
+	return committers, receivers, nil
+}
+
+func provePasswordComplexity(committers []*Committer, receivers []*Receiver,
+	T *big.Int) (bool, error) {
+	prover, err := NewPasswordComplexityProver(committers, 80)
+	if err != nil {
+		return false, err
+	}
+	verifier, err := NewPasswordComplexityVerifier(receivers, T, 80)
+	if err != nil {
+		return false, err
+	}
+	if err := verifier.SetProofRandomData(prover.GetProofRandomData()); err != nil {
+		return false, err
+	}
+	challenges, proofData := prover.GetProofData(verifier.GetChallenge())
+	return verifier.Verify(challenges, proofData), nil
+}
+
+// TestDFCommitmentPasswordComplexity demonstrates how to prove that the committed
+// password has at least 8 characters and contains a digit and an uppercase letter.
+func TestDFCommitmentPasswordComplexity(t *testing.T) {
+	receiver, err := NewReceiver(128, 80)
+	if err != nil {
+		t.Errorf("error in NewReceiver: %v", err)
+	}
+	// n^2 is used for T - but any other value can be used as well
+	T := new(big.Int).Mul(receiver.QRSpecialRSA.N, receiver.QRSpecialRSA.N)
+
+	for _, password := range []string{"Passw0rd", "correct horse B9", "abcdefgH1"} {
+		committers, receivers, err := commitPassword(receiver, T, password, 16)
+		if err != nil {
+			t.Errorf("error when committing to password: %v", err)
+		}
+		proved, err := provePasswordComplexity(committers, receivers, T)
+		if err != nil {
+			t.Errorf("error in password complexity proof: %v", err)
+		}
+		assert.Equal(t, true, proved, "password complexity proof failed for %s", password)
+	}
+
+	for _, password := range []string{"Pass0rd", "password1", "PASSWORD!", "Passw\x000rd"} {
+		committers, _, err := commitPassword(receiver, T, password, 16)
+		if err != nil {
+			t.Errorf("error when committing to password: %v", err)
+		}
+		_, err = NewPasswordComplexityProver(committers, 80)
+		assert.NotNil(t, err, "PasswordComplexityProver should fail for %q", password)
+	}
+
+	// the proof for one password does not verify for the commitments to another one
+	committers, _, err := commitPassword(receiver, T, "Passw0rd", 12)
+	if err != nil {
+		t.Errorf("error when committing to password: %v", err)
+	}
+	_, receivers, err := commitPassword(receiver, T, "password", 12)
+	if err != nil {
+		t.Errorf("error when committing to password: %v", err)
+	}
+	proved, err := provePasswordComplexity(committers, receivers, T)
+	if err != nil {
+		t.Errorf("error in password complexity proof: %v", err)
+	}
+	assert.Equal(t, false, proved, "password complexity proof should fail for other commitments")
+}
@@ -0,0 +1,188 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package df
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/awsong/crypto/common"
+	"github.com/awsong/crypto/pedersen"
+)
+
+// PedersenEqualityProver proves that the value x committed in the DF commitment
+// c1 = G^x * H^r1 mod N is the same as the value committed in the Pedersen commitment
+// c2 = g^x * h^r2 mod p. It works as GEEProver, the same random value rhoX
+// is used to blind x in both commitments:
+// prover chooses rhoX from [0, q * 2^(challengeSpaceSize + K)), rhoR1
+// from [0, 2^(B + 2*NLength + challengeSpaceSize)) and rhoR2 from Z_q and sends
+// t1 = G^rhoX * H^rhoR1 mod N and t2 = g^rhoX * h^rhoR2 mod p. For challenge c it responds with
+// sX = rhoX + c*x, sR1 = rhoR1 + c*r1 (in Z, not modulo) and sR2 = rhoR2 + c*r2 mod q.
+// Verifier checks G^sX * H^sR1 = t1 * c1^c mod N, g^sX * h^sR2 = t2 * c2^c mod p and that
+// sX is smaller than q * 2^(challengeSpaceSize + K + 1).
+// This alone proves only that the DF value is congruent to the Pedersen value modulo q
+// (the DF value could be x + k*q), thus prover additionally proves (using
+// CompoundPredicateProver with the same challenge) that the DF value is in [0, q), which
+// together with the congruence means that the two values are equal.
+type PedersenEqualityProver struct {
+	committer          *Committer
+	pedersenCommitter  *pedersen.Committer
+	rangeProver        *CompoundPredicateProver
+	challengeSpaceSize int
+	rhoX               *big.Int
+	rhoR1              *big.Int
+	rhoR2              *big.Int
+}
+
+// NewPedersenEqualityProver returns PedersenEqualityProver. It returns an error if committer
+// and pedersenCommitter do not hold a commitment to the same value from [0, q).
+func NewPedersenEqualityProver(committer *Committer, pedersenCommitter *pedersen.Committer,
+	challengeSpaceSize int) (*PedersenEqualityProver, error) {
+	x1, _ := committer.GetDecommitMsg()
+	x2, _ := pedersenCommitter.GetDecommitMsg()
+	if x1 == nil || x2 == nil || x1.Cmp(x2) != 0 {
+		return nil, fmt.Errorf("committers need to hold a commitment to the same value")
+	}
+	if x1.Sign() < 0 || x1.Cmp(pedersenCommitter.Params.Group.Q) >= 0 {
+		return nil, fmt.Errorf("committed value needs to be in [0, q)")
+	}
+	rangeProver, err := NewCompoundPredicateProver(committer,
+		getExponentRangePredicate(pedersenCommitter.Params.Group.Q), challengeSpaceSize)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PedersenEqualityProver{
+		committer:          committer,
+		pedersenCommitter:  pedersenCommitter,
+		rangeProver:        rangeProver,
+		challengeSpaceSize: challengeSpaceSize,
+	}, nil
+}
+
+// GetProofRandomData returns t1 = G^rhoX * H^rhoR1 mod N, t2 = g^rhoX * h^rhoR2 mod p and
+// the proof random data of the range proof.
+func (p *PedersenEqualityProver) GetProofRandomData() (*big.Int, *big.Int, []*big.Int) {
+	group := p.pedersenCommitter.Params.Group
+	b1 := new(big.Int).Lsh(group.Q, uint(p.challengeSpaceSize+p.committer.K))
+	p.rhoX = common.GetRandomInt(b1)
+	nLen := p.committer.QRSpecialRSA.N.BitLen()
+	b2 := new(big.Int).Lsh(big.NewInt(1), uint(p.committer.B+2*nLen+p.challengeSpaceSize))
+	p.rhoR1 = common.GetRandomInt(b2)
+	p.rhoR2 = common.GetRandomInt(group.Q)
+
+	t1 := p.committer.ComputeCommit(p.rhoX, p.rhoR1)
+	t2 := group.Mul(group.Exp(group.G, p.rhoX),
+		group.Exp(p.pedersenCommitter.Params.H, p.rhoR2))
+	return t1, t2, p.rangeProver.GetProofRandomData()
+}
+
+// GetProofData returns sX = rhoX + challenge*x, sR1 = rhoR1 + challenge*r1 (in Z, not modulo),
+// sR2 = rhoR2 + challenge*r2 mod q and the challenges and the proof data of the range proof.
+func (p *PedersenEqualityProver) GetProofData(challenge *big.Int) (*big.Int, *big.Int,
+	*big.Int, []*big.Int, []*big.Int) {
+	x, r1 := p.committer.GetDecommitMsg()
+	_, r2 := p.pedersenCommitter.GetDecommitMsg()
+	sX := new(big.Int).Mul(challenge, x)
+	sX.Add(sX, p.rhoX)
+	sR1 := new(big.Int).Mul(challenge, r1)
+	sR1.Add(sR1, p.rhoR1)
+	sR2 := new(big.Int).Mul(challenge, r2)
+	sR2.Add(sR2, p.rhoR2)
+	sR2.Mod(sR2, p.pedersenCommitter.Params.Group.Q)
+	rangeChallenges, rangeProofData := p.rangeProver.GetProofData(challenge)
+	return sX, sR1, sR2, rangeChallenges, rangeProofData
+}
+
+type PedersenEqualityVerifier struct {
+	receiver           *Receiver
+	rangeVerifier      *CompoundPredicateVerifier
+	pedersenParams     *pedersen.Params
+	pedersenCommitment *big.Int
+	challengeSpaceSize int
+	challenge          *big.Int
+	t1                 *big.Int
+	t2                 *big.Int
+}
+
+// NewPedersenEqualityVerifier returns PedersenEqualityVerifier which verifies that
+// receiver.Commitment and pedersenCommitment (computed using pedersenParams) hide the same value.
+// T is the bound for the values committed in DF commitments (as in Committer).
+func NewPedersenEqualityVerifier(receiver *Receiver, pedersenParams *pedersen.Params,
+	pedersenCommitment, T *big.Int, challengeSpaceSize int) (*PedersenEqualityVerifier, error) {
+	if pedersenParams.Group.Q.BitLen() <= challengeSpaceSize {
+		return nil, fmt.Errorf("challenge space needs to be smaller than q")
+	}
+	rangeVerifier, err := NewCompoundPredicateVerifier(receiver,
+		getExponentRangePredicate(pedersenParams.Group.Q), T, challengeSpaceSize)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PedersenEqualityVerifier{
+		receiver:           receiver,
+		rangeVerifier:      rangeVerifier,
+		pedersenParams:     pedersenParams,
+		pedersenCommitment: pedersenCommitment,
+		challengeSpaceSize: challengeSpaceSize,
+	}, nil
+}
+
+func (v *PedersenEqualityVerifier) SetProofRandomData(t1, t2 *big.Int,
+	rangeProofRandomData []*big.Int) error {
+	v.t1 = t1
+	v.t2 = t2
+	return v.rangeVerifier.SetProofRandomData(rangeProofRandomData)
+}
+
+func (v *PedersenEqualityVerifier) GetChallenge() *big.Int {
+	b := new(big.Int).Lsh(big.NewInt(1), uint(v.challengeSpaceSize))
+	challenge := common.GetRandomInt(b)
+	v.SetChallenge(challenge)
+	return challenge
+}
+
+// SetChallenge is used when Fiat-Shamir is used - when challenge is generated using hash by the prover.
+func (v *PedersenEqualityVerifier) SetChallenge(challenge *big.Int) {
+	v.challenge = challenge
+	v.rangeVerifier.SetChallenge(challenge)
+}
+
+// Verify checks the equality modulo q and the range proof that the DF value is in [0, q).
+func (v *PedersenEqualityVerifier) Verify(sX, sR1, sR2 *big.Int, rangeChallenges,
+	rangeProofData []*big.Int) bool {
+	if !v.rangeVerifier.Verify(rangeChallenges, rangeProofData) {
+		return false
+	}
+	group := v.pedersenParams.Group
+	bound := new(big.Int).Lsh(group.Q, uint(v.challengeSpaceSize+v.receiver.K+1))
+	if sX.Sign() < 0 || sX.Cmp(bound) >= 0 {
+		return false
+	}
+
+	// check G^sX * H^sR1 = t1 * c1^challenge mod N
+	left1 := v.receiver.ComputeCommit(sX, sR1)
+	right1 := v.receiver.QRSpecialRSA.Exp(v.receiver.Commitment, v.challenge)
+	right1 = v.receiver.QRSpecialRSA.Mul(v.t1, right1)
+
+	// check g^sX * h^sR2 = t2 * c2^challenge mod p
+	left2 := group.Mul(group.Exp(group.G, sX), group.Exp(v.pedersenParams.H, sR2))
+	right2 := group.Mul(v.t2, group.Exp(v.pedersenCommitment, v.challenge))
+
+	return common.ConstantTimeEq(left1, right1) && common.ConstantTimeEq(left2, right2)
+}
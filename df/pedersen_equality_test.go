@@ -0,0 +1,153 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package df
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/awsong/crypto/common"
+	"github.com/awsong/crypto/pedersen"
+	"github.com/stretchr/testify/assert"
+)
+
+func provePedersenEquality(prover *PedersenEqualityProver,
+	verifier *PedersenEqualityVerifier) (bool, error) {
+	t1, t2, rangeProofRandomData := prover.GetProofRandomData()
+	if err := verifier.SetProofRandomData(t1, t2, rangeProofRandomData); err != nil {
+		return false, err
+	}
+	return verifier.Verify(prover.GetProofData(verifier.GetChallenge())), nil
+}
+
+// TestPedersenEquality demonstrates how to prove that the value committed in DF
+// commitment c1 = G^x * H^r1 mod N is the same as the value committed in Pedersen
+// commitment c2 = g^x * h^r2 mod p.
+func TestPedersenEquality(t *testing.T) {
+	pedersenReceiver, err := pedersen.NewReceiver(160)
+	if err != nil {
+		t.Errorf("error in pedersen.NewReceiver: %v", err)
+	}
+	params := pedersenReceiver.Params
+	receiver, err := NewReceiver(128, 80)
+	if err != nil {
+		t.Errorf("error in NewReceiver: %v", err)
+	}
+	// n^2 is used for T - but any other value can be used as well
+	T := new(big.Int).Mul(receiver.QRSpecialRSA.N, receiver.QRSpecialRSA.N)
+	committer := NewCommitter(receiver.QRSpecialRSA.N,
+		receiver.G, receiver.H, T, receiver.K)
+	pedersenCommitter := pedersen.NewCommitter(params)
+
+	x := common.GetRandomInt(params.Group.Q)
+	c1, err := committer.GetCommitMsg(x)
+	if err != nil {
+		t.Errorf("error in computing commit msg: %v", err)
+	}
+	receiver.SetCommitment(c1)
+	c2, err := pedersenCommitter.GetCommitMsg(x)
+	if err != nil {
+		t.Errorf("error in computing Pedersen commit msg: %v", err)
+	}
+
+	challengeSpaceSize := 80
+	prover, err := NewPedersenEqualityProver(committer, pedersenCommitter, challengeSpaceSize)
+	if err != nil {
+		t.Errorf("error in NewPedersenEqualityProver: %v", err)
+	}
+	verifier, err := NewPedersenEqualityVerifier(receiver, params, c2, T, challengeSpaceSize)
+	if err != nil {
+		t.Errorf("error in NewPedersenEqualityVerifier: %v", err)
+	}
+	proved, err := provePedersenEquality(prover, verifier)
+	if err != nil {
+		t.Errorf("error in DF-Pedersen equality proof: %v", err)
+	}
+	assert.Equal(t, true, proved, "DF-Pedersen equality proof failed")
+
+	// Pedersen commitment to a different value
+	cOther, err := pedersen.NewCommitter(params).GetCommitMsg(new(big.Int).Add(x,
+		big.NewInt(1)))
+	if err != nil {
+		t.Errorf("error in computing Pedersen commit msg: %v", err)
+	}
+	verifier, _ = NewPedersenEqualityVerifier(receiver, params, cOther, T, challengeSpaceSize)
+	proved, err = provePedersenEquality(prover, verifier)
+	if err != nil {
+		t.Errorf("error in DF-Pedersen equality proof: %v", err)
+	}
+	assert.Equal(t, false, proved, "DF-Pedersen equality proof should fail for different values")
+
+	_, err = pedersenCommitter.GetCommitMsg(new(big.Int).Add(x, big.NewInt(1)))
+	if err != nil {
+		t.Errorf("error in computing Pedersen commit msg: %v", err)
+	}
+	_, err = NewPedersenEqualityProver(committer, pedersenCommitter, challengeSpaceSize)
+	assert.NotNil(t, err, "PedersenEqualityProver should fail for different values")
+}
+
+// TestPedersenEqualityModQ checks that the proof fails when the DF commitment hides x + q
+// and the Pedersen commitment hides x (the values are equal only modulo q). The prover is
+// built directly as NewPedersenEqualityProver rejects such values.
+func TestPedersenEqualityModQ(t *testing.T) {
+	pedersenReceiver, err := pedersen.NewReceiver(160)
+	if err != nil {
+		t.Errorf("error in pedersen.NewReceiver: %v", err)
+	}
+	params := pedersenReceiver.Params
+	q := params.Group.Q
+	receiver, err := NewReceiver(128, 80)
+	if err != nil {
+		t.Errorf("error in NewReceiver: %v", err)
+	}
+	T := new(big.Int).Mul(receiver.QRSpecialRSA.N, receiver.QRSpecialRSA.N)
+
+	for i := 0; i < 5; i++ {
+		x := common.GetRandomInt(q)
+		committer, r, err := getCommitterAndReceiver(receiver, T, new(big.Int).Add(x, q))
+		if err != nil {
+			t.Errorf("error when committing: %v", err)
+		}
+		pedersenCommitter := pedersen.NewCommitter(params)
+		c2, err := pedersenCommitter.GetCommitMsg(x)
+		if err != nil {
+			t.Errorf("error in computing Pedersen commit msg: %v", err)
+		}
+
+		rangeProver, err := newCompoundPredicateProver(committer,
+			getExponentRangePredicate(q), 80)
+		if err != nil {
+			t.Errorf("error in newCompoundPredicateProver: %v", err)
+		}
+		prover := &PedersenEqualityProver{
+			committer:          committer,
+			pedersenCommitter:  pedersenCommitter,
+			rangeProver:        rangeProver,
+			challengeSpaceSize: 80,
+		}
+		verifier, err := NewPedersenEqualityVerifier(r, params, c2, T, 80)
+		if err != nil {
+			t.Errorf("error in NewPedersenEqualityVerifier: %v", err)
+		}
+		proved, err := provePedersenEquality(prover, verifier)
+		if err != nil {
+			t.Errorf("error in DF-Pedersen equality proof: %v", err)
+		}
+		assert.Equal(t, false, proved, "DF-Pedersen equality proof should fail for x + q")
+	}
+}
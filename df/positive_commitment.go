@@ -21,16 +21,19 @@ import (
 	"math/big"
 
 	"fmt"
+	"sort"
 
 	"github.com/awsong/crypto/common"
+	"github.com/awsong/crypto/transcript"
 )
 
 // PositiveProver proves that the commitment hides the positive number. Given c,
 // prove that c = g^x * h^r (mod n) where x >= 0.
 type PositiveProver struct {
-	squareProvers    []*SquareProver
-	smallCommitments []*big.Int
-	bigCommitments   []*big.Int
+	squareProvers      []*SquareProver
+	smallCommitments   []*big.Int
+	bigCommitments     []*big.Int
+	challengeSpaceSize int
 }
 
 func NewPositiveProver(committer *Committer,
@@ -41,10 +44,73 @@ func NewPositiveProver(committer *Committer,
 	// c2 = g^(x2^2) * h^r2, c3 = g^(x3^2) * h^r3 and where r = r0 + r1 + r2 + r3.
 	// We then prove that c0, c1, c2, c3 contains squares and verifier checks that c = c0*c1*c2*c3.
 
-	roots, err := lipmaaDecomposition(x)
+	// Zero roots are kept - the number of commitments needs to be four for any x,
+	// otherwise it would reveal the number of non-zero roots of x.
+	roots, err := lipmaaDecompose(x)
 	if err != nil {
 		return nil, fmt.Errorf("error when doing Lipmaa decomposition")
 	}
+	return newPositiveProverFromRoots(committer, roots[:], r, challengeSpaceSize)
+}
+
+// NewPositiveProverFromRoots returns PositiveProver for the value x committed in committer
+// when the decomposition x = roots[0]^2 + ... + roots[3]^2 is already known (so that
+// it does not need to be computed again). It returns an error if the squares of roots
+// do not sum up to x. If less than four roots are given, zero roots are added (as
+// PositiveVerifier expects four commitments).
+func NewPositiveProverFromRoots(committer *Committer, roots []*big.Int, r *big.Int,
+	challengeSpaceSize int) (*PositiveProver, error) {
+	if len(roots) == 0 || len(roots) > 4 {
+		return nil, fmt.Errorf("the number of roots needs to be between 1 and 4")
+	}
+	if err := checkRoots(committer, roots); err != nil {
+		return nil, err
+	}
+	padded := append([]*big.Int{}, roots...)
+	for len(padded) < 4 {
+		padded = append(padded, big.NewInt(0))
+	}
+	return newPositiveProverFromRoots(committer, padded, r, challengeSpaceSize)
+}
+
+// NewPositiveProverWithDecomposition returns PositiveProver for the value x committed
+// in committer where x is written as a sum of squares using decomp (instead of the Lipmaa
+// decomposition used by NewPositiveProver). The number of roots is revealed to the
+// verifier (it needs to be passed to NewPositiveVerifierWithDecomposition), thus
+// it needs to be the same for all values of interest - otherwise the proof reveals which
+// of the values of interest x is (for example, TwoSquareDecomposition reveals that x is
+// a prime 1 mod 4, BitDecomposition reveals only BitLen).
+func NewPositiveProverWithDecomposition(committer *Committer, decomp Decomposition,
+	x, r *big.Int, challengeSpaceSize int) (*PositiveProver, error) {
+	roots, err := decomp.Decompose(x)
+	if err != nil {
+		return nil, err
+	}
+	if len(roots) == 0 {
+		return nil, fmt.Errorf("decomposition returned no roots")
+	}
+	if err := checkRoots(committer, roots); err != nil {
+		return nil, err
+	}
+	return newPositiveProverFromRoots(committer, roots, r, challengeSpaceSize)
+}
+
+// checkRoots returns an error if the squares of roots do not sum up to the value
+// committed in committer.
+func checkRoots(committer *Committer, roots []*big.Int) error {
+	x, _ := committer.GetDecommitMsg()
+	sum := big.NewInt(0)
+	for _, root := range roots {
+		sum.Add(sum, new(big.Int).Mul(root, root))
+	}
+	if x == nil || sum.Cmp(x) != 0 {
+		return fmt.Errorf("the squares of roots do not sum up to the committed value")
+	}
+	return nil
+}
+
+func newPositiveProverFromRoots(committer *Committer, roots []*big.Int, r *big.Int,
+	challengeSpaceSize int) (*PositiveProver, error) {
 	nRoots := len(roots)
 
 	// find r0, r1, r2, r3 such that r0 + r1 + r2 + r3 = r
@@ -76,32 +142,36 @@ func NewPositiveProver(committer *Committer,
 	}
 
 	return &PositiveProver{
-		squareProvers:    squareProvers,
-		smallCommitments: smallCommitments,
-		bigCommitments:   bigCommitments,
+		squareProvers:      squareProvers,
+		smallCommitments:   smallCommitments,
+		bigCommitments:     bigCommitments,
+		challengeSpaceSize: challengeSpaceSize,
 	}, nil
 }
 
 // getCommitRandoms returns slice containing r_i for 0 <= i < nRoots such that
-// r = r_0 + ... + r_(nRoots-1).
+// r = r_0 + ... + r_(nRoots-1). The values are chosen uniformly from all such (non-negative
+// for r >= 0, non-positive for r < 0) decompositions: nRoots-1 cut points are chosen
+// uniformly from [0, abs(r)] and the r_i are the differences between the sorted cut points.
 func getCommitRandoms(r *big.Int, nRoots int) []*big.Int {
 	rAbs := new(big.Int).Abs(r) // r can be negative, see range proof
-	boundary := new(big.Int).Set(rAbs)
+	bound := new(big.Int).Add(rAbs, big.NewInt(1))
 
-	rs := make([]*big.Int, nRoots)
-	for i, _ := range rs {
-		r := common.GetRandomInt(boundary)
-		if i < nRoots-1 {
-			rs[i] = r
-			boundary.Sub(boundary, r)
-		} else {
-			rs[i] = boundary
-		}
+	cuts := make([]*big.Int, nRoots+1)
+	cuts[0] = big.NewInt(0)
+	cuts[nRoots] = rAbs
+	for i := 1; i < nRoots; i++ {
+		cuts[i] = common.GetRandomInt(bound)
 	}
+	sort.Slice(cuts[1:nRoots], func(i, j int) bool {
+		return cuts[i+1].Cmp(cuts[j+1]) < 0
+	})
 
-	if rAbs.Cmp(r) != 0 { // if r is negative
-		for _, elem := range rs {
-			elem.Neg(elem)
+	rs := make([]*big.Int, nRoots)
+	for i := range rs {
+		rs[i] = new(big.Int).Sub(cuts[i+1], cuts[i])
+		if r.Sign() < 0 {
+			rs[i].Neg(rs[i])
 		}
 	}
 	return rs
@@ -150,22 +220,70 @@ func NewPositiveProof(proofRandomData, challenges, proofData []*big.Int) *Positi
 	}
 }
 
+// ProveWithTranscript returns a non-interactive proof (Fiat-Shamir) where the challenges
+// are derived from the transcript after the commitments and the proof random data
+// are appended to it.
+func (p *PositiveProver) ProveWithTranscript(tr *transcript.Transcript) *PositiveProof {
+	proofRandomData := p.GetProofRandomData()
+	challenges := getPositiveTranscriptChallenges(tr, p.smallCommitments, p.bigCommitments,
+		proofRandomData, p.challengeSpaceSize)
+	return NewPositiveProof(proofRandomData, challenges, p.GetProofData(challenges))
+}
+
+// getPositiveTranscriptChallenges appends the commitments and proofRandomData to the
+// transcript and returns a challenge for each square proof.
+func getPositiveTranscriptChallenges(tr *transcript.Transcript, smallCommitments,
+	bigCommitments, proofRandomData []*big.Int, challengeSpaceSize int) []*big.Int {
+	tr.AppendInts("positive-small-commitments", smallCommitments...)
+	tr.AppendInts("positive-big-commitments", bigCommitments...)
+	tr.AppendInts("positive-t", proofRandomData...)
+	challenges := make([]*big.Int, len(bigCommitments))
+	for i := range challenges {
+		challenges[i] = tr.ChallengeInt("positive-challenge", challengeSpaceSize)
+	}
+	return challenges
+}
+
 type PositiveVerifier struct {
-	squareVerifiers []*SquareVerifier
-	proofRandomData []*big.Int
+	squareVerifiers    []*SquareVerifier
+	proofRandomData    []*big.Int
+	smallCommitments   []*big.Int
+	bigCommitments     []*big.Int
+	challengeSpaceSize int
 }
 
+// NewPositiveVerifier returns PositiveVerifier for the proof by PositiveProver which
+// uses Lipmaa decomposition (four commitments).
 func NewPositiveVerifier(receiver *Receiver,
 	receiverCommitment *big.Int, smallCommitments, bigCommitments []*big.Int,
 	challengeSpaceSize int) (*PositiveVerifier, error) {
+	return newPositiveVerifier(receiver, receiverCommitment, smallCommitments,
+		bigCommitments, 4, challengeSpaceSize)
+}
+
+// NewPositiveVerifierWithDecomposition returns PositiveVerifier for the proof by
+// PositiveProver created with NewPositiveProverWithDecomposition. nRoots is the number
+// of roots the decomposition gives (it needs to be agreed in advance, see
+// NewPositiveProverWithDecomposition).
+func NewPositiveVerifierWithDecomposition(receiver *Receiver,
+	receiverCommitment *big.Int, smallCommitments, bigCommitments []*big.Int, nRoots int,
+	challengeSpaceSize int) (*PositiveVerifier, error) {
+	return newPositiveVerifier(receiver, receiverCommitment, smallCommitments,
+		bigCommitments, nRoots, challengeSpaceSize)
+}
 
-	nRoots := len(smallCommitments)
+func newPositiveVerifier(receiver *Receiver,
+	receiverCommitment *big.Int, smallCommitments, bigCommitments []*big.Int, nRoots int,
+	challengeSpaceSize int) (*PositiveVerifier, error) {
+	if nRoots < 1 || len(smallCommitments) != nRoots || len(bigCommitments) != nRoots {
+		return nil, fmt.Errorf("the number of commitments needs to be %d", nRoots)
+	}
 	// check: c = c0*c1*c2*c3
 	check := big.NewInt(1)
 	for i := 0; i < nRoots; i++ {
 		check = receiver.QRSpecialRSA.Mul(check, bigCommitments[i])
 	}
-	if receiverCommitment.Cmp(check) != 0 {
+	if !common.ConstantTimeEq(receiverCommitment, check) {
 		return nil, fmt.Errorf("squareProvers are not properly instantiated")
 	}
 
@@ -191,7 +309,10 @@ func NewPositiveVerifier(receiver *Receiver,
 	}
 
 	return &PositiveVerifier{
-		squareVerifiers: squareVerifiers,
+		squareVerifiers:    squareVerifiers,
+		smallCommitments:   smallCommitments,
+		bigCommitments:     bigCommitments,
+		challengeSpaceSize: challengeSpaceSize,
 	}, nil
 }
 
@@ -211,7 +332,7 @@ func (v *PositiveVerifier) SetChallenges(challenges []*big.Int) {
 }
 
 func (v *PositiveVerifier) SetProofRandomData(proofRandomData []*big.Int) error {
-	if len(proofRandomData) != 8 {
+	if len(proofRandomData) != 2*len(v.squareVerifiers) {
 		return fmt.Errorf("the length of proofRandomData is not correct")
 	}
 	for i, verifier := range v.squareVerifiers {
@@ -221,7 +342,7 @@ func (v *PositiveVerifier) SetProofRandomData(proofRandomData []*big.Int) error
 }
 
 func (v *PositiveVerifier) Verify(proofData []*big.Int) bool {
-	if len(proofData) != 12 {
+	if len(proofData) != 3*len(v.squareVerifiers) {
 		return false
 	}
 	verified := true
@@ -230,3 +351,24 @@ func (v *PositiveVerifier) Verify(proofData []*big.Int) bool {
 	}
 	return verified
 }
+
+// VerifyWithTranscript verifies the proof obtained by PositiveProver.ProveWithTranscript.
+// The transcript needs to be in the same state as the one the prover used.
+func (v *PositiveVerifier) VerifyWithTranscript(proof *PositiveProof,
+	tr *transcript.Transcript) bool {
+	challenges := getPositiveTranscriptChallenges(tr, v.smallCommitments, v.bigCommitments,
+		proof.ProofRandomData, v.challengeSpaceSize)
+	if len(proof.Challenges) != len(challenges) {
+		return false
+	}
+	for i, challenge := range challenges {
+		if !common.ConstantTimeEq(challenge, proof.Challenges[i]) {
+			return false
+		}
+	}
+	if err := v.SetProofRandomData(proof.ProofRandomData); err != nil {
+		return false
+	}
+	v.SetChallenges(challenges)
+	return v.Verify(proof.ProofData)
+}
@@ -22,6 +22,7 @@ import (
 	"testing"
 
 	"github.com/awsong/crypto/common"
+	"github.com/awsong/crypto/transcript"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -70,3 +71,181 @@ func TestDFCommitmentPositive(t *testing.T) {
 	proved := verifier.Verify(proofData)
 	assert.Equal(t, true, proved, "DamgardFujisaki positive proof failed.")
 }
+
+// TestDFCommitmentPositiveRootCount checks that the number of commitments in the positive
+// proof does not depend on the number of non-zero roots of Lipmaa decomposition.
+func TestDFCommitmentPositiveRootCount(t *testing.T) {
+	receiver, err := NewReceiver(128, 80)
+	if err != nil {
+		t.Errorf("error in NewReceiver: %v", err)
+	}
+	T := new(big.Int).Mul(receiver.QRSpecialRSA.N, receiver.QRSpecialRSA.N)
+
+	// 0, 1, 5 and 24 have 0, 1, 2 and 3 non-zero roots
+	for _, val := range []int64{0, 1, 5, 24, 7} {
+		x := big.NewInt(val)
+		committer, r, err := getCommitterAndReceiver(receiver, T, x)
+		if err != nil {
+			t.Errorf("error when committing: %v", err)
+		}
+		_, rand := committer.GetDecommitMsg()
+		prover, err := NewPositiveProver(committer, x, rand, 80)
+		if err != nil {
+			t.Errorf("error in instantiating PositiveProver: %v", err)
+		}
+		smallCommitments, bigCommitments := prover.GetVerifierInitializationData()
+		assert.Equal(t, 4, len(bigCommitments), "the number of commitments is not 4 for %d", val)
+
+		verifier, err := NewPositiveVerifier(r, r.Commitment, smallCommitments,
+			bigCommitments, 80)
+		if err != nil {
+			t.Errorf("error in instantiating PositiveVerifier: %v", err)
+		}
+		err = verifier.SetProofRandomData(prover.GetProofRandomData())
+		if err != nil {
+			t.Errorf("error when calling SetProofRandomData: %v", err)
+		}
+		proved := verifier.Verify(prover.GetProofData(verifier.GetChallenges()))
+		assert.Equal(t, true, proved, "DamgardFujisaki positive proof failed for %d.", val)
+
+		_, err = NewPositiveVerifier(r, r.Commitment, smallCommitments[:2],
+			bigCommitments[:2], 80)
+		assert.NotNil(t, err, "PositiveVerifier should fail for less than four commitments")
+	}
+}
+
+// TestDFCommitmentPositiveFromRoots demonstrates how to use an already computed Lipmaa
+// decomposition in the positive proof.
+func TestDFCommitmentPositiveFromRoots(t *testing.T) {
+	receiver, err := NewReceiver(128, 80)
+	if err != nil {
+		t.Errorf("error in NewReceiver: %v", err)
+	}
+	T := new(big.Int).Mul(receiver.QRSpecialRSA.N, receiver.QRSpecialRSA.N)
+	committer := NewCommitter(receiver.QRSpecialRSA.N,
+		receiver.G, receiver.H, T, receiver.K)
+
+	x := common.GetRandomInt(committer.QRSpecialRSA.N)
+	c, err := committer.GetCommitMsg(x)
+	if err != nil {
+		t.Errorf("error in computing commit msg: %v", err)
+	}
+	receiver.SetCommitment(c)
+	_, r := committer.GetDecommitMsg()
+
+	roots, err := lipmaaDecomposition(x)
+	if err != nil {
+		t.Errorf("error in Lipmaa decomposition: %v", err)
+	}
+	prover, err := NewPositiveProverFromRoots(committer, roots, r, 80)
+	if err != nil {
+		t.Errorf("error in instantiating PositiveProver: %v", err)
+	}
+	smallCommitments, bigCommitments := prover.GetVerifierInitializationData()
+	verifier, err := NewPositiveVerifier(receiver, receiver.Commitment,
+		smallCommitments, bigCommitments, 80)
+	if err != nil {
+		t.Errorf("error in instantiating PositiveVerifier: %v", err)
+	}
+	err = verifier.SetProofRandomData(prover.GetProofRandomData())
+	if err != nil {
+		t.Errorf("error when calling SetProofRandomData: %v", err)
+	}
+	proved := verifier.Verify(prover.GetProofData(verifier.GetChallenges()))
+	assert.Equal(t, true, proved, "DamgardFujisaki positive proof from roots failed.")
+
+	wrongRoots := []*big.Int{roots[0], roots[1], roots[2], new(big.Int).Add(roots[3], one)}
+	_, err = NewPositiveProverFromRoots(committer, wrongRoots, r, 80)
+	assert.NotNil(t, err, "roots which do not sum up to x should not be accepted")
+}
+
+// TestDFCommitmentPositiveTranscript demonstrates the non-interactive variant of the
+// positive proof, where the challenges are derived from a proof transcript.
+func TestDFCommitmentPositiveTranscript(t *testing.T) {
+	receiver, err := NewReceiver(128, 80)
+	if err != nil {
+		t.Errorf("error in NewReceiver: %v", err)
+	}
+	T := new(big.Int).Mul(receiver.QRSpecialRSA.N, receiver.QRSpecialRSA.N)
+	committer := NewCommitter(receiver.QRSpecialRSA.N,
+		receiver.G, receiver.H, T, receiver.K)
+
+	x := common.GetRandomInt(committer.QRSpecialRSA.N)
+	c, err := committer.GetCommitMsg(x)
+	if err != nil {
+		t.Errorf("error in computing commit msg: %v", err)
+	}
+	receiver.SetCommitment(c)
+	_, r := committer.GetDecommitMsg()
+
+	prover, err := NewPositiveProver(committer, x, r, 80)
+	if err != nil {
+		t.Errorf("error in instantiating PositiveProver: %v", err)
+	}
+	smallCommitments, bigCommitments := prover.GetVerifierInitializationData()
+	verifier, err := NewPositiveVerifier(receiver, receiver.Commitment,
+		smallCommitments, bigCommitments, 80)
+	if err != nil {
+		t.Errorf("error in instantiating PositiveVerifier: %v", err)
+	}
+
+	proof := prover.ProveWithTranscript(transcript.NewTranscript("positive test"))
+	proved := verifier.VerifyWithTranscript(proof, transcript.NewTranscript("positive test"))
+	assert.Equal(t, true, proved, "DamgardFujisaki positive proof with transcript failed.")
+
+	proved = verifier.VerifyWithTranscript(proof, transcript.NewTranscript("another protocol"))
+	assert.Equal(t, false, proved,
+		"DamgardFujisaki positive proof should fail with a different transcript")
+}
+
+// getCommitRandomsSequential is the previous implementation of getCommitRandoms which
+// chose r_i one after another from what was left.
+func getCommitRandomsSequential(r *big.Int, nRoots int) []*big.Int {
+	boundary := new(big.Int).Set(r)
+	rs := make([]*big.Int, nRoots)
+	for i := range rs {
+		if i < nRoots-1 {
+			rs[i] = common.GetRandomInt(boundary)
+			boundary.Sub(boundary, rs[i])
+		} else {
+			rs[i] = boundary
+		}
+	}
+	return rs
+}
+
+// getMeans returns the mean of each r_i over n samples.
+func getMeans(getRandoms func(*big.Int, int) []*big.Int, r *big.Int, nRoots,
+	n int) []float64 {
+	means := make([]float64, nRoots)
+	for i := 0; i < n; i++ {
+		for j, ri := range getRandoms(r, nRoots) {
+			means[j] += float64(ri.Int64()) / float64(n)
+		}
+	}
+	return means
+}
+
+func TestGetCommitRandoms(t *testing.T) {
+	r := big.NewInt(1000)
+	for _, sign := range []int64{1, -1} {
+		rs := getCommitRandoms(new(big.Int).Mul(r, big.NewInt(sign)), 4)
+		sum := big.NewInt(0)
+		for _, ri := range rs {
+			assert.True(t, ri.Sign()*int(sign) >= 0, "r_i has a wrong sign")
+			sum.Add(sum, ri)
+		}
+		assert.Equal(t, new(big.Int).Mul(r, big.NewInt(sign)), sum, "r_i do not sum up to r")
+	}
+
+	// All r_i should have the same distribution, with the mean r/4 (the standard deviation
+	// of the mean over 4000 samples is about 3).
+	n := 4000
+	for i, mean := range getMeans(getCommitRandoms, r, 4, n) {
+		assert.InDelta(t, 250, mean, 25, "r_%d is biased", i)
+	}
+	// The previous implementation gave r_0 the mean r/2 and r_3 the mean r/8.
+	means := getMeans(getCommitRandomsSequential, r, 4, n)
+	assert.InDelta(t, 500, means[0], 25, "unexpected mean of the sequential r_0")
+	assert.InDelta(t, 125, means[3], 25, "unexpected mean of the sequential r_3")
+}
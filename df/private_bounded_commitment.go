@@ -0,0 +1,81 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package df
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// PrivateBoundedProver proves that the value x committed in c_x = g^x * h^r_x is smaller
+// than the value b committed in c_b = g^b * h^r_b, where both x and b are secret.
+// It holds c_b * c_x^(-1) = g^(b - x) * h^(r_b - r_x), thus prover proves that this
+// commitment (which can be computed by the verifier) hides a value >= 1, that is
+// b - x - 1 >= 0 (using CompoundPredicateProver).
+type PrivateBoundedProver struct {
+	*CompoundPredicateProver
+}
+
+func NewPrivateBoundedProver(committerX, committerB *Committer,
+	challengeSpaceSize int) (*PrivateBoundedProver, error) {
+	x, rX := committerX.GetDecommitMsg()
+	b, rB := committerB.GetDecommitMsg()
+	d := new(big.Int).Sub(b, x)
+	if d.Sign() <= 0 {
+		return nil, fmt.Errorf("the committed value is not smaller than the committed bound")
+	}
+	committer := NewCommitter(committerX.QRSpecialRSA.N, committerX.G, committerX.H,
+		committerX.T, committerX.K)
+	if _, err := committer.GetCommitMsgWithGivenR(d, new(big.Int).Sub(rB, rX)); err != nil {
+		return nil, err
+	}
+	prover, err := NewCompoundPredicateProver(committer, NewGePredicate(big.NewInt(1)),
+		challengeSpaceSize)
+	if err != nil {
+		return nil, err
+	}
+	return &PrivateBoundedProver{
+		CompoundPredicateProver: prover,
+	}, nil
+}
+
+type PrivateBoundedVerifier struct {
+	*CompoundPredicateVerifier
+}
+
+// NewPrivateBoundedVerifier returns PrivateBoundedVerifier for the value committed in
+// receiverX and the bound committed in receiverB. T is the bound for the committed
+// values (as in Committer).
+func NewPrivateBoundedVerifier(receiverX, receiverB *Receiver, T *big.Int,
+	challengeSpaceSize int) (*PrivateBoundedVerifier, error) {
+	receiver, err := NewReceiverFromParams(receiverX.QRSpecialRSA.GetPrimes(),
+		receiverX.G, receiverX.H, receiverX.K)
+	if err != nil {
+		return nil, fmt.Errorf("error when calling NewReceiverFromParams")
+	}
+	group := receiverX.QRSpecialRSA
+	receiver.SetCommitment(group.Mul(receiverB.Commitment, group.Inv(receiverX.Commitment)))
+	verifier, err := NewCompoundPredicateVerifier(receiver, NewGePredicate(big.NewInt(1)), T,
+		challengeSpaceSize)
+	if err != nil {
+		return nil, err
+	}
+	return &PrivateBoundedVerifier{
+		CompoundPredicateVerifier: verifier,
+	}, nil
+}
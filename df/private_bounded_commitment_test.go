@@ -0,0 +1,100 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package df
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestDFCommitmentPrivateBounded demonstrates how to prove that the committed value is
+// smaller than the committed bound. Given c_x and c_b, prove x < b.
+func TestDFCommitmentPrivateBounded(t *testing.T) {
+	receiver, err := NewReceiver(128, 80)
+	if err != nil {
+		t.Errorf("error in NewReceiver: %v", err)
+	}
+	// n^2 is used for T - but any other value can be used as well
+	T := new(big.Int).Mul(receiver.QRSpecialRSA.N, receiver.QRSpecialRSA.N)
+	committerX, receiverX, err := getCommitterAndReceiver(receiver, T, big.NewInt(3))
+	if err != nil {
+		t.Errorf("error in computing commit msg: %v", err)
+	}
+	committerB, receiverB, err := getCommitterAndReceiver(receiver, T, big.NewInt(7))
+	if err != nil {
+		t.Errorf("error in computing commit msg: %v", err)
+	}
+
+	challengeSpaceSize := 80
+	prover, err := NewPrivateBoundedProver(committerX, committerB, challengeSpaceSize)
+	if err != nil {
+		t.Errorf("error in instantiating PrivateBoundedProver: %v", err)
+	}
+	verifier, err := NewPrivateBoundedVerifier(receiverX, receiverB, T, challengeSpaceSize)
+	if err != nil {
+		t.Errorf("error in instantiating PrivateBoundedVerifier: %v", err)
+	}
+	err = verifier.SetProofRandomData(prover.GetProofRandomData())
+	if err != nil {
+		t.Errorf("error when calling SetProofRandomData: %v", err)
+	}
+	challenges, proofData := prover.GetProofData(verifier.GetChallenge())
+	assert.Equal(t, true, verifier.Verify(challenges, proofData),
+		"DamgardFujisaki private bounded proof failed.")
+
+	// the same proof is not accepted when the commitments are swapped (7 < 3)
+	verifier, err = NewPrivateBoundedVerifier(receiverB, receiverX, T, challengeSpaceSize)
+	if err != nil {
+		t.Errorf("error in instantiating PrivateBoundedVerifier: %v", err)
+	}
+	err = verifier.SetProofRandomData(prover.GetProofRandomData())
+	if err != nil {
+		t.Errorf("error when calling SetProofRandomData: %v", err)
+	}
+	challenges, proofData = prover.GetProofData(verifier.GetChallenge())
+	assert.Equal(t, false, verifier.Verify(challenges, proofData),
+		"DamgardFujisaki private bounded proof should fail for swapped commitments")
+}
+
+func TestDFCommitmentPrivateBoundedFails(t *testing.T) {
+	receiver, err := NewReceiver(128, 80)
+	if err != nil {
+		t.Errorf("error in NewReceiver: %v", err)
+	}
+	T := new(big.Int).Mul(receiver.QRSpecialRSA.N, receiver.QRSpecialRSA.N)
+	tests := []struct {
+		x, b int64
+	}{
+		{7, 3},
+		{7, 7}, // the bound is strict
+	}
+	for _, test := range tests {
+		committerX, _, err := getCommitterAndReceiver(receiver, T, big.NewInt(test.x))
+		if err != nil {
+			t.Errorf("error in computing commit msg: %v", err)
+		}
+		committerB, _, err := getCommitterAndReceiver(receiver, T, big.NewInt(test.b))
+		if err != nil {
+			t.Errorf("error in computing commit msg: %v", err)
+		}
+		_, err = NewPrivateBoundedProver(committerX, committerB, 80)
+		assert.NotNil(t, err, "PrivateBoundedProver should fail when x >= b")
+	}
+}
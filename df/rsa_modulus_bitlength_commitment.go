@@ -0,0 +1,78 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package df
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// RSAModulusBitLengthProver proves that the commitment c = g^n * h^r hides an RSA modulus n
+// of bit length k, that is 2^(k-1) <= n < 2^k. The proof is a RangeProof for the interval
+// [2^(k-1), 2^k - 1].
+type RSAModulusBitLengthProver struct {
+	*RangeProver
+}
+
+func NewRSAModulusBitLengthProver(committer *Committer, n *big.Int, k int,
+	challengeSpaceSize int) (*RSAModulusBitLengthProver, error) {
+	if n.Sign() <= 0 || n.BitLen() != k {
+		return nil, fmt.Errorf("modulus is not of bit length %d", k)
+	}
+	a, b := getBitLengthBounds(k)
+	prover, err := NewRangeProver(committer, n, a, b, challengeSpaceSize)
+	if err != nil {
+		return nil, err
+	}
+	return &RSAModulusBitLengthProver{
+		RangeProver: prover,
+	}, nil
+}
+
+// getBitLengthBounds returns 2^(k-1) and 2^k - 1, the smallest and the largest integer
+// of bit length k.
+func getBitLengthBounds(k int) (*big.Int, *big.Int) {
+	a := new(big.Int).Lsh(big.NewInt(1), uint(k-1))
+	b := new(big.Int).Lsh(big.NewInt(1), uint(k))
+	b.Sub(b, big.NewInt(1))
+	return a, b
+}
+
+type RSAModulusBitLengthVerifier struct {
+	*RangeVerifier
+}
+
+// NewRSAModulusBitLengthVerifier returns RSAModulusBitLengthVerifier for the commitment
+// stored in receiver. The commitments smallCommitments1, bigCommitments1, smallCommitments2
+// and bigCommitments2 are obtained from RSAModulusBitLengthProver.GetVerifierInitializationData.
+func NewRSAModulusBitLengthVerifier(receiver *Receiver, k int,
+	smallCommitments1, bigCommitments1, smallCommitments2, bigCommitments2 []*big.Int,
+	challengeSpaceSize int) (*RSAModulusBitLengthVerifier, error) {
+	if k < 1 {
+		return nil, fmt.Errorf("bit length needs to be positive")
+	}
+	a, b := getBitLengthBounds(k)
+	verifier, err := NewRangeVerifier(receiver, a, b, smallCommitments1, bigCommitments1,
+		smallCommitments2, bigCommitments2, challengeSpaceSize)
+	if err != nil {
+		return nil, err
+	}
+	return &RSAModulusBitLengthVerifier{
+		RangeVerifier: verifier,
+	}, nil
+}
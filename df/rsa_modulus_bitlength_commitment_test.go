@@ -0,0 +1,91 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package df
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func proveRSAModulusBitLength(receiver *Receiver, committer *Committer, n *big.Int,
+	proverK, verifierK int) (bool, error) {
+	challengeSpaceSize := 80
+	prover, err := NewRSAModulusBitLengthProver(committer, n, proverK, challengeSpaceSize)
+	if err != nil {
+		return false, err
+	}
+	smallCommitments1, bigCommitments1, smallCommitments2, bigCommitments2 :=
+		prover.GetVerifierInitializationData()
+	verifier, err := NewRSAModulusBitLengthVerifier(receiver, verifierK, smallCommitments1,
+		bigCommitments1, smallCommitments2, bigCommitments2, challengeSpaceSize)
+	if err != nil {
+		return false, err
+	}
+
+	proofRandomData1, proofRandomData2 := prover.GetProofRandomData()
+	challenges1, challenges2 := verifier.GetChallenges()
+	err = verifier.SetProofRandomData(proofRandomData1, proofRandomData2)
+	if err != nil {
+		return false, err
+	}
+	proofData1, proofData2, err := prover.GetProofData(challenges1, challenges2)
+	if err != nil {
+		return false, err
+	}
+	return verifier.Verify(proofData1, proofData2)
+}
+
+// TestDFCommitmentRSAModulusBitLength demonstrates how to prove that the commitment
+// hides an RSA modulus of the given bit length.
+func TestDFCommitmentRSAModulusBitLength(t *testing.T) {
+	receiver, err := NewReceiver(128, 80)
+	if err != nil {
+		t.Errorf("error in NewReceiver: %v", err)
+	}
+	T := new(big.Int).Lsh(big.NewInt(1), 1025)
+	committer := NewCommitter(receiver.QRSpecialRSA.N,
+		receiver.G, receiver.H, T, receiver.K)
+
+	key, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Errorf("error when generating RSA key: %v", err)
+	}
+	n := key.N
+	c, err := committer.GetCommitMsg(n)
+	if err != nil {
+		t.Errorf("error in computing commit msg: %v", err)
+	}
+	receiver.SetCommitment(c)
+
+	proved, err := proveRSAModulusBitLength(receiver, committer, n, 1024, 1024)
+	if err != nil {
+		t.Errorf("error in RSA modulus bit length proof: %v", err)
+	}
+	assert.Equal(t, true, proved, "DamgardFujisaki RSA modulus bit length proof failed.")
+
+	_, err = NewRSAModulusBitLengthProver(committer, n, 1023, 80)
+	assert.NotNil(t, err, "RSAModulusBitLengthProver should fail for wrong bit length")
+
+	proved, _ = proveRSAModulusBitLength(receiver, committer, n, 1024, 1023)
+	assert.Equal(t, false, proved,
+		"DamgardFujisaki RSA modulus bit length proof should fail for mismatched bit length")
+}
@@ -0,0 +1,150 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package df
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math/big"
+
+	"github.com/awsong/crypto/common"
+	"github.com/awsong/crypto/qr"
+)
+
+// seedReader is a deterministic io.Reader which outputs the blocks
+// SHA-256(seed || counter) for counter = 0, 1, 2, ...
+type seedReader struct {
+	seed    []byte
+	counter uint64
+	buf     []byte
+}
+
+func newSeedReader(seed []byte) *seedReader {
+	return &seedReader{
+		seed: seed,
+	}
+}
+
+func (r *seedReader) Read(p []byte) (int, error) {
+	n := 0
+	for n < len(p) {
+		if len(r.buf) == 0 {
+			counter := make([]byte, 8)
+			binary.BigEndian.PutUint64(counter, r.counter)
+			block := sha256.Sum256(append(append([]byte{}, r.seed...), counter...))
+			r.buf = block[:]
+			r.counter++
+		}
+		copied := copy(p[n:], r.buf)
+		r.buf = r.buf[copied:]
+		n += copied
+	}
+	return n, nil
+}
+
+// getParamsFromSeed deterministically derives safe primes P = 2*p1 + 1, Q = 2*q1 + 1
+// of the given bit length, a generator h of QR_N and g = h^alpha.
+func getParamsFromSeed(seed []byte, safePrimeBitLength int) (*qr.RSASpecialPrimes, *big.Int,
+	*big.Int, error) {
+	if len(seed) == 0 {
+		return nil, nil, nil, fmt.Errorf("seed cannot be empty")
+	}
+	reader := newSeedReader(seed)
+	p, err := common.GenerateSafePrimeFromReader(safePrimeBitLength, reader)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	q := p
+	for q.Cmp(p) == 0 {
+		q, err = common.GenerateSafePrimeFromReader(safePrimeBitLength, reader)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+	}
+	p1 := new(big.Int).Rsh(p, 1)
+	q1 := new(big.Int).Rsh(q, 1)
+	primes := qr.NewRSASpecialPrimes(p, q, p1, q1)
+	group, err := qr.NewRSASpecialFromParams(primes)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	// h is a square of a random element of Z_N* which is of order p1 * q1 (see
+	// RSASpecial.GetRandomGenerator)
+	var h *big.Int
+	for {
+		a, err := readIntFromSeed(reader, group.N)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		if new(big.Int).GCD(nil, nil, a, group.N).Cmp(big.NewInt(1)) != 0 {
+			continue
+		}
+		h = group.Exp(a, big.NewInt(2))
+		if group.Exp(h, p1).Cmp(big.NewInt(1)) != 0 &&
+			group.Exp(h, q1).Cmp(big.NewInt(1)) != 0 {
+			break
+		}
+	}
+	alpha, err := readIntFromSeed(reader, group.Order)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	g := group.Exp(h, alpha)
+	return primes, g, h, nil
+}
+
+// readIntFromSeed returns an integer from [0, max) read from the reader.
+func readIntFromSeed(reader io.Reader, max *big.Int) (*big.Int, error) {
+	// additional 64 bits make the bias of the modular reduction negligible
+	b := make([]byte, (max.BitLen()+64+7)/8)
+	if _, err := io.ReadFull(reader, b); err != nil {
+		return nil, err
+	}
+	return new(big.Int).Mod(new(big.Int).SetBytes(b), max), nil
+}
+
+// NewReceiverFromSeed is FOR TESTING ONLY and must never be used in production.
+// It works as NewReceiver, but the safe primes and generators are derived
+// deterministically from the seed, thus anyone who knows the seed knows the factorization
+// of N and log_h(g), which breaks the binding property of the commitments.
+// It is useful for reproducible tests - the same seed always gives the same parameters.
+func NewReceiverFromSeed(seed []byte, safePrimeBitLength, k int) (*Receiver, error) {
+	primes, g, h, err := getParamsFromSeed(seed, safePrimeBitLength)
+	if err != nil {
+		return nil, err
+	}
+	return NewReceiverFromParams(primes, g, h, k)
+}
+
+// NewCommitterFromSeed is FOR TESTING ONLY and must never be used in production.
+// It returns a Committer with the parameters derived deterministically from the seed
+// (the same ones as in NewReceiverFromSeed for the same seed and safePrimeBitLength).
+// Anyone who knows the seed knows the factorization of N and log_h(g) and can open
+// the commitments to arbitrary values.
+func NewCommitterFromSeed(seed []byte, safePrimeBitLength int, T *big.Int,
+	k int) (*Committer, error) {
+	primes, g, h, err := getParamsFromSeed(seed, safePrimeBitLength)
+	if err != nil {
+		return nil, err
+	}
+	n := new(big.Int).Mul(primes.P, primes.Q)
+	return NewCommitter(n, g, h, T, k), nil
+}
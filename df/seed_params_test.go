@@ -0,0 +1,77 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package df
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/awsong/crypto/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDFCommitmentFromSeed(t *testing.T) {
+	seed := []byte("df test seed")
+	receiver1, err := NewReceiverFromSeed(seed, 128, 80)
+	if err != nil {
+		t.Errorf("error in NewReceiverFromSeed: %v", err)
+	}
+	receiver2, err := NewReceiverFromSeed(seed, 128, 80)
+	if err != nil {
+		t.Errorf("error in NewReceiverFromSeed: %v", err)
+	}
+	assert.Equal(t, receiver1.QRSpecialRSA.N, receiver2.QRSpecialRSA.N,
+		"the same seed should give the same N")
+	assert.Equal(t, receiver1.G, receiver2.G, "the same seed should give the same G")
+	assert.Equal(t, receiver1.H, receiver2.H, "the same seed should give the same H")
+	assert.Equal(t, 128, receiver1.QRSpecialRSA.P.BitLen(), "P is not of the given bit length")
+
+	other, err := NewReceiverFromSeed([]byte("other seed"), 128, 80)
+	if err != nil {
+		t.Errorf("error in NewReceiverFromSeed: %v", err)
+	}
+	assert.NotEqual(t, receiver1.QRSpecialRSA.N, other.QRSpecialRSA.N,
+		"different seeds should give different N")
+
+	// n^2 is used for T - but any other value can be used as well
+	T := new(big.Int).Mul(receiver1.QRSpecialRSA.N, receiver1.QRSpecialRSA.N)
+	committer, err := NewCommitterFromSeed(seed, 128, T, 80)
+	if err != nil {
+		t.Errorf("error in NewCommitterFromSeed: %v", err)
+	}
+	assert.Equal(t, receiver1.ParamsFingerprint(), committer.ParamsFingerprint(),
+		"committer and receiver from the same seed should have the same parameters")
+
+	x := common.GetRandomInt(committer.T)
+	c, err := committer.GetCommitMsg(x)
+	if err != nil {
+		t.Errorf("error in computing commit msg: %v", err)
+	}
+	receiver1.SetCommitment(c)
+
+	challengeSpaceSize := 80
+	prover := NewOpeningProver(committer, challengeSpaceSize)
+	verifier := NewOpeningVerifier(receiver1, challengeSpaceSize)
+	verifier.SetProofRandomData(prover.GetProofRandomData())
+	challenge := verifier.GetChallenge()
+	proved := verifier.Verify(prover.GetProofData(challenge))
+	assert.Equal(t, true, proved, "DamgardFujisaki opening proof failed.")
+
+	_, err = NewCommitterFromSeed(nil, 128, T, 80)
+	assert.NotNil(t, err, "empty seed should not be accepted")
+}
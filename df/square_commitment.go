@@ -25,6 +25,17 @@ import (
 
 // SquareProver proves that the commitment hides the square. Given c,
 // prove that c = g^(x^2) * h^r (mod n).
+// Prover commits to x as SmallCommitment = g^x * h^r1 and proves that SmallCommitment
+// and c, seen as a commitment with base SmallCommitment (c = SmallCommitment^x * h^r2
+// where r2 = r - r1*x), hide the same value x. This is done using EqualityProver,
+// the protocol is a three-message sigma protocol:
+// (1) prover sends GetProofRandomData() to the verifier (together with SmallCommitment
+// which is needed to instantiate SquareVerifier),
+// (2) verifier responds with GetChallenge() (or the challenge is computed with
+// Fiat-Shamir and set using SetChallenge),
+// (3) prover sends GetProofData(challenge) and verifier checks it using Verify.
+// SquareProver can be used on its own, for example to prove that the committed value
+// is a perfect square, or as a building block (see PositiveProver).
 type SquareProver struct {
 	*EqualityProver
 	// We have two commitments with the same value: SmallCommitment = g^x * h^r1 and
@@ -32,6 +43,8 @@ type SquareProver struct {
 	SmallCommitment *big.Int
 }
 
+// NewSquareProver returns SquareProver for the committer which holds a commitment
+// to x^2.
 func NewSquareProver(committer *Committer,
 	x *big.Int, challengeSpaceSize int) (*SquareProver, error) {
 
@@ -72,6 +85,8 @@ func NewSquareProver(committer *Committer,
 	}, nil
 }
 
+// SquareVerifier verifies that the commitment stored in the receiver hides the square
+// of the value hidden in c1 (SmallCommitment of SquareProver).
 type SquareVerifier struct {
 	*EqualityVerifier
 }
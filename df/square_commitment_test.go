@@ -66,3 +66,42 @@ func TestDFCommitmentSquare(t *testing.T) {
 
 	assert.Equal(t, true, proved, "DamgardFujisaki square proof failed.")
 }
+
+// TestDFCommitmentSquareFails checks that the square proof fails when the commitment
+// does not hide the square of x.
+func TestDFCommitmentSquareFails(t *testing.T) {
+	receiver, err := NewReceiver(128, 80)
+	if err != nil {
+		t.Errorf("error in NewReceiver: %v", err)
+	}
+
+	// n^2 is used for T - but any other value can be used as well
+	T := new(big.Int).Mul(receiver.QRSpecialRSA.N, receiver.QRSpecialRSA.N)
+	committer := NewCommitter(receiver.QRSpecialRSA.N,
+		receiver.G, receiver.H, T, receiver.K)
+
+	x := common.GetRandomInt(committer.QRSpecialRSA.N)
+	notSquare := new(big.Int).Mul(x, x)
+	notSquare.Add(notSquare, big.NewInt(1))
+	c, err := committer.GetCommitMsg(notSquare)
+	if err != nil {
+		t.Errorf("error in computing commit msg: %v", err)
+	}
+	receiver.SetCommitment(c)
+
+	challengeSpaceSize := 80
+	prover, err := NewSquareProver(committer, x, challengeSpaceSize)
+	if err != nil {
+		t.Errorf("error in instantiating SquareProver: %v", err)
+	}
+	verifier, err := NewSquareVerifier(receiver, prover.SmallCommitment, challengeSpaceSize)
+	if err != nil {
+		t.Errorf("error in instantiating SquareVerifier: %v", err)
+	}
+
+	proofRandomData1, proofRandomData2 := prover.GetProofRandomData()
+	verifier.SetProofRandomData(proofRandomData1, proofRandomData2)
+	challenge := verifier.GetChallenge()
+	proved := verifier.Verify(prover.GetProofData(challenge))
+	assert.Equal(t, false, proved, "DamgardFujisaki square proof should fail.")
+}
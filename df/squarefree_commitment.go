@@ -0,0 +1,279 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package df
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/awsong/crypto/common"
+)
+
+// maxSquarefreeBoundBitLength limits the bound L of SquarefreeProver, as the proof
+// contains a sub-proof for each prime smaller than sqrt(L).
+const maxSquarefreeBoundBitLength = 40
+
+// SquarefreeProver proves that the value n committed in c = g^n * h^r is squarefree, given
+// a public bound L such that 1 <= n <= L. If p^2 divides n <= L, then p <= sqrt(L),
+// thus it suffices to prove that n is not divisible by p^2 for all primes p <= sqrt(L)
+// (trial division). For each such prime p_i prover commits to the quotient and the
+// remainder of n divided by p_i^2: c_qi = g^qi * h^rqi, c_si = g^si * h^rsi, and proves:
+// (1) the knowledge of qi, rqi and rhoi such that c_qi = g^qi * h^rqi and
+// c * c_qi^(-p_i^2) * c_si^(-1) = h^rhoi, that is n = qi * p_i^2 + si,
+// (2) that 1 <= si <= p_i^2 - 1 (using CompoundPredicateProver).
+// Prover also proves that 1 <= n <= L. All sub-proofs use the same challenge.
+// Note that for values bigger than 2^maxSquarefreeBoundBitLength a proof of primality
+// of the committed factors (as by Camenisch and Michels) would be needed instead.
+type SquarefreeProver struct {
+	statement        *linRep
+	rangeProver      *CompoundPredicateProver
+	remainderProvers []*CompoundPredicateProver
+	commitments      []*big.Int // c_q1, c_s1, c_q2, c_s2, ...
+}
+
+// NewSquarefreeProver returns SquarefreeProver for the value committed in committer.
+// It returns an error if the value is not in [1, bound] or if it is not squarefree.
+func NewSquarefreeProver(committer *Committer, bound *big.Int,
+	challengeSpaceSize int) (*SquarefreeProver, error) {
+	primes, err := getSquarefreePrimes(bound)
+	if err != nil {
+		return nil, err
+	}
+	n, r := committer.GetDecommitMsg()
+	rangeProver, err := NewCompoundPredicateProver(committer, getSquarefreeRangePredicate(bound),
+		challengeSpaceSize)
+	if err != nil {
+		return nil, fmt.Errorf("the committed value is not in [1, %v]", bound)
+	}
+
+	remainderProvers := make([]*CompoundPredicateProver, len(primes))
+	commitments := make([]*big.Int, 2*len(primes))
+	witnesses := make([]*big.Int, 3*len(primes))
+	for i, p := range primes {
+		square := new(big.Int).Mul(p, p)
+		q, s := new(big.Int).DivMod(n, square, new(big.Int))
+		if s.Sign() == 0 {
+			return nil, fmt.Errorf("the committed value is divisible by %v", square)
+		}
+		committerQ := NewCommitter(committer.QRSpecialRSA.N, committer.G, committer.H,
+			committer.T, committer.K)
+		cQ, err := committerQ.GetCommitMsg(q)
+		if err != nil {
+			return nil, err
+		}
+		committerS := NewCommitter(committer.QRSpecialRSA.N, committer.G, committer.H,
+			committer.T, committer.K)
+		cS, err := committerS.GetCommitMsg(s)
+		if err != nil {
+			return nil, err
+		}
+		remainderProvers[i], err = NewCompoundPredicateProver(committerS,
+			getSquarefreeRemainderPredicate(square), challengeSpaceSize)
+		if err != nil {
+			return nil, err
+		}
+		commitments[2*i], commitments[2*i+1] = cQ, cS
+
+		// rho = r - p^2 * rq - rs
+		_, rQ := committerQ.GetDecommitMsg()
+		_, rS := committerS.GetDecommitMsg()
+		rho := new(big.Int).Sub(r, new(big.Int).Mul(square, rQ))
+		rho.Sub(rho, rS)
+		witnesses[3*i], witnesses[3*i+1], witnesses[3*i+2] = q, rQ, rho
+	}
+
+	statement := getSquarefreeStatement(&committer.df, committer.ComputeCommit(n, r), primes,
+		commitments, getPredicateRandomnessBound(&committer.df, committer.T, challengeSpaceSize))
+	statement.witnesses = witnesses
+	return &SquarefreeProver{
+		statement:        statement,
+		rangeProver:      rangeProver,
+		remainderProvers: remainderProvers,
+		commitments:      commitments,
+	}, nil
+}
+
+// getSquarefreePrimes returns all primes p such that p^2 <= bound.
+func getSquarefreePrimes(bound *big.Int) ([]*big.Int, error) {
+	if bound.Sign() <= 0 || bound.BitLen() > maxSquarefreeBoundBitLength {
+		return nil, fmt.Errorf("bound needs to be in [1, 2^%d)", maxSquarefreeBoundBitLength)
+	}
+	max := int(new(big.Int).Sqrt(bound).Int64())
+	composite := make([]bool, max+1)
+	var primes []*big.Int
+	for i := 2; i <= max; i++ {
+		if composite[i] {
+			continue
+		}
+		primes = append(primes, big.NewInt(int64(i)))
+		for j := i * i; j <= max; j += i {
+			composite[j] = true
+		}
+	}
+	return primes, nil
+}
+
+func getSquarefreeRangePredicate(bound *big.Int) *Predicate {
+	return NewAndPredicate(NewGePredicate(big.NewInt(1)), NewLePredicate(bound))
+}
+
+func getSquarefreeRemainderPredicate(square *big.Int) *Predicate {
+	return NewAndPredicate(NewGePredicate(big.NewInt(1)),
+		NewLePredicate(new(big.Int).Sub(square, big.NewInt(1))))
+}
+
+// getSquarefreeStatement returns the statement (1) from SquarefreeProver (see linRep).
+func getSquarefreeStatement(d *df, c *big.Int, primes, commitments []*big.Int,
+	bound *big.Int) *linRep {
+	group := d.QRSpecialRSA
+	l := &linRep{
+		group: group,
+		bound: bound,
+	}
+	for i, p := range primes {
+		cQ, cS := commitments[2*i], commitments[2*i+1]
+		y := group.Mul(c, group.Inv(group.Mul(group.Exp(cQ, new(big.Int).Mul(p, p)), cS)))
+		l.y = append(l.y, cQ, y)
+		l.equations = append(l.equations, []linRepTerm{{d.G, 3 * i}, {d.H, 3*i + 1}},
+			[]linRepTerm{{d.H, 3*i + 2}})
+	}
+	return l
+}
+
+// GetVerifierInitializationData returns data that are needed by SquarefreeVerifier
+// and are known only after the initialization of SquarefreeProver: the commitments to
+// the quotients and remainders (c_q1, c_s1, c_q2, c_s2, ...).
+func (p *SquarefreeProver) GetVerifierInitializationData() []*big.Int {
+	return p.commitments
+}
+
+// GetProofRandomData returns the proof random data of the statement (1) and the proof
+// random data of the predicate proofs (the range proof followed by the remainder proofs).
+func (p *SquarefreeProver) GetProofRandomData() ([]*big.Int, [][]*big.Int) {
+	predicateData := [][]*big.Int{p.rangeProver.GetProofRandomData()}
+	for _, prover := range p.remainderProvers {
+		predicateData = append(predicateData, prover.GetProofRandomData())
+	}
+	return p.statement.getProofRandomData(), predicateData
+}
+
+// GetProofData returns the proof data of the statement (1) and the challenges and
+// the proof data of the predicate proofs.
+func (p *SquarefreeProver) GetProofData(challenge *big.Int) ([]*big.Int, [][]*big.Int,
+	[][]*big.Int) {
+	provers := append([]*CompoundPredicateProver{p.rangeProver}, p.remainderProvers...)
+	challenges := make([][]*big.Int, len(provers))
+	proofData := make([][]*big.Int, len(provers))
+	for i, prover := range provers {
+		challenges[i], proofData[i] = prover.GetProofData(challenge)
+	}
+	return p.statement.getProofData(challenge), challenges, proofData
+}
+
+type SquarefreeVerifier struct {
+	statement          *linRep
+	predicateVerifiers []*CompoundPredicateVerifier // range verifier and remainder verifiers
+	challengeSpaceSize int
+	proofRandomData    []*big.Int
+	challenge          *big.Int
+}
+
+// NewSquarefreeVerifier returns SquarefreeVerifier for the commitment stored in receiver.
+// The commitments are obtained from SquarefreeProver.GetVerifierInitializationData and T is
+// the bound for the committed values (as in Committer).
+func NewSquarefreeVerifier(receiver *Receiver, bound *big.Int, commitments []*big.Int,
+	T *big.Int, challengeSpaceSize int) (*SquarefreeVerifier, error) {
+	primes, err := getSquarefreePrimes(bound)
+	if err != nil {
+		return nil, err
+	}
+	if len(commitments) != 2*len(primes) {
+		return nil, fmt.Errorf("the number of commitments is not correct")
+	}
+	rangeVerifier, err := NewCompoundPredicateVerifier(receiver,
+		getSquarefreeRangePredicate(bound), T, challengeSpaceSize)
+	if err != nil {
+		return nil, err
+	}
+	predicateVerifiers := []*CompoundPredicateVerifier{rangeVerifier}
+	for i, p := range primes {
+		receiverS, err := NewReceiverFromParams(receiver.QRSpecialRSA.GetPrimes(), receiver.G,
+			receiver.H, receiver.K)
+		if err != nil {
+			return nil, fmt.Errorf("error when calling NewReceiverFromParams")
+		}
+		receiverS.SetCommitment(commitments[2*i+1])
+		verifier, err := NewCompoundPredicateVerifier(receiverS,
+			getSquarefreeRemainderPredicate(new(big.Int).Mul(p, p)), T, challengeSpaceSize)
+		if err != nil {
+			return nil, err
+		}
+		predicateVerifiers = append(predicateVerifiers, verifier)
+	}
+
+	return &SquarefreeVerifier{
+		statement: getSquarefreeStatement(&receiver.df, receiver.Commitment, primes,
+			commitments, getPredicateRandomnessBound(&receiver.df, T, challengeSpaceSize)),
+		predicateVerifiers: predicateVerifiers,
+		challengeSpaceSize: challengeSpaceSize,
+	}, nil
+}
+
+func (v *SquarefreeVerifier) SetProofRandomData(proofRandomData []*big.Int,
+	predicateData [][]*big.Int) error {
+	if len(proofRandomData) != len(v.statement.equations) ||
+		len(predicateData) != len(v.predicateVerifiers) {
+		return fmt.Errorf("the length of proofRandomData is not correct")
+	}
+	for i, verifier := range v.predicateVerifiers {
+		if err := verifier.SetProofRandomData(predicateData[i]); err != nil {
+			return err
+		}
+	}
+	v.proofRandomData = proofRandomData
+	return nil
+}
+
+// GetChallenge returns a challenge which is used in all sub-proofs.
+func (v *SquarefreeVerifier) GetChallenge() *big.Int {
+	b := new(big.Int).Lsh(big.NewInt(1), uint(v.challengeSpaceSize))
+	v.SetChallenge(common.GetRandomInt(b))
+	return v.challenge
+}
+
+// SetChallenge is used when Fiat-Shamir is used - when challenge is generated using hash by the prover.
+func (v *SquarefreeVerifier) SetChallenge(challenge *big.Int) {
+	v.challenge = challenge
+	for _, verifier := range v.predicateVerifiers {
+		verifier.SetChallenge(challenge)
+	}
+}
+
+func (v *SquarefreeVerifier) Verify(proofData []*big.Int, challenges,
+	predicateData [][]*big.Int) bool {
+	if len(challenges) != len(v.predicateVerifiers) ||
+		len(predicateData) != len(v.predicateVerifiers) {
+		return false
+	}
+	for i, verifier := range v.predicateVerifiers {
+		if !verifier.Verify(challenges[i], predicateData[i]) {
+			return false
+		}
+	}
+	return v.statement.verify(v.challenge, v.proofRandomData, proofData)
+}
@@ -0,0 +1,107 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package df
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func proveSquarefree(receiver *Receiver, T, n, bound *big.Int) (bool, error) {
+	committer, r, err := getCommitterAndReceiver(receiver, T, n)
+	if err != nil {
+		return false, err
+	}
+	prover, err := NewSquarefreeProver(committer, bound, 80)
+	if err != nil {
+		return false, err
+	}
+	verifier, err := NewSquarefreeVerifier(r, bound, prover.GetVerifierInitializationData(),
+		T, 80)
+	if err != nil {
+		return false, err
+	}
+	if err := verifier.SetProofRandomData(prover.GetProofRandomData()); err != nil {
+		return false, err
+	}
+	return verifier.Verify(prover.GetProofData(verifier.GetChallenge())), nil
+}
+
+// TestDFCommitmentSquarefree demonstrates how to prove that the committed value
+// is squarefree.
+func TestDFCommitmentSquarefree(t *testing.T) {
+	receiver, err := NewReceiver(128, 80)
+	if err != nil {
+		t.Errorf("error in NewReceiver: %v", err)
+	}
+	// n^2 is used for T - but any other value can be used as well
+	T := new(big.Int).Mul(receiver.QRSpecialRSA.N, receiver.QRSpecialRSA.N)
+	bound := big.NewInt(1000)
+
+	for _, n := range []int64{1, 2, 30, 997, 998} { // 998 = 2 * 499
+		proved, err := proveSquarefree(receiver, T, big.NewInt(n), bound)
+		if err != nil {
+			t.Errorf("error in squarefree proof: %v", err)
+		}
+		assert.Equal(t, true, proved, "DamgardFujisaki squarefree proof failed.")
+	}
+
+	for _, n := range []int64{0, 12, 961, 1001} { // 961 = 31^2, 1001 is bigger than bound
+		_, err := proveSquarefree(receiver, T, big.NewInt(n), bound)
+		assert.NotNil(t, err, "SquarefreeProver should fail for %d", n)
+	}
+
+	_, err = getSquarefreePrimes(new(big.Int).Lsh(big.NewInt(1), maxSquarefreeBoundBitLength))
+	assert.NotNil(t, err, "too big bound should not be accepted")
+}
+
+func TestDFCommitmentSquarefreeWrongCommitment(t *testing.T) {
+	receiver, err := NewReceiver(128, 80)
+	if err != nil {
+		t.Errorf("error in NewReceiver: %v", err)
+	}
+	T := new(big.Int).Mul(receiver.QRSpecialRSA.N, receiver.QRSpecialRSA.N)
+	bound := big.NewInt(100)
+
+	committer, _, err := getCommitterAndReceiver(receiver, T, big.NewInt(35))
+	if err != nil {
+		t.Errorf("error in computing commit msg: %v", err)
+	}
+	prover, err := NewSquarefreeProver(committer, bound, 80)
+	if err != nil {
+		t.Errorf("error in instantiating SquarefreeProver: %v", err)
+	}
+	// the proof for 35 is not accepted for a commitment to 36
+	_, r, err := getCommitterAndReceiver(receiver, T, big.NewInt(36))
+	if err != nil {
+		t.Errorf("error in computing commit msg: %v", err)
+	}
+	verifier, err := NewSquarefreeVerifier(r, bound, prover.GetVerifierInitializationData(),
+		T, 80)
+	if err != nil {
+		t.Errorf("error in instantiating SquarefreeVerifier: %v", err)
+	}
+	if err := verifier.SetProofRandomData(prover.GetProofRandomData()); err != nil {
+		t.Errorf("error when calling SetProofRandomData: %v", err)
+	}
+	proved := verifier.Verify(prover.GetProofData(verifier.GetChallenge()))
+	assert.Equal(t, false, proved,
+		"DamgardFujisaki squarefree proof should fail for a different commitment")
+}
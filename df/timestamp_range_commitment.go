@@ -0,0 +1,82 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package df
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// TimestampRangeProver proves that the commitment c = g^t * h^r hides a Unix timestamp t
+// from the time window [past, future]. It uses CompoundPredicateProver for the predicate
+// t >= past AND t <= future.
+type TimestampRangeProver struct {
+	*CompoundPredicateProver
+}
+
+// NewTimestampRangeProver returns TimestampRangeProver. It returns an error if
+// past >= future, if (t, r) is not the opening of the commitment in committer, or
+// if t is not in [past, future].
+func NewTimestampRangeProver(committer *Committer, t, r *big.Int, past, future int64,
+	challengeSpaceSize int) (*TimestampRangeProver, error) {
+	pred, err := getTimestampPredicate(past, future)
+	if err != nil {
+		return nil, err
+	}
+	committedValue, committedR := committer.GetDecommitMsg()
+	if committedValue == nil || t.Cmp(committedValue) != 0 || r.Cmp(committedR) != 0 {
+		return nil, fmt.Errorf("timestamp and randomness are not the opening of the commitment")
+	}
+	if !pred.Holds(t) {
+		return nil, fmt.Errorf("timestamp is not in [%d, %d]", past, future)
+	}
+	prover, err := NewCompoundPredicateProver(committer, pred, challengeSpaceSize)
+	if err != nil {
+		return nil, err
+	}
+	return &TimestampRangeProver{prover}, nil
+}
+
+// getTimestampPredicate returns the predicate t >= past AND t <= future. It returns
+// an error if past >= future.
+func getTimestampPredicate(past, future int64) (*Predicate, error) {
+	if past >= future {
+		return nil, fmt.Errorf("past needs to be smaller than future")
+	}
+	return NewAndPredicate(NewGePredicate(big.NewInt(past)),
+		NewLePredicate(big.NewInt(future))), nil
+}
+
+type TimestampRangeVerifier struct {
+	*CompoundPredicateVerifier
+}
+
+// NewTimestampRangeVerifier returns TimestampRangeVerifier for the commitment stored
+// in receiver. T is the bound for the committed values (as in Committer).
+func NewTimestampRangeVerifier(receiver *Receiver, past, future int64, T *big.Int,
+	challengeSpaceSize int) (*TimestampRangeVerifier, error) {
+	pred, err := getTimestampPredicate(past, future)
+	if err != nil {
+		return nil, err
+	}
+	verifier, err := NewCompoundPredicateVerifier(receiver, pred, T, challengeSpaceSize)
+	if err != nil {
+		return nil, err
+	}
+	return &TimestampRangeVerifier{verifier}, nil
+}
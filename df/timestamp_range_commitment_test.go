@@ -0,0 +1,86 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package df
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func proveTimestampRange(receiver *Receiver, committer *Committer, t, r *big.Int,
+	past, future int64, T *big.Int) (bool, error) {
+	challengeSpaceSize := 80
+	prover, err := NewTimestampRangeProver(committer, t, r, past, future, challengeSpaceSize)
+	if err != nil {
+		return false, err
+	}
+	verifier, err := NewTimestampRangeVerifier(receiver, past, future, T, challengeSpaceSize)
+	if err != nil {
+		return false, err
+	}
+
+	if err := verifier.SetProofRandomData(prover.GetProofRandomData()); err != nil {
+		return false, err
+	}
+	challenges, proofData := prover.GetProofData(verifier.GetChallenge())
+	return verifier.Verify(challenges, proofData), nil
+}
+
+// TestDFCommitmentTimestampRange demonstrates how to prove that the commitment hides
+// a Unix timestamp from the given time window.
+func TestDFCommitmentTimestampRange(t *testing.T) {
+	receiver, err := NewReceiver(128, 80)
+	if err != nil {
+		t.Errorf("error in NewReceiver: %v", err)
+	}
+	T := new(big.Int).Lsh(big.NewInt(1), 64)
+
+	past, future := int64(1500000000), int64(1600000000)
+	for _, ts := range []int64{past, 1550000000, future} {
+		timestamp := big.NewInt(ts)
+		committer, r, err := getCommitterAndReceiver(receiver, T, timestamp)
+		if err != nil {
+			t.Errorf("error when committing: %v", err)
+		}
+		_, rand := committer.GetDecommitMsg()
+		proved, err := proveTimestampRange(r, committer, timestamp, rand, past, future, T)
+		if err != nil {
+			t.Errorf("error in timestamp range proof: %v", err)
+		}
+		assert.Equal(t, true, proved, "DamgardFujisaki timestamp range proof failed for %d.", ts)
+	}
+
+	timestamp := big.NewInt(1550000000)
+	committer, _, err := getCommitterAndReceiver(receiver, T, timestamp)
+	if err != nil {
+		t.Errorf("error when committing: %v", err)
+	}
+	_, r := committer.GetDecommitMsg()
+
+	_, err = NewTimestampRangeProver(committer, timestamp, r, future, past, 80)
+	assert.NotNil(t, err, "TimestampRangeProver should fail when past >= future")
+
+	_, err = NewTimestampRangeProver(committer, timestamp, r, 1560000000, future, 80)
+	assert.NotNil(t, err, "TimestampRangeProver should fail for timestamp outside the window")
+
+	_, err = NewTimestampRangeProver(committer, timestamp, new(big.Int).Add(r, big.NewInt(1)),
+		past, future, 80)
+	assert.NotNil(t, err, "TimestampRangeProver should fail for wrong randomness")
+}
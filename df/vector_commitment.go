@@ -0,0 +1,125 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package df
+
+import (
+	"crypto/sha512"
+	"encoding/binary"
+	"fmt"
+	"math/big"
+
+	"github.com/awsong/crypto/common"
+	"github.com/awsong/crypto/qr"
+)
+
+// VectorCommitter commits to a vector of values (x_1, ..., x_k) with a single
+// commitment C = g_1^x_1 * ... * g_k^x_k * h^r (mod n) where g_1, ..., g_k are
+// generators with no known discrete logarithm relations (see GetVectorGenerators).
+// Only one opening randomness r is needed for the whole vector.
+type VectorCommitter struct {
+	QRSpecialRSA *qr.RSASpecial
+	Generators   []*big.Int
+	H            *big.Int
+	B            int      // 2^B is upper bound estimation for group order, it can be len(RSASpecial.N) - 2
+	T            *big.Int // we can commit to values between -T and T
+	K            int      // security parameter
+}
+
+func NewVectorCommitter(n *big.Int, generators []*big.Int, h, t *big.Int,
+	k int) *VectorCommitter {
+	// n.BitLen() - 2 is used as B
+	return &VectorCommitter{
+		QRSpecialRSA: qr.NewRSApecialPublic(n),
+		Generators:   generators,
+		H:            h,
+		B:            n.BitLen() - 2,
+		T:            t,
+		K:            k,
+	}
+}
+
+// ComputeCommit returns g_1^x_1 * ... * g_k^x_k * h^r (mod n) for given values and r.
+func (c *VectorCommitter) ComputeCommit(values []*big.Int, r *big.Int) (*big.Int, error) {
+	if len(values) != len(c.Generators) {
+		return nil, fmt.Errorf("number of values and generators should be the same")
+	}
+	commitment := c.QRSpecialRSA.Exp(c.H, r)
+	for i, val := range values {
+		abs := new(big.Int).Abs(val)
+		if abs.Cmp(c.T) != -1 {
+			return nil, fmt.Errorf("committed values need to be in (-T, T)")
+		}
+		t := c.QRSpecialRSA.Exp(c.Generators[i], val)
+		commitment = c.QRSpecialRSA.Mul(commitment, t)
+	}
+	return commitment, nil
+}
+
+// Commit returns a commitment to values together with the randomness r that
+// was used (r is chosen from [0, 2^(B + k))).
+func (c *VectorCommitter) Commit(values []*big.Int) (*big.Int, *big.Int, error) {
+	boundary := new(big.Int).Exp(big.NewInt(2), big.NewInt(int64(c.B+c.K)), nil)
+	r := common.GetRandomInt(boundary)
+	commitment, err := c.ComputeCommit(values, r)
+	if err != nil {
+		return nil, nil, err
+	}
+	return commitment, r, nil
+}
+
+// Open returns true if commitment is a commitment to values with randomness r.
+func (c *VectorCommitter) Open(commitment *big.Int, values []*big.Int, r *big.Int) bool {
+	check, err := c.ComputeCommit(values, r)
+	if err != nil {
+		return false
+	}
+	return common.ConstantTimeEq(check, commitment)
+}
+
+// GetVectorGenerators deterministically derives k elements of QR_n from the given seed.
+// Each generator is computed as g_i = hash(seed, i)^2 (mod n) where hash is expanded
+// (using SHA-512) to be longer than n. Because generators are derived from a hash,
+// nobody (not even the one who knows the factorization of n) knows discrete logarithm
+// relations among them. Anybody can check that the generators were properly chosen by
+// calling GetVectorGenerators with the same seed.
+func GetVectorGenerators(n *big.Int, seed []byte, k int) []*big.Int {
+	generators := make([]*big.Int, 0, k)
+	nBytes := (n.BitLen()+7)/8 + 16 // to make the modular reduction negligibly biased
+	one := big.NewInt(1)
+	for counter := uint32(0); len(generators) < k; counter++ {
+		var expanded []byte
+		for block := uint32(0); len(expanded) < nBytes; block++ {
+			h := sha512.New()
+			h.Write(seed)
+			binary.Write(h, binary.BigEndian, counter)
+			binary.Write(h, binary.BigEndian, block)
+			expanded = h.Sum(expanded)
+		}
+		x := new(big.Int).SetBytes(expanded[:nBytes])
+		x.Mod(x, n)
+		if new(big.Int).GCD(nil, nil, x, n).Cmp(one) != 0 {
+			continue
+		}
+		g := x.Exp(x, big.NewInt(2), n)
+		if g.Cmp(one) == 0 {
+			continue
+		}
+		generators = append(generators, g)
+	}
+	return generators
+}
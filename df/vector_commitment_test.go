@@ -0,0 +1,60 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package df
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/awsong/crypto/common"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestDFVectorCommitment demonstrates how a vector of values can be committed with
+// a single commitment and later opened.
+func TestDFVectorCommitment(t *testing.T) {
+	receiver, err := NewReceiver(128, 80)
+	if err != nil {
+		t.Errorf("Error in NewReceiver: %v", err)
+	}
+	n := receiver.QRSpecialRSA.N
+
+	seed := []byte("df vector commitment test")
+	generators := GetVectorGenerators(n, seed, 3)
+	assert.Equal(t, generators, GetVectorGenerators(n, seed, 3),
+		"generators should be derived deterministically")
+
+	committer := NewVectorCommitter(n, generators, receiver.H, n, receiver.K)
+	values := []*big.Int{
+		common.GetRandomInt(n),
+		common.GetRandomInt(n),
+		common.GetRandomInt(n),
+	}
+	c, r, err := committer.Commit(values)
+	if err != nil {
+		t.Errorf("Error in Commit: %v", err)
+	}
+	assert.Equal(t, true, committer.Open(c, values, r), "DamgardFujisaki vector commitment failed.")
+
+	values[1] = new(big.Int).Add(values[1], big.NewInt(1))
+	assert.Equal(t, false, committer.Open(c, values, r),
+		"DamgardFujisaki vector commitment opened to wrong values.")
+
+	_, _, err = committer.Commit(values[:2])
+	assert.NotNil(t, err, "Commit should fail when the number of values is not correct")
+}
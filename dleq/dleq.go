@@ -0,0 +1,213 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package dleq
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/awsong/crypto/common"
+	"github.com/awsong/crypto/ec"
+	"github.com/awsong/crypto/schnorr"
+)
+
+const (
+	challengeSpaceSize  = 128 // challenge is from [0, 2^challengeSpaceSize)
+	statisticalSecurity = 80  // r hides c * x statistically in z = r + c * x
+)
+
+// Prover proves that it knows x such that y1 = g1^x and y2 = g2^x, where g1 and g2
+// are generators of two different groups. The groups can be of different orders q1 and q2,
+// thus the response z = r + c * x is computed in Z (not modulo q1 or q2), r is chosen
+// from [0, 2^(l + challengeSpaceSize + statisticalSecurity)) where l is the bit length of
+// min(q1, q2) and the verifier checks that z is smaller than
+// 2^(l + challengeSpaceSize + statisticalSecurity + 1). It is required that x < min(q1, q2).
+// Only one challenge is used for the verification equations in both groups.
+//
+// Note that the verifier is only convinced that the prover knows an integer x from
+// [0, 2^(l + challengeSpaceSize + statisticalSecurity + 1)) with y1 = g1^x and y2 = g2^x -
+// not that x < min(q1, q2). A prover can use x >= min(q1, q2) for which x mod q1 and
+// x mod q2 differ. For two groups with 256-bit orders a random pair (y1, y2) with
+// different discrete logarithms can be proved with probability about
+// 2^(256 + 128 + 80 + 1) / 2^512 = 2^-47. When the discrete logarithms need to be the same
+// value from [0, min(q1, q2)), the proof needs to be combined with a range proof for x.
+type Prover struct {
+	group1 group
+	group2 group
+	secret *big.Int
+	y1     Element
+	y2     Element
+	r      *big.Int
+	t1     Element
+	t2     Element
+}
+
+// NewProver returns Prover for y1 = group1.G^secret and y2 = group2.G^secret.
+func NewProver(group1, group2 *schnorr.Group, secret, y1, y2 *big.Int) (*Prover, error) {
+	return newProver(schnorrGroup{group1}, schnorrGroup{group2}, secret,
+		Element{y1}, Element{y2})
+}
+
+// NewECProver returns Prover for y1 = secret * G (G being the base point of the curve)
+// and y2 = group2.G^secret.
+func NewECProver(group1 *ec.Group, group2 *schnorr.Group, secret *big.Int,
+	y1 *ec.GroupElement, y2 *big.Int) (*Prover, error) {
+	return newProver(ecGroup{group1}, schnorrGroup{group2}, secret,
+		Element{y1.X, y1.Y}, Element{y2})
+}
+
+func newProver(group1, group2 group, secret *big.Int, y1, y2 Element) (*Prover, error) {
+	if secret.Sign() < 0 || secret.Cmp(getMinOrder(group1, group2)) >= 0 {
+		return nil, fmt.Errorf("secret needs to be in [0, min(q1, q2))")
+	}
+	return &Prover{
+		group1: group1,
+		group2: group2,
+		secret: secret,
+		y1:     y1,
+		y2:     y2,
+	}, nil
+}
+
+// getMinOrder returns the smaller of the orders of group1 and group2.
+func getMinOrder(group1, group2 group) *big.Int {
+	if group1.order().Cmp(group2.order()) < 0 {
+		return group1.order()
+	}
+	return group2.order()
+}
+
+// getResponseBound returns 2^(l + challengeSpaceSize + statisticalSecurity) where l
+// is the bit length of min(q1, q2).
+func getResponseBound(group1, group2 group) *big.Int {
+	l := getMinOrder(group1, group2).BitLen()
+	return new(big.Int).Lsh(big.NewInt(1), uint(l+challengeSpaceSize+statisticalSecurity))
+}
+
+// GetProofRandomData returns t1 = g1^r and t2 = g2^r.
+func (p *Prover) GetProofRandomData() (Element, Element) {
+	p.r = common.GetRandomInt(getResponseBound(p.group1, p.group2))
+	p.t1 = p.group1.expG(p.r)
+	p.t2 = p.group2.expG(p.r)
+	return p.t1, p.t2
+}
+
+// GetProofData returns z = r + challenge * secret (computed in Z).
+func (p *Prover) GetProofData(challenge *big.Int) *big.Int {
+	z := new(big.Int).Mul(challenge, p.secret)
+	return z.Add(z, p.r)
+}
+
+// Proof presents all three messages in sigma protocol - useful when challenge
+// is generated by prover via Fiat-Shamir.
+type Proof struct {
+	ProofRandomData1 Element
+	ProofRandomData2 Element
+	Challenge        *big.Int
+	ProofData        *big.Int
+}
+
+// Prove returns a non-interactive proof where the challenge is the hash of y1, y2, t1, t2.
+func (p *Prover) Prove() *Proof {
+	t1, t2 := p.GetProofRandomData()
+	challenge := getFiatShamirChallenge(p.y1, p.y2, t1, t2)
+	return &Proof{
+		ProofRandomData1: t1,
+		ProofRandomData2: t2,
+		Challenge:        challenge,
+		ProofData:        p.GetProofData(challenge),
+	}
+}
+
+// getFiatShamirChallenge hashes the elements of both groups together and returns the hash
+// reduced to [0, 2^challengeSpaceSize).
+func getFiatShamirChallenge(y1, y2, t1, t2 Element) *big.Int {
+	var numbers []*big.Int
+	for _, el := range []Element{y1, y2, t1, t2} {
+		numbers = append(numbers, el...)
+	}
+	hash := common.Hash(numbers...)
+	return hash.Mod(hash, new(big.Int).Lsh(big.NewInt(1), challengeSpaceSize))
+}
+
+type Verifier struct {
+	group1    group
+	group2    group
+	y1        Element
+	y2        Element
+	t1        Element
+	t2        Element
+	challenge *big.Int
+}
+
+func NewVerifier(group1, group2 *schnorr.Group, y1, y2 *big.Int) *Verifier {
+	return &Verifier{
+		group1: schnorrGroup{group1},
+		group2: schnorrGroup{group2},
+		y1:     Element{y1},
+		y2:     Element{y2},
+	}
+}
+
+func NewECVerifier(group1 *ec.Group, group2 *schnorr.Group, y1 *ec.GroupElement,
+	y2 *big.Int) *Verifier {
+	return &Verifier{
+		group1: ecGroup{group1},
+		group2: schnorrGroup{group2},
+		y1:     Element{y1.X, y1.Y},
+		y2:     Element{y2},
+	}
+}
+
+func (v *Verifier) SetProofRandomData(t1, t2 Element) {
+	v.t1 = t1
+	v.t2 = t2
+}
+
+func (v *Verifier) GetChallenge() *big.Int {
+	b := new(big.Int).Lsh(big.NewInt(1), challengeSpaceSize)
+	v.challenge = common.GetRandomInt(b)
+	return v.challenge
+}
+
+// SetChallenge is used when Fiat-Shamir is used - when challenge is generated using hash by the prover.
+func (v *Verifier) SetChallenge(challenge *big.Int) {
+	v.challenge = challenge
+}
+
+// Verify checks that g1^z = t1 * y1^c, g2^z = t2 * y2^c and that z is not too big.
+func (v *Verifier) Verify(z *big.Int) bool {
+	bound := new(big.Int).Lsh(getResponseBound(v.group1, v.group2), 1)
+	if z.Sign() < 0 || z.Cmp(bound) >= 0 {
+		return false
+	}
+	return v.group1.check(z, v.challenge, v.t1, v.y1) &&
+		v.group2.check(z, v.challenge, v.t2, v.y2)
+}
+
+// VerifyProof verifies a non-interactive proof obtained from Prover.Prove.
+func (v *Verifier) VerifyProof(proof *Proof) bool {
+	challenge := getFiatShamirChallenge(v.y1, v.y2, proof.ProofRandomData1,
+		proof.ProofRandomData2)
+	if !common.ConstantTimeEq(challenge, proof.Challenge) {
+		return false
+	}
+	v.SetProofRandomData(proof.ProofRandomData1, proof.ProofRandomData2)
+	v.SetChallenge(challenge)
+	return v.Verify(proof.ProofData)
+}
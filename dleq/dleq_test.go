@@ -0,0 +1,133 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package dleq
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/awsong/crypto/common"
+	"github.com/awsong/crypto/ec"
+	"github.com/awsong/crypto/schnorr"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDLEQ(t *testing.T) {
+	group1, err := schnorr.NewGroup(160)
+	if err != nil {
+		t.Errorf("error when creating group: %v", err)
+	}
+	group2, err := schnorr.NewGroup(256)
+	if err != nil {
+		t.Errorf("error when creating group: %v", err)
+	}
+
+	secret := common.GetRandomInt(group1.Q)
+	y1 := group1.Exp(group1.G, secret)
+	y2 := group2.Exp(group2.G, secret)
+
+	prover, err := NewProver(group1, group2, secret, y1, y2)
+	if err != nil {
+		t.Errorf("error when creating Prover: %v", err)
+	}
+	verifier := NewVerifier(group1, group2, y1, y2)
+
+	t1, t2 := prover.GetProofRandomData()
+	verifier.SetProofRandomData(t1, t2)
+	challenge := verifier.GetChallenge()
+	z := prover.GetProofData(challenge)
+	assert.Equal(t, true, verifier.Verify(z), "DLEQ proof failed")
+
+	// y2 with a different discrete logarithm
+	y2Wrong := group2.Exp(group2.G, new(big.Int).Add(secret, big.NewInt(1)))
+	prover, _ = NewProver(group1, group2, secret, y1, y2Wrong)
+	verifier = NewVerifier(group1, group2, y1, y2Wrong)
+	proof := prover.Prove()
+	assert.Equal(t, false, verifier.VerifyProof(proof),
+		"DLEQ proof should fail for different discrete logarithms")
+
+	_, err = NewProver(group1, group2, group2.Q, y1, y2)
+	assert.NotNil(t, err, "secret bigger than the group order should fail")
+}
+
+func TestDLEQEC(t *testing.T) {
+	group1 := ec.NewGroup(ec.P256)
+	group2, err := schnorr.NewGroup(256)
+	if err != nil {
+		t.Errorf("error when creating group: %v", err)
+	}
+
+	secret := common.GetRandomInt(group2.Q)
+	y1 := group1.ExpBaseG(secret)
+	y2 := group2.Exp(group2.G, secret)
+
+	prover, err := NewECProver(group1, group2, secret, y1, y2)
+	if err != nil {
+		t.Errorf("error when creating Prover: %v", err)
+	}
+	verifier := NewECVerifier(group1, group2, y1, y2)
+	proof := prover.Prove()
+	assert.Equal(t, true, verifier.VerifyProof(proof), "DLEQ proof (Fiat-Shamir) failed")
+
+	// tampered response
+	proof.ProofData.Add(proof.ProofData, big.NewInt(1))
+	assert.Equal(t, false, verifier.VerifyProof(proof), "tampered DLEQ proof should fail")
+
+	// y1 with a different discrete logarithm
+	y1Wrong := group1.ExpBaseG(new(big.Int).Add(secret, big.NewInt(1)))
+	prover, _ = NewECProver(group1, group2, secret, y1Wrong, y2)
+	verifier = NewECVerifier(group1, group2, y1Wrong, y2)
+	assert.Equal(t, false, verifier.VerifyProof(prover.Prove()),
+		"DLEQ proof should fail for different discrete logarithms")
+}
+
+// TestDLEQBound checks the bound for x which the verifier is convinced of (see Prover):
+// x >= min(q1, q2) is accepted, while for x = 2^(l + challengeSpaceSize +
+// statisticalSecurity + 1) the response is too big for any nonzero challenge.
+func TestDLEQBound(t *testing.T) {
+	group1, err := schnorr.NewGroup(160)
+	if err != nil {
+		t.Errorf("error when creating group: %v", err)
+	}
+	group2, err := schnorr.NewGroup(256)
+	if err != nil {
+		t.Errorf("error when creating group: %v", err)
+	}
+	g1, g2 := schnorrGroup{group1}, schnorrGroup{group2}
+	bound := getResponseBound(g1, g2)
+	l := getMinOrder(g1, g2).BitLen()
+	assert.Equal(t, l+challengeSpaceSize+statisticalSecurity, bound.BitLen()-1,
+		"wrong response bound")
+
+	prove := func(x *big.Int) bool {
+		y1 := group1.Exp(group1.G, x)
+		y2 := group2.Exp(group2.G, x)
+		prover := &Prover{group1: g1, group2: g2, secret: x, y1: Element{y1},
+			y2: Element{y2}}
+		verifier := NewVerifier(group1, group2, y1, y2)
+		return verifier.VerifyProof(prover.Prove())
+	}
+
+	// x mod q1 != x mod q2, but the proof is accepted
+	x := new(big.Int).Add(group1.Q, big.NewInt(1))
+	assert.Equal(t, true, prove(x), "DLEQ proof should be accepted for x = q1 + 1")
+
+	// the response is not smaller than 2 * bound
+	x = new(big.Int).Lsh(bound, 1)
+	assert.Equal(t, false, prove(x), "DLEQ proof should fail for too big x")
+}
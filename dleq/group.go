@@ -0,0 +1,84 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package dleq
+
+import (
+	"math/big"
+
+	"github.com/awsong/crypto/common"
+	"github.com/awsong/crypto/ec"
+	"github.com/awsong/crypto/schnorr"
+)
+
+// Element represents an element of one of the groups in which discrete logarithm equality
+// is proved. It holds a single integer for schnorr.Group and coordinates (X, Y)
+// for ec.Group.
+type Element []*big.Int
+
+// group is what Prover and Verifier need from each of the two groups.
+type group interface {
+	order() *big.Int
+	// expG returns g^x where g is the generator of the group.
+	expG(x *big.Int) Element
+	// check returns true if g^z = t * y^c.
+	check(z, c *big.Int, t, y Element) bool
+}
+
+type schnorrGroup struct {
+	*schnorr.Group
+}
+
+func (g schnorrGroup) order() *big.Int {
+	return g.Q
+}
+
+func (g schnorrGroup) expG(x *big.Int) Element {
+	return Element{g.Exp(g.G, new(big.Int).Mod(x, g.Q))}
+}
+
+func (g schnorrGroup) check(z, c *big.Int, t, y Element) bool {
+	if len(t) != 1 || len(y) != 1 || !g.IsElementInGroup(t[0]) {
+		return false
+	}
+	left := g.expG(z)[0]
+	right := g.Mul(t[0], g.Exp(y[0], new(big.Int).Mod(c, g.Q)))
+	return common.ConstantTimeEq(left, right)
+}
+
+type ecGroup struct {
+	*ec.Group
+}
+
+func (g ecGroup) order() *big.Int {
+	return g.Q
+}
+
+func (g ecGroup) expG(x *big.Int) Element {
+	el := g.ExpBaseG(new(big.Int).Mod(x, g.Q))
+	return Element{el.X, el.Y}
+}
+
+func (g ecGroup) check(z, c *big.Int, t, y Element) bool {
+	if len(t) != 2 || len(y) != 2 || !g.Curve.IsOnCurve(t[0], t[1]) {
+		return false
+	}
+	left := g.expG(z)
+	right := g.Mul(ec.NewGroupElement(t[0], t[1]),
+		g.Exp(ec.NewGroupElement(y[0], y[1]), new(big.Int).Mod(c, g.Q)))
+	return common.ConstantTimeEq(left[0], right.X) && common.ConstantTimeEq(left[1], right.Y)
+}
@@ -0,0 +1,158 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package ec
+
+import (
+	"crypto/elliptic"
+	"math/big"
+
+	"github.com/awsong/crypto/common"
+)
+
+// EncodedGroup is an elliptic curve group in which the elements are represented as
+// *big.Int - the point (x, y) is represented by the integer with the big-endian bytes
+// of its compressed encoding (see elliptic.MarshalCompressed) and the point at infinity
+// (the neutral element) is represented by 0. EncodedGroup thus offers the same methods
+// as the groups for modular arithmetic (it implements crypto.Group). The methods return
+// nil if some of the given elements is not a valid encoding of a point on the curve.
+// Note that NewP256Group returns NIST P-256 (while GetCurve(P256) returns SM2 P-256).
+type EncodedGroup struct {
+	Curve elliptic.Curve
+	Q     *big.Int // order of the generator
+}
+
+func NewEncodedGroup(curve elliptic.Curve) *EncodedGroup {
+	return &EncodedGroup{
+		Curve: curve,
+		Q:     curve.Params().N,
+	}
+}
+
+func NewP256Group() *EncodedGroup {
+	return NewEncodedGroup(elliptic.P256())
+}
+
+func NewP384Group() *EncodedGroup {
+	return NewEncodedGroup(elliptic.P384())
+}
+
+func NewP521Group() *EncodedGroup {
+	return NewEncodedGroup(elliptic.P521())
+}
+
+// Encode returns the representation of the point (x, y).
+func (g *EncodedGroup) Encode(x, y *big.Int) *big.Int {
+	if x.Sign() == 0 && y.Sign() == 0 {
+		return big.NewInt(0)
+	}
+	return new(big.Int).SetBytes(elliptic.MarshalCompressed(g.Curve, x, y))
+}
+
+// Decode returns the point represented by a. The last return value is false if a does not
+// represent a point on the curve. The point at infinity is returned as (0, 0).
+func (g *EncodedGroup) Decode(a *big.Int) (*big.Int, *big.Int, bool) {
+	if a == nil {
+		return nil, nil, false
+	}
+	if a.Sign() == 0 {
+		return new(big.Int), new(big.Int), true
+	}
+	byteLen := (g.Curve.Params().BitSize + 7) / 8
+	data := a.Bytes()
+	if len(data) != 1+byteLen {
+		return nil, nil, false
+	}
+	x, y := elliptic.UnmarshalCompressed(g.Curve, data)
+	if x == nil {
+		return nil, nil, false
+	}
+	return x, y, true
+}
+
+// IsElementInGroup returns true if a represents a point on the curve (or the point
+// at infinity).
+func (g *EncodedGroup) IsElementInGroup(a *big.Int) bool {
+	_, _, ok := g.Decode(a)
+	return ok
+}
+
+// GetRandomElement returns a random element from this group.
+func (g *EncodedGroup) GetRandomElement() *big.Int {
+	return g.ExpBaseG(common.GetRandomInt(g.Q))
+}
+
+// Mul computes a * b in EncodedGroup. This actually means a + b (point addition) as
+// this is additive group.
+func (g *EncodedGroup) Mul(a, b *big.Int) *big.Int {
+	ax, ay, okA := g.Decode(a)
+	bx, by, okB := g.Decode(b)
+	if !okA || !okB {
+		return nil
+	}
+	switch {
+	case a.Sign() == 0:
+		return new(big.Int).Set(b)
+	case b.Sign() == 0:
+		return new(big.Int).Set(a)
+	}
+	x, y := g.Curve.Add(ax, ay, bx, by)
+	return g.Encode(x, y)
+}
+
+// Add is the same as Mul - the group operation on elliptic curves is point addition.
+func (g *EncodedGroup) Add(a, b *big.Int) *big.Int {
+	return g.Mul(a, b)
+}
+
+// Exp computes base^exponent in EncodedGroup. This actually means exponent * base as this is
+// additive group. Negative exponents are supported.
+func (g *EncodedGroup) Exp(base, exponent *big.Int) *big.Int {
+	x, y, ok := g.Decode(base)
+	if !ok {
+		return nil
+	}
+	e := new(big.Int).Mod(exponent, g.Q)
+	if base.Sign() == 0 || e.Sign() == 0 {
+		return big.NewInt(0)
+	}
+	hx, hy := g.Curve.ScalarMult(x, y, e.Bytes())
+	return g.Encode(hx, hy)
+}
+
+// ExpBaseG computes exponent * G where G is the generator of the curve.
+func (g *EncodedGroup) ExpBaseG(exponent *big.Int) *big.Int {
+	e := new(big.Int).Mod(exponent, g.Q)
+	if e.Sign() == 0 {
+		return big.NewInt(0)
+	}
+	hx, hy := g.Curve.ScalarBaseMult(e.Bytes())
+	return g.Encode(hx, hy)
+}
+
+// Inv computes inverse of a in EncodedGroup, that is the point (x, -y).
+func (g *EncodedGroup) Inv(a *big.Int) *big.Int {
+	x, y, ok := g.Decode(a)
+	if !ok {
+		return nil
+	}
+	if a.Sign() == 0 {
+		return big.NewInt(0)
+	}
+	p := g.Curve.Params().P
+	return g.Encode(x, new(big.Int).Sub(p, y))
+}
@@ -0,0 +1,62 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package ec
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/awsong/crypto"
+	"github.com/awsong/crypto/common"
+	"github.com/stretchr/testify/assert"
+)
+
+var _ crypto.Group = (*EncodedGroup)(nil)
+
+func TestEncodedGroup(t *testing.T) {
+	for _, group := range []*EncodedGroup{NewP256Group(), NewP384Group(), NewP521Group()} {
+		name := group.Curve.Params().Name
+		g := group.ExpBaseG(big.NewInt(1))
+		a := common.GetRandomInt(group.Q)
+		b := common.GetRandomInt(group.Q)
+		ga := group.Exp(g, a)
+		gb := group.Exp(g, b)
+
+		assert.Equal(t, group.ExpBaseG(a), ga, "%s: Exp does not match ExpBaseG", name)
+		assert.Equal(t, group.Exp(g, new(big.Int).Add(a, b)), group.Mul(ga, gb),
+			"%s: g^a * g^b != g^(a+b)", name)
+		assert.Equal(t, group.Mul(ga, gb), group.Add(ga, gb), "%s: Add does not match Mul",
+			name)
+		assert.Equal(t, big.NewInt(0), group.Mul(ga, group.Inv(ga)),
+			"%s: a * a^(-1) is not the neutral element", name)
+		assert.Equal(t, group.Inv(ga), group.Exp(g, new(big.Int).Neg(a)),
+			"%s: negative exponent does not give the inverse", name)
+		assert.Equal(t, ga, group.Mul(ga, big.NewInt(0)),
+			"%s: neutral element does not work", name)
+
+		byteLen := (group.Curve.Params().BitSize + 7) / 8
+		assert.Equal(t, 1+byteLen, len(ga.Bytes()), "%s: point is not compressed", name)
+		assert.True(t, group.IsElementInGroup(group.GetRandomElement()),
+			"%s: random element is not in the group", name)
+		invalid := ga.Bytes()
+		invalid[0] = 5 // compressed encoding starts with 2 or 3
+		assert.False(t, group.IsElementInGroup(new(big.Int).SetBytes(invalid)),
+			"%s: invalid encoding is accepted", name)
+		assert.Nil(t, group.Mul(big.NewInt(5), ga), "%s: invalid encoding is accepted", name)
+	}
+}
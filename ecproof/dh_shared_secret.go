@@ -0,0 +1,91 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package ecproof
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/awsong/crypto/ec"
+	"github.com/awsong/crypto/ecschnorr"
+)
+
+// DHSharedSecretProver proves the knowledge of a such that A = a*G and K = a*B, where
+// A is the prover's public key, B is the other party's public key and K is the shared
+// Diffie-Hellman key. Neither a nor b (B = b*G) are revealed. It is an EC Chaum-Pedersen
+// proof (see ecschnorr.EqualityProver) that log_G(A) = log_B(K).
+type DHSharedSecretProver struct {
+	*ecschnorr.EqualityProver
+	a *big.Int
+	b *ec.GroupElement
+}
+
+func NewDHSharedSecretProver(curve ec.Curve, a *big.Int,
+	b *ec.GroupElement) *DHSharedSecretProver {
+	return &DHSharedSecretProver{
+		EqualityProver: ecschnorr.NewEqualityProver(curve),
+		a:              a,
+		b:              b,
+	}
+}
+
+// GetSharedSecret returns K = a*B.
+func (p *DHSharedSecretProver) GetSharedSecret() *ec.GroupElement {
+	return p.Group.Exp(p.b, p.a)
+}
+
+// GetProofRandomData returns r*G and r*B for a random r.
+func (p *DHSharedSecretProver) GetProofRandomData() (*ec.GroupElement, *ec.GroupElement) {
+	return p.EqualityProver.GetProofRandomData(p.a, getBasePoint(p.Group), p.b)
+}
+
+// getBasePoint returns the generator G of the curve.
+func getBasePoint(group *ec.Group) *ec.GroupElement {
+	params := group.Curve.Params()
+	return ec.NewGroupElement(params.Gx, params.Gy)
+}
+
+type DHSharedSecretVerifier struct {
+	*ecschnorr.EqualityVerifier
+	a *ec.GroupElement
+	b *ec.GroupElement
+	k *ec.GroupElement
+}
+
+// NewDHSharedSecretVerifier returns a verifier for the public keys A, B and the shared
+// key K. It returns an error if any of them is not a point on the curve.
+func NewDHSharedSecretVerifier(curve ec.Curve, a, b,
+	k *ec.GroupElement) (*DHSharedSecretVerifier, error) {
+	verifier := ecschnorr.NewEqualityVerifier(curve)
+	for _, el := range []*ec.GroupElement{a, b, k} {
+		if !verifier.Group.Curve.IsOnCurve(el.X, el.Y) {
+			return nil, fmt.Errorf("public keys and shared key need to be on the curve")
+		}
+	}
+	return &DHSharedSecretVerifier{
+		EqualityVerifier: verifier,
+		a:                a,
+		b:                b,
+		k:                k,
+	}, nil
+}
+
+// GetChallenge receives r*G and r*B and returns a random challenge.
+func (v *DHSharedSecretVerifier) GetChallenge(x1, x2 *ec.GroupElement) *big.Int {
+	return v.EqualityVerifier.GetChallenge(getBasePoint(v.Group), v.b, v.a, v.k, x1, x2)
+}
@@ -0,0 +1,70 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package ecproof
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/awsong/crypto/common"
+	"github.com/awsong/crypto/ec"
+	"github.com/stretchr/testify/assert"
+)
+
+func proveDHSharedSecret(curve ec.Curve, proverA *big.Int, a, b, k *ec.GroupElement) (bool,
+	error) {
+	prover := NewDHSharedSecretProver(curve, proverA, b)
+	verifier, err := NewDHSharedSecretVerifier(curve, a, b, k)
+	if err != nil {
+		return false, err
+	}
+	x1, x2 := prover.GetProofRandomData()
+	challenge := verifier.GetChallenge(x1, x2)
+	return verifier.Verify(prover.GetProofData(challenge)), nil
+}
+
+func TestDHSharedSecret(t *testing.T) {
+	curve := ec.P256
+	group := ec.NewGroup(curve)
+	a := common.GetRandomInt(group.Q)
+	b := common.GetRandomInt(group.Q)
+	pubA := group.ExpBaseG(a)
+	pubB := group.ExpBaseG(b)
+
+	prover := NewDHSharedSecretProver(curve, a, pubB)
+	k := prover.GetSharedSecret()
+	assert.Equal(t, true, k.Equals(group.Exp(pubA, b)), "a*B and b*A should be equal")
+
+	proved, err := proveDHSharedSecret(curve, a, pubA, pubB, k)
+	if err != nil {
+		t.Errorf("error in DH shared secret proof: %v", err)
+	}
+	assert.Equal(t, true, proved, "DH shared secret proof failed")
+
+	// prover uses a wrong a
+	wrongA := new(big.Int).Add(a, big.NewInt(1))
+	proved, err = proveDHSharedSecret(curve, wrongA, pubA, pubB, k)
+	if err != nil {
+		t.Errorf("error in DH shared secret proof: %v", err)
+	}
+	assert.Equal(t, false, proved, "DH shared secret proof should fail for a wrong a")
+
+	_, err = NewDHSharedSecretVerifier(curve, pubA, pubB, ec.NewGroupElement(big.NewInt(1),
+		big.NewInt(1)))
+	assert.NotNil(t, err, "a point which is not on the curve should not be accepted")
+}
@@ -0,0 +1,76 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package ecproof
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/awsong/crypto/df"
+	"github.com/awsong/crypto/ec"
+)
+
+// ECDSANonceValidityProver proves that the DF commitment c = g^k * h^r hides a valid
+// ECDSA nonce, that is k in [1, n-1] where n is the order of the curve. It uses
+// df.CompoundPredicateProver for the predicate k >= 1 AND k <= n-1.
+type ECDSANonceValidityProver struct {
+	*df.CompoundPredicateProver
+}
+
+// NewECDSANonceValidityProver returns ECDSANonceValidityProver. It returns an error if
+// k is not the value committed in committer or if k is not in [1, n-1].
+func NewECDSANonceValidityProver(curve ec.Curve, committer *df.Committer, k *big.Int,
+	challengeSpaceSize int) (*ECDSANonceValidityProver, error) {
+	committedValue, _ := committer.GetDecommitMsg()
+	if committedValue == nil || k.Cmp(committedValue) != 0 {
+		return nil, fmt.Errorf("nonce is not the committed value")
+	}
+	pred := getNoncePredicate(curve)
+	if !pred.Holds(k) {
+		return nil, fmt.Errorf("nonce needs to be in [1, n-1]")
+	}
+	prover, err := df.NewCompoundPredicateProver(committer, pred, challengeSpaceSize)
+	if err != nil {
+		return nil, err
+	}
+	return &ECDSANonceValidityProver{prover}, nil
+}
+
+// getNoncePredicate returns the predicate k >= 1 AND k <= n-1 where n is the order
+// of the curve.
+func getNoncePredicate(curve ec.Curve) *df.Predicate {
+	n := ec.GetCurve(curve).Params().N
+	return df.NewAndPredicate(df.NewGePredicate(big.NewInt(1)),
+		df.NewLePredicate(new(big.Int).Sub(n, big.NewInt(1))))
+}
+
+type ECDSANonceValidityVerifier struct {
+	*df.CompoundPredicateVerifier
+}
+
+// NewECDSANonceValidityVerifier returns ECDSANonceValidityVerifier for the commitment
+// stored in receiver. T is the bound for the committed values (as in df.Committer).
+func NewECDSANonceValidityVerifier(curve ec.Curve, receiver *df.Receiver, T *big.Int,
+	challengeSpaceSize int) (*ECDSANonceValidityVerifier, error) {
+	verifier, err := df.NewCompoundPredicateVerifier(receiver, getNoncePredicate(curve), T,
+		challengeSpaceSize)
+	if err != nil {
+		return nil, err
+	}
+	return &ECDSANonceValidityVerifier{verifier}, nil
+}
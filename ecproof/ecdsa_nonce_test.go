@@ -0,0 +1,84 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package ecproof
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/awsong/crypto/common"
+	"github.com/awsong/crypto/df"
+	"github.com/awsong/crypto/ec"
+	"github.com/stretchr/testify/assert"
+)
+
+func proveECDSANonceValidity(curve ec.Curve, receiver *df.Receiver, T,
+	k *big.Int) (bool, error) {
+	committer := df.NewCommitter(receiver.QRSpecialRSA.N,
+		receiver.G, receiver.H, T, receiver.K)
+	c, err := committer.GetCommitMsg(k)
+	if err != nil {
+		return false, err
+	}
+	receiver.SetCommitment(c)
+
+	challengeSpaceSize := 80
+	prover, err := NewECDSANonceValidityProver(curve, committer, k, challengeSpaceSize)
+	if err != nil {
+		return false, err
+	}
+	verifier, err := NewECDSANonceValidityVerifier(curve, receiver, T, challengeSpaceSize)
+	if err != nil {
+		return false, err
+	}
+
+	if err := verifier.SetProofRandomData(prover.GetProofRandomData()); err != nil {
+		return false, err
+	}
+	challenges, proofData := prover.GetProofData(verifier.GetChallenge())
+	return verifier.Verify(challenges, proofData), nil
+}
+
+func TestECDSANonceValidity(t *testing.T) {
+	receiver, err := df.NewReceiver(128, 80)
+	if err != nil {
+		t.Errorf("error in NewReceiver: %v", err)
+	}
+	// n^2 is used for T - but any other value can be used as well
+	T := new(big.Int).Mul(receiver.QRSpecialRSA.N, receiver.QRSpecialRSA.N)
+
+	curve := ec.P256
+	n := ec.GetCurve(curve).Params().N
+	k, err := common.GetRandomIntInRange(big.NewInt(1), n)
+	if err != nil {
+		t.Errorf("error in GetRandomIntInRange: %v", err)
+	}
+	nonces := []*big.Int{big.NewInt(1), k, new(big.Int).Sub(n, big.NewInt(1))}
+	for _, k := range nonces {
+		proved, err := proveECDSANonceValidity(curve, receiver, T, k)
+		if err != nil {
+			t.Errorf("error in ECDSA nonce validity proof: %v", err)
+		}
+		assert.Equal(t, true, proved, "ECDSA nonce validity proof failed for %v", k)
+	}
+
+	_, err = proveECDSANonceValidity(curve, receiver, T, big.NewInt(0))
+	assert.NotNil(t, err, "nonce 0 should not be accepted")
+	_, err = proveECDSANonceValidity(curve, receiver, T, n)
+	assert.NotNil(t, err, "nonce n should not be accepted")
+}
@@ -46,3 +46,30 @@ func TestECDLogKnowledge(t *testing.T) {
 
 	assert.Equal(t, verified, true, "dlog equality proof does not work")
 }
+
+func BenchmarkSchnorrProveEC(b *testing.B) {
+	group := ec.NewGroup(ec.P256)
+	a := group.ExpBaseG(common.GetRandomInt(group.Q))
+	secret := common.GetRandomInt(group.Q)
+	prover := NewProver(ec.P256)
+	challenge := common.GetRandomInt(group.Q)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		prover.GetProofRandomData(secret, a)
+		prover.GetProofData(challenge)
+	}
+}
+
+func BenchmarkSchnorrVerifyEC(b *testing.B) {
+	group := ec.NewGroup(ec.P256)
+	a := group.ExpBaseG(common.GetRandomInt(group.Q))
+	secret := common.GetRandomInt(group.Q)
+	prover := NewProver(ec.P256)
+	verifier := NewVerifier(ec.P256)
+	verifier.SetProofRandomData(prover.GetProofRandomData(secret, a), a, group.Exp(a, secret))
+	z := prover.GetProofData(verifier.GetChallenge())
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		verifier.Verify(z)
+	}
+}
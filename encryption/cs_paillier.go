@@ -115,13 +115,57 @@ type CSPaillierVerifierEncData struct {
 	Delta *big.Int
 }
 
-func NewCSPaillier(secParams *CSPaillierSecParams) *CSPaillier {
+// SecParams1024 returns security parameters for a modulus n of about 1024 bits.
+func SecParams1024() *CSPaillierSecParams {
+	return &CSPaillierSecParams{
+		L:        512,
+		RoLength: 160,
+		K:        158,
+		K1:       158,
+	}
+}
+
+// SecParams2048 returns security parameters for a modulus n of about 2048 bits.
+func SecParams2048() *CSPaillierSecParams {
+	return &CSPaillierSecParams{
+		L:        1024,
+		RoLength: 256,
+		K:        254,
+		K1:       254,
+	}
+}
+
+// Validate returns an error if security parameters do not satisfy the basic constraints
+// (L >= 512, RoLength >= 80, K >= 80, K1 >= 80, K <= L, K1 <= L). Note that the constraints
+// 2**K < min{p1, q1, ro} and ro * 2**(K + K1 + 3) < n are checked during key generation.
+func (sp *CSPaillierSecParams) Validate() error {
+	if sp.L < 512 {
+		return fmt.Errorf("L needs to be at least 512")
+	}
+	if sp.RoLength < 80 {
+		return fmt.Errorf("RoLength needs to be at least 80")
+	}
+	if sp.K < 80 || sp.K1 < 80 {
+		return fmt.Errorf("K and K1 need to be at least 80")
+	}
+	if sp.K > sp.L || sp.K1 > sp.L {
+		return fmt.Errorf("K and K1 need to be at most L")
+	}
+	return nil
+}
+
+func NewCSPaillier(secParams *CSPaillierSecParams) (*CSPaillier, error) {
+	if err := secParams.Validate(); err != nil {
+		return nil, err
+	}
 	cspaillier := CSPaillier{
 		SecParams: secParams,
 	}
-	cspaillier.generateKey()
+	if err := cspaillier.generateKey(); err != nil {
+		return nil, err
+	}
 
-	return &cspaillier
+	return &cspaillier, nil
 }
 
 func NewCSPaillierFromSecKey(secKey *CSPaillierSecKey) (*CSPaillier, error) {
@@ -139,12 +183,18 @@ func NewCSPaillierFromPubKey(pubKey *CSPaillierPubKey) *CSPaillier {
 	}
 }
 
-// Returns (u, e, v).
+// Returns (u, e, v). The label is an application-specific context value (for example
+// session ID or recipient identity) which is bound to the ciphertext - decryption
+// succeeds only with the same label. It needs to be positive and should be chosen uniformly at
+// random or derived from a non-empty string.
 func (csp *CSPaillier) Encrypt(m, label *big.Int) (*big.Int, *big.Int, *big.Int, error) {
 	if m.Cmp(csp.PubKey.N) >= 0 {
 		err := fmt.Errorf("msg is too big")
 		return nil, nil, nil, err
 	}
+	if label == nil || label.Sign() <= 0 {
+		return nil, nil, nil, fmt.Errorf("label needs to be positive")
+	}
 
 	b := new(big.Int).Div(csp.PubKey.N, big.NewInt(4))
 	r := common.GetRandomInt(b)
@@ -178,7 +228,8 @@ func (csp *CSPaillier) Encrypt(m, label *big.Int) (*big.Int, *big.Int, *big.Int,
 	return u, e, v, nil
 }
 
-func (csp *CSPaillier) Decrypt(u, e, v, label *big.Int) (*big.Int, error) {
+func (csp *CSPaillier) Decrypt(c *Ciphertext, label *big.Int) (*big.Int, error) {
+	u, e, v := c.U(), c.E(), c.V()
 	// check whether Abs(v) = v:
 	vAbs, _ := csp.Abs(v)
 	if v.Cmp(vAbs) != 0 {
@@ -204,7 +255,7 @@ func (csp *CSPaillier) Decrypt(u, e, v, label *big.Int) (*big.Int, error) {
 	v2 := new(big.Int).Mul(v, v)
 	v2.Mod(v2, n2)
 
-	if t.Cmp(v2) != 0 {
+	if !common.ConstantTimeEq(t, v2) {
 		err := fmt.Errorf("CSPaillier decryption failed 1")
 		return nil, err
 	}
@@ -219,7 +270,7 @@ func (csp *CSPaillier) Decrypt(u, e, v, label *big.Int) (*big.Int, error) {
 	m1min := new(big.Int).Sub(m1, big.NewInt(1))
 	m1minModulo := new(big.Int).Mod(m1min, csp.PubKey.N)
 
-	if m1minModulo.Cmp(big.NewInt(0)) != 0 {
+	if !common.ConstantTimeEq(m1minModulo, big.NewInt(0)) {
 		err := fmt.Errorf("CSPaillier decryption failed 2")
 		return nil, err
 	}
@@ -244,7 +295,7 @@ func (csp *CSPaillier) Abs(a *big.Int) (*big.Int, error) {
 	}
 }
 
-func (csp *CSPaillier) generateKey() {
+func (csp *CSPaillier) generateKey() error {
 	p1 := common.GetGermainPrime(csp.SecParams.L)
 	q1 := common.GetGermainPrime(csp.SecParams.L)
 
@@ -266,7 +317,7 @@ func (csp *CSPaillier) generateKey() {
 	// for verifiable encryption:
 	Gamma, err := schnorr.NewGroup(csp.SecParams.RoLength)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	pubKey.Gamma = Gamma
 
@@ -276,7 +327,7 @@ func (csp *CSPaillier) generateKey() {
 
 	check1 := new(big.Int).Exp(big.NewInt(2), big.NewInt(int64(csp.SecParams.K)), nil)
 	if check1.Cmp(p1) >= 0 || check1.Cmp(q1) >= 0 || check1.Cmp(Gamma.Q) >= 0 {
-		log.Fatal(err)
+		return fmt.Errorf("it must hold 2**K < min{p1, q1, ro}")
 	}
 
 	tmp := csp.SecParams.K + csp.SecParams.K1 + 3
@@ -284,7 +335,7 @@ func (csp *CSPaillier) generateKey() {
 	check2.Mul(check2, Gamma.Q)
 
 	if check2.Cmp(n) >= 0 {
-		log.Fatal(err)
+		return fmt.Errorf("it must hold ro * 2**(K + K1 + 3) < n")
 	}
 
 	pubKey.K = csp.SecParams.K
@@ -297,7 +348,7 @@ func (csp *CSPaillier) generateKey() {
 	primes := qr.NewRSASpecialPrimes(p, q, p1, q1)
 	verifiableEncGroup, err := NewVerifiableEncGroup(primes)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 
 	pubKey.VerifiableEncGroupN = verifiableEncGroup.N
@@ -337,6 +388,7 @@ func (csp *CSPaillier) generateKey() {
 	pubKey.Y3 = new(big.Int).Exp(pubKey.G, secretKey.X3, n2)
 	csp.PubKey = &pubKey
 	csp.SecKey = &secretKey
+	return nil
 }
 
 // Returns l = g1^m * h1^s where s is a random integer smaller than n/4.
@@ -365,20 +417,20 @@ func (csp *CSPaillier) GetProofRandomData(u, e, label *big.Int) (*big.Int, *big.
 	two := big.NewInt(2)
 	t1 := new(big.Int).Exp(two, big.NewInt(int64(csp.PubKey.K+csp.PubKey.K1-2)), nil)
 	b1 := new(big.Int).Mul(csp.PubKey.N, t1)
-	r1, err := common.GetRandomIntFromRange(new(big.Int).Neg(b1), b1)
+	r1, err := common.GetRandomIntInRange(new(big.Int).Neg(b1), b1)
 	if err != nil {
 		return nil, nil, nil, nil, nil, err
 	}
 
 	b2 := new(big.Int).Mul(csp.PubKey.VerifiableEncGroupN, t1)
-	s1, err := common.GetRandomIntFromRange(new(big.Int).Neg(b2), b2)
+	s1, err := common.GetRandomIntInRange(new(big.Int).Neg(b2), b2)
 	if err != nil {
 		return nil, nil, nil, nil, nil, err
 	}
 
 	t2 := new(big.Int).Exp(two, big.NewInt(int64(csp.PubKey.K+csp.PubKey.K1)), nil)
 	b3 := new(big.Int).Mul(csp.PubKey.Gamma.Q, t2)
-	m1, err := common.GetRandomIntFromRange(new(big.Int).Neg(b3), b3)
+	m1, err := common.GetRandomIntInRange(new(big.Int).Neg(b3), b3)
 	if err != nil {
 		return nil, nil, nil, nil, nil, err
 	}
@@ -465,7 +517,7 @@ func (csp *CSPaillier) Verify(rTilde, sTilde, mTilde *big.Int) bool {
 	t2 := common.Exponentiate(csp.SecKey.G, twoRTilde, n2)
 	t := new(big.Int).Mul(t1, t2)
 	t.Mod(t, n2)
-	if csp.verifierRandomData.U1.Cmp(t) != 0 {
+	if !common.ConstantTimeEq(csp.verifierRandomData.U1, t) {
 		log.Println("NOT OK 1")
 		return false
 	}
@@ -479,7 +531,7 @@ func (csp *CSPaillier) Verify(rTilde, sTilde, mTilde *big.Int) bool {
 	t.Mul(t1, t2)
 	t.Mul(t, t3)
 	t.Mod(t, n2)
-	if csp.verifierRandomData.E1.Cmp(t) != 0 {
+	if !common.ConstantTimeEq(csp.verifierRandomData.E1, t) {
 		log.Println("NOT OK 2")
 		return false
 	}
@@ -495,7 +547,7 @@ func (csp *CSPaillier) Verify(rTilde, sTilde, mTilde *big.Int) bool {
 	t2 = common.Exponentiate(t21, twoRTilde, n2)
 	t.Mul(t1, t2)
 	t.Mod(t, n2)
-	if csp.verifierRandomData.V1.Cmp(t) != 0 {
+	if !common.ConstantTimeEq(csp.verifierRandomData.V1, t) {
 		log.Println("NOT OK 3")
 		return false
 	}
@@ -506,7 +558,7 @@ func (csp *CSPaillier) Verify(rTilde, sTilde, mTilde *big.Int) bool {
 	t2 = common.Exponentiate(csp.SecKey.Gamma.G, mTilde, csp.SecKey.Gamma.P)
 	t.Mul(t1, t2)
 	t.Mod(t, csp.SecKey.Gamma.P)
-	if csp.verifierRandomData.Delta1.Cmp(t) != 0 {
+	if !common.ConstantTimeEq(csp.verifierRandomData.Delta1, t) {
 		log.Println("NOT OK 4")
 		return false
 	}
@@ -520,7 +572,7 @@ func (csp *CSPaillier) Verify(rTilde, sTilde, mTilde *big.Int) bool {
 	t.Mul(t1, t2)
 	t.Mul(t, t3)
 	t.Mod(t, csp.SecKey.VerifiableEncGroupN)
-	if csp.verifierRandomData.L1.Cmp(t) != 0 {
+	if !common.ConstantTimeEq(csp.verifierRandomData.L1, t) {
 		log.Println("NOT OK 5")
 		return false
 	}
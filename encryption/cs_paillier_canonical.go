@@ -0,0 +1,171 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package encryption
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/awsong/crypto/common"
+)
+
+// IsCanonicalCiphertext returns true if the ciphertext (u, e, v) satisfies the range
+// conditions of the CSPaillier scheme: u, e and v need to be from Z_n^2* and
+// v needs to be equal to abs(v) (v <= n^2/2). Note that this does not
+// check that u and v were computed using the same randomness, see CanonicalFormProver.
+func IsCanonicalCiphertext(u, e, v *big.Int, pk *CSPaillierPubKey) bool {
+	n2 := new(big.Int).Mul(pk.N, pk.N)
+	for _, x := range []*big.Int{u, e, v} {
+		if x == nil || x.Sign() <= 0 || x.Cmp(n2) >= 0 {
+			return false
+		}
+		if new(big.Int).GCD(nil, nil, x, pk.N).Cmp(big.NewInt(1)) != 0 {
+			return false
+		}
+	}
+
+	halfN2 := new(big.Int).Div(n2, big.NewInt(2))
+	return v.Cmp(halfN2) <= 0
+}
+
+// CanonicalFormProver proves that the ciphertext (u, e, v) is in canonical form. Besides
+// the range conditions (which are checked by IsCanonicalCiphertext), it proves
+// the knowledge of r such that u^2 = g^(2*r) and v^2 = (y2 * y3^hash(u, e, L))^(2*r),
+// which means that u and v were computed using the same randomness (as in Encrypt).
+type CanonicalFormProver struct {
+	pubKey *CSPaillierPubKey
+	u      *big.Int
+	vBase  *big.Int // y2 * y3^hash(u, e, L)
+	r      *big.Int
+	r1     *big.Int
+}
+
+// NewCanonicalFormProver returns CanonicalFormProver for the ciphertext (u, e, v) which was
+// computed by csp.Encrypt (encryption randomness is taken from csp).
+func NewCanonicalFormProver(csp *CSPaillier, u, e, v, label *big.Int) (*CanonicalFormProver,
+	error) {
+	if csp.proverEncData == nil {
+		return nil, fmt.Errorf("encryption data is not available, call Encrypt first")
+	}
+	if !IsCanonicalCiphertext(u, e, v, csp.PubKey) {
+		return nil, fmt.Errorf("ciphertext is not in canonical form")
+	}
+
+	return &CanonicalFormProver{
+		pubKey: csp.PubKey,
+		u:      u,
+		vBase:  getVBase(csp.PubKey, u, e, label),
+		r:      csp.proverEncData.R,
+	}, nil
+}
+
+// getVBase returns y2 * y3^hash(u, e, L) % n^2.
+func getVBase(pk *CSPaillierPubKey, u, e, label *big.Int) *big.Int {
+	n2 := new(big.Int).Mul(pk.N, pk.N)
+	hashNum := common.Hash(u, e, label)
+	t := new(big.Int).Exp(pk.Y3, hashNum, n2)
+	t.Mul(pk.Y2, t)
+	return t.Mod(t, n2)
+}
+
+// GetProofRandomData returns u1 = g^(2*r1) and v1 = (y2 * y3^hash(u, e, L))^(2*r1) where
+// r1 is chosen from (-n * 2^(K+K1-2), n * 2^(K+K1-2)).
+func (p *CanonicalFormProver) GetProofRandomData() (*big.Int, *big.Int, error) {
+	t := new(big.Int).Exp(big.NewInt(2), big.NewInt(int64(p.pubKey.K+p.pubKey.K1-2)), nil)
+	b := new(big.Int).Mul(p.pubKey.N, t)
+	r1, err := common.GetRandomIntInRange(new(big.Int).Neg(b), b)
+	if err != nil {
+		return nil, nil, err
+	}
+	p.r1 = r1
+
+	n2 := new(big.Int).Mul(p.pubKey.N, p.pubKey.N)
+	twoR1 := new(big.Int).Mul(big.NewInt(2), r1)
+	u1 := common.Exponentiate(p.pubKey.G, twoR1, n2)
+	v1 := common.Exponentiate(p.vBase, twoR1, n2)
+	return u1, v1, nil
+}
+
+// GetProofData returns rTilde = r1 - c * r.
+func (p *CanonicalFormProver) GetProofData(c *big.Int) *big.Int {
+	rTilde := new(big.Int).Mul(c, p.r)
+	return rTilde.Sub(p.r1, rTilde)
+}
+
+type CanonicalFormVerifier struct {
+	pubKey    *CSPaillierPubKey
+	u         *big.Int
+	v         *big.Int
+	vBase     *big.Int
+	u1        *big.Int
+	v1        *big.Int
+	challenge *big.Int
+}
+
+func NewCanonicalFormVerifier(pubKey *CSPaillierPubKey, u, e, v,
+	label *big.Int) (*CanonicalFormVerifier, error) {
+	if !IsCanonicalCiphertext(u, e, v, pubKey) {
+		return nil, fmt.Errorf("ciphertext is not in canonical form")
+	}
+
+	return &CanonicalFormVerifier{
+		pubKey: pubKey,
+		u:      u,
+		v:      v,
+		vBase:  getVBase(pubKey, u, e, label),
+	}, nil
+}
+
+func (v *CanonicalFormVerifier) SetProofRandomData(u1, v1 *big.Int) {
+	v.u1 = u1
+	v.v1 = v1
+}
+
+func (v *CanonicalFormVerifier) GetChallenge() *big.Int {
+	b := new(big.Int).Exp(big.NewInt(2), big.NewInt(int64(v.pubKey.K)), nil)
+	challenge := common.GetRandomInt(b)
+	v.challenge = challenge
+	return challenge
+}
+
+// SetChallenge is used when Fiat-Shamir is used - when challenge is generated using hash by the prover.
+func (v *CanonicalFormVerifier) SetChallenge(challenge *big.Int) {
+	v.challenge = challenge
+}
+
+func (v *CanonicalFormVerifier) Verify(rTilde *big.Int) bool {
+	n2 := new(big.Int).Mul(v.pubKey.N, v.pubKey.N)
+	twoC := new(big.Int).Mul(v.challenge, big.NewInt(2))
+	twoRTilde := new(big.Int).Mul(rTilde, big.NewInt(2))
+
+	// check if u1 = u^(2*c) * g^(2*rTilde)
+	t1 := common.Exponentiate(v.u, twoC, n2)
+	t2 := common.Exponentiate(v.pubKey.G, twoRTilde, n2)
+	t := new(big.Int).Mul(t1, t2)
+	t.Mod(t, n2)
+	if !common.ConstantTimeEq(v.u1, t) {
+		return false
+	}
+
+	// check if v1 = v^(2*c) * (y2 * y3^hash(u, e, L))^(2*rTilde)
+	t1 = common.Exponentiate(v.v, twoC, n2)
+	t2 = common.Exponentiate(v.vBase, twoRTilde, n2)
+	t.Mul(t1, t2)
+	t.Mod(t, n2)
+	return common.ConstantTimeEq(v.v1, t)
+}
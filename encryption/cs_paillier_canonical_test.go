@@ -0,0 +1,89 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package encryption
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/awsong/crypto/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCSPaillierCanonicalForm(t *testing.T) {
+	csp, err := NewCSPaillier(
+		&CSPaillierSecParams{
+			L:        512,
+			RoLength: 160,
+			K:        158,
+			K1:       158,
+		})
+	if err != nil {
+		t.Errorf("Error when creating CSPaillier: %v", err)
+	}
+
+	m := common.GetRandomInt(big.NewInt(8685849))
+	label := common.GetRandomInt(big.NewInt(340002223232))
+	u, e, v, _ := csp.Encrypt(m, label)
+
+	assert.Equal(t, true, IsCanonicalCiphertext(u, e, v, csp.PubKey),
+		"generated ciphertext should be in canonical form")
+
+	prover, err := NewCanonicalFormProver(csp, u, e, v, label)
+	if err != nil {
+		t.Errorf("Error in NewCanonicalFormProver: %v", err)
+	}
+	verifier, err := NewCanonicalFormVerifier(csp.PubKey, u, e, v, label)
+	if err != nil {
+		t.Errorf("Error in NewCanonicalFormVerifier: %v", err)
+	}
+
+	u1, v1, err := prover.GetProofRandomData()
+	if err != nil {
+		t.Errorf("Error in GetProofRandomData: %v", err)
+	}
+	verifier.SetProofRandomData(u1, v1)
+	challenge := verifier.GetChallenge()
+	rTilde := prover.GetProofData(challenge)
+	assert.Equal(t, true, verifier.Verify(rTilde), "canonical form proof failed")
+
+	// malformed ciphertexts
+	n2 := new(big.Int).Mul(csp.PubKey.N, csp.PubKey.N)
+	vNeg := new(big.Int).Sub(n2, v)
+	assert.Equal(t, false, IsCanonicalCiphertext(big.NewInt(0), e, v, csp.PubKey),
+		"u = 0 should not be in canonical form")
+	assert.Equal(t, false, IsCanonicalCiphertext(u, n2, v, csp.PubKey),
+		"e = n^2 should not be in canonical form")
+	assert.Equal(t, false, IsCanonicalCiphertext(u, e, vNeg, csp.PubKey),
+		"v != abs(v) should not be in canonical form")
+	assert.Equal(t, false, IsCanonicalCiphertext(csp.PubKey.N, e, v, csp.PubKey),
+		"u not from Z_n^2* should not be in canonical form")
+
+	// v which is in the right range, but not computed with the same randomness as u
+	vWrong, _ := csp.Abs(new(big.Int).Exp(v, big.NewInt(3), n2))
+	verifier, err = NewCanonicalFormVerifier(csp.PubKey, u, e, vWrong, label)
+	if err != nil {
+		t.Errorf("Error in NewCanonicalFormVerifier: %v", err)
+	}
+	u1, v1, _ = prover.GetProofRandomData()
+	verifier.SetProofRandomData(u1, v1)
+	challenge = verifier.GetChallenge()
+	rTilde = prover.GetProofData(challenge)
+	assert.Equal(t, false, verifier.Verify(rTilde),
+		"canonical form proof should fail for malformed ciphertext")
+}
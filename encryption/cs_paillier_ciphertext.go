@@ -0,0 +1,91 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package encryption
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math/big"
+)
+
+// Ciphertext represents CSPaillier ciphertext (u, e, v) as returned by Encrypt.
+type Ciphertext struct {
+	u *big.Int
+	e *big.Int
+	v *big.Int
+}
+
+func NewCiphertext(u, e, v *big.Int) *Ciphertext {
+	return &Ciphertext{
+		u: u,
+		e: e,
+		v: v,
+	}
+}
+
+func (c *Ciphertext) U() *big.Int {
+	return c.u
+}
+
+func (c *Ciphertext) E() *big.Int {
+	return c.e
+}
+
+func (c *Ciphertext) V() *big.Int {
+	return c.v
+}
+
+// MarshalBinary encodes u, e and v one after another, each of them as a 4-byte
+// big-endian length followed by the big-endian bytes of the value.
+func (c *Ciphertext) MarshalBinary() ([]byte, error) {
+	var data []byte
+	for _, val := range []*big.Int{c.u, c.e, c.v} {
+		if val == nil || val.Sign() < 0 {
+			return nil, fmt.Errorf("ciphertext values need to be non-negative")
+		}
+		b := val.Bytes()
+		length := make([]byte, 4)
+		binary.BigEndian.PutUint32(length, uint32(len(b)))
+		data = append(data, length...)
+		data = append(data, b...)
+	}
+	return data, nil
+}
+
+// UnmarshalBinary decodes the ciphertext encoded by MarshalBinary.
+func (c *Ciphertext) UnmarshalBinary(data []byte) error {
+	vals := make([]*big.Int, 3)
+	for i := range vals {
+		if len(data) < 4 {
+			return fmt.Errorf("ciphertext data is too short")
+		}
+		length := binary.BigEndian.Uint32(data[:4])
+		data = data[4:]
+		if uint32(len(data)) < length {
+			return fmt.Errorf("ciphertext data is too short")
+		}
+		vals[i] = new(big.Int).SetBytes(data[:length])
+		data = data[length:]
+	}
+	if len(data) != 0 {
+		return fmt.Errorf("ciphertext data is too long")
+	}
+
+	c.u, c.e, c.v = vals[0], vals[1], vals[2]
+	return nil
+}
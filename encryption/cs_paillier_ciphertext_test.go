@@ -0,0 +1,81 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package encryption
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/awsong/crypto/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCSPaillierCiphertextBinary(t *testing.T) {
+	csp, err := NewCSPaillier(SecParams1024())
+	if err != nil {
+		t.Errorf("Error when creating CSPaillier: %v", err)
+	}
+
+	cspSec, _ := NewCSPaillierFromSecKey(csp.SecKey)
+	cspPub := NewCSPaillierFromPubKey(csp.PubKey)
+
+	m := common.GetRandomInt(big.NewInt(8685849))
+	label := common.GetRandomInt(big.NewInt(340002223232))
+	u, e, v, _ := cspPub.Encrypt(m, label)
+
+	data, err := NewCiphertext(u, e, v).MarshalBinary()
+	if err != nil {
+		t.Errorf("Error in MarshalBinary: %v", err)
+	}
+
+	c := new(Ciphertext)
+	err = c.UnmarshalBinary(data)
+	if err != nil {
+		t.Errorf("Error in UnmarshalBinary: %v", err)
+	}
+	assert.Equal(t, u, c.U(), "u was not properly deserialized")
+	assert.Equal(t, e, c.E(), "e was not properly deserialized")
+	assert.Equal(t, v, c.V(), "v was not properly deserialized")
+
+	p, err := cspSec.Decrypt(c, label)
+	if err != nil {
+		t.Errorf("Error in Decrypt: %v", err)
+	}
+	assert.Equal(t, m, p, "decryption of deserialized ciphertext does not work")
+
+	// flip a byte in each of u, e, v
+	uStart := 4
+	eStart := uStart + len(u.Bytes()) + 4
+	vStart := eStart + len(e.Bytes()) + 4
+	for _, pos := range []int{uStart + 1, eStart + 1, vStart + 1} {
+		modified := make([]byte, len(data))
+		copy(modified, data)
+		modified[pos] ^= 0xff
+
+		c := new(Ciphertext)
+		err = c.UnmarshalBinary(modified)
+		if err != nil {
+			t.Errorf("Error in UnmarshalBinary: %v", err)
+		}
+		_, err = cspSec.Decrypt(c, label)
+		assert.NotNil(t, err, "decryption of modified ciphertext should fail")
+	}
+
+	err = new(Ciphertext).UnmarshalBinary(data[:len(data)-1])
+	assert.NotNil(t, err, "truncated data should not be deserialized")
+}
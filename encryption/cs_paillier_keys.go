@@ -0,0 +1,407 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package encryption
+
+import (
+	"encoding/asn1"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+
+	"github.com/awsong/crypto/schnorr"
+)
+
+const (
+	pemTypeCSPaillierPubKey = "CS PAILLIER PUBLIC KEY"
+	pemTypeCSPaillierSecKey = "CS PAILLIER PRIVATE KEY"
+)
+
+// csPaillierPubKeyASN1 is the ASN.1 structure of CSPaillierPubKey. It is also used
+// (with hex-encoded values) for JSON representation.
+type csPaillierPubKeyASN1 struct {
+	N                    *big.Int
+	G                    *big.Int
+	Y1                   *big.Int
+	Y2                   *big.Int
+	Y3                   *big.Int
+	GammaP               *big.Int
+	GammaG               *big.Int
+	GammaQ               *big.Int
+	VerifiableEncGroupN  *big.Int
+	VerifiableEncGroupG1 *big.Int
+	VerifiableEncGroupH1 *big.Int
+	K                    int
+	K1                   int
+}
+
+// csPaillierSecKeyASN1 is the ASN.1 structure of CSPaillierSecKey.
+type csPaillierSecKeyASN1 struct {
+	N                    *big.Int
+	G                    *big.Int
+	X1                   *big.Int
+	X2                   *big.Int
+	X3                   *big.Int
+	GammaP               *big.Int
+	GammaG               *big.Int
+	GammaQ               *big.Int
+	VerifiableEncGroupN  *big.Int
+	VerifiableEncGroupG1 *big.Int
+	VerifiableEncGroupH1 *big.Int
+	K                    int
+	K1                   int
+}
+
+type csPaillierPubKeyJSON struct {
+	N                    string `json:"n"`
+	G                    string `json:"g"`
+	Y1                   string `json:"y1"`
+	Y2                   string `json:"y2"`
+	Y3                   string `json:"y3"`
+	GammaP               string `json:"gammaP"`
+	GammaG               string `json:"gammaG"`
+	GammaQ               string `json:"gammaQ"`
+	VerifiableEncGroupN  string `json:"verifiableEncGroupN"`
+	VerifiableEncGroupG1 string `json:"verifiableEncGroupG1"`
+	VerifiableEncGroupH1 string `json:"verifiableEncGroupH1"`
+	K                    int    `json:"k"`
+	K1                   int    `json:"k1"`
+}
+
+type csPaillierSecKeyJSON struct {
+	N                    string `json:"n"`
+	G                    string `json:"g"`
+	X1                   string `json:"x1"`
+	X2                   string `json:"x2"`
+	X3                   string `json:"x3"`
+	GammaP               string `json:"gammaP"`
+	GammaG               string `json:"gammaG"`
+	GammaQ               string `json:"gammaQ"`
+	VerifiableEncGroupN  string `json:"verifiableEncGroupN"`
+	VerifiableEncGroupG1 string `json:"verifiableEncGroupG1"`
+	VerifiableEncGroupH1 string `json:"verifiableEncGroupH1"`
+	K                    int    `json:"k"`
+	K1                   int    `json:"k1"`
+}
+
+// toHex returns hex representation of x (empty string for nil).
+func toHex(x *big.Int) string {
+	if x == nil {
+		return ""
+	}
+	return x.Text(16)
+}
+
+// fromHex parses hex representation of a big integer (empty string is parsed as nil).
+func fromHex(s string) (*big.Int, error) {
+	if s == "" {
+		return nil, nil
+	}
+	x, ok := new(big.Int).SetString(s, 16)
+	if !ok {
+		return nil, fmt.Errorf("invalid hex-encoded integer: %s", s)
+	}
+	return x, nil
+}
+
+// fromHexAll parses hex strings into the given destinations.
+func fromHexAll(dst []**big.Int, src []string) error {
+	for i, s := range src {
+		x, err := fromHex(s)
+		if err != nil {
+			return err
+		}
+		*dst[i] = x
+	}
+	return nil
+}
+
+// gammaParams returns P, G, Q of gamma (all nil if gamma is nil).
+func gammaParams(gamma *schnorr.Group) (*big.Int, *big.Int, *big.Int) {
+	if gamma == nil {
+		return nil, nil, nil
+	}
+	return gamma.P, gamma.G, gamma.Q
+}
+
+// newGamma returns Schnorr group from its parameters (nil if they are not given).
+func newGamma(p, g, q *big.Int) *schnorr.Group {
+	if p == nil || g == nil || q == nil {
+		return nil
+	}
+	return schnorr.NewGroupFromParams(p, g, q)
+}
+
+func (pk *CSPaillierPubKey) toASN1() *csPaillierPubKeyASN1 {
+	gammaP, gammaG, gammaQ := gammaParams(pk.Gamma)
+	return &csPaillierPubKeyASN1{
+		N:                    pk.N,
+		G:                    pk.G,
+		Y1:                   pk.Y1,
+		Y2:                   pk.Y2,
+		Y3:                   pk.Y3,
+		GammaP:               gammaP,
+		GammaG:               gammaG,
+		GammaQ:               gammaQ,
+		VerifiableEncGroupN:  pk.VerifiableEncGroupN,
+		VerifiableEncGroupG1: pk.VerifiableEncGroupG1,
+		VerifiableEncGroupH1: pk.VerifiableEncGroupH1,
+		K:                    pk.K,
+		K1:                   pk.K1,
+	}
+}
+
+func (k *csPaillierPubKeyASN1) toPubKey() *CSPaillierPubKey {
+	return &CSPaillierPubKey{
+		N:                    k.N,
+		G:                    k.G,
+		Y1:                   k.Y1,
+		Y2:                   k.Y2,
+		Y3:                   k.Y3,
+		Gamma:                newGamma(k.GammaP, k.GammaG, k.GammaQ),
+		VerifiableEncGroupN:  k.VerifiableEncGroupN,
+		VerifiableEncGroupG1: k.VerifiableEncGroupG1,
+		VerifiableEncGroupH1: k.VerifiableEncGroupH1,
+		K:                    k.K,
+		K1:                   k.K1,
+	}
+}
+
+func (sk *CSPaillierSecKey) toASN1() *csPaillierSecKeyASN1 {
+	gammaP, gammaG, gammaQ := gammaParams(sk.Gamma)
+	return &csPaillierSecKeyASN1{
+		N:                    sk.N,
+		G:                    sk.G,
+		X1:                   sk.X1,
+		X2:                   sk.X2,
+		X3:                   sk.X3,
+		GammaP:               gammaP,
+		GammaG:               gammaG,
+		GammaQ:               gammaQ,
+		VerifiableEncGroupN:  sk.VerifiableEncGroupN,
+		VerifiableEncGroupG1: sk.VerifiableEncGroupG1,
+		VerifiableEncGroupH1: sk.VerifiableEncGroupH1,
+		K:                    sk.K,
+		K1:                   sk.K1,
+	}
+}
+
+func (k *csPaillierSecKeyASN1) toSecKey() *CSPaillierSecKey {
+	return &CSPaillierSecKey{
+		N:                    k.N,
+		G:                    k.G,
+		X1:                   k.X1,
+		X2:                   k.X2,
+		X3:                   k.X3,
+		Gamma:                newGamma(k.GammaP, k.GammaG, k.GammaQ),
+		VerifiableEncGroupN:  k.VerifiableEncGroupN,
+		VerifiableEncGroupG1: k.VerifiableEncGroupG1,
+		VerifiableEncGroupH1: k.VerifiableEncGroupH1,
+		K:                    k.K,
+		K1:                   k.K1,
+	}
+}
+
+// MarshalJSON encodes CSPaillierPubKey as JSON object with hex-encoded big integers.
+func (pk *CSPaillierPubKey) MarshalJSON() ([]byte, error) {
+	k := pk.toASN1()
+	return json.Marshal(&csPaillierPubKeyJSON{
+		N:                    toHex(k.N),
+		G:                    toHex(k.G),
+		Y1:                   toHex(k.Y1),
+		Y2:                   toHex(k.Y2),
+		Y3:                   toHex(k.Y3),
+		GammaP:               toHex(k.GammaP),
+		GammaG:               toHex(k.GammaG),
+		GammaQ:               toHex(k.GammaQ),
+		VerifiableEncGroupN:  toHex(k.VerifiableEncGroupN),
+		VerifiableEncGroupG1: toHex(k.VerifiableEncGroupG1),
+		VerifiableEncGroupH1: toHex(k.VerifiableEncGroupH1),
+		K:                    k.K,
+		K1:                   k.K1,
+	})
+}
+
+// UnmarshalJSON decodes CSPaillierPubKey from JSON object produced by MarshalJSON.
+func (pk *CSPaillierPubKey) UnmarshalJSON(data []byte) error {
+	var j csPaillierPubKeyJSON
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	k := csPaillierPubKeyASN1{K: j.K, K1: j.K1}
+	err := fromHexAll([]**big.Int{&k.N, &k.G, &k.Y1, &k.Y2, &k.Y3, &k.GammaP, &k.GammaG,
+		&k.GammaQ, &k.VerifiableEncGroupN, &k.VerifiableEncGroupG1, &k.VerifiableEncGroupH1},
+		[]string{j.N, j.G, j.Y1, j.Y2, j.Y3, j.GammaP, j.GammaG, j.GammaQ,
+			j.VerifiableEncGroupN, j.VerifiableEncGroupG1, j.VerifiableEncGroupH1})
+	if err != nil {
+		return err
+	}
+	*pk = *k.toPubKey()
+	return nil
+}
+
+// MarshalJSON encodes CSPaillierSecKey as JSON object with hex-encoded big integers.
+func (sk *CSPaillierSecKey) MarshalJSON() ([]byte, error) {
+	k := sk.toASN1()
+	return json.Marshal(&csPaillierSecKeyJSON{
+		N:                    toHex(k.N),
+		G:                    toHex(k.G),
+		X1:                   toHex(k.X1),
+		X2:                   toHex(k.X2),
+		X3:                   toHex(k.X3),
+		GammaP:               toHex(k.GammaP),
+		GammaG:               toHex(k.GammaG),
+		GammaQ:               toHex(k.GammaQ),
+		VerifiableEncGroupN:  toHex(k.VerifiableEncGroupN),
+		VerifiableEncGroupG1: toHex(k.VerifiableEncGroupG1),
+		VerifiableEncGroupH1: toHex(k.VerifiableEncGroupH1),
+		K:                    k.K,
+		K1:                   k.K1,
+	})
+}
+
+// UnmarshalJSON decodes CSPaillierSecKey from JSON object produced by MarshalJSON.
+func (sk *CSPaillierSecKey) UnmarshalJSON(data []byte) error {
+	var j csPaillierSecKeyJSON
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	k := csPaillierSecKeyASN1{K: j.K, K1: j.K1}
+	err := fromHexAll([]**big.Int{&k.N, &k.G, &k.X1, &k.X2, &k.X3, &k.GammaP, &k.GammaG,
+		&k.GammaQ, &k.VerifiableEncGroupN, &k.VerifiableEncGroupG1, &k.VerifiableEncGroupH1},
+		[]string{j.N, j.G, j.X1, j.X2, j.X3, j.GammaP, j.GammaG, j.GammaQ,
+			j.VerifiableEncGroupN, j.VerifiableEncGroupG1, j.VerifiableEncGroupH1})
+	if err != nil {
+		return err
+	}
+	*sk = *k.toSecKey()
+	return nil
+}
+
+// MarshalPEM encodes *CSPaillierPubKey or *CSPaillierSecKey into a PEM block
+// (of type "CS PAILLIER PUBLIC KEY" or "CS PAILLIER PRIVATE KEY") containing
+// DER-encoded ASN.1 structure of the key.
+func MarshalPEM(key interface{}) ([]byte, error) {
+	var blockType string
+	var der []byte
+	var err error
+	switch k := key.(type) {
+	case *CSPaillierPubKey:
+		blockType = pemTypeCSPaillierPubKey
+		der, err = asn1.Marshal(*k.toASN1())
+	case *CSPaillierSecKey:
+		blockType = pemTypeCSPaillierSecKey
+		der, err = asn1.Marshal(*k.toASN1())
+	default:
+		return nil, fmt.Errorf("unsupported key type %T", key)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return pem.EncodeToMemory(&pem.Block{
+		Type:  blockType,
+		Bytes: der,
+	}), nil
+}
+
+// UnmarshalPEM decodes the first PEM block in data and returns *CSPaillierPubKey or
+// *CSPaillierSecKey, depending on the type of the block.
+func UnmarshalPEM(data []byte) (interface{}, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM block found")
+	}
+
+	switch block.Type {
+	case pemTypeCSPaillierPubKey:
+		var k csPaillierPubKeyASN1
+		if _, err := asn1.Unmarshal(block.Bytes, &k); err != nil {
+			return nil, err
+		}
+		return k.toPubKey(), nil
+	case pemTypeCSPaillierSecKey:
+		var k csPaillierSecKeyASN1
+		if _, err := asn1.Unmarshal(block.Bytes, &k); err != nil {
+			return nil, err
+		}
+		return k.toSecKey(), nil
+	default:
+		return nil, fmt.Errorf("unsupported PEM block type %s", block.Type)
+	}
+}
+
+// MarshalBinary encodes CSPaillierPubKey as DER-encoded ASN.1 structure (the same as
+// the content of the PEM block produced by MarshalPEM).
+func (pk *CSPaillierPubKey) MarshalBinary() ([]byte, error) {
+	return asn1.Marshal(*pk.toASN1())
+}
+
+// UnmarshalBinary decodes CSPaillierPubKey encoded by MarshalBinary.
+func (pk *CSPaillierPubKey) UnmarshalBinary(data []byte) error {
+	var k csPaillierPubKeyASN1
+	rest, err := asn1.Unmarshal(data, &k)
+	if err != nil {
+		return err
+	}
+	if len(rest) != 0 {
+		return fmt.Errorf("public key data is too long")
+	}
+	*pk = *k.toPubKey()
+	return nil
+}
+
+// MarshalBinary encodes CSPaillierSecKey as DER-encoded ASN.1 structure (the same as
+// the content of the PEM block produced by MarshalPEM).
+func (sk *CSPaillierSecKey) MarshalBinary() ([]byte, error) {
+	return asn1.Marshal(*sk.toASN1())
+}
+
+// UnmarshalBinary decodes CSPaillierSecKey encoded by MarshalBinary.
+func (sk *CSPaillierSecKey) UnmarshalBinary(data []byte) error {
+	var k csPaillierSecKeyASN1
+	rest, err := asn1.Unmarshal(data, &k)
+	if err != nil {
+		return err
+	}
+	if len(rest) != 0 {
+		return fmt.Errorf("secret key data is too long")
+	}
+	*sk = *k.toSecKey()
+	return nil
+}
+
+// NewCSPaillierFromPubKeyBytes returns CSPaillier (which can be used for encryption)
+// for the public key encoded by CSPaillierPubKey.MarshalBinary.
+func NewCSPaillierFromPubKeyBytes(data []byte) (*CSPaillier, error) {
+	pubKey := new(CSPaillierPubKey)
+	if err := pubKey.UnmarshalBinary(data); err != nil {
+		return nil, err
+	}
+	return NewCSPaillierFromPubKey(pubKey), nil
+}
+
+// NewCSPaillierFromSecKeyBytes returns CSPaillier (which can be used for decryption)
+// for the secret key encoded by CSPaillierSecKey.MarshalBinary.
+func NewCSPaillierFromSecKeyBytes(data []byte) (*CSPaillier, error) {
+	secKey := new(CSPaillierSecKey)
+	if err := secKey.UnmarshalBinary(data); err != nil {
+		return nil, err
+	}
+	return NewCSPaillierFromSecKey(secKey)
+}
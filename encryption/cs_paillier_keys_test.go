@@ -0,0 +1,144 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package encryption
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/awsong/crypto/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCSPaillierKeysSerialization(t *testing.T) {
+	csp, err := NewCSPaillier(
+		&CSPaillierSecParams{
+			L:        512,
+			RoLength: 160,
+			K:        158,
+			K1:       158,
+		})
+	if err != nil {
+		t.Errorf("Error when creating CSPaillier: %v", err)
+	}
+
+	// JSON
+	pubKeyJSON, err := json.Marshal(csp.PubKey)
+	if err != nil {
+		t.Errorf("Error when marshaling public key to JSON: %v", err)
+	}
+	secKeyJSON, err := json.Marshal(csp.SecKey)
+	if err != nil {
+		t.Errorf("Error when marshaling secret key to JSON: %v", err)
+	}
+	var pubKey CSPaillierPubKey
+	if err := json.Unmarshal(pubKeyJSON, &pubKey); err != nil {
+		t.Errorf("Error when unmarshaling public key from JSON: %v", err)
+	}
+	var secKey CSPaillierSecKey
+	if err := json.Unmarshal(secKeyJSON, &secKey); err != nil {
+		t.Errorf("Error when unmarshaling secret key from JSON: %v", err)
+	}
+	assert.Equal(t, csp.PubKey, &pubKey, "public key changed in JSON round-trip")
+	assert.Equal(t, csp.SecKey, &secKey, "secret key changed in JSON round-trip")
+
+	// PEM
+	var buf bytes.Buffer
+	pubKeyPEM, err := MarshalPEM(csp.PubKey)
+	if err != nil {
+		t.Errorf("Error in MarshalPEM: %v", err)
+	}
+	secKeyPEM, err := MarshalPEM(csp.SecKey)
+	if err != nil {
+		t.Errorf("Error in MarshalPEM: %v", err)
+	}
+	buf.Write(pubKeyPEM)
+	buf.Write(secKeyPEM)
+
+	pemData := buf.Bytes()
+	pubKeyRead, err := UnmarshalPEM(pemData)
+	if err != nil {
+		t.Errorf("Error in UnmarshalPEM: %v", err)
+	}
+	secKeyRead, err := UnmarshalPEM(pemData[len(pubKeyPEM):])
+	if err != nil {
+		t.Errorf("Error in UnmarshalPEM: %v", err)
+	}
+
+	cspPub := NewCSPaillierFromPubKey(pubKeyRead.(*CSPaillierPubKey))
+	cspSec, _ := NewCSPaillierFromSecKey(secKeyRead.(*CSPaillierSecKey))
+
+	m := common.GetRandomInt(big.NewInt(8685849))
+	label := common.GetRandomInt(big.NewInt(340002223232))
+	u, e, v, _ := cspPub.Encrypt(m, label)
+	p, _ := cspSec.Decrypt(NewCiphertext(u, e, v), label)
+
+	assert.Equal(t, m, p, "encryption/decryption with deserialized keys does not work correctly")
+}
+
+func TestCSPaillierKeysBinary(t *testing.T) {
+	csp, err := NewCSPaillier(
+		&CSPaillierSecParams{
+			L:        512,
+			RoLength: 160,
+			K:        158,
+			K1:       158,
+		})
+	if err != nil {
+		t.Errorf("Error when creating CSPaillier: %v", err)
+	}
+
+	pubKeyBytes, err := csp.PubKey.MarshalBinary()
+	if err != nil {
+		t.Errorf("Error when marshaling public key: %v", err)
+	}
+	secKeyBytes, err := csp.SecKey.MarshalBinary()
+	if err != nil {
+		t.Errorf("Error when marshaling secret key: %v", err)
+	}
+
+	cspPub, err := NewCSPaillierFromPubKeyBytes(pubKeyBytes)
+	if err != nil {
+		t.Errorf("Error in NewCSPaillierFromPubKeyBytes: %v", err)
+	}
+	cspSec, err := NewCSPaillierFromSecKeyBytes(secKeyBytes)
+	if err != nil {
+		t.Errorf("Error in NewCSPaillierFromSecKeyBytes: %v", err)
+	}
+	assert.Equal(t, csp.PubKey, cspPub.PubKey, "public key changed in binary round-trip")
+	assert.Equal(t, csp.SecKey, cspSec.SecKey, "secret key changed in binary round-trip")
+
+	m := common.GetRandomInt(big.NewInt(8685849))
+	label := common.GetRandomInt(big.NewInt(340002223232))
+	u, e, v, err := cspPub.Encrypt(m, label)
+	if err != nil {
+		t.Errorf("Error in Encrypt: %v", err)
+	}
+	p, err := cspSec.Decrypt(NewCiphertext(u, e, v), label)
+	if err != nil {
+		t.Errorf("Error in Decrypt: %v", err)
+	}
+	assert.Equal(t, m, p, "encryption/decryption with deserialized keys does not work correctly")
+
+	_, err = NewCSPaillierFromPubKeyBytes(pubKeyBytes[:len(pubKeyBytes)-1])
+	assert.NotNil(t, err, "truncated public key should not be accepted")
+	_, err = NewCSPaillierFromSecKeyBytes(append(secKeyBytes, 0))
+	assert.NotNil(t, err, "secret key with trailing data should not be accepted")
+}
@@ -0,0 +1,227 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package encryption
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/awsong/crypto/common"
+)
+
+// CSPaillierProver proves the knowledge of the plaintext m and randomness r such that
+// (u, e, v) = Encrypt(m, label). It proves the knowledge of r, m such that
+// u^2 = g^(2*r), e^2 = y1^(2*r) * h^(2*m) and v^2 = (y2 * y3^hash(u, e, L))^(2*r).
+// Unlike the verifiable encryption protocol (see GetProofRandomData), only the public key
+// is needed by the verifier.
+type CSPaillierProver struct {
+	pubKey *CSPaillierPubKey
+	vBase  *big.Int // y2 * y3^hash(u, e, L)
+	r      *big.Int
+	m      *big.Int
+	r1     *big.Int
+	m1     *big.Int
+}
+
+// NewCSPaillierProver returns CSPaillierProver for the ciphertext (u, e, v) which was
+// computed by csp.Encrypt (plaintext and encryption randomness are taken from csp).
+func NewCSPaillierProver(csp *CSPaillier, u, e, label *big.Int) (*CSPaillierProver, error) {
+	if csp.proverEncData == nil {
+		return nil, fmt.Errorf("encryption data is not available, call Encrypt first")
+	}
+
+	return &CSPaillierProver{
+		pubKey: csp.PubKey,
+		vBase:  getVBase(csp.PubKey, u, e, label),
+		r:      csp.proverEncData.R,
+		m:      csp.proverEncData.M,
+	}, nil
+}
+
+// GetProofRandomData returns u1 = g^(2*r1), e1 = y1^(2*r1) * h^(2*m1) and
+// v1 = (y2 * y3^hash(u, e, L))^(2*r1) where r1 and m1 are chosen from
+// (-n * 2^(K+K1-2), n * 2^(K+K1-2)) and (-n * 2^(K+K1), n * 2^(K+K1)).
+func (p *CSPaillierProver) GetProofRandomData() (*big.Int, *big.Int, *big.Int, error) {
+	two := big.NewInt(2)
+	t1 := new(big.Int).Exp(two, big.NewInt(int64(p.pubKey.K+p.pubKey.K1-2)), nil)
+	b1 := new(big.Int).Mul(p.pubKey.N, t1)
+	r1, err := common.GetRandomIntInRange(new(big.Int).Neg(b1), b1)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	t2 := new(big.Int).Exp(two, big.NewInt(int64(p.pubKey.K+p.pubKey.K1)), nil)
+	b2 := new(big.Int).Mul(p.pubKey.N, t2)
+	m1, err := common.GetRandomIntInRange(new(big.Int).Neg(b2), b2)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	p.r1 = r1
+	p.m1 = m1
+
+	n2 := new(big.Int).Mul(p.pubKey.N, p.pubKey.N)
+	twoR1 := new(big.Int).Mul(two, r1)
+	u1 := common.Exponentiate(p.pubKey.G, twoR1, n2)
+
+	h := new(big.Int).Add(p.pubKey.N, big.NewInt(1)) // 1 + n
+	e1 := common.Exponentiate(p.pubKey.Y1, twoR1, n2)
+	e1.Mul(e1, common.Exponentiate(h, new(big.Int).Mul(two, m1), n2))
+	e1.Mod(e1, n2)
+
+	v1 := common.Exponentiate(p.vBase, twoR1, n2)
+	return u1, e1, v1, nil
+}
+
+// GetProofData returns rTilde = r1 - c * r and mTilde = m1 - c * m.
+func (p *CSPaillierProver) GetProofData(c *big.Int) (*big.Int, *big.Int) {
+	rTilde := new(big.Int).Mul(c, p.r)
+	rTilde.Sub(p.r1, rTilde)
+	mTilde := new(big.Int).Mul(c, p.m)
+	mTilde.Sub(p.m1, mTilde)
+	return rTilde, mTilde
+}
+
+type CSPaillierVerifier struct {
+	pubKey    *CSPaillierPubKey
+	u         *big.Int
+	e         *big.Int
+	v         *big.Int
+	vBase     *big.Int
+	u1        *big.Int
+	e1        *big.Int
+	v1        *big.Int
+	challenge *big.Int
+}
+
+func NewCSPaillierVerifier(pubKey *CSPaillierPubKey, u, e, v,
+	label *big.Int) *CSPaillierVerifier {
+	return &CSPaillierVerifier{
+		pubKey: pubKey,
+		u:      u,
+		e:      e,
+		v:      v,
+		vBase:  getVBase(pubKey, u, e, label),
+	}
+}
+
+func (v *CSPaillierVerifier) SetProofRandomData(u1, e1, v1 *big.Int) {
+	v.u1 = u1
+	v.e1 = e1
+	v.v1 = v1
+}
+
+func (v *CSPaillierVerifier) GetChallenge() *big.Int {
+	b := new(big.Int).Exp(big.NewInt(2), big.NewInt(int64(v.pubKey.K)), nil)
+	challenge := common.GetRandomInt(b)
+	v.challenge = challenge
+	return challenge
+}
+
+// SetChallenge is used when Fiat-Shamir is used - when challenge is generated using hash by the prover.
+func (v *CSPaillierVerifier) SetChallenge(challenge *big.Int) {
+	v.challenge = challenge
+}
+
+func (v *CSPaillierVerifier) Verify(rTilde, mTilde *big.Int) bool {
+	n2 := new(big.Int).Mul(v.pubKey.N, v.pubKey.N)
+	twoC := new(big.Int).Mul(v.challenge, big.NewInt(2))
+	twoRTilde := new(big.Int).Mul(rTilde, big.NewInt(2))
+
+	// check if u1 = u^(2*c) * g^(2*rTilde)
+	t := common.Exponentiate(v.u, twoC, n2)
+	t.Mul(t, common.Exponentiate(v.pubKey.G, twoRTilde, n2))
+	t.Mod(t, n2)
+	if !common.ConstantTimeEq(v.u1, t) {
+		return false
+	}
+
+	// check if e1 = e^(2*c) * y1^(2*rTilde) * h^(2*mTilde)
+	h := new(big.Int).Add(v.pubKey.N, big.NewInt(1)) // 1 + n
+	t = common.Exponentiate(v.e, twoC, n2)
+	t.Mul(t, common.Exponentiate(v.pubKey.Y1, twoRTilde, n2))
+	t.Mul(t, common.Exponentiate(h, new(big.Int).Mul(big.NewInt(2), mTilde), n2))
+	t.Mod(t, n2)
+	if !common.ConstantTimeEq(v.e1, t) {
+		return false
+	}
+
+	// check if v1 = v^(2*c) * (y2 * y3^hash(u, e, L))^(2*rTilde)
+	t = common.Exponentiate(v.v, twoC, n2)
+	t.Mul(t, common.Exponentiate(v.vBase, twoRTilde, n2))
+	t.Mod(t, n2)
+	return common.ConstantTimeEq(v.v1, t)
+}
+
+// CSPaillierProof presents all three messages in sigma protocol - useful when challenge
+// is generated by prover via Fiat-Shamir.
+type CSPaillierProof struct {
+	U1        *big.Int
+	E1        *big.Int
+	V1        *big.Int
+	Challenge *big.Int
+	RTilde    *big.Int
+	MTilde    *big.Int
+}
+
+// getFiatShamirChallenge returns hash(u, e, v, L, u1, e1, v1) mod 2^K.
+func getFiatShamirChallenge(pubKey *CSPaillierPubKey, u, e, v, label, u1, e1,
+	v1 *big.Int) *big.Int {
+	b := new(big.Int).Exp(big.NewInt(2), big.NewInt(int64(pubKey.K)), nil)
+	challenge := common.Hash(u, e, v, label, u1, e1, v1)
+	return challenge.Mod(challenge, b)
+}
+
+// ProofOfKnowledge returns a non-interactive (Fiat-Shamir) proof of the knowledge of
+// the plaintext of the ciphertext (u, e, v) which was computed by the last call of csp.Encrypt.
+func (csp *CSPaillier) ProofOfKnowledge(u, e, v, label *big.Int) (*CSPaillierProof, error) {
+	prover, err := NewCSPaillierProver(csp, u, e, label)
+	if err != nil {
+		return nil, err
+	}
+	u1, e1, v1, err := prover.GetProofRandomData()
+	if err != nil {
+		return nil, err
+	}
+	challenge := getFiatShamirChallenge(csp.PubKey, u, e, v, label, u1, e1, v1)
+	rTilde, mTilde := prover.GetProofData(challenge)
+
+	return &CSPaillierProof{
+		U1:        u1,
+		E1:        e1,
+		V1:        v1,
+		Challenge: challenge,
+		RTilde:    rTilde,
+		MTilde:    mTilde,
+	}, nil
+}
+
+// VerifyProofOfKnowledge verifies the proof returned by ProofOfKnowledge. Only the public
+// key is needed.
+func (csp *CSPaillier) VerifyProofOfKnowledge(u, e, v, label *big.Int,
+	proof *CSPaillierProof) bool {
+	challenge := getFiatShamirChallenge(csp.PubKey, u, e, v, label, proof.U1, proof.E1,
+		proof.V1)
+	if !common.ConstantTimeEq(challenge, proof.Challenge) {
+		return false
+	}
+
+	verifier := NewCSPaillierVerifier(csp.PubKey, u, e, v, label)
+	verifier.SetProofRandomData(proof.U1, proof.E1, proof.V1)
+	verifier.SetChallenge(challenge)
+	return verifier.Verify(proof.RTilde, proof.MTilde)
+}
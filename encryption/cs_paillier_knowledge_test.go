@@ -0,0 +1,71 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package encryption
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/awsong/crypto/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCSPaillierPlaintextKnowledge(t *testing.T) {
+	csp, err := NewCSPaillier(SecParams1024())
+	if err != nil {
+		t.Errorf("Error when creating CSPaillier: %v", err)
+	}
+
+	prover := NewCSPaillierFromPubKey(csp.PubKey)
+	m := common.GetRandomInt(big.NewInt(8685849))
+	label := common.GetRandomInt(big.NewInt(340002223232))
+	u, e, v, _ := prover.Encrypt(m, label)
+
+	// interactive
+	p, err := NewCSPaillierProver(prover, u, e, label)
+	if err != nil {
+		t.Errorf("Error in NewCSPaillierProver: %v", err)
+	}
+	verifier := NewCSPaillierVerifier(csp.PubKey, u, e, v, label)
+	u1, e1, v1, err := p.GetProofRandomData()
+	if err != nil {
+		t.Errorf("Error in GetProofRandomData: %v", err)
+	}
+	verifier.SetProofRandomData(u1, e1, v1)
+	c := verifier.GetChallenge()
+	rTilde, mTilde := p.GetProofData(c)
+	assert.Equal(t, true, verifier.Verify(rTilde, mTilde),
+		"CSPaillier plaintext knowledge proof does not work")
+
+	// non-interactive
+	cspPub := NewCSPaillierFromPubKey(csp.PubKey)
+	proof, err := prover.ProofOfKnowledge(u, e, v, label)
+	if err != nil {
+		t.Errorf("Error in ProofOfKnowledge: %v", err)
+	}
+	assert.Equal(t, true, cspPub.VerifyProofOfKnowledge(u, e, v, label, proof),
+		"CSPaillier non-interactive plaintext knowledge proof does not work")
+
+	otherLabel := new(big.Int).Add(label, big.NewInt(1))
+	assert.Equal(t, false, cspPub.VerifyProofOfKnowledge(u, e, v, otherLabel, proof),
+		"proof should not verify for a different label")
+
+	proof.MTilde.Add(proof.MTilde, big.NewInt(1))
+	assert.Equal(t, false, cspPub.VerifyProofOfKnowledge(u, e, v, label, proof),
+		"modified proof should not verify")
+}
@@ -0,0 +1,145 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package encryption
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/awsong/crypto/common"
+)
+
+// OffsetCiphertextProver proves that the ciphertexts ct1 = (u1, e1, v1) of m1 and
+// ct2 = (u2, e2, v2) of m2 satisfy m2 = m1 + k (mod n) for a public k. From the homomorphic
+// property it follows u2 * u1^(-1) = g^delta and e2 * e1^(-1) * h^(-k) = y1^delta for
+// delta = r2 - r1, where r1 and r2 are the encryption randomnesses. Prover proves the knowledge
+// of delta such that U = (u2 * u1^(-1))^2 = g^(2*delta) and
+// E = (e2 * e1^(-1) * h^(-k))^2 = y1^(2*delta) (squares are used as in CSPaillierProver).
+// Only u and e are used, thus the labels of the two ciphertexts can differ.
+type OffsetCiphertextProver struct {
+	pubKey *CSPaillierPubKey
+	delta  *big.Int
+	r      *big.Int
+}
+
+// NewOffsetCiphertextProver returns OffsetCiphertextProver for the ciphertexts computed by
+// csp1.Encrypt and csp2.Encrypt (plaintexts and encryption randomnesses are taken from
+// csp1 and csp2). It returns an error if m2 != m1 + k (mod n).
+func NewOffsetCiphertextProver(csp1, csp2 *CSPaillier, k *big.Int) (*OffsetCiphertextProver,
+	error) {
+	if csp1.proverEncData == nil || csp2.proverEncData == nil {
+		return nil, fmt.Errorf("encryption data is not available, call Encrypt first")
+	}
+	diff := new(big.Int).Sub(csp2.proverEncData.M, csp1.proverEncData.M)
+	diff.Sub(diff, k)
+	if diff.Mod(diff, csp1.PubKey.N).Sign() != 0 {
+		return nil, fmt.Errorf("plaintexts do not differ by k")
+	}
+	return &OffsetCiphertextProver{
+		pubKey: csp1.PubKey,
+		delta:  new(big.Int).Sub(csp2.proverEncData.R, csp1.proverEncData.R),
+	}, nil
+}
+
+// GetProofRandomData returns t1 = g^(2*r) and t2 = y1^(2*r) where r is chosen from
+// (-n * 2^(K+K1-2), n * 2^(K+K1-2)).
+func (p *OffsetCiphertextProver) GetProofRandomData() (*big.Int, *big.Int, error) {
+	t := new(big.Int).Exp(big.NewInt(2), big.NewInt(int64(p.pubKey.K+p.pubKey.K1-2)), nil)
+	b := new(big.Int).Mul(p.pubKey.N, t)
+	r, err := common.GetRandomIntInRange(new(big.Int).Neg(b), b)
+	if err != nil {
+		return nil, nil, err
+	}
+	p.r = r
+
+	n2 := new(big.Int).Mul(p.pubKey.N, p.pubKey.N)
+	twoR := new(big.Int).Mul(big.NewInt(2), r)
+	return common.Exponentiate(p.pubKey.G, twoR, n2),
+		common.Exponentiate(p.pubKey.Y1, twoR, n2), nil
+}
+
+// GetProofData returns z = r - c * delta.
+func (p *OffsetCiphertextProver) GetProofData(c *big.Int) *big.Int {
+	z := new(big.Int).Mul(c, p.delta)
+	return z.Sub(p.r, z)
+}
+
+type OffsetCiphertextVerifier struct {
+	pubKey    *CSPaillierPubKey
+	u         *big.Int // (u2 * u1^(-1))^2
+	e         *big.Int // (e2 * e1^(-1) * h^(-k))^2
+	t1        *big.Int
+	t2        *big.Int
+	challenge *big.Int
+}
+
+func NewOffsetCiphertextVerifier(pubKey *CSPaillierPubKey, ct1, ct2 *Ciphertext,
+	k *big.Int) (*OffsetCiphertextVerifier, error) {
+	n2 := new(big.Int).Mul(pubKey.N, pubKey.N)
+	u1Inv := new(big.Int).ModInverse(ct1.U(), n2)
+	e1Inv := new(big.Int).ModInverse(ct1.E(), n2)
+	if u1Inv == nil || e1Inv == nil {
+		return nil, fmt.Errorf("ciphertext is not valid")
+	}
+	u := new(big.Int).Mul(ct2.U(), u1Inv)
+	u.Exp(u, big.NewInt(2), n2)
+
+	h := new(big.Int).Add(pubKey.N, big.NewInt(1)) // 1 + n
+	e := new(big.Int).Mul(ct2.E(), e1Inv)
+	e.Mul(e, common.Exponentiate(h, new(big.Int).Neg(k), n2))
+	e.Exp(e, big.NewInt(2), n2)
+
+	return &OffsetCiphertextVerifier{
+		pubKey: pubKey,
+		u:      u,
+		e:      e,
+	}, nil
+}
+
+func (v *OffsetCiphertextVerifier) SetProofRandomData(t1, t2 *big.Int) {
+	v.t1 = t1
+	v.t2 = t2
+}
+
+func (v *OffsetCiphertextVerifier) GetChallenge() *big.Int {
+	b := new(big.Int).Exp(big.NewInt(2), big.NewInt(int64(v.pubKey.K)), nil)
+	challenge := common.GetRandomInt(b)
+	v.challenge = challenge
+	return challenge
+}
+
+// SetChallenge is used when Fiat-Shamir is used - when challenge is generated using hash by the prover.
+func (v *OffsetCiphertextVerifier) SetChallenge(challenge *big.Int) {
+	v.challenge = challenge
+}
+
+// Verify checks g^(2*z) * U^c = t1 and y1^(2*z) * E^c = t2.
+func (v *OffsetCiphertextVerifier) Verify(z *big.Int) bool {
+	n2 := new(big.Int).Mul(v.pubKey.N, v.pubKey.N)
+	twoZ := new(big.Int).Mul(big.NewInt(2), z)
+
+	left1 := common.Exponentiate(v.pubKey.G, twoZ, n2)
+	left1.Mul(left1, new(big.Int).Exp(v.u, v.challenge, n2))
+	left1.Mod(left1, n2)
+
+	left2 := common.Exponentiate(v.pubKey.Y1, twoZ, n2)
+	left2.Mul(left2, new(big.Int).Exp(v.e, v.challenge, n2))
+	left2.Mod(left2, n2)
+
+	return common.ConstantTimeEq(left1, v.t1) && common.ConstantTimeEq(left2, v.t2)
+}
@@ -0,0 +1,151 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package encryption
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/awsong/crypto/common"
+)
+
+// CiphertextScalingProver proves that the ciphertexts ct1 = (u1, e1, v1) of m1 and
+// ct2 = (u2, e2, v2) of m2 satisfy m2 = k * m1 (mod n) for a public k. Note that ct1^k
+// cannot be simply published as ct2: CSPaillier is not malleable, v can be recomputed only
+// with the secret key, and ct1^k would reveal the link between the ciphertexts anyway.
+// Instead, ct2 is a fresh encryption of k * m1 and from the homomorphic property it follows
+// u2 * u1^(-k) = g^delta and e2 * e1^(-k) = y1^delta for delta = r2 - k * r1, where r1 and
+// r2 are the encryption randomnesses. Prover proves the knowledge of delta such that
+// U = (u2 * u1^(-k))^2 = g^(2*delta) and E = (e2 * e1^(-k))^2 = y1^(2*delta).
+// Only u and e are used, thus the labels of the two ciphertexts can differ.
+type CiphertextScalingProver struct {
+	pubKey *CSPaillierPubKey
+	k      *big.Int
+	delta  *big.Int
+	r      *big.Int
+}
+
+// NewCiphertextScalingProver returns CiphertextScalingProver for the ciphertexts computed by
+// csp1.Encrypt and csp2.Encrypt (plaintexts and encryption randomnesses are taken from
+// csp1 and csp2). It returns an error if m2 != k * m1 (mod n).
+func NewCiphertextScalingProver(csp1, csp2 *CSPaillier, k *big.Int) (*CiphertextScalingProver,
+	error) {
+	if csp1.proverEncData == nil || csp2.proverEncData == nil {
+		return nil, fmt.Errorf("encryption data is not available, call Encrypt first")
+	}
+	diff := new(big.Int).Mul(k, csp1.proverEncData.M)
+	diff.Sub(csp2.proverEncData.M, diff)
+	if diff.Mod(diff, csp1.PubKey.N).Sign() != 0 {
+		return nil, fmt.Errorf("plaintext is not scaled by k")
+	}
+	delta := new(big.Int).Mul(k, csp1.proverEncData.R)
+	delta.Sub(csp2.proverEncData.R, delta)
+	return &CiphertextScalingProver{
+		pubKey: csp1.PubKey,
+		k:      new(big.Int).Set(k),
+		delta:  delta,
+	}, nil
+}
+
+// GetProofRandomData returns t1 = g^(2*r) and t2 = y1^(2*r) where r is chosen from
+// (-n * (abs(k)+1) * 2^(K+K1-2), n * (abs(k)+1) * 2^(K+K1-2)) - delta grows with k,
+// so the interval grows too.
+func (p *CiphertextScalingProver) GetProofRandomData() (*big.Int, *big.Int, error) {
+	t := new(big.Int).Exp(big.NewInt(2), big.NewInt(int64(p.pubKey.K+p.pubKey.K1-2)), nil)
+	b := new(big.Int).Mul(p.pubKey.N, t)
+	b.Mul(b, new(big.Int).Add(new(big.Int).Abs(p.k), big.NewInt(1)))
+	r, err := common.GetRandomIntInRange(new(big.Int).Neg(b), b)
+	if err != nil {
+		return nil, nil, err
+	}
+	p.r = r
+
+	n2 := new(big.Int).Mul(p.pubKey.N, p.pubKey.N)
+	twoR := new(big.Int).Mul(big.NewInt(2), r)
+	return common.Exponentiate(p.pubKey.G, twoR, n2),
+		common.Exponentiate(p.pubKey.Y1, twoR, n2), nil
+}
+
+// GetProofData returns z = r - c * delta.
+func (p *CiphertextScalingProver) GetProofData(c *big.Int) *big.Int {
+	z := new(big.Int).Mul(c, p.delta)
+	return z.Sub(p.r, z)
+}
+
+type CiphertextScalingVerifier struct {
+	pubKey    *CSPaillierPubKey
+	u         *big.Int // (u2 * u1^(-k))^2
+	e         *big.Int // (e2 * e1^(-k))^2
+	t1        *big.Int
+	t2        *big.Int
+	challenge *big.Int
+}
+
+func NewCiphertextScalingVerifier(pubKey *CSPaillierPubKey, ct1, ct2 *Ciphertext,
+	k *big.Int) (*CiphertextScalingVerifier, error) {
+	n2 := new(big.Int).Mul(pubKey.N, pubKey.N)
+	if new(big.Int).ModInverse(ct1.U(), n2) == nil ||
+		new(big.Int).ModInverse(ct1.E(), n2) == nil {
+		return nil, fmt.Errorf("ciphertext is not valid")
+	}
+	negK := new(big.Int).Neg(k)
+	u := new(big.Int).Mul(ct2.U(), common.Exponentiate(ct1.U(), negK, n2))
+	u.Exp(u, big.NewInt(2), n2)
+
+	e := new(big.Int).Mul(ct2.E(), common.Exponentiate(ct1.E(), negK, n2))
+	e.Exp(e, big.NewInt(2), n2)
+
+	return &CiphertextScalingVerifier{
+		pubKey: pubKey,
+		u:      u,
+		e:      e,
+	}, nil
+}
+
+func (v *CiphertextScalingVerifier) SetProofRandomData(t1, t2 *big.Int) {
+	v.t1 = t1
+	v.t2 = t2
+}
+
+func (v *CiphertextScalingVerifier) GetChallenge() *big.Int {
+	b := new(big.Int).Exp(big.NewInt(2), big.NewInt(int64(v.pubKey.K)), nil)
+	challenge := common.GetRandomInt(b)
+	v.challenge = challenge
+	return challenge
+}
+
+// SetChallenge is used when Fiat-Shamir is used - when challenge is generated using hash by the prover.
+func (v *CiphertextScalingVerifier) SetChallenge(challenge *big.Int) {
+	v.challenge = challenge
+}
+
+// Verify checks g^(2*z) * U^c = t1 and y1^(2*z) * E^c = t2.
+func (v *CiphertextScalingVerifier) Verify(z *big.Int) bool {
+	n2 := new(big.Int).Mul(v.pubKey.N, v.pubKey.N)
+	twoZ := new(big.Int).Mul(big.NewInt(2), z)
+
+	left1 := common.Exponentiate(v.pubKey.G, twoZ, n2)
+	left1.Mul(left1, new(big.Int).Exp(v.u, v.challenge, n2))
+	left1.Mod(left1, n2)
+
+	left2 := common.Exponentiate(v.pubKey.Y1, twoZ, n2)
+	left2.Mul(left2, new(big.Int).Exp(v.e, v.challenge, n2))
+	left2.Mod(left2, n2)
+
+	return common.ConstantTimeEq(left1, v.t1) && common.ConstantTimeEq(left2, v.t2)
+}
@@ -0,0 +1,86 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package encryption
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/awsong/crypto/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func proveScaling(pubKey *CSPaillierPubKey, m, k, verifierK *big.Int) (bool, error) {
+	csp1 := NewCSPaillierFromPubKey(pubKey)
+	csp2 := NewCSPaillierFromPubKey(pubKey)
+	label := big.NewInt(340002223232)
+	u1, e1, v1, err := csp1.Encrypt(m, label)
+	if err != nil {
+		return false, err
+	}
+	u2, e2, v2, err := csp2.Encrypt(new(big.Int).Mul(m, k), label)
+	if err != nil {
+		return false, err
+	}
+
+	prover, err := NewCiphertextScalingProver(csp1, csp2, k)
+	if err != nil {
+		return false, err
+	}
+	verifier, err := NewCiphertextScalingVerifier(pubKey, NewCiphertext(u1, e1, v1),
+		NewCiphertext(u2, e2, v2), verifierK)
+	if err != nil {
+		return false, err
+	}
+	t1, t2, err := prover.GetProofRandomData()
+	if err != nil {
+		return false, err
+	}
+	verifier.SetProofRandomData(t1, t2)
+	z := prover.GetProofData(verifier.GetChallenge())
+	return verifier.Verify(z), nil
+}
+
+func TestCSPaillierCiphertextScaling(t *testing.T) {
+	csp, err := NewCSPaillier(SecParams1024())
+	if err != nil {
+		t.Errorf("Error when creating CSPaillier: %v", err)
+	}
+	m := common.GetRandomInt(big.NewInt(8685849))
+
+	for _, k := range []*big.Int{big.NewInt(17), big.NewInt(1), big.NewInt(98765432123)} {
+		proved, err := proveScaling(csp.PubKey, m, k, k)
+		if err != nil {
+			t.Errorf("Error in scaling proof: %v", err)
+		}
+		assert.Equal(t, true, proved, "ciphertext scaling proof does not work for k = %v", k)
+	}
+
+	proved, err := proveScaling(csp.PubKey, m, big.NewInt(17), big.NewInt(18))
+	if err != nil {
+		t.Errorf("Error in scaling proof: %v", err)
+	}
+	assert.Equal(t, false, proved, "ciphertext scaling proof should fail for a wrong factor")
+
+	csp1 := NewCSPaillierFromPubKey(csp.PubKey)
+	csp2 := NewCSPaillierFromPubKey(csp.PubKey)
+	csp1.Encrypt(m, big.NewInt(1))
+	csp2.Encrypt(new(big.Int).Mul(m, big.NewInt(3)), big.NewInt(1))
+	_, err = NewCiphertextScalingProver(csp1, csp2, big.NewInt(4))
+	assert.NotNil(t, err, "CiphertextScalingProver should fail for a wrong factor")
+}
@@ -26,22 +26,53 @@ import (
 )
 
 func TestCSPaillier(t *testing.T) {
-	csp := NewCSPaillier(
+	csp, err := NewCSPaillier(
 		&CSPaillierSecParams{
 			L:        512,
 			RoLength: 160,
 			K:        158,
 			K1:       158,
 		})
+	if err != nil {
+		t.Errorf("Error when creating CSPaillier: %v", err)
+	}
 
 	cspSec, _ := NewCSPaillierFromSecKey(csp.SecKey)
 	cspPub := NewCSPaillierFromPubKey(csp.PubKey)
 
 	m := common.GetRandomInt(big.NewInt(8685849))
-	label := common.GetRandomInt(big.NewInt(340002223232))
+	// label derived from the session ID and the recipient identity
+	label := new(big.Int).SetBytes([]byte("session 4f1c2a; recipient alice"))
 
-	u, e, v, _ := cspPub.Encrypt(m, label)
-	p, _ := cspSec.Decrypt(u, e, v, label)
+	u, e, v, err := cspPub.Encrypt(m, label)
+	if err != nil {
+		t.Errorf("Error in encryption: %v", err)
+	}
+	p, _ := cspSec.Decrypt(NewCiphertext(u, e, v), label)
 
 	assert.Equal(t, m, p, "Camenisch-Shoup modified Paillier encryption/decryption does not work correctly")
+
+	for _, l := range []*big.Int{nil, big.NewInt(0), big.NewInt(-1)} {
+		_, _, _, err = cspPub.Encrypt(m, l)
+		assert.NotNil(t, err, "encryption should fail for label %v", l)
+	}
+}
+
+func TestCSPaillierSecParamsValidate(t *testing.T) {
+	assert.Nil(t, SecParams1024().Validate(), "SecParams1024 should be valid")
+	assert.Nil(t, SecParams2048().Validate(), "SecParams2048 should be valid")
+
+	invalid := []*CSPaillierSecParams{
+		{L: 0, RoLength: 160, K: 158, K1: 158},
+		{L: 512, RoLength: 40, K: 158, K1: 158},
+		{L: 512, RoLength: 160, K: 40, K1: 158},
+		{L: 512, RoLength: 160, K: 158, K1: 40},
+		{L: 512, RoLength: 160, K: 600, K1: 158},
+		{L: 512, RoLength: 160, K: 158, K1: 600},
+	}
+	for _, secParams := range invalid {
+		assert.NotNil(t, secParams.Validate(), "invalid parameters should not pass validation")
+		_, err := NewCSPaillier(secParams)
+		assert.NotNil(t, err, "NewCSPaillier should fail for invalid parameters")
+	}
 }
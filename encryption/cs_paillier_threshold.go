@@ -0,0 +1,240 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package encryption
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/awsong/crypto/common"
+)
+
+// CSPaillierThreshold is a t-of-n threshold variant of CSPaillier decryption. The secret
+// keys x1, x2, x3 are split using Shamir secret sharing modulo n * n' (the dealer knows n',
+// share holders do not). Each share holder computes a partial decryption and any t partial
+// decryptions can be combined into the plaintext. As share holders do not know n', Lagrange
+// coefficients cannot be computed modulo n * n' - they are multiplied by delta = n_parties!
+// to become integers (as in V. Shoup, Practical Threshold Signatures, EUROCRYPT 2000).
+// Note that partial decryptions are not accompanied by proofs of correctness, thus
+// a cheating share holder can make the decryption fail.
+type CSPaillierThreshold struct {
+	*CSPaillier // holds only the public key, can be used for encryption
+	T           int
+	N           int
+	delta       *big.Int // N!
+}
+
+// CSPaillierShareKey holds the shares of the secret keys x1, x2, x3 of the share holder
+// with the given index (from 1 to N).
+type CSPaillierShareKey struct {
+	Index int
+	X1    *big.Int
+	X2    *big.Int
+	X3    *big.Int
+}
+
+// CSPaillierPartialDecryption is a partial decryption of ciphertext (u, e, v) computed by
+// the share holder with the given index: D1 = u^(4 * x1_i) and
+// D2 = u^(4 * (x2_i + hash(u, e, L) * x3_i)). D2 is needed to check the validity of v
+// (which makes the scheme CCA2 secure).
+type CSPaillierPartialDecryption struct {
+	Index int
+	D1    *big.Int
+	D2    *big.Int
+}
+
+// GenerateThresholdKeys generates CSPaillier keys and splits the secret key into n shares
+// such that any t of them can decrypt. The returned CSPaillierThreshold does not contain
+// the secret key - the dealer (the caller of GenerateThresholdKeys) needs to be trusted
+// to not keep it.
+func GenerateThresholdKeys(t, n int, secParams *CSPaillierSecParams) ([]*CSPaillierShareKey,
+	*CSPaillierThreshold, error) {
+	if t < 1 || t > n {
+		return nil, nil, fmt.Errorf("threshold needs to be in [1, n]")
+	}
+	csp, err := NewCSPaillier(secParams)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// g is of order n', the shares are computed modulo n * n' to work also for u
+	// that is not in the subgroup generated by g (u^4 is of order that divides n * n')
+	mod := new(big.Int).Mul(csp.PubKey.N, csp.n1)
+	shares := make([][]*big.Int, 3)
+	for i, x := range []*big.Int{csp.SecKey.X1, csp.SecKey.X2, csp.SecKey.X3} {
+		polynomial, err := common.NewRandomPolynomial(t-1, mod)
+		if err != nil {
+			return nil, nil, err
+		}
+		polynomial.SetCoefficient(0, new(big.Int).Mod(x, mod))
+		shares[i] = make([]*big.Int, n)
+		for j := 0; j < n; j++ {
+			shares[i][j] = polynomial.GetValue(big.NewInt(int64(j + 1)))
+		}
+	}
+
+	shareKeys := make([]*CSPaillierShareKey, n)
+	for j := 0; j < n; j++ {
+		shareKeys[j] = &CSPaillierShareKey{
+			Index: j + 1,
+			X1:    shares[0][j],
+			X2:    shares[1][j],
+			X3:    shares[2][j],
+		}
+	}
+
+	threshold, err := NewCSPaillierThreshold(csp.PubKey, t, n)
+	if err != nil {
+		return nil, nil, err
+	}
+	return shareKeys, threshold, nil
+}
+
+// NewCSPaillierThreshold returns CSPaillierThreshold for the given public key, where the secret
+// key was split into n shares such that any t of them can decrypt.
+func NewCSPaillierThreshold(pubKey *CSPaillierPubKey, t, n int) (*CSPaillierThreshold, error) {
+	if t < 1 || t > n {
+		return nil, fmt.Errorf("threshold needs to be in [1, n]")
+	}
+	return &CSPaillierThreshold{
+		CSPaillier: NewCSPaillierFromPubKey(pubKey),
+		T:          t,
+		N:          n,
+		delta:      new(big.Int).MulRange(1, int64(n)),
+	}, nil
+}
+
+// PartialDecrypt computes the partial decryption of (u, e, v) using the given share key.
+func (ct *CSPaillierThreshold) PartialDecrypt(shareKey *CSPaillierShareKey,
+	u, e, v, label *big.Int) (*CSPaillierPartialDecryption, error) {
+	if shareKey.Index < 1 || shareKey.Index > ct.N {
+		return nil, fmt.Errorf("share index needs to be in [1, %d]", ct.N)
+	}
+	if err := ct.checkCiphertext(u, e, v); err != nil {
+		return nil, err
+	}
+
+	n2 := new(big.Int).Mul(ct.PubKey.N, ct.PubKey.N)
+	hashNum := common.Hash(u, e, label)
+	// x2_i + hash(u, e, L) * x3_i
+	x := new(big.Int).Mul(hashNum, shareKey.X3)
+	x.Add(x, shareKey.X2)
+
+	u4 := new(big.Int).Exp(u, big.NewInt(4), n2)
+	return &CSPaillierPartialDecryption{
+		Index: shareKey.Index,
+		D1:    new(big.Int).Exp(u4, shareKey.X1, n2),
+		D2:    new(big.Int).Exp(u4, x, n2),
+	}, nil
+}
+
+// CombinePartialDecryptions combines at least T partial decryptions of (u, e, v) computed by
+// different share holders and returns the plaintext. The label is not needed as it is already
+// incorporated into D2. Using Lagrange interpolation in
+// the exponent it computes u^(4 * delta * x1) and u^(4 * delta * (x2 + hash(u, e, L) * x3)).
+// It returns an error if v is not valid (as in Decrypt) or if the partial decryptions
+// are not correct.
+func (ct *CSPaillierThreshold) CombinePartialDecryptions(partials []*CSPaillierPartialDecryption,
+	u, e, v *big.Int) (*big.Int, error) {
+	if len(partials) < ct.T {
+		return nil, fmt.Errorf("at least %d partial decryptions are needed", ct.T)
+	}
+	if err := ct.checkCiphertext(u, e, v); err != nil {
+		return nil, err
+	}
+	partials = partials[:ct.T]
+	indices := make([]int, len(partials))
+	seen := make(map[int]bool)
+	for i, p := range partials {
+		if p.Index < 1 || p.Index > ct.N || seen[p.Index] {
+			return nil, fmt.Errorf("indices need to be distinct and in [1, %d]", ct.N)
+		}
+		seen[p.Index] = true
+		indices[i] = p.Index
+	}
+
+	n2 := new(big.Int).Mul(ct.PubKey.N, ct.PubKey.N)
+	d1 := big.NewInt(1)
+	d2 := big.NewInt(1)
+	for i, p := range partials {
+		lambda := ct.getLagrangeCoefficient(indices, i)
+		d1.Mul(d1, common.Exponentiate(p.D1, lambda, n2))
+		d1.Mod(d1, n2)
+		d2.Mul(d2, common.Exponentiate(p.D2, lambda, n2))
+		d2.Mod(d2, n2)
+	}
+
+	// check whether u^(4 * delta * (x2 + hash(u, e, L) * x3)) = v^(4 * delta)
+	exp := new(big.Int).Mul(big.NewInt(4), ct.delta)
+	if !common.ConstantTimeEq(d2, new(big.Int).Exp(v, exp, n2)) {
+		return nil, fmt.Errorf("CSPaillier threshold decryption failed 1")
+	}
+
+	// e^(4 * delta) * u^(-4 * delta * x1) = h^(4 * delta * m) = 1 + 4 * delta * m * n
+	d1Inv := new(big.Int).ModInverse(d1, n2)
+	if d1Inv == nil {
+		return nil, fmt.Errorf("CSPaillier threshold decryption failed 2")
+	}
+	m1 := new(big.Int).Exp(e, exp, n2)
+	m1.Mul(m1, d1Inv)
+	m1.Mod(m1, n2)
+
+	m1min := new(big.Int).Sub(m1, big.NewInt(1))
+	if !common.ConstantTimeEq(new(big.Int).Mod(m1min, ct.PubKey.N), big.NewInt(0)) {
+		return nil, fmt.Errorf("CSPaillier threshold decryption failed 2")
+	}
+
+	m := new(big.Int).Div(m1min, ct.PubKey.N)
+	expInv := new(big.Int).ModInverse(exp, ct.PubKey.N)
+	m.Mul(m, expInv)
+	return m.Mod(m, ct.PubKey.N), nil
+}
+
+// checkCiphertext checks whether Abs(v) = v and whether u and e are in Z_n^2*.
+func (ct *CSPaillierThreshold) checkCiphertext(u, e, v *big.Int) error {
+	vAbs, err := ct.Abs(v)
+	if err != nil {
+		return err
+	}
+	if v.Cmp(vAbs) != 0 {
+		return fmt.Errorf("v != abs(v)")
+	}
+	n2 := new(big.Int).Mul(ct.PubKey.N, ct.PubKey.N)
+	for _, x := range []*big.Int{u, e} {
+		if x.Sign() <= 0 || x.Cmp(n2) >= 0 {
+			return fmt.Errorf("ciphertext is not valid")
+		}
+	}
+	return nil
+}
+
+// getLagrangeCoefficient returns delta * l_i where l_i is Lagrange coefficient (for
+// evaluation at 0) of the i-th index: delta * prod_{j != i} (indices[j] / (indices[j] - indices[i])).
+// As delta = N!, this is an integer.
+func (ct *CSPaillierThreshold) getLagrangeCoefficient(indices []int, i int) *big.Int {
+	num := new(big.Int).Set(ct.delta)
+	den := big.NewInt(1)
+	for j, idx := range indices {
+		if j == i {
+			continue
+		}
+		num.Mul(num, big.NewInt(int64(idx)))
+		den.Mul(den, big.NewInt(int64(idx-indices[i])))
+	}
+	return num.Quo(num, den)
+}
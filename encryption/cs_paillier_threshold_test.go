@@ -0,0 +1,77 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package encryption
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCSPaillierThreshold(t *testing.T) {
+	shareKeys, threshold, err := GenerateThresholdKeys(3, 5, SecParams1024())
+	if err != nil {
+		t.Errorf("Error in GenerateThresholdKeys: %v", err)
+	}
+	assert.Nil(t, threshold.SecKey, "secret key should not be available")
+
+	m := big.NewInt(8685849)
+	label := big.NewInt(340002223232)
+	u, e, v, err := threshold.Encrypt(m, label)
+	if err != nil {
+		t.Errorf("Error in encryption: %v", err)
+	}
+
+	partials := make([]*CSPaillierPartialDecryption, len(shareKeys))
+	for i, shareKey := range shareKeys {
+		partials[i], err = threshold.PartialDecrypt(shareKey, u, e, v, label)
+		if err != nil {
+			t.Errorf("Error in PartialDecrypt: %v", err)
+		}
+	}
+
+	for _, subset := range [][]int{{0, 1, 2}, {4, 2, 0}, {1, 3, 4}} {
+		chosen := make([]*CSPaillierPartialDecryption, len(subset))
+		for i, j := range subset {
+			chosen[i] = partials[j]
+		}
+		p, err := threshold.CombinePartialDecryptions(chosen, u, e, v)
+		if err != nil {
+			t.Errorf("Error in CombinePartialDecryptions: %v", err)
+		}
+		assert.Equal(t, m, p, "threshold decryption failed for shares %v", subset)
+	}
+
+	_, err = threshold.CombinePartialDecryptions(partials[:2], u, e, v)
+	assert.NotNil(t, err, "two partial decryptions should not be enough")
+
+	wrongLabel := make([]*CSPaillierPartialDecryption, 3)
+	for i := range wrongLabel {
+		wrongLabel[i], err = threshold.PartialDecrypt(shareKeys[i], u, e, v, big.NewInt(1))
+		if err != nil {
+			t.Errorf("Error in PartialDecrypt: %v", err)
+		}
+	}
+	_, err = threshold.CombinePartialDecryptions(wrongLabel, u, e, v)
+	assert.NotNil(t, err, "decryption with a wrong label should fail")
+
+	_, err = threshold.CombinePartialDecryptions([]*CSPaillierPartialDecryption{partials[0],
+		partials[0], partials[1]}, u, e, v)
+	assert.NotNil(t, err, "repeated partial decryptions should not be accepted")
+}
@@ -0,0 +1,104 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package h2g
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"math/big"
+
+	"github.com/awsong/crypto/schnorr"
+)
+
+// Domain separation: the same data hashed in two different protocols gives the same
+// output, which might enable cross-protocol attacks (a value obtained in one protocol is
+// replayed in another). Callers should thus prepend a domain separation prefix which
+// is unique for the protocol (and its version), for example:
+//
+//	h := HashToSchnorrGroup(WithDomain("myprotocol-v1-generator", data), group)
+//
+// WithDomain encodes the length of the prefix, so that the encoding is injective
+// (prefix "ab" with data "c" differs from prefix "a" with data "bc").
+
+// WithDomain returns len(domain) || domain || data where the length is encoded
+// as 4-byte big-endian integer.
+func WithDomain(domain string, data []byte) []byte {
+	out := make([]byte, 4, 4+len(domain)+len(data))
+	binary.BigEndian.PutUint32(out, uint32(len(domain)))
+	out = append(out, domain...)
+	return append(out, data...)
+}
+
+// expand returns nBits bits obtained by concatenating SHA-256(counter || i || data)
+// for i = 0, 1, ... (the bits above nBits are cleared).
+func expand(data []byte, counter uint32, nBits int) *big.Int {
+	var digest []byte
+	var prefix [8]byte
+	binary.BigEndian.PutUint32(prefix[:4], counter)
+	for i := uint32(0); len(digest)*8 < nBits; i++ {
+		binary.BigEndian.PutUint32(prefix[4:], i)
+		h := sha256.New()
+		h.Write(prefix[:])
+		h.Write(data)
+		digest = h.Sum(digest)
+	}
+	x := new(big.Int).SetBytes(digest)
+	return x.Rsh(x, uint(len(digest)*8-nBits))
+}
+
+// HashToBigInt deterministically maps data to an integer from [0, max) using the
+// try-and-increment method: the counter is incremented until SHA-256 based output of
+// max.BitLen() bits is smaller than max. The output is thus uniform in [0, max)
+// (assuming SHA-256 is a random oracle) and in expectation less than two tries are needed.
+// It returns an error if max is not positive.
+func HashToBigInt(data []byte, max *big.Int) (*big.Int, error) {
+	if max.Sign() <= 0 {
+		return nil, fmt.Errorf("max needs to be positive")
+	}
+	for counter := uint32(0); ; counter++ {
+		x := expand(data, counter, max.BitLen())
+		if x.Cmp(max) < 0 {
+			return x, nil
+		}
+	}
+}
+
+// HashToSchnorrGroup deterministically maps data to an element of the group (the subgroup
+// of order q in Z_p*) different from 1, using the try-and-increment method: for
+// counter = 0, 1, ... it computes x from [1, p-1] (see HashToBigInt) and returns
+// x^((p-1)/q) mod p as soon as it is not 1. The discrete logarithm of the returned
+// element is not known to anybody, thus it can be used for example as an independent
+// generator. It returns an error if group.P is smaller than 2.
+func HashToSchnorrGroup(data []byte, group *schnorr.Group) (*big.Int, error) {
+	pMinusOne := new(big.Int).Sub(group.P, big.NewInt(1))
+	cofactor := new(big.Int).Div(pMinusOne, group.Q)
+	one := big.NewInt(1)
+	for counter := uint32(0); ; counter++ {
+		x, err := HashToBigInt(WithDomain("h2g-schnorr", expand(data, counter, 256).Bytes()),
+			pMinusOne)
+		if err != nil {
+			return nil, err
+		}
+		x.Add(x, one)
+		el := new(big.Int).Exp(x, cofactor, group.P)
+		if el.Cmp(one) != 0 {
+			return el, nil
+		}
+	}
+}
@@ -0,0 +1,71 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package h2g
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/awsong/crypto/schnorr"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHashToBigInt(t *testing.T) {
+	max := big.NewInt(1000)
+	for i := 0; i < 100; i++ {
+		x, err := HashToBigInt([]byte{byte(i)}, max)
+		if err != nil {
+			t.Errorf("error in HashToBigInt: %v", err)
+		}
+		assert.Equal(t, true, x.Sign() >= 0 && x.Cmp(max) < 0, "value should be in [0, max)")
+	}
+	x1, _ := HashToBigInt([]byte("data"), max)
+	x2, _ := HashToBigInt([]byte("data"), max)
+	assert.Equal(t, x1, x2, "HashToBigInt should be deterministic")
+
+	for _, m := range []*big.Int{big.NewInt(0), big.NewInt(-5)} {
+		_, err := HashToBigInt([]byte("data"), m)
+		assert.NotNil(t, err, "HashToBigInt should fail for max = %v", m)
+	}
+}
+
+func TestHashToSchnorrGroup(t *testing.T) {
+	group, err := schnorr.NewGroup(160)
+	if err != nil {
+		t.Errorf("error when creating Schnorr group: %v", err)
+	}
+
+	data := []byte("some data")
+	el, err := HashToSchnorrGroup(data, group)
+	if err != nil {
+		t.Errorf("error in HashToSchnorrGroup: %v", err)
+	}
+	assert.Equal(t, true, group.IsElementInGroup(el), "element should be in the group")
+	assert.NotEqual(t, big.NewInt(1), el, "element should not be 1")
+	el1, _ := HashToSchnorrGroup(data, group)
+	assert.Equal(t, el, el1, "HashToSchnorrGroup should be deterministic")
+
+	other, _ := HashToSchnorrGroup(WithDomain("other protocol", data), group)
+	assert.NotEqual(t, el, other, "domain separation prefix should change the element")
+	assert.NotEqual(t, WithDomain("ab", []byte("c")), WithDomain("a", []byte("bc")),
+		"domain separation encoding should be injective")
+
+	_, err = HashToSchnorrGroup(data, schnorr.NewGroupFromParams(big.NewInt(1), big.NewInt(1),
+		big.NewInt(1)))
+	assert.NotNil(t, err, "HashToSchnorrGroup should fail for p = 1")
+}
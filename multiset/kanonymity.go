@@ -0,0 +1,219 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package multiset
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/awsong/crypto/common"
+	"github.com/awsong/crypto/pedersen"
+)
+
+// KAnonymityProver proves that the value v committed in a Pedersen commitment cv = g^v * h^s
+// appears at least k times in the multiset {a_1, ..., a_n} where each element is committed
+// as c_j = g^a_j * h^r_j. Neither the elements nor the positions of v are revealed.
+// If a_j = v, then D_j = c_j / cv = h^(r_j - s) and prover knows log_h(D_j). Prover proves
+// the knowledge of log_h(D_j) for at least k out of n indices j using the threshold
+// partial knowledge proof by Cramer, Damgard and Schoenmakers: for indices where the
+// witness is not known, the transcript is simulated with a chosen challenge c_j. The challenges
+// c_1, ..., c_n together with the verifier's challenge c = f(0) need to lie on a polynomial
+// f of degree n-k over Z_q, thus prover can choose at most n-k challenges and needs to know
+// the witnesses for at least k indices.
+type KAnonymityProver struct {
+	params     *pedersen.Params
+	ds         []*big.Int // D_j = c_j / cv
+	known      []bool     // whether log_h(D_j) is used as a witness
+	witnesses  []*big.Int // log_h(D_j) for known indices
+	randomVals []*big.Int // w_j for known indices, z_j for the simulated ones
+	challenges []*big.Int // c_j for the simulated indices
+	k          int
+}
+
+// NewKAnonymityProver returns KAnonymityProver where elements hold the commitments to the
+// multiset elements and value holds the commitment to the value. It returns an error if
+// the value appears less than k times.
+func NewKAnonymityProver(params *pedersen.Params, elements []*pedersen.Committer,
+	value *pedersen.Committer, k int) (*KAnonymityProver, error) {
+	if k < 1 || k > len(elements) {
+		return nil, fmt.Errorf("k needs to be in [1, n]")
+	}
+	group := params.Group
+	v, s := value.GetDecommitMsg()
+
+	n := len(elements)
+	ds := make([]*big.Int, n)
+	known := make([]bool, n)
+	witnesses := make([]*big.Int, n)
+	nKnown := 0
+	cvInv := group.Inv(value.Commitment)
+	for j, el := range elements {
+		ds[j] = group.Mul(el.Commitment, cvInv)
+		a, r := el.GetDecommitMsg()
+		// exactly k witnesses are used, the other statements are simulated
+		if a.Cmp(v) == 0 && nKnown < k {
+			known[j] = true
+			x := new(big.Int).Sub(r, s)
+			witnesses[j] = x.Mod(x, group.Q)
+			nKnown++
+		}
+	}
+	if nKnown < k {
+		return nil, fmt.Errorf("value appears less than k times in the multiset")
+	}
+
+	return &KAnonymityProver{
+		params:    params,
+		ds:        ds,
+		known:     known,
+		witnesses: witnesses,
+		k:         k,
+	}, nil
+}
+
+// GetProofRandomData returns t_j = h^w_j for known indices and t_j = h^z_j * D_j^(-c_j)
+// for the simulated ones (where z_j and c_j are chosen randomly).
+func (p *KAnonymityProver) GetProofRandomData() []*big.Int {
+	group := p.params.Group
+	n := len(p.ds)
+	p.randomVals = make([]*big.Int, n)
+	p.challenges = make([]*big.Int, n)
+	proofRandomData := make([]*big.Int, n)
+	for j := range p.ds {
+		p.randomVals[j] = common.GetRandomInt(group.Q)
+		if p.known[j] {
+			proofRandomData[j] = group.Exp(p.params.H, p.randomVals[j])
+		} else {
+			p.challenges[j] = common.GetRandomInt(group.Q)
+			cNeg := new(big.Int).Sub(group.Q, p.challenges[j])
+			proofRandomData[j] = group.Mul(group.Exp(p.params.H, p.randomVals[j]),
+				group.Exp(p.ds[j], cNeg))
+		}
+	}
+	return proofRandomData
+}
+
+// GetProofData returns challenges c_1, ..., c_n and responses z_1, ..., z_n. The challenges
+// for known indices are computed as values of the polynomial f of degree n-k which
+// is given by f(0) = challenge and f(j) = c_j for simulated indices.
+func (p *KAnonymityProver) GetProofData(challenge *big.Int) ([]*big.Int, []*big.Int) {
+	group := p.params.Group
+	points := map[*big.Int]*big.Int{big.NewInt(0): challenge}
+	for j, c := range p.challenges {
+		if !p.known[j] {
+			points[big.NewInt(int64(j+1))] = c
+		}
+	}
+
+	n := len(p.ds)
+	challenges := make([]*big.Int, n)
+	proofData := make([]*big.Int, n)
+	for j := range p.ds {
+		if p.known[j] {
+			c := common.LagrangeInterpolation(big.NewInt(int64(j+1)), points, group.Q)
+			// z_j = w_j + c_j * x_j mod q
+			z := new(big.Int).Mul(c, p.witnesses[j])
+			z.Add(z, p.randomVals[j])
+			z.Mod(z, group.Q)
+			challenges[j] = c
+			proofData[j] = z
+		} else {
+			challenges[j] = p.challenges[j]
+			proofData[j] = p.randomVals[j]
+		}
+	}
+	return challenges, proofData
+}
+
+type KAnonymityVerifier struct {
+	params          *pedersen.Params
+	ds              []*big.Int
+	k               int
+	proofRandomData []*big.Int
+	challenge       *big.Int
+}
+
+func NewKAnonymityVerifier(params *pedersen.Params, elements []*big.Int,
+	valueCommitment *big.Int, k int) (*KAnonymityVerifier, error) {
+	if k < 1 || k > len(elements) {
+		return nil, fmt.Errorf("k needs to be in [1, n]")
+	}
+	group := params.Group
+	cvInv := group.Inv(valueCommitment)
+	ds := make([]*big.Int, len(elements))
+	for j, c := range elements {
+		ds[j] = group.Mul(c, cvInv)
+	}
+
+	return &KAnonymityVerifier{
+		params: params,
+		ds:     ds,
+		k:      k,
+	}, nil
+}
+
+func (v *KAnonymityVerifier) SetProofRandomData(proofRandomData []*big.Int) error {
+	if len(proofRandomData) != len(v.ds) {
+		return fmt.Errorf("the length of proofRandomData is not correct")
+	}
+	v.proofRandomData = proofRandomData
+	return nil
+}
+
+func (v *KAnonymityVerifier) GetChallenge() *big.Int {
+	challenge := common.GetRandomInt(v.params.Group.Q)
+	v.challenge = challenge
+	return challenge
+}
+
+// SetChallenge is used when Fiat-Shamir is used - when challenge is generated using hash by the prover.
+func (v *KAnonymityVerifier) SetChallenge(challenge *big.Int) {
+	v.challenge = challenge
+}
+
+// Verify checks that (0, challenge), (1, c_1), ..., (n, c_n) lie on a polynomial
+// of degree n-k and that h^z_j = t_j * D_j^c_j for each j.
+func (v *KAnonymityVerifier) Verify(challenges, proofData []*big.Int) bool {
+	group := v.params.Group
+	n := len(v.ds)
+	if len(challenges) != n || len(proofData) != n {
+		return false
+	}
+
+	// polynomial is determined by f(0) and f(1), ..., f(n-k), the rest of the
+	// points need to lie on it
+	points := map[*big.Int]*big.Int{big.NewInt(0): v.challenge}
+	for j := 0; j < n-v.k; j++ {
+		points[big.NewInt(int64(j+1))] = challenges[j]
+	}
+	for j := n - v.k; j < n; j++ {
+		c := common.LagrangeInterpolation(big.NewInt(int64(j+1)), points, group.Q)
+		if c.Cmp(new(big.Int).Mod(challenges[j], group.Q)) != 0 {
+			return false
+		}
+	}
+
+	for j, d := range v.ds {
+		left := group.Exp(v.params.H, proofData[j])
+		right := group.Mul(v.proofRandomData[j], group.Exp(d, challenges[j]))
+		if left.Cmp(right) != 0 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,122 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package multiset
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/awsong/crypto/pedersen"
+	"github.com/stretchr/testify/assert"
+)
+
+func commitMultiset(params *pedersen.Params, elements []int64) ([]*pedersen.Committer,
+	[]*big.Int, error) {
+	committers := make([]*pedersen.Committer, len(elements))
+	commitments := make([]*big.Int, len(elements))
+	for i, el := range elements {
+		committer := pedersen.NewCommitter(params)
+		c, err := committer.GetCommitMsg(big.NewInt(el))
+		if err != nil {
+			return nil, nil, err
+		}
+		committers[i] = committer
+		commitments[i] = c
+	}
+	return committers, commitments, nil
+}
+
+func proveKAnonymity(params *pedersen.Params, multiset []int64, value int64,
+	k int) (bool, error) {
+	committers, commitments, err := commitMultiset(params, multiset)
+	if err != nil {
+		return false, err
+	}
+	valueCommitter := pedersen.NewCommitter(params)
+	valueCommitment, err := valueCommitter.GetCommitMsg(big.NewInt(value))
+	if err != nil {
+		return false, err
+	}
+
+	prover, err := NewKAnonymityProver(params, committers, valueCommitter, k)
+	if err != nil {
+		return false, err
+	}
+	verifier, err := NewKAnonymityVerifier(params, commitments, valueCommitment, k)
+	if err != nil {
+		return false, err
+	}
+
+	err = verifier.SetProofRandomData(prover.GetProofRandomData())
+	if err != nil {
+		return false, err
+	}
+	challenge := verifier.GetChallenge()
+	return verifier.Verify(prover.GetProofData(challenge)), nil
+}
+
+func TestKAnonymity(t *testing.T) {
+	params, err := pedersen.GenerateParams(256)
+	if err != nil {
+		t.Errorf("error when generating Pedersen params: %v", err)
+	}
+
+	// value 7 appears 5 times
+	multiset := []int64{7, 3, 7, 7, 11, 7, 2, 7}
+	proved, err := proveKAnonymity(params, multiset, 7, 3)
+	if err != nil {
+		t.Errorf("error in k-anonymity proof: %v", err)
+	}
+	assert.Equal(t, true, proved, "k-anonymity proof failed")
+
+	// value 3 appears only once
+	_, err = proveKAnonymity(params, multiset, 3, 3)
+	assert.NotNil(t, err, "prover should fail when value appears less than k times")
+}
+
+func TestKAnonymityCheatingProver(t *testing.T) {
+	params, err := pedersen.GenerateParams(256)
+	if err != nil {
+		t.Errorf("error when generating Pedersen params: %v", err)
+	}
+
+	// value 7 appears 2 times, prover creates a proof for k=2, verifier requires k=3
+	multiset := []int64{7, 3, 7, 5, 11}
+	committers, commitments, err := commitMultiset(params, multiset)
+	if err != nil {
+		t.Errorf("error when committing: %v", err)
+	}
+	valueCommitter := pedersen.NewCommitter(params)
+	valueCommitment, _ := valueCommitter.GetCommitMsg(big.NewInt(7))
+
+	prover, err := NewKAnonymityProver(params, committers, valueCommitter, 2)
+	if err != nil {
+		t.Errorf("error in NewKAnonymityProver: %v", err)
+	}
+	verifier, err := NewKAnonymityVerifier(params, commitments, valueCommitment, 3)
+	if err != nil {
+		t.Errorf("error in NewKAnonymityVerifier: %v", err)
+	}
+	err = verifier.SetProofRandomData(prover.GetProofRandomData())
+	if err != nil {
+		t.Errorf("error in SetProofRandomData: %v", err)
+	}
+	challenge := verifier.GetChallenge()
+	proved := verifier.Verify(prover.GetProofData(challenge))
+	assert.Equal(t, false, proved, "k-anonymity proof should fail for k=3")
+}
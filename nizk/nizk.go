@@ -0,0 +1,81 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package nizk
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/awsong/crypto/sigma"
+)
+
+// Transcript is a non-interactive proof obtained from a sigma protocol using Fiat-Shamir
+// heuristic. It can be serialized to JSON using encoding/json.
+type Transcript struct {
+	ProofRandomData []byte   `json:"proofRandomData"`
+	Challenge       *big.Int `json:"challenge"`
+	ProofData       []byte   `json:"proofData"`
+}
+
+// Prove runs the prover side of proto where the challenge is computed as
+// hash(proofRandomData) (interpreted as a big-endian integer) instead of being chosen by
+// the verifier. Note that sigma.Protocol does not expose the statement (for example y in
+// SchnorrProtocol), thus it is not hashed - the statement (and any other context) should be
+// bound by the hash function, for example:
+//
+//	hash := func(data []byte) []byte {
+//		h := sha256.Sum256(append(y.Bytes(), data...))
+//		return h[:]
+//	}
+//
+// The challenge has the bit length of the hash output, thus the hash output should not be
+// longer than the challenge space of proto (for SchnorrProtocol challenges bigger than
+// the group order are accepted as well).
+func Prove(proto sigma.Protocol, hash func([]byte) []byte) (*Transcript, error) {
+	if hash == nil {
+		return nil, fmt.Errorf("hash function must not be nil")
+	}
+	proofRandomData := proto.GetProofRandomData()
+	challenge := getChallenge(proofRandomData, hash)
+	return &Transcript{
+		ProofRandomData: proofRandomData,
+		Challenge:       challenge,
+		ProofData:       proto.GetProofData(challenge),
+	}, nil
+}
+
+// Verify verifies the transcript obtained by Prove: it checks that the challenge was
+// computed as hash(proofRandomData) and that proto accepts the transcript.
+func Verify(proto sigma.Protocol, transcript *Transcript, hash func([]byte) []byte) bool {
+	if hash == nil || transcript == nil || transcript.Challenge == nil {
+		return false
+	}
+	challenge := getChallenge(transcript.ProofRandomData, hash)
+	if challenge.Cmp(transcript.Challenge) != 0 {
+		return false
+	}
+	if err := proto.SetProofRandomData(transcript.ProofRandomData); err != nil {
+		return false
+	}
+	proto.SetChallenge(challenge)
+	return proto.Verify(transcript.ProofData)
+}
+
+func getChallenge(proofRandomData []byte, hash func([]byte) []byte) *big.Int {
+	return new(big.Int).SetBytes(hash(proofRandomData))
+}
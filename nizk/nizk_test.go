@@ -0,0 +1,106 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package nizk
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/awsong/crypto/common"
+	"github.com/awsong/crypto/schnorr"
+	"github.com/awsong/crypto/sigma"
+	"github.com/stretchr/testify/assert"
+)
+
+// getHash returns SHA-256 of y || data truncated to 248 bits (the challenge space of
+// SchnorrProtocol over a group with 256-bit order is [0, 2^255)).
+func getHash(y *big.Int) func([]byte) []byte {
+	return func(data []byte) []byte {
+		h := sha256.Sum256(append(y.Bytes(), data...))
+		return h[1:]
+	}
+}
+
+func TestNIZK(t *testing.T) {
+	group, err := schnorr.NewGroup(256)
+	if err != nil {
+		t.Errorf("error when creating group: %v", err)
+	}
+	x := common.GetRandomInt(group.Q)
+	y := group.Exp(group.G, x)
+	hash := getHash(y)
+
+	prover, err := sigma.NewSchnorrProver(group, x, y)
+	if err != nil {
+		t.Errorf("error when creating SchnorrProtocol: %v", err)
+	}
+	transcript, err := Prove(prover, hash)
+	if err != nil {
+		t.Errorf("error in Prove: %v", err)
+	}
+
+	data, err := json.Marshal(transcript)
+	if err != nil {
+		t.Errorf("error when marshaling transcript: %v", err)
+	}
+	decoded := &Transcript{}
+	if err := json.Unmarshal(data, decoded); err != nil {
+		t.Errorf("error when unmarshaling transcript: %v", err)
+	}
+	assert.Equal(t, transcript, decoded, "transcript changed after JSON round trip")
+
+	verified := Verify(sigma.NewSchnorrVerifier(group, y), decoded, hash)
+	assert.Equal(t, true, verified, "non-interactive Schnorr proof failed")
+
+	// the challenge was not computed by hash
+	decoded.Challenge.Add(decoded.Challenge, big.NewInt(1))
+	verified = Verify(sigma.NewSchnorrVerifier(group, y), decoded, hash)
+	assert.Equal(t, false, verified, "proof with a wrong challenge should not be accepted")
+
+	// the proof for y does not verify for a different statement
+	yOther := group.Exp(group.G, new(big.Int).Add(x, big.NewInt(1)))
+	verified = Verify(sigma.NewSchnorrVerifier(group, yOther), transcript, getHash(yOther))
+	assert.Equal(t, false, verified, "proof should not be accepted for a different statement")
+
+	_, err = Prove(prover, nil)
+	assert.NotNil(t, err, "Prove should fail without a hash function")
+}
+
+func TestNIZKComposed(t *testing.T) {
+	group, err := schnorr.NewGroup(256)
+	if err != nil {
+		t.Errorf("error when creating group: %v", err)
+	}
+	x1 := common.GetRandomInt(group.Q)
+	y1 := group.Exp(group.G, x1)
+	y2 := group.Exp(group.G, common.GetRandomInt(group.Q))
+	hash := getHash(new(big.Int).Mul(y1, y2))
+
+	// the prover knows only x1
+	p1, _ := sigma.NewSchnorrProver(group, x1, y1)
+	prover := sigma.OR(0, p1, sigma.NewSchnorrVerifier(group, y2))
+	transcript, err := Prove(prover, hash)
+	if err != nil {
+		t.Errorf("error in Prove: %v", err)
+	}
+
+	verifier := sigma.OR(0, sigma.NewSchnorrVerifier(group, y1), sigma.NewSchnorrVerifier(group, y2))
+	assert.Equal(t, true, Verify(verifier, transcript, hash), "non-interactive OR proof failed")
+}
@@ -56,8 +56,11 @@ func GenerateParams(bitLengthGroupOrder int) (*Params, error) {
 	if err != nil {
 		return nil, fmt.Errorf("error when creating SchnorrGroup: %s", err)
 	}
-	a := common.GetRandomInt(group.Q)
-	return NewParams(group, group.Exp(group.G, a), a), nil
+	h, a, err := group.Sample()
+	if err != nil {
+		return nil, err
+	}
+	return NewParams(group, h, a), nil
 }
 
 type Committer struct {
@@ -83,6 +86,16 @@ func (c *Committer) GetCommitMsg(val *big.Int) (*big.Int, error) {
 
 	// c = g^x * h^r
 	r := common.GetRandomInt(c.Params.Group.Q)
+	return c.GetCommitMsgWithGivenR(val, r)
+}
+
+// GetCommitMsgWithGivenR is like GetCommitMsg, but the randomness r is given
+// (it needs to be in Z_q).
+func (c *Committer) GetCommitMsgWithGivenR(val, r *big.Int) (*big.Int, error) {
+	if val.Cmp(c.Params.Group.Q) == 1 || val.Cmp(big.NewInt(0)) == -1 {
+		err := fmt.Errorf("committed value needs to be in Z_q (order of a base point)")
+		return nil, err
+	}
 
 	c.r = r
 	c.committedValue = val
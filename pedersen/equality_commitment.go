@@ -0,0 +1,83 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package pedersen
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/awsong/crypto/schnorr"
+)
+
+// EqualityProver proves that two commitments c1 = g^x * h^r1 and c2 = g^x * h^r2
+// (created with the same Params) hide the same value x. It holds c1 * c2^(-1) = h^(r1 - r2),
+// thus prover proves the knowledge of log_h(c1 * c2^(-1)) (Schnorr proof).
+type EqualityProver struct {
+	*schnorr.Prover
+}
+
+func NewEqualityProver(committer1, committer2 *Committer) (*EqualityProver, error) {
+	if committer1.Params != committer2.Params {
+		return nil, fmt.Errorf("commitments need to be created with the same params")
+	}
+	x1, r1 := committer1.GetDecommitMsg()
+	x2, r2 := committer2.GetDecommitMsg()
+	if x1.Cmp(x2) != 0 {
+		return nil, fmt.Errorf("committed values are not equal")
+	}
+	params := committer1.Params
+	r := new(big.Int).Sub(r1, r2)
+	r.Mod(r, params.Group.Q)
+	y := getQuotient(params.Group, committer1.Commitment, committer2.Commitment)
+	prover, err := schnorr.NewProver(params.Group, []*big.Int{r}, []*big.Int{params.H}, y)
+	if err != nil {
+		return nil, err
+	}
+	return &EqualityProver{
+		Prover: prover,
+	}, nil
+}
+
+// getQuotient returns c1 * c2^(-1).
+func getQuotient(group *schnorr.Group, c1, c2 *big.Int) *big.Int {
+	return group.Div(c1, c2)
+}
+
+type EqualityVerifier struct {
+	*schnorr.Verifier
+	h *big.Int
+	y *big.Int
+}
+
+// NewEqualityVerifier returns EqualityVerifier for the commitments stored in receiver1
+// and receiver2.
+func NewEqualityVerifier(receiver1, receiver2 *Receiver) (*EqualityVerifier, error) {
+	if receiver1.Params != receiver2.Params {
+		return nil, fmt.Errorf("commitments need to be created with the same params")
+	}
+	params := receiver1.Params
+	return &EqualityVerifier{
+		Verifier: schnorr.NewVerifier(params.Group),
+		h:        params.H,
+		y:        getQuotient(params.Group, receiver1.commitment, receiver2.commitment),
+	}, nil
+}
+
+func (v *EqualityVerifier) SetProofRandomData(proofRandomData *big.Int) {
+	v.Verifier.SetProofRandomData(proofRandomData, []*big.Int{v.h}, v.y)
+}
@@ -0,0 +1,84 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package pedersen
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/awsong/crypto/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func proveEquality(receiver1, receiver2 *Receiver, committer1, committer2 *Committer) (bool,
+	error) {
+	prover, err := NewEqualityProver(committer1, committer2)
+	if err != nil {
+		return false, err
+	}
+	verifier, err := NewEqualityVerifier(receiver1, receiver2)
+	if err != nil {
+		return false, err
+	}
+	verifier.SetProofRandomData(prover.GetProofRandomData())
+	challenge := verifier.GetChallenge()
+	return verifier.Verify(prover.GetProofData(challenge)), nil
+}
+
+func TestPedersenEquality(t *testing.T) {
+	params, err := GenerateParams(256)
+	if err != nil {
+		t.Errorf("error when generating Pedersen params: %v", err)
+	}
+	x := common.GetRandomInt(params.Group.Q)
+	committer1, committer2 := NewCommitter(params), NewCommitter(params)
+	receiver1, receiver2 := NewReceiverFromParams(params), NewReceiverFromParams(params)
+	c1, err := committer1.GetCommitMsg(x)
+	if err != nil {
+		t.Errorf("error in GetCommitMsg: %v", err)
+	}
+	c2, err := committer2.GetCommitMsg(x)
+	if err != nil {
+		t.Errorf("error in GetCommitMsg: %v", err)
+	}
+	receiver1.SetCommitment(c1)
+	receiver2.SetCommitment(c2)
+
+	proved, err := proveEquality(receiver1, receiver2, committer1, committer2)
+	if err != nil {
+		t.Errorf("error in Pedersen equality proof: %v", err)
+	}
+	assert.Equal(t, true, proved, "Pedersen equality proof failed")
+
+	// different values
+	committer3 := NewCommitter(params)
+	c3, err := committer3.GetCommitMsg(new(big.Int).Sub(x, big.NewInt(1)))
+	if err != nil {
+		t.Errorf("error in GetCommitMsg: %v", err)
+	}
+	_, err = NewEqualityProver(committer1, committer3)
+	assert.NotNil(t, err, "EqualityProver should fail for different values")
+
+	receiver3 := NewReceiverFromParams(params)
+	receiver3.SetCommitment(c3)
+	proved, err = proveEquality(receiver1, receiver3, committer1, committer2)
+	if err != nil {
+		t.Errorf("error in Pedersen equality proof: %v", err)
+	}
+	assert.Equal(t, false, proved, "Pedersen equality proof should fail for different values")
+}
@@ -0,0 +1,221 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package pedersen
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/awsong/crypto/common"
+	"github.com/awsong/crypto/schnorr"
+)
+
+// NBitRangeProver proves that the commitment c = g^x * h^r hides x such that 0 <= x < 2^n
+// (positivity for n-bit values). It uses binary decomposition x = b_0 + 2*b_1 + ... +
+// 2^(n-1)*b_(n-1): prover commits to each bit c_i = g^b_i * h^r_i where randomnesses are
+// chosen such that r = r_0 + 2*r_1 + ... + 2^(n-1)*r_(n-1) mod q. Verifier checks that
+// c = c_0 * c_1^2 * ... * c_(n-1)^(2^(n-1)), and for each bit prover proves (OR proof
+// by Cramer, Damgard and Schoenmakers) that it knows log_h(c_i) or log_h(c_i * g^(-1)),
+// that is b_i is 0 or 1. All bit proofs use the same challenge.
+// Note that this proof is linear in n, it is not based on inner product arguments.
+type NBitRangeProver struct {
+	group          *schnorr.Group
+	h              *big.Int
+	bits           []int
+	rs             []*big.Int
+	bitCommitments []*big.Int
+	randomVals     []*big.Int
+	simChallenges  []*big.Int
+	simProofData   []*big.Int
+}
+
+func NewNBitRangeProver(committer *Committer, x *big.Int, n int) (*NBitRangeProver, error) {
+	group := committer.Params.Group
+	if n < 1 || n >= group.Q.BitLen() {
+		return nil, fmt.Errorf("n needs to be in [1, %d)", group.Q.BitLen())
+	}
+	bound := new(big.Int).Lsh(big.NewInt(1), uint(n))
+	if x.Sign() < 0 || x.Cmp(bound) != -1 {
+		return nil, fmt.Errorf("x needs to be in [0, 2^n)")
+	}
+	committedValue, r := committer.GetDecommitMsg()
+	if committedValue.Cmp(x) != 0 {
+		return nil, fmt.Errorf("x is not the committed value")
+	}
+
+	// r_1, ..., r_(n-1) are chosen randomly, r_0 = r - (2*r_1 + ... + 2^(n-1)*r_(n-1)) mod q
+	rs := make([]*big.Int, n)
+	r0 := new(big.Int).Set(r)
+	for i := 1; i < n; i++ {
+		rs[i] = common.GetRandomInt(group.Q)
+		r0.Sub(r0, new(big.Int).Lsh(rs[i], uint(i)))
+	}
+	rs[0] = r0.Mod(r0, group.Q)
+
+	bits := make([]int, n)
+	bitCommitments := make([]*big.Int, n)
+	for i := 0; i < n; i++ {
+		bits[i] = int(x.Bit(i))
+		bitCommitter := NewCommitter(committer.Params)
+		commitment, err := bitCommitter.GetCommitMsgWithGivenR(big.NewInt(int64(bits[i])), rs[i])
+		if err != nil {
+			return nil, err
+		}
+		bitCommitments[i] = commitment
+	}
+
+	return &NBitRangeProver{
+		group:          group,
+		h:              committer.Params.H,
+		bits:           bits,
+		rs:             rs,
+		bitCommitments: bitCommitments,
+	}, nil
+}
+
+// GetVerifierInitializationData returns data that are needed by NBitRangeVerifier
+// and are known only after the initialization of NBitRangeProver: commitments to bits.
+func (p *NBitRangeProver) GetVerifierInitializationData() []*big.Int {
+	return p.bitCommitments
+}
+
+// getBitStatements returns c_i and c_i * g^(-1) - if c_i is a commitment to 0 or 1,
+// one of them is a power of h.
+func getBitStatements(group *schnorr.Group, c *big.Int) [2]*big.Int {
+	return [2]*big.Int{c, group.Div(c, group.G)}
+}
+
+// GetProofRandomData returns (t_i0, t_i1) for each bit. The proof for the branch
+// that does not hold is simulated: t = h^z * y^(-c) for random c, z.
+func (p *NBitRangeProver) GetProofRandomData() []*big.Int {
+	n := len(p.bits)
+	p.randomVals = make([]*big.Int, n)
+	p.simChallenges = make([]*big.Int, n)
+	p.simProofData = make([]*big.Int, n)
+	proofRandomData := make([]*big.Int, 2*n)
+	for i, bit := range p.bits {
+		statements := getBitStatements(p.group, p.bitCommitments[i])
+		p.randomVals[i] = common.GetRandomInt(p.group.Q)
+		proofRandomData[2*i+bit] = p.group.Exp(p.h, p.randomVals[i])
+
+		p.simChallenges[i] = common.GetRandomInt(p.group.Q)
+		p.simProofData[i] = common.GetRandomInt(p.group.Q)
+		t := p.group.Exp(p.h, p.simProofData[i])
+		t = p.group.Div(t, p.group.Exp(statements[1-bit], p.simChallenges[i]))
+		proofRandomData[2*i+1-bit] = t
+	}
+	return proofRandomData
+}
+
+// GetProofData returns (c_i0, z_i0, z_i1) for each bit, c_i1 = challenge - c_i0 mod q.
+func (p *NBitRangeProver) GetProofData(challenge *big.Int) []*big.Int {
+	proofData := make([]*big.Int, 3*len(p.bits))
+	for i, bit := range p.bits {
+		c := new(big.Int).Sub(challenge, p.simChallenges[i])
+		c.Mod(c, p.group.Q)
+		z := new(big.Int).Mul(c, p.rs[i])
+		z.Add(z, p.randomVals[i])
+		z.Mod(z, p.group.Q)
+
+		challenges := [2]*big.Int{}
+		challenges[bit] = c
+		challenges[1-bit] = p.simChallenges[i]
+		proofData[3*i] = challenges[0]
+		proofData[3*i+1+bit] = z
+		proofData[3*i+2-bit] = p.simProofData[i]
+	}
+	return proofData
+}
+
+type NBitRangeVerifier struct {
+	group           *schnorr.Group
+	h               *big.Int
+	bitCommitments  []*big.Int
+	proofRandomData []*big.Int
+	challenge       *big.Int
+}
+
+func NewNBitRangeVerifier(receiver *Receiver, n int,
+	bitCommitments []*big.Int) (*NBitRangeVerifier, error) {
+	group := receiver.Params.Group
+	if n < 1 || n >= group.Q.BitLen() || len(bitCommitments) != n {
+		return nil, fmt.Errorf("the length of bitCommitments is not correct")
+	}
+
+	// check: c = c_0 * c_1^2 * ... * c_(n-1)^(2^(n-1))
+	check := big.NewInt(1)
+	for i, comm := range bitCommitments {
+		if !group.IsElementInGroup(comm) {
+			return nil, fmt.Errorf("bit commitments need to be in the group")
+		}
+		pow := new(big.Int).Lsh(big.NewInt(1), uint(i))
+		check = group.Mul(check, group.Exp(comm, pow))
+	}
+	if !common.ConstantTimeEq(check, receiver.commitment) {
+		return nil, fmt.Errorf("bit commitments do not compose the commitment")
+	}
+
+	return &NBitRangeVerifier{
+		group:          group,
+		h:              receiver.Params.H,
+		bitCommitments: bitCommitments,
+	}, nil
+}
+
+func (v *NBitRangeVerifier) SetProofRandomData(proofRandomData []*big.Int) error {
+	if len(proofRandomData) != 2*len(v.bitCommitments) {
+		return fmt.Errorf("the length of proofRandomData is not correct")
+	}
+	v.proofRandomData = proofRandomData
+	return nil
+}
+
+// GetChallenge returns a challenge which is used in all bit proofs.
+func (v *NBitRangeVerifier) GetChallenge() *big.Int {
+	challenge := common.GetRandomInt(v.group.Q)
+	v.challenge = challenge
+	return challenge
+}
+
+// SetChallenge is used when Fiat-Shamir is used - when challenge is generated using hash by the prover.
+func (v *NBitRangeVerifier) SetChallenge(challenge *big.Int) {
+	v.challenge = challenge
+}
+
+// Verify checks for each bit that h^z_i0 = t_i0 * c_i^c_i0 and
+// h^z_i1 = t_i1 * (c_i * g^(-1))^c_i1 where c_i1 = challenge - c_i0 mod q.
+func (v *NBitRangeVerifier) Verify(proofData []*big.Int) bool {
+	if len(proofData) != 3*len(v.bitCommitments) {
+		return false
+	}
+	for i, comm := range v.bitCommitments {
+		statements := getBitStatements(v.group, comm)
+		c1 := new(big.Int).Sub(v.challenge, proofData[3*i])
+		c1.Mod(c1, v.group.Q)
+		challenges := [2]*big.Int{proofData[3*i], c1}
+		for j := 0; j < 2; j++ {
+			left := v.group.Exp(v.h, proofData[3*i+1+j])
+			right := v.group.Mul(v.proofRandomData[2*i+j],
+				v.group.Exp(statements[j], challenges[j]))
+			if !common.ConstantTimeEq(left, right) {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,81 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package pedersen
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func proveNBitRange(receiver *Receiver, committer *Committer, x *big.Int, n,
+	verifierN int) (bool, error) {
+	prover, err := NewNBitRangeProver(committer, x, n)
+	if err != nil {
+		return false, err
+	}
+	verifier, err := NewNBitRangeVerifier(receiver, verifierN,
+		prover.GetVerifierInitializationData())
+	if err != nil {
+		return false, err
+	}
+	err = verifier.SetProofRandomData(prover.GetProofRandomData())
+	if err != nil {
+		return false, err
+	}
+	challenge := verifier.GetChallenge()
+	return verifier.Verify(prover.GetProofData(challenge)), nil
+}
+
+func TestPedersenNBitRange(t *testing.T) {
+	params, err := GenerateParams(256)
+	if err != nil {
+		t.Errorf("error when generating Pedersen params: %v", err)
+	}
+	committer := NewCommitter(params)
+	receiver := NewReceiverFromParams(params)
+	x := big.NewInt(181)
+	c, err := committer.GetCommitMsg(x)
+	if err != nil {
+		t.Errorf("error in GetCommitMsg: %v", err)
+	}
+	receiver.SetCommitment(c)
+
+	proved, err := proveNBitRange(receiver, committer, x, 8, 8)
+	if err != nil {
+		t.Errorf("error in Pedersen n-bit range proof: %v", err)
+	}
+	assert.Equal(t, true, proved, "Pedersen n-bit range proof failed")
+
+	_, err = NewNBitRangeProver(committer, x, 7)
+	assert.NotNil(t, err, "NBitRangeProver should fail for x >= 2^n")
+
+	_, err = proveNBitRange(receiver, committer, x, 8, 7)
+	assert.NotNil(t, err, "NBitRangeVerifier should fail for the wrong number of bits")
+
+	// negative value (q - 1 = -1 mod q)
+	committer = NewCommitter(params)
+	c, err = committer.GetCommitMsg(new(big.Int).Sub(params.Group.Q, big.NewInt(1)))
+	if err != nil {
+		t.Errorf("error in GetCommitMsg: %v", err)
+	}
+	receiver.SetCommitment(c)
+	_, err = NewNBitRangeProver(committer, big.NewInt(1), 8)
+	assert.NotNil(t, err, "NBitRangeProver should fail for a value that is not committed")
+}
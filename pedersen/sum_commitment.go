@@ -0,0 +1,90 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package pedersen
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/awsong/crypto/schnorr"
+)
+
+// SumProver proves that the commitments c1 = g^x1 * h^r1, c2 = g^x2 * h^r2 and
+// c3 = g^x3 * h^r3 (created with the same Params) satisfy x3 = x1 + x2 mod q.
+// It holds c1 * c2 * c3^(-1) = h^(r1 + r2 - r3), thus prover proves the knowledge
+// of log_h(c1 * c2 * c3^(-1)) (Schnorr proof).
+type SumProver struct {
+	*schnorr.Prover
+}
+
+func NewSumProver(committer1, committer2, committer3 *Committer) (*SumProver, error) {
+	params := committer1.Params
+	if committer2.Params != params || committer3.Params != params {
+		return nil, fmt.Errorf("commitments need to be created with the same params")
+	}
+	x1, r1 := committer1.GetDecommitMsg()
+	x2, r2 := committer2.GetDecommitMsg()
+	x3, r3 := committer3.GetDecommitMsg()
+	sum := new(big.Int).Add(x1, x2)
+	sum.Sub(sum, x3)
+	if sum.Mod(sum, params.Group.Q).Sign() != 0 {
+		return nil, fmt.Errorf("the third committed value is not the sum of the first two")
+	}
+	r := new(big.Int).Add(r1, r2)
+	r.Sub(r, r3)
+	r.Mod(r, params.Group.Q)
+	y := getSumQuotient(params.Group, committer1.Commitment, committer2.Commitment,
+		committer3.Commitment)
+	prover, err := schnorr.NewProver(params.Group, []*big.Int{r}, []*big.Int{params.H}, y)
+	if err != nil {
+		return nil, err
+	}
+	return &SumProver{
+		Prover: prover,
+	}, nil
+}
+
+// getSumQuotient returns c1 * c2 * c3^(-1).
+func getSumQuotient(group *schnorr.Group, c1, c2, c3 *big.Int) *big.Int {
+	return getQuotient(group, group.Mul(c1, c2), c3)
+}
+
+type SumVerifier struct {
+	*schnorr.Verifier
+	h *big.Int
+	y *big.Int
+}
+
+// NewSumVerifier returns SumVerifier for the commitments stored in receiver1, receiver2
+// and receiver3.
+func NewSumVerifier(receiver1, receiver2, receiver3 *Receiver) (*SumVerifier, error) {
+	params := receiver1.Params
+	if receiver2.Params != params || receiver3.Params != params {
+		return nil, fmt.Errorf("commitments need to be created with the same params")
+	}
+	return &SumVerifier{
+		Verifier: schnorr.NewVerifier(params.Group),
+		h:        params.H,
+		y: getSumQuotient(params.Group, receiver1.commitment, receiver2.commitment,
+			receiver3.commitment),
+	}, nil
+}
+
+func (v *SumVerifier) SetProofRandomData(proofRandomData *big.Int) {
+	v.Verifier.SetProofRandomData(proofRandomData, []*big.Int{v.h}, v.y)
+}
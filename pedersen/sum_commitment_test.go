@@ -0,0 +1,77 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package pedersen
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/awsong/crypto/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPedersenSum(t *testing.T) {
+	params, err := GenerateParams(256)
+	if err != nil {
+		t.Errorf("error when generating Pedersen params: %v", err)
+	}
+	q := params.Group.Q
+	x1 := common.GetRandomInt(q)
+	x2 := common.GetRandomInt(q)
+	x3 := new(big.Int).Add(x1, x2)
+	x3.Mod(x3, q)
+
+	values := []*big.Int{x1, x2, x3}
+	committers := make([]*Committer, 3)
+	receivers := make([]*Receiver, 3)
+	for i, x := range values {
+		committers[i] = NewCommitter(params)
+		c, err := committers[i].GetCommitMsg(x)
+		if err != nil {
+			t.Errorf("error in GetCommitMsg: %v", err)
+		}
+		receivers[i] = NewReceiverFromParams(params)
+		receivers[i].SetCommitment(c)
+	}
+
+	prover, err := NewSumProver(committers[0], committers[1], committers[2])
+	if err != nil {
+		t.Errorf("error in NewSumProver: %v", err)
+	}
+	verifier, err := NewSumVerifier(receivers[0], receivers[1], receivers[2])
+	if err != nil {
+		t.Errorf("error in NewSumVerifier: %v", err)
+	}
+	verifier.SetProofRandomData(prover.GetProofRandomData())
+	challenge := verifier.GetChallenge()
+	assert.Equal(t, true, verifier.Verify(prover.GetProofData(challenge)),
+		"Pedersen sum proof failed")
+
+	// the third value is not the sum
+	_, err = NewSumProver(committers[0], committers[2], committers[1])
+	assert.NotNil(t, err, "SumProver should fail when the third value is not the sum")
+
+	verifier, err = NewSumVerifier(receivers[0], receivers[2], receivers[1])
+	if err != nil {
+		t.Errorf("error in NewSumVerifier: %v", err)
+	}
+	verifier.SetProofRandomData(prover.GetProofRandomData())
+	challenge = verifier.GetChallenge()
+	assert.Equal(t, false, verifier.Verify(prover.GetProofData(challenge)),
+		"Pedersen sum proof should fail for wrong commitments")
+}
@@ -0,0 +1,224 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package pedersen
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/awsong/crypto/common"
+	"github.com/awsong/crypto/schnorr"
+)
+
+// XORConsistencyProver proves that for the commitments c_x = g^x * h^s and c_r = g^r * h^t
+// and a public n-bit value c it holds c = x XOR r (a one-time pad encryption of x under r).
+// It uses binary decomposition of x and r: x is decomposed into bit commitments
+// X_i = g^x_i * h^s_i using NBitRangeProver (which proves that x_i are bits), r is decomposed
+// into bit commitments R_i = g^r_i * h^t_i (r = r_0 + 2*r_1 + ... + 2^(n-1)*r_(n-1) and
+// t = t_0 + 2*t_1 + ... + 2^(n-1)*t_(n-1) mod q). For each bit it is proved that x_i = c_i XOR r_i:
+// if c_i = 0, then x_i = r_i and X_i * R_i^(-1) = h^(s_i - t_i);
+// if c_i = 1, then x_i + r_i = 1 and X_i * R_i * g^(-1) = h^(s_i + t_i).
+// The knowledge of these discrete logarithms is proved by Schnorr proofs. As x_i are bits,
+// r_i are bits too. All the proofs use the same challenge.
+type XORConsistencyProver struct {
+	group          *schnorr.Group
+	h              *big.Int
+	nBitProver     *NBitRangeProver
+	bitCommitments []*big.Int // R_i
+	secrets        []*big.Int // discrete logarithms of the linking statements
+	randomVals     []*big.Int
+}
+
+// NewXORConsistencyProver returns XORConsistencyProver. committerX and committerR need to hold
+// the commitments to x and r such that c = x XOR r, all of them need to be in [0, 2^n).
+func NewXORConsistencyProver(committerX, committerR *Committer, c *big.Int,
+	n int) (*XORConsistencyProver, error) {
+	group := committerX.Params.Group
+	x, _ := committerX.GetDecommitMsg()
+	r, t := committerR.GetDecommitMsg()
+	if x == nil || r == nil {
+		return nil, fmt.Errorf("committers need to hold a commitment")
+	}
+	bound := new(big.Int).Lsh(big.NewInt(1), uint(n))
+	if r.Sign() < 0 || r.Cmp(bound) >= 0 || c.Sign() < 0 || c.Cmp(bound) >= 0 {
+		return nil, fmt.Errorf("r and c need to be in [0, 2^n)")
+	}
+	if new(big.Int).Xor(c, r).Cmp(x) != 0 {
+		return nil, fmt.Errorf("c XOR r is not x")
+	}
+	nBitProver, err := NewNBitRangeProver(committerX, x, n)
+	if err != nil {
+		return nil, err
+	}
+
+	// t_1, ..., t_(n-1) are chosen randomly, t_0 = t - (2*t_1 + ... + 2^(n-1)*t_(n-1)) mod q
+	ts := make([]*big.Int, n)
+	t0 := new(big.Int).Set(t)
+	for i := 1; i < n; i++ {
+		ts[i] = common.GetRandomInt(group.Q)
+		t0.Sub(t0, new(big.Int).Lsh(ts[i], uint(i)))
+	}
+	ts[0] = t0.Mod(t0, group.Q)
+
+	bitCommitments := make([]*big.Int, n)
+	secrets := make([]*big.Int, n)
+	for i := 0; i < n; i++ {
+		bitCommitter := NewCommitter(committerR.Params)
+		bitCommitments[i], err = bitCommitter.GetCommitMsgWithGivenR(big.NewInt(int64(r.Bit(i))),
+			ts[i])
+		if err != nil {
+			return nil, err
+		}
+		secret := new(big.Int)
+		if c.Bit(i) == 0 {
+			secret.Sub(nBitProver.rs[i], ts[i])
+		} else {
+			secret.Add(nBitProver.rs[i], ts[i])
+		}
+		secrets[i] = secret.Mod(secret, group.Q)
+	}
+
+	return &XORConsistencyProver{
+		group:          group,
+		h:              committerX.Params.H,
+		nBitProver:     nBitProver,
+		bitCommitments: bitCommitments,
+		secrets:        secrets,
+	}, nil
+}
+
+// GetVerifierInitializationData returns commitments to the bits of x and to the bits of r.
+func (p *XORConsistencyProver) GetVerifierInitializationData() ([]*big.Int, []*big.Int) {
+	return p.nBitProver.GetVerifierInitializationData(), p.bitCommitments
+}
+
+// GetProofRandomData returns the proof random data of NBitRangeProver and h^rho_i
+// for random rho_i for each linking statement.
+func (p *XORConsistencyProver) GetProofRandomData() ([]*big.Int, []*big.Int) {
+	p.randomVals = make([]*big.Int, len(p.secrets))
+	proofRandomData := make([]*big.Int, len(p.secrets))
+	for i := range p.secrets {
+		p.randomVals[i] = common.GetRandomInt(p.group.Q)
+		proofRandomData[i] = p.group.Exp(p.h, p.randomVals[i])
+	}
+	return p.nBitProver.GetProofRandomData(), proofRandomData
+}
+
+// GetProofData returns the proof data of NBitRangeProver and z_i = rho_i + challenge * w_i mod q
+// where w_i are the discrete logarithms of the linking statements.
+func (p *XORConsistencyProver) GetProofData(challenge *big.Int) ([]*big.Int, []*big.Int) {
+	proofData := make([]*big.Int, len(p.secrets))
+	for i, w := range p.secrets {
+		z := new(big.Int).Mul(challenge, w)
+		z.Add(z, p.randomVals[i])
+		proofData[i] = z.Mod(z, p.group.Q)
+	}
+	return p.nBitProver.GetProofData(challenge), proofData
+}
+
+type XORConsistencyVerifier struct {
+	group           *schnorr.Group
+	h               *big.Int
+	nBitVerifier    *NBitRangeVerifier
+	statements      []*big.Int
+	proofRandomData []*big.Int
+	challenge       *big.Int
+}
+
+// NewXORConsistencyVerifier returns XORConsistencyVerifier for the commitments stored in
+// receiverX and receiverR. It returns an error if bit commitments do not compose the commitments.
+func NewXORConsistencyVerifier(receiverX, receiverR *Receiver, c *big.Int, n int,
+	bitCommitmentsX, bitCommitmentsR []*big.Int) (*XORConsistencyVerifier, error) {
+	group := receiverX.Params.Group
+	bound := new(big.Int).Lsh(big.NewInt(1), uint(n))
+	if c.Sign() < 0 || c.Cmp(bound) >= 0 {
+		return nil, fmt.Errorf("c needs to be in [0, 2^n)")
+	}
+	nBitVerifier, err := NewNBitRangeVerifier(receiverX, n, bitCommitmentsX)
+	if err != nil {
+		return nil, err
+	}
+	if len(bitCommitmentsR) != n {
+		return nil, fmt.Errorf("the length of bitCommitmentsR is not correct")
+	}
+
+	// check: c_r = R_0 * R_1^2 * ... * R_(n-1)^(2^(n-1))
+	check := big.NewInt(1)
+	statements := make([]*big.Int, n)
+	for i, comm := range bitCommitmentsR {
+		if !group.IsElementInGroup(comm) {
+			return nil, fmt.Errorf("bit commitments need to be in the group")
+		}
+		pow := new(big.Int).Lsh(big.NewInt(1), uint(i))
+		check = group.Mul(check, group.Exp(comm, pow))
+
+		if c.Bit(i) == 0 {
+			statements[i] = group.Div(bitCommitmentsX[i], comm)
+		} else {
+			statements[i] = group.Div(group.Mul(bitCommitmentsX[i], comm), group.G)
+		}
+	}
+	if !common.ConstantTimeEq(check, receiverR.commitment) {
+		return nil, fmt.Errorf("bit commitments do not compose the commitment")
+	}
+
+	return &XORConsistencyVerifier{
+		group:        group,
+		h:            receiverX.Params.H,
+		nBitVerifier: nBitVerifier,
+		statements:   statements,
+	}, nil
+}
+
+func (v *XORConsistencyVerifier) SetProofRandomData(nBitProofRandomData,
+	proofRandomData []*big.Int) error {
+	if len(proofRandomData) != len(v.statements) {
+		return fmt.Errorf("the length of proofRandomData is not correct")
+	}
+	v.proofRandomData = proofRandomData
+	return v.nBitVerifier.SetProofRandomData(nBitProofRandomData)
+}
+
+// GetChallenge returns a challenge which is used in all the proofs.
+func (v *XORConsistencyVerifier) GetChallenge() *big.Int {
+	challenge := common.GetRandomInt(v.group.Q)
+	v.SetChallenge(challenge)
+	return challenge
+}
+
+// SetChallenge is used when Fiat-Shamir is used - when challenge is generated using hash by the prover.
+func (v *XORConsistencyVerifier) SetChallenge(challenge *big.Int) {
+	v.challenge = challenge
+	v.nBitVerifier.SetChallenge(challenge)
+}
+
+// Verify checks the proof of NBitRangeVerifier and h^z_i = t_i * Y_i^challenge for each
+// linking statement Y_i.
+func (v *XORConsistencyVerifier) Verify(nBitProofData, proofData []*big.Int) bool {
+	if len(proofData) != len(v.statements) || !v.nBitVerifier.Verify(nBitProofData) {
+		return false
+	}
+	for i, y := range v.statements {
+		left := v.group.Exp(v.h, proofData[i])
+		right := v.group.Mul(v.proofRandomData[i], v.group.Exp(y, v.challenge))
+		if !common.ConstantTimeEq(left, right) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,88 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package pedersen
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func proveXORConsistency(params *Params, x, r, c, verifierC *big.Int, n int) (bool, error) {
+	committerX := NewCommitter(params)
+	cX, err := committerX.GetCommitMsg(x)
+	if err != nil {
+		return false, err
+	}
+	committerR := NewCommitter(params)
+	cR, err := committerR.GetCommitMsg(r)
+	if err != nil {
+		return false, err
+	}
+	receiverX := NewReceiverFromParams(params)
+	receiverX.SetCommitment(cX)
+	receiverR := NewReceiverFromParams(params)
+	receiverR.SetCommitment(cR)
+
+	prover, err := NewXORConsistencyProver(committerX, committerR, c, n)
+	if err != nil {
+		return false, err
+	}
+	bitCommitmentsX, bitCommitmentsR := prover.GetVerifierInitializationData()
+	verifier, err := NewXORConsistencyVerifier(receiverX, receiverR, verifierC, n,
+		bitCommitmentsX, bitCommitmentsR)
+	if err != nil {
+		return false, err
+	}
+	err = verifier.SetProofRandomData(prover.GetProofRandomData())
+	if err != nil {
+		return false, err
+	}
+	challenge := verifier.GetChallenge()
+	return verifier.Verify(prover.GetProofData(challenge)), nil
+}
+
+func TestPedersenXORConsistency(t *testing.T) {
+	params, err := GenerateParams(256)
+	if err != nil {
+		t.Errorf("error when generating Pedersen params: %v", err)
+	}
+	x := big.NewInt(181)
+	r := big.NewInt(92)
+	c := new(big.Int).Xor(x, r)
+
+	proved, err := proveXORConsistency(params, x, r, c, c, 8)
+	if err != nil {
+		t.Errorf("error in XOR consistency proof: %v", err)
+	}
+	assert.Equal(t, true, proved, "XOR consistency proof failed")
+
+	// the proof does not verify for a different c
+	proved, err = proveXORConsistency(params, x, r, c, new(big.Int).Xor(c, big.NewInt(4)), 8)
+	if err != nil {
+		t.Errorf("error in XOR consistency proof: %v", err)
+	}
+	assert.Equal(t, false, proved, "XOR consistency proof should fail for a different c")
+
+	_, err = proveXORConsistency(params, x, r, big.NewInt(0), big.NewInt(0), 8)
+	assert.NotNil(t, err, "XORConsistencyProver should fail when c XOR r is not x")
+
+	_, err = proveXORConsistency(params, x, big.NewInt(256), c, c, 8)
+	assert.NotNil(t, err, "XORConsistencyProver should fail for r >= 2^n")
+}
@@ -0,0 +1,139 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package ratelimit
+
+import (
+	"crypto/sha512"
+	"encoding/binary"
+	"fmt"
+	"math/big"
+
+	"github.com/awsong/crypto/common"
+	"github.com/awsong/crypto/schnorr"
+)
+
+// Blind Schnorr signatures: signer with the secret key x (public key y = g^x) chooses k
+// and sends R = g^k. The client chooses blinding factors alpha and beta, computes
+// R' = R * g^alpha * y^beta, e = H(R', m) and sends the blinded message e' = e + beta
+// to the signer. Signer returns s' = k + e' * x and the client obtains the signature
+// (R', s = s' + alpha) for which it holds g^s = R' * y^e.
+// The signer does not learn m, R' or s, thus it cannot link the signature to the session.
+// Note that blind Schnorr signatures are not secure when the signer runs many sessions
+// concurrently (ROS attack, Benhamouda et al. 2020) - Issuer allows only one pending
+// session per client, but it does not prevent concurrent sessions of different clients.
+
+// Signature is a Schnorr signature (R, s) such that g^s = R * y^H(R, m).
+type Signature struct {
+	R *big.Int
+	S *big.Int
+}
+
+// BlindFactor holds the values which the client needs to unblind the signature.
+type BlindFactor struct {
+	alpha *big.Int
+	beta  *big.Int
+	r     *big.Int // R' = R * g^alpha * y^beta
+	e     *big.Int // e = H(R', m)
+}
+
+// GenerateKey returns a secret key x and a public key y = g^x.
+func GenerateKey(group *schnorr.Group) (*big.Int, *big.Int, error) {
+	pk, sk, err := group.Sample()
+	if err != nil {
+		return nil, nil, err
+	}
+	return sk, pk, nil
+}
+
+// GetSignerCommitment returns a random k and R = g^k. The value k must be used only for
+// one BlindSign call.
+func GetSignerCommitment(group *schnorr.Group) (*big.Int, *big.Int, error) {
+	r, k, err := group.Sample()
+	if err != nil {
+		return nil, nil, err
+	}
+	return k, r, nil
+}
+
+// BlindSign returns s' = k + blindedMessage * sk mod q.
+func BlindSign(group *schnorr.Group, sk, k, blindedMessage *big.Int) *big.Int {
+	s := new(big.Int).Mul(blindedMessage, sk)
+	s.Add(s, k)
+	return s.Mod(s, group.Q)
+}
+
+// Blind returns the blinded message e' = H(R', m) + beta mod q for the signer commitment R
+// and the blinding factor needed to unblind the signature.
+func Blind(group *schnorr.Group, pk, r *big.Int, m []byte) (*big.Int, *BlindFactor) {
+	alpha := common.GetRandomInt(group.Q)
+	beta := common.GetRandomInt(group.Q)
+	r1 := group.Mul(r, group.Mul(group.Exp(group.G, alpha), group.Exp(pk, beta)))
+	e := getChallenge(group, r1, m)
+	blindedMessage := new(big.Int).Add(e, beta)
+	blindedMessage.Mod(blindedMessage, group.Q)
+	return blindedMessage, &BlindFactor{
+		alpha: alpha,
+		beta:  beta,
+		r:     r1,
+		e:     e,
+	}
+}
+
+// Unblind checks the blinded signature (g^blindedSig = R * pk^e') and returns
+// the signature (R', blindedSig + alpha).
+func Unblind(group *schnorr.Group, pk, r, blindedSig *big.Int,
+	blindFactor *BlindFactor) (*Signature, error) {
+	blindedMessage := new(big.Int).Add(blindFactor.e, blindFactor.beta)
+	blindedMessage.Mod(blindedMessage, group.Q)
+	left := group.Exp(group.G, blindedSig)
+	right := group.Mul(r, group.Exp(pk, blindedMessage))
+	if !common.ConstantTimeEq(left, right) {
+		return nil, fmt.Errorf("blinded signature is not valid")
+	}
+	s := new(big.Int).Add(blindedSig, blindFactor.alpha)
+	s.Mod(s, group.Q)
+	return &Signature{
+		R: blindFactor.r,
+		S: s,
+	}, nil
+}
+
+// Verify returns true if sig is a valid signature of m for the public key pk.
+func Verify(group *schnorr.Group, pk *big.Int, m []byte, sig *Signature) bool {
+	if sig == nil || sig.R == nil || sig.S == nil || !group.IsElementInGroup(sig.R) {
+		return false
+	}
+	left := group.Exp(group.G, sig.S)
+	right := group.Mul(sig.R, group.Exp(pk, getChallenge(group, sig.R, m)))
+	return common.ConstantTimeEq(left, right)
+}
+
+// getChallenge returns H(R, m) mod q. R and m are hashed as length-prefixed byte strings,
+// thus m is not converted into an integer - m and m with prepended zero bytes give
+// different challenges.
+func getChallenge(group *schnorr.Group, r *big.Int, m []byte) *big.Int {
+	h := sha512.New()
+	for _, b := range [][]byte{r.Bytes(), m} {
+		var length [8]byte
+		binary.BigEndian.PutUint64(length[:], uint64(len(b)))
+		h.Write(length[:])
+		h.Write(b)
+	}
+	e := new(big.Int).SetBytes(h.Sum(nil))
+	return e.Mod(e, group.Q)
+}
@@ -0,0 +1,58 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package ratelimit
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/awsong/crypto/schnorr"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBlindSchnorr(t *testing.T) {
+	group, err := schnorr.NewGroup(256)
+	if err != nil {
+		t.Errorf("error when creating Schnorr group: %v", err)
+	}
+	sk, pk, err := GenerateKey(group)
+	if err != nil {
+		t.Errorf("error in GenerateKey: %v", err)
+	}
+	m := []byte("rate-limit token")
+
+	k, r, err := GetSignerCommitment(group)
+	if err != nil {
+		t.Errorf("error in GetSignerCommitment: %v", err)
+	}
+	blindedMessage, blindFactor := Blind(group, pk, r, m)
+	blindedSig := BlindSign(group, sk, k, blindedMessage)
+	sig, err := Unblind(group, pk, r, blindedSig, blindFactor)
+	if err != nil {
+		t.Errorf("error in Unblind: %v", err)
+	}
+	assert.Equal(t, true, Verify(group, pk, m, sig), "blind Schnorr signature is not valid")
+	assert.Equal(t, false, Verify(group, pk, []byte("another message"), sig),
+		"signature should not be valid for another message")
+	assert.Equal(t, false, Verify(group, pk, append([]byte{0}, m...), sig),
+		"signature should not be valid for the message with prepended zero byte")
+	assert.NotEqual(t, r, sig.R, "signer commitment is not blinded")
+
+	_, err = Unblind(group, pk, r, new(big.Int).Add(blindedSig, big.NewInt(1)), blindFactor)
+	assert.NotNil(t, err, "invalid blinded signature should not be accepted")
+}
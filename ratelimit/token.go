@@ -0,0 +1,168 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package ratelimit
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+
+	"github.com/awsong/crypto/schnorr"
+)
+
+// tokenNonceLength is the length (in bytes) of the random value of a token.
+const tokenNonceLength = 32
+
+// Token is a rate-limit token: a random value signed (blindly) by Issuer.
+type Token struct {
+	Value     []byte
+	Signature *Signature
+}
+
+// Issuer is a token server which issues at most limit tokens to each client. It signs
+// the tokens blindly, thus it cannot link the tokens presented to RedemptionVerifier
+// to the clients. Issuer is not safe for concurrent use.
+type Issuer struct {
+	group   *schnorr.Group
+	sk      *big.Int
+	PubKey  *big.Int
+	limit   int
+	issued  map[string]int
+	pending map[string]*big.Int // k of the pending session for each client
+}
+
+func NewIssuer(group *schnorr.Group, limit int) (*Issuer, error) {
+	sk, pk, err := GenerateKey(group)
+	if err != nil {
+		return nil, err
+	}
+	return &Issuer{
+		group:   group,
+		sk:      sk,
+		PubKey:  pk,
+		limit:   limit,
+		issued:  make(map[string]int),
+		pending: make(map[string]*big.Int),
+	}, nil
+}
+
+// GetCommitment starts a signing session for the client and returns R = g^k. It returns
+// an error if the client already received limit tokens or has a pending session.
+func (i *Issuer) GetCommitment(clientID string) (*big.Int, error) {
+	if i.issued[clientID] >= i.limit {
+		return nil, fmt.Errorf("client %s reached the limit of %d tokens", clientID, i.limit)
+	}
+	if _, ok := i.pending[clientID]; ok {
+		return nil, fmt.Errorf("client %s has a pending session", clientID)
+	}
+	k, r, err := GetSignerCommitment(i.group)
+	if err != nil {
+		return nil, err
+	}
+	i.pending[clientID] = k
+	return r, nil
+}
+
+// Sign finishes the signing session of the client and returns the blinded signature.
+func (i *Issuer) Sign(clientID string, blindedMessage *big.Int) (*big.Int, error) {
+	k, ok := i.pending[clientID]
+	if !ok {
+		return nil, fmt.Errorf("client %s has no pending session", clientID)
+	}
+	delete(i.pending, clientID)
+	i.issued[clientID]++
+	return BlindSign(i.group, i.sk, k, blindedMessage), nil
+}
+
+// Client obtains tokens from Issuer.
+type Client struct {
+	group       *schnorr.Group
+	pubKey      *big.Int
+	value       []byte
+	r           *big.Int
+	blindFactor *BlindFactor
+}
+
+func NewClient(group *schnorr.Group, issuerPubKey *big.Int) *Client {
+	return &Client{
+		group:  group,
+		pubKey: issuerPubKey,
+	}
+}
+
+// GetBlindedMessage chooses a random token value and returns it blinded for the issuer
+// commitment r.
+func (c *Client) GetBlindedMessage(r *big.Int) (*big.Int, error) {
+	c.value = make([]byte, tokenNonceLength)
+	if _, err := rand.Read(c.value); err != nil {
+		return nil, err
+	}
+	c.r = r
+	blindedMessage, blindFactor := Blind(c.group, c.pubKey, r, c.value)
+	c.blindFactor = blindFactor
+	return blindedMessage, nil
+}
+
+// GetToken unblinds the signature obtained from Issuer and returns the token.
+func (c *Client) GetToken(blindedSig *big.Int) (*Token, error) {
+	if c.blindFactor == nil {
+		return nil, fmt.Errorf("GetBlindedMessage needs to be called first")
+	}
+	sig, err := Unblind(c.group, c.pubKey, c.r, blindedSig, c.blindFactor)
+	if err != nil {
+		return nil, err
+	}
+	c.blindFactor = nil
+	return &Token{
+		Value:     c.value,
+		Signature: sig,
+	}, nil
+}
+
+// RedemptionVerifier is a rate-limited service which accepts each valid token only once.
+// RedemptionVerifier is not safe for concurrent use.
+type RedemptionVerifier struct {
+	group        *schnorr.Group
+	issuerPubKey *big.Int
+	spent        map[string]bool
+}
+
+func NewRedemptionVerifier(group *schnorr.Group, issuerPubKey *big.Int) *RedemptionVerifier {
+	return &RedemptionVerifier{
+		group:        group,
+		issuerPubKey: issuerPubKey,
+		spent:        make(map[string]bool),
+	}
+}
+
+// Redeem returns an error if the token value is not of length tokenNonceLength, if the
+// token is not signed by the issuer or if it has already been redeemed.
+func (v *RedemptionVerifier) Redeem(token *Token) error {
+	if len(token.Value) != tokenNonceLength {
+		return fmt.Errorf("token value needs to be %d bytes long", tokenNonceLength)
+	}
+	if !Verify(v.group, v.issuerPubKey, token.Value, token.Signature) {
+		return fmt.Errorf("token signature is not valid")
+	}
+	key := string(token.Value)
+	if v.spent[key] {
+		return fmt.Errorf("token has already been redeemed")
+	}
+	v.spent[key] = true
+	return nil
+}
@@ -0,0 +1,93 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package ratelimit
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/awsong/crypto/schnorr"
+	"github.com/stretchr/testify/assert"
+)
+
+func getToken(issuer *Issuer, client *Client, clientID string) (*Token, error) {
+	r, err := issuer.GetCommitment(clientID)
+	if err != nil {
+		return nil, err
+	}
+	blindedMessage, err := client.GetBlindedMessage(r)
+	if err != nil {
+		return nil, err
+	}
+	blindedSig, err := issuer.Sign(clientID, blindedMessage)
+	if err != nil {
+		return nil, err
+	}
+	return client.GetToken(blindedSig)
+}
+
+func TestRateLimitTokens(t *testing.T) {
+	group, err := schnorr.NewGroup(256)
+	if err != nil {
+		t.Errorf("error when creating Schnorr group: %v", err)
+	}
+	issuer, err := NewIssuer(group, 2)
+	if err != nil {
+		t.Errorf("error in NewIssuer: %v", err)
+	}
+	client := NewClient(group, issuer.PubKey)
+	verifier := NewRedemptionVerifier(group, issuer.PubKey)
+
+	token, err := getToken(issuer, client, "alice")
+	if err != nil {
+		t.Errorf("error when obtaining token: %v", err)
+	}
+	assert.Nil(t, verifier.Redeem(token), "valid token should be accepted")
+	assert.NotNil(t, verifier.Redeem(token), "replayed token should not be accepted")
+
+	// the value with prepended zero bytes is not a new token
+	for i := 1; i <= 5; i++ {
+		replayed := &Token{
+			Value:     append(make([]byte, i), token.Value...),
+			Signature: token.Signature,
+		}
+		assert.NotNil(t, verifier.Redeem(replayed),
+			"token with zero-prefixed value should not be accepted")
+	}
+
+	forged := &Token{
+		Value: []byte("forged token"),
+		Signature: &Signature{
+			R: token.Signature.R,
+			S: new(big.Int).Add(token.Signature.S, big.NewInt(1)),
+		},
+	}
+	assert.NotNil(t, verifier.Redeem(forged), "forged token should not be accepted")
+	forged.Signature = token.Signature
+	assert.NotNil(t, verifier.Redeem(forged),
+		"token with a signature for another value should not be accepted")
+
+	_, err = getToken(issuer, client, "alice")
+	if err != nil {
+		t.Errorf("error when obtaining token: %v", err)
+	}
+	_, err = getToken(issuer, client, "alice")
+	assert.NotNil(t, err, "issuer should not issue more than limit tokens")
+	_, err = getToken(issuer, client, "bob")
+	assert.Nil(t, err, "limit should be per client")
+}
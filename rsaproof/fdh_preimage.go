@@ -0,0 +1,200 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package rsaproof
+
+import (
+	"crypto/rsa"
+	"crypto/sha512"
+	"fmt"
+	"math/big"
+
+	"github.com/awsong/crypto/common"
+)
+
+// FullDomainHash returns H(m) from Z_n. It concatenates SHA-512(counter || m) for
+// counter = 0, 1, ... to obtain n.BitLen() + 128 bits and reduces the result modulo n
+// (the additional 128 bits make the bias of the reduction negligible).
+func FullDomainHash(m []byte, n *big.Int) *big.Int {
+	var digest []byte
+	for counter := 0; len(digest)*8 < n.BitLen()+128; counter++ {
+		h := sha512.New()
+		h.Write([]byte{byte(counter >> 24), byte(counter >> 16), byte(counter >> 8), byte(counter)})
+		h.Write(m)
+		digest = h.Sum(digest)
+	}
+	x := new(big.Int).SetBytes(digest)
+	return x.Mod(x, n)
+}
+
+// soundnessBits is the targeted soundness of RSAFDHPreimageProver: the probability that
+// a prover who does not know the preimage is accepted is at most 2^-soundnessBits.
+const soundnessBits = 80
+
+// getRounds returns the number of parallel Guillou-Quisquater rounds needed for the
+// soundness error 2^-soundnessBits when the challenges are chosen from [0, e). Each
+// round has the soundness error 1/e <= 2^-(e.BitLen()-1).
+func getRounds(e int) int {
+	bits := big.NewInt(int64(e)).BitLen() - 1
+	return (soundnessBits + bits - 1) / bits
+}
+
+// RSAFDHPreimageProver proves the knowledge of an RSA-FDH signature y = H(m)^d mod n
+// for the public hash H(m), that is the knowledge of the preimage y of H(m) under
+// the RSA function x -> x^e mod n. The verifier is given only H(m) and learns only that
+// the prover holds a valid signature - the signature y is not revealed.
+// Note that the message m is not hidden if it comes from a small or guessable set: the
+// verifier can compute H(m') for candidate messages m' and compare it with H(m).
+// This is the Guillou-Quisquater protocol - challenges are chosen from [0, e), thus e
+// needs to be a prime. The soundness error of a single round is 1/e, thus the
+// protocol runs getRounds(e) rounds in parallel to obtain the soundness error of at
+// most 2^-80 (5 rounds for e = 65537).
+type RSAFDHPreimageProver struct {
+	PubKey *rsa.PublicKey
+	Hash   *big.Int
+	y      *big.Int
+	r      []*big.Int
+}
+
+// NewRSAFDHPreimageProver returns RSAFDHPreimageProver. It returns an error if y
+// is not a valid RSA-FDH signature of m.
+func NewRSAFDHPreimageProver(pubKey *rsa.PublicKey, m []byte,
+	y *big.Int) (*RSAFDHPreimageProver, error) {
+	e := big.NewInt(int64(pubKey.E))
+	h := FullDomainHash(m, pubKey.N)
+	if new(big.Int).Exp(y, e, pubKey.N).Cmp(h) != 0 {
+		return nil, fmt.Errorf("y is not a valid signature of m")
+	}
+	return &RSAFDHPreimageProver{
+		PubKey: pubKey,
+		Hash:   h,
+		y:      y,
+	}, nil
+}
+
+// GetProofRandomData returns t_i = r_i^e mod n for random r_i from Z_n*, one for
+// each round.
+func (p *RSAFDHPreimageProver) GetProofRandomData() []*big.Int {
+	e := big.NewInt(int64(p.PubKey.E))
+	rounds := getRounds(p.PubKey.E)
+	p.r = make([]*big.Int, rounds)
+	proofRandomData := make([]*big.Int, rounds)
+	for i := 0; i < rounds; i++ {
+		p.r[i] = getRandomUnit(p.PubKey.N)
+		proofRandomData[i] = new(big.Int).Exp(p.r[i], e, p.PubKey.N)
+	}
+	return proofRandomData
+}
+
+// GetProofData returns z_i = r_i * y^challenge_i mod n. It returns an error if the
+// number of challenges does not match the number of rounds.
+func (p *RSAFDHPreimageProver) GetProofData(challenges []*big.Int) ([]*big.Int, error) {
+	if len(challenges) != len(p.r) {
+		return nil, fmt.Errorf("the number of challenges is not correct")
+	}
+	proofData := make([]*big.Int, len(p.r))
+	for i, challenge := range challenges {
+		z := new(big.Int).Exp(p.y, challenge, p.PubKey.N)
+		z.Mul(z, p.r[i])
+		proofData[i] = z.Mod(z, p.PubKey.N)
+	}
+	return proofData, nil
+}
+
+// getRandomUnit returns a random element of Z_n*.
+func getRandomUnit(n *big.Int) *big.Int {
+	for {
+		r := common.GetRandomInt(n)
+		if r.Sign() > 0 && new(big.Int).GCD(nil, nil, r, n).Cmp(big.NewInt(1)) == 0 {
+			return r
+		}
+	}
+}
+
+type RSAFDHPreimageVerifier struct {
+	PubKey          *rsa.PublicKey
+	hash            *big.Int
+	proofRandomData []*big.Int
+	challenges      []*big.Int
+}
+
+// NewRSAFDHPreimageVerifier returns RSAFDHPreimageVerifier for the hash h = H(m).
+// It returns an error if the public exponent is not a prime or if h is not from Z_n*.
+func NewRSAFDHPreimageVerifier(pubKey *rsa.PublicKey,
+	h *big.Int) (*RSAFDHPreimageVerifier, error) {
+	if !big.NewInt(int64(pubKey.E)).ProbablyPrime(20) {
+		return nil, fmt.Errorf("public exponent needs to be a prime")
+	}
+	if !isUnit(h, pubKey.N) {
+		return nil, fmt.Errorf("hash needs to be from Z_n*")
+	}
+	return &RSAFDHPreimageVerifier{
+		PubKey: pubKey,
+		hash:   h,
+	}, nil
+}
+
+// isUnit returns true if x is from Z_n*.
+func isUnit(x, n *big.Int) bool {
+	return x.Sign() > 0 && x.Cmp(n) < 0 &&
+		new(big.Int).GCD(nil, nil, x, n).Cmp(big.NewInt(1)) == 0
+}
+
+func (v *RSAFDHPreimageVerifier) SetProofRandomData(proofRandomData []*big.Int) {
+	v.proofRandomData = proofRandomData
+}
+
+// GetChallenge returns a random challenge from [0, e) for each round.
+func (v *RSAFDHPreimageVerifier) GetChallenge() []*big.Int {
+	e := big.NewInt(int64(v.PubKey.E))
+	challenges := make([]*big.Int, getRounds(v.PubKey.E))
+	for i := range challenges {
+		challenges[i] = common.GetRandomInt(e)
+	}
+	v.challenges = challenges
+	return challenges
+}
+
+// SetChallenge is used when Fiat-Shamir is used - when challenge is generated using hash by the prover.
+func (v *RSAFDHPreimageVerifier) SetChallenge(challenges []*big.Int) {
+	v.challenges = challenges
+}
+
+// Verify checks that there are getRounds(e) rounds and that for each round z_i is
+// from Z_n*, challenge_i is from [0, e) and z_i^e = t_i * H(m)^challenge_i mod n.
+func (v *RSAFDHPreimageVerifier) Verify(proofData []*big.Int) bool {
+	rounds := getRounds(v.PubKey.E)
+	if len(proofData) != rounds || len(v.proofRandomData) != rounds ||
+		len(v.challenges) != rounds {
+		return false
+	}
+	e := big.NewInt(int64(v.PubKey.E))
+	for i, z := range proofData {
+		if !isUnit(z, v.PubKey.N) || v.challenges[i].Sign() < 0 ||
+			v.challenges[i].Cmp(e) >= 0 {
+			return false
+		}
+		left := new(big.Int).Exp(z, e, v.PubKey.N)
+		right := new(big.Int).Exp(v.hash, v.challenges[i], v.PubKey.N)
+		right.Mul(right, v.proofRandomData[i])
+		right.Mod(right, v.PubKey.N)
+		if !common.ConstantTimeEq(left, right) {
+			return false
+		}
+	}
+	return true
+}
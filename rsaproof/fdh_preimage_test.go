@@ -0,0 +1,93 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package rsaproof
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func proveRSAFDHPreimage(pubKey *rsa.PublicKey, m []byte, y, h *big.Int) (bool, error) {
+	prover, err := NewRSAFDHPreimageProver(pubKey, m, y)
+	if err != nil {
+		return false, err
+	}
+	verifier, err := NewRSAFDHPreimageVerifier(pubKey, h)
+	if err != nil {
+		return false, err
+	}
+	verifier.SetProofRandomData(prover.GetProofRandomData())
+	proofData, err := prover.GetProofData(verifier.GetChallenge())
+	if err != nil {
+		return false, err
+	}
+	return verifier.Verify(proofData), nil
+}
+
+func TestRSAFDHPreimage(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Errorf("error when generating RSA key: %v", err)
+	}
+	pubKey := &key.PublicKey
+
+	m := []byte("blind signature message")
+	h := FullDomainHash(m, pubKey.N)
+	y := new(big.Int).Exp(h, key.D, pubKey.N) // RSA-FDH signature
+
+	proved, err := proveRSAFDHPreimage(pubKey, m, y, h)
+	if err != nil {
+		t.Errorf("error in RSA-FDH preimage proof: %v", err)
+	}
+	assert.Equal(t, true, proved, "RSA-FDH preimage proof failed")
+
+	// invalid signature
+	_, err = NewRSAFDHPreimageProver(pubKey, m, new(big.Int).Add(y, big.NewInt(1)))
+	assert.NotNil(t, err, "prover should fail for an invalid signature")
+
+	// the verifier expects the hash of a different message
+	hOther := FullDomainHash([]byte("another message"), pubKey.N)
+	proved, err = proveRSAFDHPreimage(pubKey, m, y, hOther)
+	if err != nil {
+		t.Errorf("error in RSA-FDH preimage proof: %v", err)
+	}
+	assert.Equal(t, false, proved, "RSA-FDH preimage proof should fail for a different hash")
+
+	// a proof with a single round is not accepted
+	prover, _ := NewRSAFDHPreimageProver(pubKey, m, y)
+	verifier, _ := NewRSAFDHPreimageVerifier(pubKey, h)
+	verifier.SetProofRandomData(prover.GetProofRandomData()[:1])
+	challenges := verifier.GetChallenge()
+	verifier.SetChallenge(challenges[:1])
+	proofData, _ := prover.GetProofData(challenges)
+	assert.Equal(t, false, verifier.Verify(proofData[:1]),
+		"RSA-FDH preimage proof with a single round should fail")
+}
+
+func TestRSAFDHPreimageRounds(t *testing.T) {
+	assert.Equal(t, 5, getRounds(65537), "wrong number of rounds for e = 65537")
+	assert.Equal(t, 80, getRounds(3), "wrong number of rounds for e = 3")
+	for _, e := range []int{3, 17, 257, 65537, 2147483647} {
+		bits := big.NewInt(int64(e)).BitLen() - 1
+		assert.True(t, getRounds(e)*bits >= 80, "soundness error above 2^-80 for e = %d", e)
+	}
+}
@@ -125,5 +125,5 @@ func (v *EqualityVerifier) Verify(z *big.Int) bool {
 	right1 := v.Group.Mul(r11, v.x1)
 	right2 := v.Group.Mul(r12, v.x2)
 
-	return left1.Cmp(right1) == 0 && left2.Cmp(right2) == 0
+	return common.ConstantTimeEq(left1, right1) && common.ConstantTimeEq(left2, right2)
 }
@@ -48,7 +48,7 @@ func (t *BlindedTrans) Verify(group *Group, g1, t1, G2, T2 *big.Int) bool {
 
 	// check hash:
 	hashNum := common.Hash(t.A, t.B)
-	if hashNum.Cmp(t.Hash) != 0 {
+	if !common.ConstantTimeEq(hashNum, t.Hash) {
 		return false
 	}
 
@@ -63,7 +63,7 @@ func (t *BlindedTrans) Verify(group *Group, g1, t1, G2, T2 *big.Int) bool {
 	right2 := group.Exp(T2, t.Hash)
 	right2 = group.Mul(t.B, right2)
 
-	if left1.Cmp(right1) == 0 && left2.Cmp(right2) == 0 {
+	if common.ConstantTimeEq(left1, right1) && common.ConstantTimeEq(left2, right2) {
 		return true
 	} else {
 		return false
@@ -199,7 +199,7 @@ func (v *BTEqualityVerifier) Verify(z *big.Int) (bool, *BlindedTrans,
 	G2 := v.Group.Exp(v.g2, v.gamma)
 	T2 := v.Group.Exp(v.t2, v.gamma)
 
-	if left1.Cmp(right1) == 0 && left2.Cmp(right2) == 0 {
+	if common.ConstantTimeEq(left1, right1) && common.ConstantTimeEq(left2, right2) {
 		return true, v.transcript, G2, T2
 	} else {
 		return false, nil, nil, nil
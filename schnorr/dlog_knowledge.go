@@ -22,6 +22,7 @@ import (
 	"math/big"
 
 	"github.com/awsong/crypto/common"
+	"github.com/awsong/crypto/transcript"
 )
 
 // Prover is a generalized Schnorr - while usually Schnorr proof is executed with one base,
@@ -65,16 +66,23 @@ func (p *Prover) GetProofRandomData() *big.Int {
 }
 
 func (p *Prover) GetProofData(challenge *big.Int) []*big.Int {
-	// z_i = r_i + challenge * secrets[i]
+	// z_i = r_i + challenge * secrets[i] mod group.Q
 	var proofData = make([]*big.Int, len(p.bases))
 	for i, _ := range proofData {
-		z_i := p.Group.Mul(challenge, p.secrets[i])
-		z_i = p.Group.Add(z_i, p.randomVals[i])
-		proofData[i] = z_i
+		proofData[i] = getResponse(p.Group, p.randomVals[i], challenge, p.secrets[i])
 	}
 	return proofData
 }
 
+// getResponse returns r + challenge * secret mod group.Q. Exponents are computed
+// modulo the order of the group, not modulo group.P - for a group of quadratic
+// residues modulo a safe prime P = 2Q + 1, a response reduced modulo P does not verify.
+func getResponse(group *Group, r, challenge, secret *big.Int) *big.Int {
+	z := new(big.Int).Mul(challenge, secret)
+	z.Add(z, r)
+	return z.Mod(z, group.Q)
+}
+
 // Proof presents all three messages in sigma protocol - useful when challenge
 // is generated by prover via Fiat-Shamir.
 type Proof struct {
@@ -92,6 +100,24 @@ func NewProof(proofRandomData, challenge *big.Int,
 	}
 }
 
+// ProveWithTranscript returns a non-interactive proof (Fiat-Shamir) where the challenge
+// is derived from the transcript after bases, y and the proof random data are appended to it.
+func (p *Prover) ProveWithTranscript(tr *transcript.Transcript) *Proof {
+	proofRandomData := p.GetProofRandomData()
+	challenge := getTranscriptChallenge(tr, p.Group, p.bases, p.y, proofRandomData)
+	return NewProof(proofRandomData, challenge, p.GetProofData(challenge))
+}
+
+// getTranscriptChallenge appends bases, y and proofRandomData to the transcript and
+// returns a challenge from [0, 2^(Q.BitLen()-1)).
+func getTranscriptChallenge(tr *transcript.Transcript, group *Group, bases []*big.Int,
+	y, proofRandomData *big.Int) *big.Int {
+	tr.AppendInts("schnorr-bases", bases...)
+	tr.AppendInts("schnorr-y", y)
+	tr.AppendInts("schnorr-t", proofRandomData)
+	return tr.ChallengeInt("schnorr-challenge", group.Q.BitLen()-1)
+}
+
 type Verifier struct {
 	Group           *Group
 	bases           []*big.Int
@@ -139,5 +165,21 @@ func (v *Verifier) Verify(proofData []*big.Int) bool {
 	right := v.Group.Exp(v.y, v.challenge)
 	right = v.Group.Mul(right, v.proofRandomData)
 
-	return left.Cmp(right) == 0
+	return common.ConstantTimeEq(left, right)
+}
+
+// VerifyWithTranscript verifies the proof obtained by Prover.ProveWithTranscript. The
+// transcript needs to be in the same state as the one the prover used.
+func (v *Verifier) VerifyWithTranscript(proof *Proof, bases []*big.Int, y *big.Int,
+	tr *transcript.Transcript) bool {
+	if len(proof.ProofData) != len(bases) {
+		return false
+	}
+	challenge := getTranscriptChallenge(tr, v.Group, bases, y, proof.ProofRandomData)
+	if !common.ConstantTimeEq(challenge, proof.Challenge) {
+		return false
+	}
+	v.SetProofRandomData(proof.ProofRandomData, bases, y)
+	v.SetChallenge(challenge)
+	return v.Verify(proof.ProofData)
 }
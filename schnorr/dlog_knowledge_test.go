@@ -18,10 +18,14 @@
 package schnorr
 
 import (
+	"math"
 	"math/big"
+	"os"
 	"testing"
+	"time"
 
 	"github.com/awsong/crypto/common"
+	"github.com/awsong/crypto/transcript"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -66,3 +70,209 @@ func TestDLogKnowledge(t *testing.T) {
 
 	assert.Equal(t, verified, true, "dlog knowledge proof does not work")
 }
+
+// TestDLogKnowledgeResponseModQ checks that the responses are reduced modulo the group
+// order Q and not modulo P. In a group of quadratic residues modulo a safe prime P = 2Q + 1,
+// r + challenge * secret reduced modulo P is in general not a valid response.
+func TestDLogKnowledgeResponseModQ(t *testing.T) {
+	group, err := NewGroupFromSafePrime(256)
+	if err != nil {
+		t.Errorf("error when creating Schnorr group: %v", err)
+	}
+	qMinusOne := new(big.Int).Sub(group.Q, big.NewInt(1))
+	bases := []*big.Int{group.G}
+	y := group.Exp(group.G, qMinusOne)
+
+	prover, err := NewProver(group, []*big.Int{qMinusOne}, bases, y)
+	if err != nil {
+		t.Errorf("error when creating Prover: %v", err)
+	}
+	verifier := NewVerifier(group)
+	verifier.SetProofRandomData(prover.GetProofRandomData(), bases, y)
+	// challenge * secret is much bigger than P
+	verifier.SetChallenge(qMinusOne)
+	proofData := prover.GetProofData(qMinusOne)
+
+	assert.True(t, proofData[0].Cmp(group.Q) < 0, "response needs to be reduced modulo Q")
+	assert.Equal(t, true, verifier.Verify(proofData), "dlog knowledge proof does not work")
+
+	// r + challenge * secret = (Q-1) + (Q-1)^2 = Q^2 - Q = 0 mod Q, but not mod P
+	z := getResponse(group, qMinusOne, qMinusOne, qMinusOne)
+	assert.Equal(t, 0, z.Sign(), "response needs to be reduced modulo Q")
+}
+
+// TestDLogKnowledgeTranscript demonstrates the non-interactive variant of the proof,
+// where the challenge is derived from a proof transcript.
+func TestDLogKnowledgeTranscript(t *testing.T) {
+	group, err := NewGroup(256)
+	if err != nil {
+		t.Errorf("error when creating Schnorr group: %v", err)
+	}
+	secret := common.GetRandomInt(group.Q)
+	bases := []*big.Int{group.G}
+	y := group.Exp(group.G, secret)
+
+	prover, err := NewProver(group, []*big.Int{secret}, bases, y)
+	if err != nil {
+		t.Errorf("error when creating Prover: %v", err)
+	}
+	proof := prover.ProveWithTranscript(transcript.NewTranscript("dlog knowledge test"))
+
+	verifier := NewVerifier(group)
+	verified := verifier.VerifyWithTranscript(proof, bases, y,
+		transcript.NewTranscript("dlog knowledge test"))
+	assert.Equal(t, true, verified, "dlog knowledge proof with transcript does not work")
+
+	// the challenge is bound to the protocol label
+	verified = verifier.VerifyWithTranscript(proof, bases, y,
+		transcript.NewTranscript("another protocol"))
+	assert.Equal(t, false, verified, "proof should not verify with a different transcript")
+}
+
+// TestTimingLeakage measures Verify for valid and invalid proofs and checks with Welch's
+// two-sample t-test that the execution times do not differ significantly (p < 0.01).
+// It guards against non-constant-time comparisons being introduced in Verify.
+// Invalid proofs are obtained by modifying proof random data, thus Verify does exactly
+// the same exponentiations for both kinds of proofs and only the final comparison differs.
+// The test is statistical (it fails in about 1% of runs even without a leak) and slow, thus
+// it runs only when the environment variable TIMING_TESTS is set to 1.
+func TestTimingLeakage(t *testing.T) {
+	if os.Getenv("TIMING_TESTS") != "1" {
+		t.Skip("skipping timing measurements, set TIMING_TESTS=1 to run them")
+	}
+	group, err := NewGroup(160)
+	if err != nil {
+		t.Fatalf("error when creating Schnorr group: %v", err)
+	}
+	bases := []*big.Int{group.GetRandomElement(), group.GetRandomElement()}
+	secrets := []*big.Int{common.GetRandomInt(group.Q), common.GetRandomInt(group.Q)}
+	y := group.Mul(group.Exp(bases[0], secrets[0]), group.Exp(bases[1], secrets[1]))
+	prover, err := NewProver(group, secrets, bases, y)
+	if err != nil {
+		t.Fatalf("error when creating Prover: %v", err)
+	}
+
+	n := 1000
+	validTimes := make([]float64, n)
+	invalidTimes := make([]float64, n)
+	measure := func(valid bool) float64 {
+		verifier := NewVerifier(group)
+		proofRandomData := prover.GetProofRandomData()
+		if !valid {
+			proofRandomData = group.Mul(proofRandomData, group.G)
+		}
+		verifier.SetProofRandomData(proofRandomData, bases, y)
+		proofData := prover.GetProofData(verifier.GetChallenge())
+		start := time.Now()
+		verified := verifier.Verify(proofData)
+		elapsed := time.Since(start)
+		if verified != valid {
+			t.Errorf("unexpected verification result for a valid=%v proof", valid)
+		}
+		return float64(elapsed.Nanoseconds())
+	}
+	for i := 0; i < 100; i++ { // warm up
+		measure(i%2 == 0)
+	}
+	// measurements are interleaved so that both samples are equally affected by the load
+	// of the machine
+	for i := 0; i < n; i++ {
+		validTimes[i] = measure(true)
+		invalidTimes[i] = measure(false)
+	}
+
+	// two-sided critical value for p < 0.01 (normal approximation, the samples are large)
+	tStat := welchTStatistic(validTimes, invalidTimes)
+	assert.True(t, math.Abs(tStat) < 2.576,
+		"Verify timing differs between valid and invalid proofs (t = %.2f)", tStat)
+}
+
+// welchTStatistic returns Welch's t statistic for two samples.
+func welchTStatistic(a, b []float64) float64 {
+	meanA, varA := meanAndVariance(a)
+	meanB, varB := meanAndVariance(b)
+	return (meanA - meanB) / math.Sqrt(varA/float64(len(a))+varB/float64(len(b)))
+}
+
+// meanAndVariance returns the mean and the (unbiased) sample variance.
+func meanAndVariance(x []float64) (float64, float64) {
+	mean := 0.0
+	for _, v := range x {
+		mean += v
+	}
+	mean /= float64(len(x))
+	variance := 0.0
+	for _, v := range x {
+		variance += (v - mean) * (v - mean)
+	}
+	return mean, variance / float64(len(x)-1)
+}
+
+// benchmarkGroups caches the groups used in benchmarks, because generating DSA
+// parameters takes much longer than the proof itself.
+var benchmarkGroups = map[int]*Group{}
+
+func getDLogKnowledgeBenchmarkParams(b *testing.B, qBitLength, n int) (*Group, []*big.Int,
+	[]*big.Int, *big.Int) {
+	group, ok := benchmarkGroups[qBitLength]
+	if !ok {
+		var err error
+		group, err = NewGroup(qBitLength)
+		if err != nil {
+			b.Fatalf("error when creating Schnorr group: %v", err)
+		}
+		benchmarkGroups[qBitLength] = group
+	}
+
+	secrets := make([]*big.Int, n)
+	bases := make([]*big.Int, n)
+	y := big.NewInt(1)
+	for i := 0; i < n; i++ {
+		secrets[i] = common.GetRandomInt(group.Q)
+		bases[i] = group.Exp(group.G, common.GetRandomInt(group.Q))
+		y = group.Mul(y, group.Exp(bases[i], secrets[i]))
+	}
+	return group, secrets, bases, y
+}
+
+func benchmarkDLogKnowledgeProve(b *testing.B, qBitLength, n int) {
+	group, secrets, bases, y := getDLogKnowledgeBenchmarkParams(b, qBitLength, n)
+	prover, err := NewProver(group, secrets, bases, y)
+	if err != nil {
+		b.Fatalf("error when creating Prover: %v", err)
+	}
+	challenge := common.GetRandomInt(group.Q)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		prover.GetProofRandomData()
+		prover.GetProofData(challenge)
+	}
+}
+
+func benchmarkDLogKnowledgeVerify(b *testing.B, qBitLength, n int) {
+	group, secrets, bases, y := getDLogKnowledgeBenchmarkParams(b, qBitLength, n)
+	prover, err := NewProver(group, secrets, bases, y)
+	if err != nil {
+		b.Fatalf("error when creating Prover: %v", err)
+	}
+	verifier := NewVerifier(group)
+	verifier.SetProofRandomData(prover.GetProofRandomData(), bases, y)
+	proofData := prover.GetProofData(verifier.GetChallenge())
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		verifier.Verify(proofData)
+	}
+}
+
+func BenchmarkSchnorrProve1Base160bit(b *testing.B)   { benchmarkDLogKnowledgeProve(b, 160, 1) }
+func BenchmarkSchnorrProve4Base160bit(b *testing.B)   { benchmarkDLogKnowledgeProve(b, 160, 4) }
+func BenchmarkSchnorrProve16Base160bit(b *testing.B)  { benchmarkDLogKnowledgeProve(b, 160, 16) }
+func BenchmarkSchnorrProve1Base256bit(b *testing.B)   { benchmarkDLogKnowledgeProve(b, 256, 1) }
+func BenchmarkSchnorrProve4Base256bit(b *testing.B)   { benchmarkDLogKnowledgeProve(b, 256, 4) }
+func BenchmarkSchnorrProve16Base256bit(b *testing.B)  { benchmarkDLogKnowledgeProve(b, 256, 16) }
+func BenchmarkSchnorrVerify1Base160bit(b *testing.B)  { benchmarkDLogKnowledgeVerify(b, 160, 1) }
+func BenchmarkSchnorrVerify4Base160bit(b *testing.B)  { benchmarkDLogKnowledgeVerify(b, 160, 4) }
+func BenchmarkSchnorrVerify16Base160bit(b *testing.B) { benchmarkDLogKnowledgeVerify(b, 160, 16) }
+func BenchmarkSchnorrVerify1Base256bit(b *testing.B)  { benchmarkDLogKnowledgeVerify(b, 256, 1) }
+func BenchmarkSchnorrVerify4Base256bit(b *testing.B)  { benchmarkDLogKnowledgeVerify(b, 256, 4) }
+func BenchmarkSchnorrVerify16Base256bit(b *testing.B) { benchmarkDLogKnowledgeVerify(b, 256, 16) }
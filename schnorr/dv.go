@@ -0,0 +1,185 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package schnorr
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/awsong/crypto/common"
+)
+
+// Based on:
+// M. Jakobsson, K. Sako, R. Impagliazzo. Designated Verifier Proofs and Their Applications. EUROCRYPT 1996.
+//
+// DVProver generates a non-interactive designated verifier proof of the knowledge of
+// secrets x_1,...,x_k such that y = g_1^x_1 * ... * g_k^x_k. The proof is an OR proof:
+// prover proves that it knows either the secrets or the verifier's secret key w
+// (verifierPublicKey = G^w). Prover knows only the secrets, thus the designated verifier
+// (who knows that it did not produce the proof itself) is convinced. However, the verifier
+// can produce (simulate) a valid proof using w (see DVVerifier.Simulate), thus the proof
+// does not convince anybody else - it is not transferable.
+// The challenge is computed by Fiat-Shamir: c = hash(g_1, ..., g_k, t1, t2, y, verifierPublicKey)
+// and c = c1 + c2 mod Q.
+type DVProver struct {
+	Group             *Group
+	prover            *Prover
+	bases             []*big.Int
+	y                 *big.Int
+	verifierPublicKey *big.Int
+}
+
+// NewDVProver returns DVProver for y = bases[0]^secrets[0] * ... * bases[k-1]^secrets[k-1]
+// and the designated verifier with the public key verifierPublicKey.
+func NewDVProver(group *Group, secrets, bases []*big.Int, y *big.Int,
+	verifierPublicKey *big.Int) (*DVProver, error) {
+	prover, err := NewProver(group, secrets, bases, y)
+	if err != nil {
+		return nil, err
+	}
+	if !group.IsElementInGroup(verifierPublicKey) {
+		return nil, fmt.Errorf("verifier's public key needs to be in the group")
+	}
+	return &DVProver{
+		Group:             group,
+		prover:            prover,
+		bases:             bases,
+		y:                 y,
+		verifierPublicKey: verifierPublicKey,
+	}, nil
+}
+
+// DVProof is a designated verifier proof: (t1, c1, z1) is a transcript for the knowledge of
+// secrets, (t2, c2, z2) a transcript for the knowledge of the verifier's secret key.
+type DVProof struct {
+	T1 *big.Int
+	T2 *big.Int
+	C1 *big.Int
+	C2 *big.Int
+	Z1 []*big.Int
+	Z2 *big.Int
+}
+
+// getDVChallenge returns hash(g_1, ..., g_k, t1, t2, y, verifierPublicKey) mod Q.
+func getDVChallenge(group *Group, bases []*big.Int, t1, t2, y,
+	verifierPublicKey *big.Int) *big.Int {
+	numbers := append(append([]*big.Int{}, bases...), t1, t2, y, verifierPublicKey)
+	c := common.Hash(numbers...)
+	return c.Mod(c, group.Q)
+}
+
+// Prove returns DVProof for y = bases[0]^secrets[0] * ... * bases[k-1]^secrets[k-1].
+func (p *DVProver) Prove() *DVProof {
+	// the proof for the verifier's secret key is simulated:
+	// t2 = G^z2 * verifierPublicKey^(-c2) for random c2, z2
+	c2 := common.GetRandomInt(p.Group.Q)
+	z2 := common.GetRandomInt(p.Group.Q)
+	t2 := p.Group.Mul(p.Group.Exp(p.Group.G, z2),
+		p.Group.Exp(p.verifierPublicKey, new(big.Int).Neg(c2)))
+
+	// t1 = g_1^r_1 * ... * g_k^r_k
+	t1 := p.prover.GetProofRandomData()
+
+	c := getDVChallenge(p.Group, p.bases, t1, t2, p.y, p.verifierPublicKey)
+	c1 := new(big.Int).Sub(c, c2)
+	c1.Mod(c1, p.Group.Q)
+
+	return &DVProof{
+		T1: t1,
+		T2: t2,
+		C1: c1,
+		C2: c2,
+		Z1: p.prover.GetProofData(c1),
+		Z2: z2,
+	}
+}
+
+// DVVerifier is the designated verifier, it holds the secret key w such that publicKey = G^w.
+type DVVerifier struct {
+	Group     *Group
+	PublicKey *big.Int
+	secretKey *big.Int
+}
+
+// NewDVVerifier generates a new key pair for the designated verifier.
+func NewDVVerifier(group *Group) *DVVerifier {
+	w := common.GetRandomInt(group.Q)
+	return &DVVerifier{
+		Group:     group,
+		PublicKey: group.Exp(group.G, w),
+		secretKey: w,
+	}
+}
+
+// Verify checks that c1 + c2 = hash(g_1, ..., g_k, t1, t2, y, PublicKey) mod Q,
+// g_1^z1_1 * ... * g_k^z1_k = t1 * y^c1 and G^z2 = t2 * PublicKey^c2.
+func (v *DVVerifier) Verify(proof *DVProof, bases []*big.Int, y *big.Int) bool {
+	if len(proof.Z1) != len(bases) {
+		return false
+	}
+	c := getDVChallenge(v.Group, bases, proof.T1, proof.T2, y, v.PublicKey)
+	cSum := new(big.Int).Add(proof.C1, proof.C2)
+	cSum.Mod(cSum, v.Group.Q)
+	if !common.ConstantTimeEq(c, cSum) {
+		return false
+	}
+
+	verifier := NewVerifier(v.Group)
+	verifier.SetProofRandomData(proof.T1, bases, y)
+	verifier.SetChallenge(proof.C1)
+	if !verifier.Verify(proof.Z1) {
+		return false
+	}
+
+	left := v.Group.Exp(v.Group.G, proof.Z2)
+	right := v.Group.Mul(proof.T2, v.Group.Exp(v.PublicKey, proof.C2))
+	return common.ConstantTimeEq(left, right)
+}
+
+// Simulate returns a valid DVProof for y without knowing the secrets (using the verifier's
+// secret key). This shows that the verifier cannot convince anybody else with the proofs
+// it receives.
+func (v *DVVerifier) Simulate(bases []*big.Int, y *big.Int) *DVProof {
+	// the proof for the secrets is simulated:
+	// t1 = g_1^z1_1 * ... * g_k^z1_k * y^(-c1) for random c1, z1_i
+	c1 := common.GetRandomInt(v.Group.Q)
+	z1 := make([]*big.Int, len(bases))
+	t1 := v.Group.Exp(y, new(big.Int).Neg(c1))
+	for i, base := range bases {
+		z1[i] = common.GetRandomInt(v.Group.Q)
+		t1 = v.Group.Mul(t1, v.Group.Exp(base, z1[i]))
+	}
+
+	r2 := common.GetRandomInt(v.Group.Q)
+	t2 := v.Group.Exp(v.Group.G, r2)
+	c := getDVChallenge(v.Group, bases, t1, t2, y, v.PublicKey)
+	c2 := new(big.Int).Sub(c, c1)
+	c2.Mod(c2, v.Group.Q)
+	z2 := new(big.Int).Mul(c2, v.secretKey)
+	z2.Add(z2, r2)
+	z2.Mod(z2, v.Group.Q)
+
+	return &DVProof{
+		T1: t1,
+		T2: t2,
+		C1: c1,
+		C2: c2,
+		Z1: z1,
+		Z2: z2,
+	}
+}
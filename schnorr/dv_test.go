@@ -0,0 +1,61 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package schnorr
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/awsong/crypto/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDesignatedVerifier(t *testing.T) {
+	group, err := NewGroup(256)
+	if err != nil {
+		t.Errorf("error when creating Schnorr group: %v", err)
+	}
+
+	secret := common.GetRandomInt(group.Q)
+	bases := []*big.Int{group.G}
+	y := group.Exp(group.G, secret)
+
+	verifier := NewDVVerifier(group)
+	prover, err := NewDVProver(group, []*big.Int{secret}, bases, y, verifier.PublicKey)
+	if err != nil {
+		t.Errorf("error in NewDVProver: %v", err)
+	}
+	proof := prover.Prove()
+	assert.Equal(t, true, verifier.Verify(proof, bases, y),
+		"designated verifier proof does not work")
+
+	// the proof is bound to the designated verifier
+	otherVerifier := NewDVVerifier(group)
+	assert.Equal(t, false, otherVerifier.Verify(proof, bases, y),
+		"proof should not verify for a different verifier")
+
+	// the verifier can simulate the proof without knowing the secret
+	yOther := group.GetRandomElement()
+	simulated := verifier.Simulate(bases, yOther)
+	assert.Equal(t, true, verifier.Verify(simulated, bases, yOther),
+		"simulated proof should be valid")
+
+	// the number of secrets and bases differs
+	_, err = NewDVProver(group, []*big.Int{secret, secret}, bases, y, verifier.PublicKey)
+	assert.NotNil(t, err, "NewDVProver should fail for mismatched secrets and bases")
+}
@@ -0,0 +1,100 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package schnorr
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/awsong/crypto/common"
+)
+
+// GeneratorProver and GeneratorVerifier are helpers for parameter verification ceremonies.
+// For a safe prime p = 2q + 1 the prover demonstrates that h is a generator of the full
+// multiplicative group Z_p* (h has order p-1 = 2q), not only of the subgroup of quadratic
+// residues (order q).
+// The order of any element of Z_p* is 1, 2, q or 2q. It holds h^q = 1 mod p for quadratic
+// residues and h^q = -1 mod p for quadratic non-residues (Euler's criterion). Thus h
+// has order 2q iff h^q = -1 mod p and h != -1 mod p (-1 has order 2).
+// Note that the statement involves only public values, so the verifier recomputes
+// h^q mod p and compares it with the value provided by the prover - no secret is involved.
+type GeneratorProver struct {
+	p *big.Int
+	h *big.Int
+}
+
+func NewGeneratorProver(p, h *big.Int) (*GeneratorProver, error) {
+	if _, err := getSafePrimeSubgroupOrder(p); err != nil {
+		return nil, err
+	}
+	return &GeneratorProver{
+		p: p,
+		h: h,
+	}, nil
+}
+
+// GetProofData returns h^q mod p, which is p-1 when h generates Z_p*.
+func (p *GeneratorProver) GetProofData() *big.Int {
+	q, _ := getSafePrimeSubgroupOrder(p.p)
+	return new(big.Int).Exp(p.h, q, p.p)
+}
+
+type GeneratorVerifier struct {
+	p *big.Int
+	q *big.Int
+	h *big.Int
+}
+
+func NewGeneratorVerifier(p, h *big.Int) (*GeneratorVerifier, error) {
+	q, err := getSafePrimeSubgroupOrder(p)
+	if err != nil {
+		return nil, err
+	}
+	return &GeneratorVerifier{
+		p: p,
+		q: q,
+		h: h,
+	}, nil
+}
+
+// Verify returns true if h is from (1, p-1) and h^q = -1 mod p (that is proofData = p-1).
+func (v *GeneratorVerifier) Verify(proofData *big.Int) bool {
+	one := big.NewInt(1)
+	minusOne := new(big.Int).Sub(v.p, one)
+	if v.h.Cmp(one) != 1 || v.h.Cmp(minusOne) != -1 {
+		return false
+	}
+	if !common.ConstantTimeEq(proofData, minusOne) {
+		return false
+	}
+	check := new(big.Int).Exp(v.h, v.q, v.p)
+	return common.ConstantTimeEq(check, proofData)
+}
+
+// getSafePrimeSubgroupOrder returns q = (p-1)/2 and checks that both p and q are prime.
+func getSafePrimeSubgroupOrder(p *big.Int) (*big.Int, error) {
+	if !p.ProbablyPrime(20) {
+		return nil, fmt.Errorf("p is not prime")
+	}
+	q := new(big.Int).Sub(p, big.NewInt(1))
+	q.Rsh(q, 1)
+	if !q.ProbablyPrime(20) {
+		return nil, fmt.Errorf("p is not a safe prime")
+	}
+	return q, nil
+}
@@ -0,0 +1,67 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package schnorr
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/awsong/crypto/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerator(t *testing.T) {
+	p, err := common.GetSafePrime(256)
+	if err != nil {
+		t.Errorf("error when generating safe prime: %v", err)
+	}
+
+	// find a generator of Z_p* and a quadratic residue
+	var h *big.Int
+	for {
+		h = common.GetRandomInt(p)
+		if h.Cmp(big.NewInt(1)) == 1 && big.Jacobi(h, p) == -1 {
+			break
+		}
+	}
+	qr := new(big.Int).Exp(h, big.NewInt(2), p)
+
+	prover, err := NewGeneratorProver(p, h)
+	if err != nil {
+		t.Errorf("error when creating GeneratorProver: %v", err)
+	}
+	verifier, err := NewGeneratorVerifier(p, h)
+	if err != nil {
+		t.Errorf("error when creating GeneratorVerifier: %v", err)
+	}
+	verified := verifier.Verify(prover.GetProofData())
+	assert.Equal(t, true, verified, "generator proof does not work")
+
+	prover, _ = NewGeneratorProver(p, qr)
+	verifier, _ = NewGeneratorVerifier(p, qr)
+	verified = verifier.Verify(prover.GetProofData())
+	assert.Equal(t, false, verified, "quadratic residue should not pass as generator")
+
+	minusOne := new(big.Int).Sub(p, big.NewInt(1))
+	verifier, _ = NewGeneratorVerifier(p, minusOne)
+	verified = verifier.Verify(minusOne)
+	assert.Equal(t, false, verified, "-1 should not pass as generator")
+
+	_, err = NewGeneratorVerifier(new(big.Int).Add(p, big.NewInt(2)), h)
+	assert.NotNil(t, err, "non safe prime should be rejected")
+}
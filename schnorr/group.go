@@ -30,6 +30,8 @@ import (
 // The actual value R is never used (although a random element from this group could be computed
 // by a^R for some random a from Z_p* - this element would have order Q and would be thus from this group),
 // the important thing is that Q divides P-1.
+// Group holds no mutable state (no precomputed tables or caches) - all methods only read P, G
+// and Q and return newly allocated values, thus a single *Group can be shared among goroutines.
 type Group struct {
 	P *big.Int // modulus of the group
 	G *big.Int // generator of subgroup
@@ -66,6 +68,51 @@ func NewGroup(qBitLength int) (*Group, error) {
 
 }
 
+// NewGroupFromSafePrime generates a safe prime P = 2 * Q + 1 of the given bit length and
+// returns the Group of quadratic residues modulo P (of prime order Q) with a random
+// generator G. For 112-bit security bits should be at least 2048 (3072 for 128-bit
+// security) - Q is of about the same size as P, thus the security depends only on the
+// hardness of discrete logarithm in Z_p*. Generation of large safe primes can take
+// a minute or more.
+func NewGroupFromSafePrime(bits int) (*Group, error) {
+	p, err := common.GetSafePrime(bits)
+	if err != nil {
+		return nil, err
+	}
+	q := new(big.Int).Rsh(p, 1) // (p-1)/2
+	return NewGroupFromPrimes(p, q)
+}
+
+// NewGroupFromPrimes returns the Group of quadratic residues modulo p (of order q) with
+// a random generator G. It returns an error if q != (p-1)/2 or if p or q is not prime.
+// Security requirements are the same as in NewGroupFromSafePrime.
+func NewGroupFromPrimes(p, q *big.Int) (*Group, error) {
+	qFromP, err := getSafePrimeSubgroupOrder(p)
+	if err != nil {
+		return nil, err
+	}
+	if qFromP.Cmp(q) != 0 {
+		return nil, fmt.Errorf("it does not hold q = (p-1)/2")
+	}
+
+	// h^2 is a quadratic residue, thus its order is 1 or q; it is 1 only for h = 1, p-1
+	pMinusOne := new(big.Int).Sub(p, big.NewInt(1))
+	var g *big.Int
+	for g == nil || g.Cmp(big.NewInt(1)) == 0 {
+		h, err := common.GetRandomIntInRange(big.NewInt(2), pMinusOne) // h from [2, p-2]
+		if err != nil {
+			return nil, err
+		}
+		g = new(big.Int).Exp(h, big.NewInt(2), p)
+	}
+
+	return &Group{
+		P: new(big.Int).Set(p),
+		G: g,
+		Q: new(big.Int).Set(q),
+	}, nil
+}
+
 func NewGroupFromParams(p, g, q *big.Int) *Group {
 	return &Group{
 		P: p,
@@ -83,6 +130,20 @@ func (g *Group) GetRandomElement() *big.Int {
 	return el
 }
 
+// Sample returns a random element of the group together with its discrete logarithm:
+// (element, exponent) where element = G^exponent and exponent is chosen uniformly from
+// [1, Q-1]. It returns an error if the group order is too small.
+func (g *Group) Sample() (*big.Int, *big.Int, error) {
+	if g.Q.Cmp(big.NewInt(2)) < 0 {
+		return nil, nil, fmt.Errorf("group order needs to be at least 2")
+	}
+	exponent, err := common.GetRandomIntInRange(big.NewInt(1), g.Q)
+	if err != nil {
+		return nil, nil, err
+	}
+	return g.Exp(g.G, exponent), exponent, nil
+}
+
 // Add computes x + y in Group. This means x + y mod group.P.
 func (g *Group) Add(x, y *big.Int) *big.Int {
 	r := new(big.Int)
@@ -114,6 +175,11 @@ func (g *Group) Inv(x *big.Int) *big.Int {
 	return new(big.Int).ModInverse(x, g.P)
 }
 
+// Div computes x * y^(-1) mod group.P.
+func (g *Group) Div(x, y *big.Int) *big.Int {
+	return g.Mul(x, g.Inv(y))
+}
+
 // IsElementInGroup returns true if x is in the group and false otherwise. Note that
 // an element x is in Schnorr group when x^group.Q = 1 mod group.P.
 func (g *Group) IsElementInGroup(x *big.Int) bool {
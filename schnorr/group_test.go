@@ -0,0 +1,129 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package schnorr
+
+import (
+	"math/big"
+	"sync"
+	"testing"
+
+	"github.com/awsong/crypto/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGroupSample(t *testing.T) {
+	group, err := NewGroup(160)
+	if err != nil {
+		t.Errorf("error when creating Schnorr group: %v", err)
+	}
+	for i := 0; i < 10; i++ {
+		el, exp, err := group.Sample()
+		if err != nil {
+			t.Errorf("error in Sample: %v", err)
+		}
+		assert.Equal(t, group.Exp(group.G, exp), el, "element is not G^exponent")
+		assert.True(t, exp.Sign() > 0 && exp.Cmp(group.Q) < 0, "exponent is not in [1, Q-1]")
+		assert.True(t, group.IsElementInGroup(el), "sampled element is not in the group")
+	}
+
+	_, _, err = NewGroupFromParams(big.NewInt(3), big.NewInt(1), big.NewInt(1)).Sample()
+	assert.NotNil(t, err, "Sample should fail for a trivial group")
+}
+
+// TestGroupConcurrentUse runs many provers and verifiers sharing a single *Group
+// concurrently. Run it with -race to detect data races.
+func TestNewGroupFromSafePrime(t *testing.T) {
+	bits := 2048
+	if testing.Short() {
+		bits = 512
+	}
+	group, err := NewGroupFromSafePrime(bits)
+	if err != nil {
+		t.Errorf("error in NewGroupFromSafePrime: %v", err)
+	}
+	assert.Equal(t, bits, group.P.BitLen(), "P does not have the given bit length")
+	assert.True(t, group.P.ProbablyPrime(20), "P is not prime")
+	assert.True(t, group.Q.ProbablyPrime(20), "Q is not prime")
+	pMinusOne := new(big.Int).Sub(group.P, big.NewInt(1))
+	assert.Equal(t, pMinusOne, new(big.Int).Lsh(group.Q, 1), "it does not hold P = 2 * Q + 1")
+	assert.True(t, group.IsElementInGroup(group.G), "G is not in the group")
+	assert.NotEqual(t, big.NewInt(1), group.G, "G is not a generator")
+}
+
+func TestNewGroupFromPrimes(t *testing.T) {
+	group, err := NewGroupFromPrimes(big.NewInt(23), big.NewInt(11))
+	if err != nil {
+		t.Errorf("error in NewGroupFromPrimes: %v", err)
+	}
+	assert.True(t, group.IsElementInGroup(group.G), "G is not in the group")
+	assert.NotEqual(t, big.NewInt(1), group.G, "G is not a generator")
+
+	_, err = NewGroupFromPrimes(big.NewInt(23), big.NewInt(7))
+	assert.NotNil(t, err, "NewGroupFromPrimes should fail for q != (p-1)/2")
+	_, err = NewGroupFromPrimes(big.NewInt(13), big.NewInt(6))
+	assert.NotNil(t, err, "NewGroupFromPrimes should fail for q that is not prime")
+	_, err = NewGroupFromPrimes(big.NewInt(25), big.NewInt(12))
+	assert.NotNil(t, err, "NewGroupFromPrimes should fail for p that is not prime")
+}
+
+func TestGroupInvDiv(t *testing.T) {
+	group, err := NewGroup(160)
+	if err != nil {
+		t.Errorf("error when creating Schnorr group: %v", err)
+	}
+	x := group.GetRandomElement()
+	y := group.GetRandomElement()
+	assert.Equal(t, big.NewInt(1), group.Mul(x, group.Inv(x)), "x * x^(-1) is not 1")
+	assert.Equal(t, x, group.Mul(group.Div(x, y), y), "x / y * y is not x")
+	assert.True(t, group.IsElementInGroup(group.Div(x, y)), "x / y is not in the group")
+}
+
+func TestGroupConcurrentUse(t *testing.T) {
+	group, err := NewGroup(160)
+	if err != nil {
+		t.Errorf("error when creating Schnorr group: %v", err)
+	}
+
+	n := 100
+	verified := make([]bool, n)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			base1, _, _ := group.Sample()
+			base2, _, _ := group.Sample()
+			bases := []*big.Int{base1, base2}
+			secrets := []*big.Int{common.GetRandomInt(group.Q), common.GetRandomInt(group.Q)}
+			y := group.Mul(group.Exp(bases[0], secrets[0]), group.Exp(bases[1], secrets[1]))
+			prover, err := NewProver(group, secrets, bases, y)
+			if err != nil {
+				return
+			}
+			verifier := NewVerifier(group)
+			verifier.SetProofRandomData(prover.GetProofRandomData(), bases, y)
+			challenge := verifier.GetChallenge()
+			verified[i] = verifier.Verify(prover.GetProofData(challenge))
+		}(i)
+	}
+	wg.Wait()
+
+	for i := 0; i < n; i++ {
+		assert.Equal(t, true, verified[i], "dlog knowledge proof with a shared group failed")
+	}
+}
@@ -0,0 +1,164 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package schnorr
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/awsong/crypto/common"
+)
+
+// Homomorphism is a group homomorphism f: Z_q^k -> G^m where G is a Schnorr group of
+// order q. Image returns f(x) for a vector x of length InputLen.
+type Homomorphism interface {
+	Image(x []*big.Int) []*big.Int
+	InputLen() int
+}
+
+// LinearHomomorphism is a homomorphism defined by a matrix of bases:
+// f(x_1,...,x_k)_j = bases[j][0]^x_1 * ... * bases[j][k-1]^x_k.
+// For example, a single row (g) gives Schnorr proof of knowledge of discrete logarithm,
+// rows (g) and (h) give the proof of discrete logarithm equality, and a single row (g, h)
+// gives the proof of knowledge of an opening of Pedersen commitment.
+type LinearHomomorphism struct {
+	group *Group
+	bases [][]*big.Int
+}
+
+func NewLinearHomomorphism(group *Group, bases [][]*big.Int) (*LinearHomomorphism, error) {
+	if len(bases) == 0 || len(bases[0]) == 0 {
+		return nil, fmt.Errorf("bases must not be empty")
+	}
+	for _, row := range bases {
+		if len(row) != len(bases[0]) {
+			return nil, fmt.Errorf("all rows of bases need to be of the same length")
+		}
+	}
+	return &LinearHomomorphism{
+		group: group,
+		bases: bases,
+	}, nil
+}
+
+func (f *LinearHomomorphism) Image(x []*big.Int) []*big.Int {
+	image := make([]*big.Int, len(f.bases))
+	for j, row := range f.bases {
+		image[j] = big.NewInt(1)
+		for i, base := range row {
+			image[j] = f.group.Mul(image[j], f.group.Exp(base, x[i]))
+		}
+	}
+	return image
+}
+
+func (f *LinearHomomorphism) InputLen() int {
+	return len(f.bases[0])
+}
+
+// HomomorphismProver proves the knowledge of x such that f(x) = y for a given homomorphism f.
+// This generalizes Prover which corresponds to LinearHomomorphism with one row.
+type HomomorphismProver struct {
+	Group      *Group
+	f          Homomorphism
+	secrets    []*big.Int
+	randomVals []*big.Int
+}
+
+func NewHomomorphismProver(group *Group, f Homomorphism,
+	secrets []*big.Int) (*HomomorphismProver, error) {
+	if len(secrets) != f.InputLen() {
+		return nil, fmt.Errorf("number of secrets does not match the homomorphism input length")
+	}
+	return &HomomorphismProver{
+		Group:   group,
+		f:       f,
+		secrets: secrets,
+	}, nil
+}
+
+// GetProofRandomData returns f(r) for random r from Z_q^k.
+func (p *HomomorphismProver) GetProofRandomData() []*big.Int {
+	randomVals := make([]*big.Int, len(p.secrets))
+	for i := range randomVals {
+		randomVals[i] = common.GetRandomInt(p.Group.Q)
+	}
+	p.randomVals = randomVals
+	return p.f.Image(randomVals)
+}
+
+// GetProofData returns z = r + challenge * x (mod q).
+func (p *HomomorphismProver) GetProofData(challenge *big.Int) []*big.Int {
+	proofData := make([]*big.Int, len(p.secrets))
+	for i := range proofData {
+		z := new(big.Int).Mul(challenge, p.secrets[i])
+		z.Add(z, p.randomVals[i])
+		proofData[i] = z.Mod(z, p.Group.Q)
+	}
+	return proofData
+}
+
+type HomomorphismVerifier struct {
+	Group           *Group
+	f               Homomorphism
+	y               []*big.Int
+	proofRandomData []*big.Int
+	challenge       *big.Int
+}
+
+func NewHomomorphismVerifier(group *Group, f Homomorphism,
+	y []*big.Int) *HomomorphismVerifier {
+	return &HomomorphismVerifier{
+		Group: group,
+		f:     f,
+		y:     y,
+	}
+}
+
+func (v *HomomorphismVerifier) SetProofRandomData(proofRandomData []*big.Int) {
+	v.proofRandomData = proofRandomData
+}
+
+func (v *HomomorphismVerifier) GetChallenge() *big.Int {
+	challenge := common.GetRandomInt(v.Group.Q)
+	v.challenge = challenge
+	return challenge
+}
+
+// SetChallenge is used when Fiat-Shamir is used - when challenge is generated using hash by the prover.
+func (v *HomomorphismVerifier) SetChallenge(challenge *big.Int) {
+	v.challenge = challenge
+}
+
+// Verify checks whether f(z) = y^challenge * f(r) (component-wise).
+func (v *HomomorphismVerifier) Verify(proofData []*big.Int) bool {
+	if len(proofData) != v.f.InputLen() || len(v.proofRandomData) != len(v.y) {
+		return false
+	}
+	left := v.f.Image(proofData)
+	if len(left) != len(v.y) {
+		return false
+	}
+	for j := range left {
+		right := v.Group.Mul(v.Group.Exp(v.y[j], v.challenge), v.proofRandomData[j])
+		if !common.ConstantTimeEq(left[j], right) {
+			return false
+		}
+	}
+	return true
+}
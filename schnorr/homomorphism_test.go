@@ -0,0 +1,86 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package schnorr
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/awsong/crypto/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func proveHomomorphism(group *Group, f Homomorphism, secrets, y []*big.Int) (bool, error) {
+	prover, err := NewHomomorphismProver(group, f, secrets)
+	if err != nil {
+		return false, err
+	}
+	verifier := NewHomomorphismVerifier(group, f, y)
+	verifier.SetProofRandomData(prover.GetProofRandomData())
+	challenge := verifier.GetChallenge()
+	return verifier.Verify(prover.GetProofData(challenge)), nil
+}
+
+// TestHomomorphism demonstrates how to prove the knowledge of a preimage under
+// homomorphisms given by a matrix of bases: the opening of Pedersen commitment
+// c = g^x * h^r and the equality of discrete logarithms of (g^x, h^x).
+func TestHomomorphism(t *testing.T) {
+	group, err := NewGroup(256)
+	if err != nil {
+		t.Errorf("error when creating Schnorr group: %v", err)
+	}
+	h := group.Exp(group.G, common.GetRandomInt(group.Q))
+	x := common.GetRandomInt(group.Q)
+	r := common.GetRandomInt(group.Q)
+
+	pedersen, err := NewLinearHomomorphism(group, [][]*big.Int{{group.G, h}})
+	if err != nil {
+		t.Errorf("error in NewLinearHomomorphism: %v", err)
+	}
+	c := pedersen.Image([]*big.Int{x, r})
+	proved, err := proveHomomorphism(group, pedersen, []*big.Int{x, r}, c)
+	if err != nil {
+		t.Errorf("error in homomorphism proof: %v", err)
+	}
+	assert.Equal(t, true, proved, "proof of Pedersen commitment opening failed")
+
+	dleq, err := NewLinearHomomorphism(group, [][]*big.Int{{group.G}, {h}})
+	if err != nil {
+		t.Errorf("error in NewLinearHomomorphism: %v", err)
+	}
+	y := []*big.Int{group.Exp(group.G, x), group.Exp(h, x)}
+	proved, err = proveHomomorphism(group, dleq, []*big.Int{x}, y)
+	if err != nil {
+		t.Errorf("error in homomorphism proof: %v", err)
+	}
+	assert.Equal(t, true, proved, "proof of discrete logarithm equality failed")
+
+	// g^x, h^x' for x != x'
+	y[1] = group.Exp(h, new(big.Int).Add(x, big.NewInt(1)))
+	proved, err = proveHomomorphism(group, dleq, []*big.Int{x}, y)
+	if err != nil {
+		t.Errorf("error in homomorphism proof: %v", err)
+	}
+	assert.Equal(t, false, proved, "proof should fail for different discrete logarithms")
+
+	_, err = NewHomomorphismProver(group, dleq, []*big.Int{x, r})
+	assert.NotNil(t, err, "number of secrets should match the homomorphism input length")
+
+	_, err = NewLinearHomomorphism(group, [][]*big.Int{{group.G, h}, {h}})
+	assert.NotNil(t, err, "rows of bases should be of the same length")
+}
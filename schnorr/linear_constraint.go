@@ -0,0 +1,185 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package schnorr
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/awsong/crypto/common"
+)
+
+// LinearConstraintProver proves the knowledge of secrets x = (x_1, ..., x_n) such that
+// y_j = g_j^(A_j1 * x_1 + ... + A_jn * x_n + b_j) for each output j, where the matrix A,
+// the vector b, the bases g_j and the outputs y_j are public. For example
+// y1 = g^x AND y2 = h^(a*x + b) is expressed with A = [[1], [a]], b = [0, b].
+// The constrained exponents are expressed in terms of the free variables x_i - the prover
+// chooses random r_i for free variables, sends t_j = g_j^(A_j * r) and responds with
+// z_i = r_i + challenge * x_i. The verifier checks g_j^(A_j * z) = t_j * (y_j * g_j^(-b_j))^challenge.
+type LinearConstraintProver struct {
+	Group      *Group
+	secrets    []*big.Int
+	bases      []*big.Int
+	A          [][]*big.Int
+	randomVals []*big.Int
+}
+
+// NewLinearConstraintProver returns LinearConstraintProver. It returns an error if the
+// dimensions of A, b, bases and y do not match or if the secrets do not satisfy
+// the constraints.
+func NewLinearConstraintProver(group *Group, secrets, bases []*big.Int, A [][]*big.Int,
+	b, y []*big.Int) (*LinearConstraintProver, error) {
+	if err := checkLinearConstraints(len(secrets), bases, A, b, y); err != nil {
+		return nil, err
+	}
+	for j := range A {
+		e := new(big.Int).Add(linearCombination(A[j], secrets), b[j])
+		if group.Exp(bases[j], e).Cmp(y[j]) != 0 {
+			return nil, fmt.Errorf("secrets do not satisfy the constraint %d", j)
+		}
+	}
+
+	return &LinearConstraintProver{
+		Group:   group,
+		secrets: secrets,
+		bases:   bases,
+		A:       A,
+	}, nil
+}
+
+// checkLinearConstraints returns an error if A is not a len(bases) x n matrix or if
+// the lengths of b and y do not match the number of rows of A.
+func checkLinearConstraints(n int, bases []*big.Int, A [][]*big.Int, b, y []*big.Int) error {
+	if n == 0 || len(A) == 0 {
+		return fmt.Errorf("at least one secret and one constraint are needed")
+	}
+	if len(bases) != len(A) || len(b) != len(A) || len(y) != len(A) {
+		return fmt.Errorf("number of bases, outputs and constraints shoud be the same")
+	}
+	for _, row := range A {
+		if len(row) != n {
+			return fmt.Errorf("each row of the constraint matrix needs %d coefficients", n)
+		}
+	}
+	return nil
+}
+
+// linearCombination returns coefficients[0] * x[0] + ... + coefficients[n-1] * x[n-1].
+func linearCombination(coefficients, x []*big.Int) *big.Int {
+	s := big.NewInt(0)
+	for i, a := range coefficients {
+		s.Add(s, new(big.Int).Mul(a, x[i]))
+	}
+	return s
+}
+
+func (p *LinearConstraintProver) GetProofRandomData() []*big.Int {
+	// t_j = g_j^(A_j1 * r_1 + ... + A_jn * r_n)
+	p.randomVals = make([]*big.Int, len(p.secrets))
+	for i := range p.randomVals {
+		p.randomVals[i] = common.GetRandomInt(p.Group.Q)
+	}
+	proofRandomData := make([]*big.Int, len(p.A))
+	for j := range p.A {
+		proofRandomData[j] = p.Group.Exp(p.bases[j], linearCombination(p.A[j], p.randomVals))
+	}
+	return proofRandomData
+}
+
+func (p *LinearConstraintProver) GetProofData(challenge *big.Int) []*big.Int {
+	// z_i = r_i + challenge * x_i mod group.Q
+	proofData := make([]*big.Int, len(p.secrets))
+	for i := range proofData {
+		z := new(big.Int).Mul(challenge, p.secrets[i])
+		z.Add(z, p.randomVals[i])
+		z.Mod(z, p.Group.Q)
+		proofData[i] = z
+	}
+	return proofData
+}
+
+type LinearConstraintVerifier struct {
+	Group           *Group
+	bases           []*big.Int
+	A               [][]*big.Int
+	b               []*big.Int
+	y               []*big.Int
+	proofRandomData []*big.Int
+	challenge       *big.Int
+}
+
+// NewLinearConstraintVerifier returns LinearConstraintVerifier for the outputs
+// y_j = g_j^(A_j * x + b_j). It returns an error if the dimensions do not match or
+// if some output is not in the group.
+func NewLinearConstraintVerifier(group *Group, bases []*big.Int, A [][]*big.Int,
+	b, y []*big.Int) (*LinearConstraintVerifier, error) {
+	if len(A) == 0 {
+		return nil, fmt.Errorf("at least one constraint is needed")
+	}
+	if err := checkLinearConstraints(len(A[0]), bases, A, b, y); err != nil {
+		return nil, err
+	}
+	for _, yj := range y {
+		if !group.IsElementInGroup(yj) {
+			return nil, fmt.Errorf("outputs need to be in the group")
+		}
+	}
+
+	return &LinearConstraintVerifier{
+		Group: group,
+		bases: bases,
+		A:     A,
+		b:     b,
+		y:     y,
+	}, nil
+}
+
+func (v *LinearConstraintVerifier) SetProofRandomData(proofRandomData []*big.Int) error {
+	if len(proofRandomData) != len(v.A) {
+		return fmt.Errorf("proof random data needs %d values", len(v.A))
+	}
+	v.proofRandomData = proofRandomData
+	return nil
+}
+
+func (v *LinearConstraintVerifier) GetChallenge() *big.Int {
+	challenge := common.GetRandomInt(v.Group.Q)
+	v.challenge = challenge
+	return challenge
+}
+
+// SetChallenge is used when Fiat-Shamir is used - when challenge is generated using hash by the prover.
+func (v *LinearConstraintVerifier) SetChallenge(challenge *big.Int) {
+	v.challenge = challenge
+}
+
+func (v *LinearConstraintVerifier) Verify(proofData []*big.Int) bool {
+	if len(proofData) != len(v.A[0]) {
+		return false
+	}
+	// check g_j^(A_j * z) = t_j * (y_j * g_j^(-b_j))^challenge for each j
+	for j := range v.A {
+		left := v.Group.Exp(v.bases[j], linearCombination(v.A[j], proofData))
+		yj := v.Group.Div(v.y[j], v.Group.Exp(v.bases[j], v.b[j]))
+		right := v.Group.Mul(v.proofRandomData[j], v.Group.Exp(yj, v.challenge))
+		if !common.ConstantTimeEq(left, right) {
+			return false
+		}
+	}
+	return true
+}
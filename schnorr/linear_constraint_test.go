@@ -0,0 +1,89 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package schnorr
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/awsong/crypto/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func proveLinearConstraint(group *Group, secrets, bases []*big.Int, A [][]*big.Int,
+	b, y, bVerifier []*big.Int) (bool, error) {
+	prover, err := NewLinearConstraintProver(group, secrets, bases, A, b, y)
+	if err != nil {
+		return false, err
+	}
+	verifier, err := NewLinearConstraintVerifier(group, bases, A, bVerifier, y)
+	if err != nil {
+		return false, err
+	}
+	if err := verifier.SetProofRandomData(prover.GetProofRandomData()); err != nil {
+		return false, err
+	}
+	challenge := verifier.GetChallenge()
+	return verifier.Verify(prover.GetProofData(challenge)), nil
+}
+
+// TestLinearConstraint demonstrates how the prover proves that it knows x such that
+// y1 = g^x and y2 = h^(a*x + b).
+func TestLinearConstraint(t *testing.T) {
+	group, err := NewGroup(256)
+	if err != nil {
+		t.Errorf("error when creating Schnorr group: %v", err)
+	}
+
+	h := group.Exp(group.G, common.GetRandomInt(group.Q))
+	x := common.GetRandomInt(group.Q)
+	a := big.NewInt(5)
+	b := big.NewInt(17)
+
+	bases := []*big.Int{group.G, h}
+	A := [][]*big.Int{{big.NewInt(1)}, {a}}
+	offsets := []*big.Int{big.NewInt(0), b}
+	y1 := group.Exp(group.G, x)
+	y2 := group.Exp(h, new(big.Int).Add(new(big.Int).Mul(a, x), b))
+	y := []*big.Int{y1, y2}
+
+	proved, err := proveLinearConstraint(group, []*big.Int{x}, bases, A, offsets, y, offsets)
+	if err != nil {
+		t.Errorf("error in linear constraint proof: %v", err)
+	}
+	assert.Equal(t, true, proved, "linear constraint proof failed")
+
+	// y2 does not satisfy the constraint
+	y2Wrong := group.Exp(h, new(big.Int).Add(new(big.Int).Mul(a, x), big.NewInt(18)))
+	_, err = NewLinearConstraintProver(group, []*big.Int{x}, bases, A, offsets,
+		[]*big.Int{y1, y2Wrong})
+	assert.NotNil(t, err, "prover should fail for the unsatisfied constraint")
+
+	// verifier checks a different constraint
+	proved, err = proveLinearConstraint(group, []*big.Int{x}, bases, A, offsets, y,
+		[]*big.Int{big.NewInt(0), big.NewInt(18)})
+	if err != nil {
+		t.Errorf("error in linear constraint proof: %v", err)
+	}
+	assert.Equal(t, false, proved, "linear constraint proof should fail for a different constraint")
+
+	// dimensions do not match
+	_, err = NewLinearConstraintVerifier(group, bases, [][]*big.Int{{big.NewInt(1)}, {a, a}},
+		offsets, y)
+	assert.NotNil(t, err, "verifier should fail for a malformed constraint matrix")
+}
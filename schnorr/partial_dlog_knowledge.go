@@ -137,12 +137,12 @@ func (v *PartialVerifier) verifyTriple(triple *common.Triple,
 	r1 := v.Group.Exp(triple.C, challenge) // (b, challenge)
 	right := v.Group.Mul(r1, triple.A)     // (r1, x1)
 
-	return left.Cmp(right) == 0
+	return common.ConstantTimeEq(left, right)
 }
 
 func (v *PartialVerifier) Verify(c1, z1, c2, z2 *big.Int) bool {
 	c := new(big.Int).Xor(c1, c2)
-	if c.Cmp(v.challenge) != 0 {
+	if !common.ConstantTimeEq(c, v.challenge) {
 		return false
 	}
 
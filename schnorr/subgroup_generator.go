@@ -0,0 +1,95 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package schnorr
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// SubgroupGeneratorProver and SubgroupGeneratorVerifier are helpers for parameter generation
+// ceremonies. For p = 2q + 1 (p, q prime) and the reference generator G of the subgroup
+// of order q, the prover holds a candidate generator g = G^x and a Pedersen commitment to it
+// c = G^x * H^r. Prover proves that g generates the subgroup of order q without revealing g.
+// Since the order of the subgroup is prime, g generates it iff g^q = 1 mod p and g != 1.
+// The first condition holds for any power of G (verifier checks G^q = 1 mod p and
+// that c is in the subgroup). The second condition means x != 0 mod q - prover proves
+// it by proving the knowledge of a, b such that G = c^a * H^b (a = x^-1, b = -r * x^-1
+// mod q). If x = 0, such a representation would reveal log_H(G).
+type SubgroupGeneratorProver struct {
+	*Prover
+	Commitment *big.Int
+}
+
+func NewSubgroupGeneratorProver(group *Group, h, x, r *big.Int) (*SubgroupGeneratorProver,
+	error) {
+	xInv := new(big.Int).ModInverse(x, group.Q)
+	if xInv == nil {
+		return nil, fmt.Errorf("g = G^x is not a generator (x = 0 mod q)")
+	}
+	// b = -r * x^-1 mod q
+	b := new(big.Int).Mul(r, xInv)
+	b.Neg(b)
+	b.Mod(b, group.Q)
+
+	c := group.Mul(group.Exp(group.G, x), group.Exp(h, r))
+	prover, err := NewProver(group, []*big.Int{xInv, b}, []*big.Int{c, h}, group.G)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SubgroupGeneratorProver{
+		Prover:     prover,
+		Commitment: c,
+	}, nil
+}
+
+type SubgroupGeneratorVerifier struct {
+	*Verifier
+	h *big.Int
+	c *big.Int
+}
+
+// NewSubgroupGeneratorVerifier returns an error if p != 2q + 1 or if G, h and c
+// are not in the subgroup of order q.
+func NewSubgroupGeneratorVerifier(group *Group, h, c *big.Int) (*SubgroupGeneratorVerifier,
+	error) {
+	q, err := getSafePrimeSubgroupOrder(group.P)
+	if err != nil {
+		return nil, err
+	}
+	if q.Cmp(group.Q) != 0 {
+		return nil, fmt.Errorf("it does not hold p = 2q + 1")
+	}
+	if group.G.Cmp(big.NewInt(1)) == 0 || !group.IsElementInGroup(group.G) {
+		return nil, fmt.Errorf("G is not a generator of the subgroup of order q")
+	}
+	if !group.IsElementInGroup(h) || !group.IsElementInGroup(c) {
+		return nil, fmt.Errorf("h and c need to be in the subgroup of order q")
+	}
+
+	return &SubgroupGeneratorVerifier{
+		Verifier: NewVerifier(group),
+		h:        h,
+		c:        c,
+	}, nil
+}
+
+func (v *SubgroupGeneratorVerifier) SetProofRandomData(proofRandomData *big.Int) {
+	v.Verifier.SetProofRandomData(proofRandomData, []*big.Int{v.c, v.h}, v.Group.G)
+}
@@ -0,0 +1,77 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package schnorr
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/awsong/crypto/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func getSafePrimeGroup() (*Group, error) {
+	p, err := common.GetSafePrime(256)
+	if err != nil {
+		return nil, err
+	}
+	q := new(big.Int).Sub(p, big.NewInt(1))
+	q.Rsh(q, 1)
+	// squares of elements different from -1, 1 generate the subgroup of order q
+	var g *big.Int
+	for {
+		g = common.GetRandomInt(p)
+		g.Exp(g, big.NewInt(2), p)
+		if g.Cmp(big.NewInt(1)) != 0 {
+			break
+		}
+	}
+	return NewGroupFromParams(p, g, q), nil
+}
+
+func TestSubgroupGenerator(t *testing.T) {
+	group, err := getSafePrimeGroup()
+	if err != nil {
+		t.Errorf("error when creating group: %v", err)
+	}
+	h := group.GetRandomElement()
+
+	x := common.GetRandomInt(group.Q)
+	r := common.GetRandomInt(group.Q)
+	prover, err := NewSubgroupGeneratorProver(group, h, x, r)
+	if err != nil {
+		t.Errorf("error when creating SubgroupGeneratorProver: %v", err)
+	}
+	verifier, err := NewSubgroupGeneratorVerifier(group, h, prover.Commitment)
+	if err != nil {
+		t.Errorf("error when creating SubgroupGeneratorVerifier: %v", err)
+	}
+
+	verifier.SetProofRandomData(prover.GetProofRandomData())
+	challenge := verifier.GetChallenge()
+	verified := verifier.Verify(prover.GetProofData(challenge))
+	assert.Equal(t, true, verified, "subgroup generator proof does not work")
+
+	_, err = NewSubgroupGeneratorProver(group, h, group.Q, r)
+	assert.NotNil(t, err, "g = 1 should be rejected")
+
+	// commitment outside of the subgroup
+	minusOne := new(big.Int).Sub(group.P, big.NewInt(1))
+	_, err = NewSubgroupGeneratorVerifier(group, h, minusOne)
+	assert.NotNil(t, err, "commitment outside of the subgroup should be rejected")
+}
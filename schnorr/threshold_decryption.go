@@ -0,0 +1,86 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package schnorr
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// ThresholdDecryptShareProver is a building block for threshold ElGamal decryption in
+// Schnorr group. The ElGamal secret key x is shared among parties (for example using Shamir
+// secret sharing), party i holds the key share x_i and the public verification key
+// h_i = g^x_i is known to everybody. To decrypt the ciphertext (c1, c2) = (g^r, m * y^r),
+// each party publishes the decryption share D_i = c1^x_i and proves (using Chaum-Pedersen
+// protocol, see EqualityProver) that log_g(h_i) = log_c1(D_i), without revealing x_i.
+// Note that there is no ElGamal package in this library - shares are combined by the caller
+// (c1^x = D_1^l_1 * ... * D_t^l_t where l_i are Lagrange coefficients).
+type ThresholdDecryptShareProver struct {
+	*EqualityProver
+	keyShare *big.Int
+	c1       *big.Int
+}
+
+func NewThresholdDecryptShareProver(group *Group, keyShare,
+	c1 *big.Int) *ThresholdDecryptShareProver {
+	return &ThresholdDecryptShareProver{
+		EqualityProver: NewEqualityProver(group),
+		keyShare:       keyShare,
+		c1:             c1,
+	}
+}
+
+// GetDecryptionShare returns D_i = c1^x_i.
+func (p *ThresholdDecryptShareProver) GetDecryptionShare() *big.Int {
+	return p.Group.Exp(p.c1, p.keyShare)
+}
+
+// GetProofRandomData returns g^r and c1^r for a random r.
+func (p *ThresholdDecryptShareProver) GetProofRandomData() (*big.Int, *big.Int) {
+	return p.EqualityProver.GetProofRandomData(p.keyShare, p.Group.G, p.c1)
+}
+
+type ThresholdDecryptShareVerifier struct {
+	*EqualityVerifier
+	verificationKey *big.Int
+	c1              *big.Int
+	share           *big.Int
+}
+
+// NewThresholdDecryptShareVerifier returns a verifier for the decryption share D_i
+// (share) of the ciphertext with the first component c1 for the party with
+// the verification key h_i = g^x_i. It returns an error if any of these is not a group element.
+func NewThresholdDecryptShareVerifier(group *Group, verificationKey, c1,
+	share *big.Int) (*ThresholdDecryptShareVerifier, error) {
+	for _, el := range []*big.Int{verificationKey, c1, share} {
+		if !group.IsElementInGroup(el) {
+			return nil, fmt.Errorf("verification key, c1 and share need to be in the group")
+		}
+	}
+	return &ThresholdDecryptShareVerifier{
+		EqualityVerifier: NewEqualityVerifier(group),
+		verificationKey:  verificationKey,
+		c1:               c1,
+		share:            share,
+	}, nil
+}
+
+// GetChallenge receives g^r and c1^r and returns a random challenge.
+func (v *ThresholdDecryptShareVerifier) GetChallenge(x1, x2 *big.Int) *big.Int {
+	return v.EqualityVerifier.GetChallenge(v.Group.G, v.c1, v.verificationKey, v.share, x1, x2)
+}
@@ -0,0 +1,87 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package schnorr
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/awsong/crypto/common"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestThresholdDecryptShare demonstrates 2-out-of-3 threshold ElGamal decryption where each
+// decryption share is accompanied by a proof of correctness.
+func TestThresholdDecryptShare(t *testing.T) {
+	group, err := NewGroup(256)
+	if err != nil {
+		t.Errorf("error when creating Schnorr group: %v", err)
+	}
+
+	// secret key x is shared using polynomial f of degree 1, x = f(0), x_i = f(i)
+	polynomial, _ := common.NewRandomPolynomial(1, group.Q)
+	x := polynomial.GetValue(big.NewInt(0))
+	y := group.Exp(group.G, x)
+	keyShares := make([]*big.Int, 3)
+	for i := range keyShares {
+		keyShares[i] = polynomial.GetValue(big.NewInt(int64(i + 1)))
+	}
+
+	// ElGamal encryption of m
+	m := group.GetRandomElement()
+	r := common.GetRandomInt(group.Q)
+	c1 := group.Exp(group.G, r)
+	c2 := group.Mul(m, group.Exp(y, r))
+
+	// parties 1 and 3 decrypt
+	parties := []int{0, 2}
+	shares := make(map[int]*big.Int)
+	for _, i := range parties {
+		prover := NewThresholdDecryptShareProver(group, keyShares[i], c1)
+		share := prover.GetDecryptionShare()
+		verificationKey := group.Exp(group.G, keyShares[i])
+		verifier, err := NewThresholdDecryptShareVerifier(group, verificationKey, c1, share)
+		if err != nil {
+			t.Errorf("error in NewThresholdDecryptShareVerifier: %v", err)
+		}
+
+		x1, x2 := prover.GetProofRandomData()
+		challenge := verifier.GetChallenge(x1, x2)
+		z := prover.GetProofData(challenge)
+		assert.Equal(t, true, verifier.Verify(z), "decryption share proof does not work")
+		shares[i] = share
+	}
+
+	// c1^x = D_1^l_1 * D_3^l_3 where l_1 = 3/2, l_3 = -1/2 (Lagrange coefficients at 0)
+	two := big.NewInt(2)
+	l1 := new(big.Int).Mul(big.NewInt(3), new(big.Int).ModInverse(two, group.Q))
+	l3 := new(big.Int).Neg(new(big.Int).ModInverse(two, group.Q))
+	c1ToX := group.Mul(group.Exp(shares[0], l1), group.Exp(shares[2], l3))
+	decrypted := group.Mul(c2, group.Inv(c1ToX))
+	assert.Equal(t, m, decrypted, "threshold decryption does not work")
+
+	// wrong decryption share
+	prover := NewThresholdDecryptShareProver(group, keyShares[1], c1)
+	verificationKey := group.Exp(group.G, keyShares[0])
+	verifier, _ := NewThresholdDecryptShareVerifier(group, verificationKey, c1,
+		prover.GetDecryptionShare())
+	x1, x2 := prover.GetProofRandomData()
+	challenge := verifier.GetChallenge(x1, x2)
+	z := prover.GetProofData(challenge)
+	assert.Equal(t, false, verifier.Verify(z), "proof for a wrong key share should fail")
+}
@@ -0,0 +1,75 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package schnorr
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// WellFormedPublicKeyProver proves that a public key y = g^x mod p is well-formed: that
+// y is in the subgroup of order q generated by g and y != 1. Membership in the subgroup can be
+// checked by anybody (y^q = 1 mod p), but wrapping it into a sigma protocol (Schnorr proof of
+// knowledge of x) enables composition with other proofs.
+type WellFormedPublicKeyProver struct {
+	*Prover
+}
+
+func NewWellFormedPublicKeyProver(group *Group, x *big.Int) (*WellFormedPublicKeyProver,
+	error) {
+	if new(big.Int).Mod(x, group.Q).Sign() == 0 {
+		return nil, fmt.Errorf("secret key must not be 0 mod q")
+	}
+	y := group.Exp(group.G, x)
+	prover, err := NewProver(group, []*big.Int{x}, []*big.Int{group.G}, y)
+	if err != nil {
+		return nil, err
+	}
+
+	return &WellFormedPublicKeyProver{prover}, nil
+}
+
+type WellFormedPublicKeyVerifier struct {
+	*Verifier
+	y *big.Int
+}
+
+func NewWellFormedPublicKeyVerifier(group *Group, y *big.Int) *WellFormedPublicKeyVerifier {
+	return &WellFormedPublicKeyVerifier{
+		Verifier: NewVerifier(group),
+		y:        y,
+	}
+}
+
+func (v *WellFormedPublicKeyVerifier) SetProofRandomData(proofRandomData *big.Int) {
+	v.Verifier.SetProofRandomData(proofRandomData, []*big.Int{v.Group.G}, v.y)
+}
+
+// Verify checks that 1 < y < p, y^q = 1 mod p and that the proof of knowledge of
+// the discrete logarithm of y is valid.
+func (v *WellFormedPublicKeyVerifier) Verify(proofData []*big.Int) bool {
+	if v.y.Cmp(big.NewInt(1)) <= 0 || v.y.Cmp(v.Group.P) >= 0 ||
+		!v.Group.IsElementInGroup(v.y) {
+		return false
+	}
+	if len(proofData) != 1 {
+		return false
+	}
+
+	return v.Verifier.Verify(proofData)
+}
@@ -0,0 +1,62 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package schnorr
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/awsong/crypto/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func proveWellFormedPublicKey(group *Group, prover *WellFormedPublicKeyProver,
+	y *big.Int) bool {
+	verifier := NewWellFormedPublicKeyVerifier(group, y)
+	verifier.SetProofRandomData(prover.GetProofRandomData())
+	challenge := verifier.GetChallenge()
+	return verifier.Verify(prover.GetProofData(challenge))
+}
+
+func TestWellFormedPublicKey(t *testing.T) {
+	group, err := NewGroup(256)
+	if err != nil {
+		t.Errorf("error when creating Schnorr group: %v", err)
+	}
+
+	x := common.GetRandomInt(group.Q)
+	x.Add(x, big.NewInt(1)) // x != 0
+	prover, err := NewWellFormedPublicKeyProver(group, x)
+	if err != nil {
+		t.Errorf("error when creating WellFormedPublicKeyProver: %v", err)
+	}
+	y := group.Exp(group.G, x)
+	assert.Equal(t, true, proveWellFormedPublicKey(group, prover, y),
+		"proof of well-formed public key failed")
+
+	// y * (p-1) is not in the subgroup of order q
+	notInSubgroup := group.Mul(y, new(big.Int).Sub(group.P, big.NewInt(1)))
+	assert.Equal(t, false, proveWellFormedPublicKey(group, prover, notInSubgroup),
+		"public key not in the subgroup should not be accepted")
+
+	assert.Equal(t, false, proveWellFormedPublicKey(group, prover, big.NewInt(1)),
+		"public key 1 should not be accepted")
+
+	_, err = NewWellFormedPublicKeyProver(group, group.Q)
+	assert.NotNil(t, err, "secret key 0 mod q should not be accepted")
+}
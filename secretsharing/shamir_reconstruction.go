@@ -0,0 +1,151 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package secretsharing
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/awsong/crypto/pedersen"
+	"github.com/awsong/crypto/schnorr"
+)
+
+// ShamirReconstructionProver proves that t+1 shares s_i = f(x_i), committed individually
+// in Pedersen commitments c_i = g^s_i * h^r_i, reconstruct the public secret S = f(0),
+// where f is a polynomial of degree t over Z_q (q is the order of the Pedersen group).
+// Any t+1 points lie on a polynomial of degree t, so what needs to be proved is
+// S = sum_i lambda_i * s_i mod q where lambda_i are the Lagrange coefficients for x = 0.
+// Verifier computes c = prod_i c_i^lambda_i = g^S * h^rho (rho = sum_i lambda_i * r_i)
+// and prover proves the knowledge of rho such that c * g^(-S) = h^rho (Schnorr proof).
+type ShamirReconstructionProver struct {
+	*schnorr.Prover
+}
+
+// NewShamirReconstructionProver returns ShamirReconstructionProver for the shares
+// f(indices[i]) committed in shares[i]. It returns an error if the shares do not
+// reconstruct the secret.
+func NewShamirReconstructionProver(params *pedersen.Params, indices []*big.Int,
+	shares []*pedersen.Committer, secret *big.Int) (*ShamirReconstructionProver, error) {
+	if len(indices) != len(shares) {
+		return nil, fmt.Errorf("the number of indices and shares should be the same")
+	}
+	lambdas, err := getLagrangeCoefficients(indices, params.Group.Q)
+	if err != nil {
+		return nil, err
+	}
+
+	s := big.NewInt(0)
+	rho := big.NewInt(0)
+	for i, share := range shares {
+		si, ri := share.GetDecommitMsg()
+		s.Add(s, new(big.Int).Mul(lambdas[i], si))
+		rho.Add(rho, new(big.Int).Mul(lambdas[i], ri))
+	}
+	s.Mod(s, params.Group.Q)
+	rho.Mod(rho, params.Group.Q)
+	if s.Cmp(new(big.Int).Mod(secret, params.Group.Q)) != 0 {
+		return nil, fmt.Errorf("the shares do not reconstruct the secret")
+	}
+
+	commitments := make([]*big.Int, len(shares))
+	for i, share := range shares {
+		commitments[i] = share.Commitment
+	}
+	y := getReconstructionCommitment(params, lambdas, commitments, secret)
+	prover, err := schnorr.NewProver(params.Group, []*big.Int{rho}, []*big.Int{params.H}, y)
+	if err != nil {
+		return nil, err
+	}
+	return &ShamirReconstructionProver{
+		Prover: prover,
+	}, nil
+}
+
+// getLagrangeCoefficients returns lambda_i = prod_(j != i) x_j / (x_j - x_i) mod q,
+// such that f(0) = sum_i lambda_i * f(x_i) for each polynomial f of degree len(indices)-1.
+func getLagrangeCoefficients(indices []*big.Int, q *big.Int) ([]*big.Int, error) {
+	if len(indices) == 0 {
+		return nil, fmt.Errorf("at least one share is needed")
+	}
+	lambdas := make([]*big.Int, len(indices))
+	for i, xi := range indices {
+		numerator := big.NewInt(1)
+		denominator := big.NewInt(1)
+		for j, xj := range indices {
+			if i == j {
+				continue
+			}
+			numerator.Mul(numerator, xj)
+			numerator.Mod(numerator, q)
+			denominator.Mul(denominator, new(big.Int).Sub(xj, xi))
+			denominator.Mod(denominator, q)
+		}
+		denominatorInv := new(big.Int).ModInverse(denominator, q)
+		if denominatorInv == nil {
+			return nil, fmt.Errorf("indices need to be distinct modulo q")
+		}
+		lambdas[i] = numerator.Mul(numerator, denominatorInv)
+		lambdas[i].Mod(lambdas[i], q)
+	}
+	return lambdas, nil
+}
+
+// getReconstructionCommitment returns prod_i c_i^lambda_i * g^(-S) = h^rho.
+func getReconstructionCommitment(params *pedersen.Params, lambdas, commitments []*big.Int,
+	secret *big.Int) *big.Int {
+	group := params.Group
+	c := big.NewInt(1)
+	for i, ci := range commitments {
+		c = group.Mul(c, group.Exp(ci, lambdas[i]))
+	}
+	return group.Div(c, group.Exp(group.G, secret))
+}
+
+type ShamirReconstructionVerifier struct {
+	*schnorr.Verifier
+	h *big.Int
+	y *big.Int
+}
+
+// NewShamirReconstructionVerifier returns ShamirReconstructionVerifier for the commitments
+// to the shares f(indices[i]) and the claimed secret.
+func NewShamirReconstructionVerifier(params *pedersen.Params, indices, commitments []*big.Int,
+	secret *big.Int) (*ShamirReconstructionVerifier, error) {
+	if len(indices) != len(commitments) {
+		return nil, fmt.Errorf("the number of indices and commitments should be the same")
+	}
+	lambdas, err := getLagrangeCoefficients(indices, params.Group.Q)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range commitments {
+		if !params.Group.IsElementInGroup(c) {
+			return nil, fmt.Errorf("commitments need to be in the group")
+		}
+	}
+
+	return &ShamirReconstructionVerifier{
+		Verifier: schnorr.NewVerifier(params.Group),
+		h:        params.H,
+		y:        getReconstructionCommitment(params, lambdas, commitments, secret),
+	}, nil
+}
+
+func (v *ShamirReconstructionVerifier) SetProofRandomData(proofRandomData *big.Int) {
+	v.Verifier.SetProofRandomData(proofRandomData, []*big.Int{v.h}, v.y)
+}
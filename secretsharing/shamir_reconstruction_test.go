@@ -0,0 +1,99 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package secretsharing
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/awsong/crypto/common"
+	"github.com/awsong/crypto/pedersen"
+	"github.com/stretchr/testify/assert"
+)
+
+func proveShamirReconstruction(params *pedersen.Params, indices []*big.Int,
+	shares []*pedersen.Committer, commitments []*big.Int, secret *big.Int) (bool, error) {
+	prover, err := NewShamirReconstructionProver(params, indices, shares, secret)
+	if err != nil {
+		return false, err
+	}
+	verifier, err := NewShamirReconstructionVerifier(params, indices, commitments, secret)
+	if err != nil {
+		return false, err
+	}
+	verifier.SetProofRandomData(prover.GetProofRandomData())
+	challenge := verifier.GetChallenge()
+	return verifier.Verify(prover.GetProofData(challenge)), nil
+}
+
+func TestShamirReconstruction(t *testing.T) {
+	params, err := pedersen.GenerateParams(256)
+	if err != nil {
+		t.Errorf("error when generating Pedersen params: %v", err)
+	}
+	q := params.Group.Q
+
+	threshold := 3 // polynomial of degree 2
+	secret := common.GetRandomInt(q)
+	polynomial, err := common.NewRandomPolynomial(threshold-1, q)
+	if err != nil {
+		t.Errorf("error when creating polynomial: %v", err)
+	}
+	polynomial.SetCoefficient(0, secret)
+
+	indices := []*big.Int{big.NewInt(2), big.NewInt(5), big.NewInt(7)}
+	shares := make([]*pedersen.Committer, len(indices))
+	commitments := make([]*big.Int, len(indices))
+	for i, x := range indices {
+		shares[i] = pedersen.NewCommitter(params)
+		commitments[i], err = shares[i].GetCommitMsg(polynomial.GetValue(x))
+		if err != nil {
+			t.Errorf("error when committing to share: %v", err)
+		}
+	}
+
+	proved, err := proveShamirReconstruction(params, indices, shares, commitments, secret)
+	if err != nil {
+		t.Errorf("error in Shamir reconstruction proof: %v", err)
+	}
+	assert.Equal(t, true, proved, "Shamir reconstruction proof failed")
+
+	// modified share
+	modified := pedersen.NewCommitter(params)
+	s1, _ := shares[1].GetDecommitMsg()
+	modifiedCommitment, err := modified.GetCommitMsg(new(big.Int).Add(s1, big.NewInt(1)))
+	if err != nil {
+		t.Errorf("error when committing to share: %v", err)
+	}
+	modifiedShares := []*pedersen.Committer{shares[0], modified, shares[2]}
+	_, err = NewShamirReconstructionProver(params, indices, modifiedShares, secret)
+	assert.NotNil(t, err, "prover should fail for a modified share")
+
+	// the verifier receives a commitment to the modified share
+	modifiedCommitments := []*big.Int{commitments[0], modifiedCommitment, commitments[2]}
+	proved, err = proveShamirReconstruction(params, indices, shares, modifiedCommitments, secret)
+	if err != nil {
+		t.Errorf("error in Shamir reconstruction proof: %v", err)
+	}
+	assert.Equal(t, false, proved, "Shamir reconstruction proof should fail for a modified share")
+
+	// duplicate indices
+	_, err = NewShamirReconstructionVerifier(params,
+		[]*big.Int{big.NewInt(2), big.NewInt(2), big.NewInt(7)}, commitments, secret)
+	assert.NotNil(t, err, "duplicate indices should fail")
+}
@@ -0,0 +1,98 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package sigma
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/awsong/crypto/common"
+	"github.com/awsong/crypto/schnorr"
+)
+
+// SchnorrProtocol is a Protocol for the proof of knowledge of x such that y = g^x in
+// schnorr.Group. Challenges are chosen from [0, 2^(l-1)) where l is the bit length of
+// the group order, thus SchnorrProtocols over groups with orders of the same bit length can
+// be composed using OR.
+type SchnorrProtocol struct {
+	group    *schnorr.Group
+	y        *big.Int
+	prover   *schnorr.Prover // nil when the secret is not known
+	verifier *schnorr.Verifier
+}
+
+// NewSchnorrProver returns SchnorrProtocol for the prover who knows secret x such that y = g^x.
+func NewSchnorrProver(group *schnorr.Group, secret, y *big.Int) (*SchnorrProtocol, error) {
+	prover, err := schnorr.NewProver(group, []*big.Int{secret}, []*big.Int{group.G}, y)
+	if err != nil {
+		return nil, err
+	}
+	p := NewSchnorrVerifier(group, y)
+	p.prover = prover
+	return p, nil
+}
+
+// NewSchnorrVerifier returns SchnorrProtocol for y. It can be used by the verifier and
+// by the prover who does not know the discrete logarithm of y (to simulate the proof in OR).
+func NewSchnorrVerifier(group *schnorr.Group, y *big.Int) *SchnorrProtocol {
+	return &SchnorrProtocol{
+		group:    group,
+		y:        y,
+		verifier: schnorr.NewVerifier(group),
+	}
+}
+
+func (p *SchnorrProtocol) GetProofRandomData() []byte {
+	return p.prover.GetProofRandomData().Bytes()
+}
+
+func (p *SchnorrProtocol) SetProofRandomData(data []byte) error {
+	t := new(big.Int).SetBytes(data)
+	if !p.group.IsElementInGroup(t) {
+		return fmt.Errorf("proof random data is not in the group")
+	}
+	p.verifier.SetProofRandomData(t, []*big.Int{p.group.G}, p.y)
+	return nil
+}
+
+func (p *SchnorrProtocol) GetChallenge() *big.Int {
+	b := new(big.Int).Lsh(big.NewInt(1), uint(p.group.Q.BitLen()-1))
+	challenge := common.GetRandomInt(b)
+	p.verifier.SetChallenge(challenge)
+	return challenge
+}
+
+func (p *SchnorrProtocol) SetChallenge(challenge *big.Int) {
+	p.verifier.SetChallenge(challenge)
+}
+
+func (p *SchnorrProtocol) GetProofData(challenge *big.Int) []byte {
+	return p.prover.GetProofData(challenge)[0].Bytes()
+}
+
+func (p *SchnorrProtocol) Verify(data []byte) bool {
+	return p.verifier.Verify([]*big.Int{new(big.Int).SetBytes(data)})
+}
+
+// Simulate chooses z randomly and computes t = g^z * y^(-challenge).
+func (p *SchnorrProtocol) Simulate(challenge *big.Int) ([]byte, []byte) {
+	z := common.GetRandomInt(p.group.Q)
+	t := p.group.Mul(p.group.Exp(p.group.G, z),
+		p.group.Exp(p.y, new(big.Int).Neg(challenge)))
+	return t.Bytes(), z.Bytes()
+}
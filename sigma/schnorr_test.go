@@ -0,0 +1,54 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package sigma
+
+import (
+	"testing"
+
+	"github.com/awsong/crypto/common"
+	"github.com/awsong/crypto/schnorr"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchnorrProtocol(t *testing.T) {
+	group, err := schnorr.NewGroup(256)
+	if err != nil {
+		t.Errorf("error when creating group: %v", err)
+	}
+	secret := common.GetRandomInt(group.Q)
+	y := group.Exp(group.G, secret)
+
+	prover, err := NewSchnorrProver(group, secret, y)
+	if err != nil {
+		t.Errorf("error when creating SchnorrProtocol: %v", err)
+	}
+	verifier := NewSchnorrVerifier(group, y)
+	assert.Equal(t, true, run(prover, verifier), "Schnorr protocol failed")
+
+	// simulated transcript
+	challenge := verifier.GetChallenge()
+	proofRandomData, proofData := NewSchnorrVerifier(group, y).Simulate(challenge)
+	err = verifier.SetProofRandomData(proofRandomData)
+	if err != nil {
+		t.Errorf("error when calling SetProofRandomData: %v", err)
+	}
+	assert.Equal(t, true, verifier.Verify(proofData), "simulated transcript was not accepted")
+
+	err = verifier.SetProofRandomData(group.P.Bytes())
+	assert.NotNil(t, err, "proof random data outside of the group should be rejected")
+}
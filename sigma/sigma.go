@@ -0,0 +1,267 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package sigma
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math/big"
+)
+
+// Protocol is a sigma protocol with messages encoded as bytes. The same type is used
+// by the prover (GetProofRandomData, GetProofData) and by the verifier (SetProofRandomData,
+// GetChallenge or SetChallenge, Verify). Simulate returns an accepting transcript
+// for a given challenge without knowing the secret, which is needed by OR.
+//
+// Protocols can be composed using AND and OR, for example:
+//
+//	OR(1, p1, AND(p2, p3))
+//
+// Challenges of all the protocols composed by OR need to be from the same
+// interval [0, 2^k), because OR splits the challenge using XOR.
+type Protocol interface {
+	GetProofRandomData() []byte
+	SetProofRandomData(data []byte) error
+	GetChallenge() *big.Int
+	SetChallenge(challenge *big.Int)
+	GetProofData(challenge *big.Int) []byte
+	Verify(data []byte) bool
+	Simulate(challenge *big.Int) ([]byte, []byte)
+}
+
+type and struct {
+	protos []Protocol
+}
+
+// AND returns a Protocol which proves all the given protocols. The same challenge is
+// used for all of them.
+func AND(protos ...Protocol) Protocol {
+	return &and{
+		protos: protos,
+	}
+}
+
+func (a *and) GetProofRandomData() []byte {
+	parts := make([][]byte, len(a.protos))
+	for i, proto := range a.protos {
+		parts[i] = proto.GetProofRandomData()
+	}
+	return encodeParts(parts)
+}
+
+func (a *and) SetProofRandomData(data []byte) error {
+	parts, err := decodeParts(data, len(a.protos))
+	if err != nil {
+		return err
+	}
+	for i, proto := range a.protos {
+		if err := proto.SetProofRandomData(parts[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (a *and) GetChallenge() *big.Int {
+	challenge := a.protos[0].GetChallenge()
+	a.SetChallenge(challenge)
+	return challenge
+}
+
+func (a *and) SetChallenge(challenge *big.Int) {
+	for _, proto := range a.protos {
+		proto.SetChallenge(challenge)
+	}
+}
+
+func (a *and) GetProofData(challenge *big.Int) []byte {
+	parts := make([][]byte, len(a.protos))
+	for i, proto := range a.protos {
+		parts[i] = proto.GetProofData(challenge)
+	}
+	return encodeParts(parts)
+}
+
+func (a *and) Verify(data []byte) bool {
+	parts, err := decodeParts(data, len(a.protos))
+	if err != nil {
+		return false
+	}
+	for i, proto := range a.protos {
+		if !proto.Verify(parts[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func (a *and) Simulate(challenge *big.Int) ([]byte, []byte) {
+	randomParts := make([][]byte, len(a.protos))
+	parts := make([][]byte, len(a.protos))
+	for i, proto := range a.protos {
+		randomParts[i], parts[i] = proto.Simulate(challenge)
+	}
+	return encodeParts(randomParts), encodeParts(parts)
+}
+
+type or struct {
+	known      int
+	protos     []Protocol
+	challenge  *big.Int
+	challenges []*big.Int
+	proofData  [][]byte // proof data of the simulated protocols
+}
+
+// OR returns a Protocol which proves at least one of the given protocols. The prover
+// needs to know the secret of the protocol with index known, all the other protocols are
+// simulated [Cramer, Damgard, Schoenmakers]. The verifier does not know which of the
+// protocols is proved, the value known is ignored when the Protocol is used by the verifier.
+// Challenges of the protocols XOR to the challenge of the OR protocol.
+func OR(known int, protos ...Protocol) Protocol {
+	return &or{
+		known:  known,
+		protos: protos,
+	}
+}
+
+func (o *or) GetProofRandomData() []byte {
+	randomParts := make([][]byte, len(o.protos))
+	o.challenges = make([]*big.Int, len(o.protos))
+	o.proofData = make([][]byte, len(o.protos))
+	for i, proto := range o.protos {
+		if i == o.known {
+			randomParts[i] = proto.GetProofRandomData()
+			continue
+		}
+		// the challenge is chosen by the prover from the protocol's challenge space
+		o.challenges[i] = proto.GetChallenge()
+		randomParts[i], o.proofData[i] = proto.Simulate(o.challenges[i])
+	}
+	return encodeParts(randomParts)
+}
+
+func (o *or) SetProofRandomData(data []byte) error {
+	parts, err := decodeParts(data, len(o.protos))
+	if err != nil {
+		return err
+	}
+	for i, proto := range o.protos {
+		if err := proto.SetProofRandomData(parts[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (o *or) GetChallenge() *big.Int {
+	o.challenge = o.protos[0].GetChallenge()
+	return o.challenge
+}
+
+func (o *or) SetChallenge(challenge *big.Int) {
+	o.challenge = challenge
+}
+
+// GetProofData returns the challenges and the proof data of all the protocols.
+func (o *or) GetProofData(challenge *big.Int) []byte {
+	c := new(big.Int).Set(challenge)
+	for i, ci := range o.challenges {
+		if i != o.known {
+			c.Xor(c, ci)
+		}
+	}
+	o.challenges[o.known] = c
+	o.proofData[o.known] = o.protos[o.known].GetProofData(c)
+	return encodeOrProofData(o.challenges, o.proofData)
+}
+
+func encodeOrProofData(challenges []*big.Int, proofData [][]byte) []byte {
+	parts := make([][]byte, 2*len(challenges))
+	for i, c := range challenges {
+		parts[2*i] = c.Bytes()
+		parts[2*i+1] = proofData[i]
+	}
+	return encodeParts(parts)
+}
+
+func (o *or) Verify(data []byte) bool {
+	parts, err := decodeParts(data, 2*len(o.protos))
+	if err != nil {
+		return false
+	}
+	c := new(big.Int).Set(o.challenge)
+	for i, proto := range o.protos {
+		ci := new(big.Int).SetBytes(parts[2*i])
+		c.Xor(c, ci)
+		proto.SetChallenge(ci)
+		if !proto.Verify(parts[2*i+1]) {
+			return false
+		}
+	}
+	return c.Sign() == 0
+}
+
+func (o *or) Simulate(challenge *big.Int) ([]byte, []byte) {
+	randomParts := make([][]byte, len(o.protos))
+	challenges := make([]*big.Int, len(o.protos))
+	proofData := make([][]byte, len(o.protos))
+	last := new(big.Int).Set(challenge)
+	for i := range o.protos[:len(o.protos)-1] {
+		challenges[i] = o.protos[i].GetChallenge()
+		last.Xor(last, challenges[i])
+	}
+	challenges[len(o.protos)-1] = last
+	for i, proto := range o.protos {
+		randomParts[i], proofData[i] = proto.Simulate(challenges[i])
+	}
+	return encodeParts(randomParts), encodeOrProofData(challenges, proofData)
+}
+
+// encodeParts encodes parts one after another, each of them as a 4-byte big-endian
+// length followed by the bytes of the part.
+func encodeParts(parts [][]byte) []byte {
+	var data []byte
+	for _, part := range parts {
+		length := make([]byte, 4)
+		binary.BigEndian.PutUint32(length, uint32(len(part)))
+		data = append(data, length...)
+		data = append(data, part...)
+	}
+	return data
+}
+
+// decodeParts decodes n parts encoded by encodeParts.
+func decodeParts(data []byte, n int) ([][]byte, error) {
+	parts := make([][]byte, n)
+	for i := range parts {
+		if len(data) < 4 {
+			return nil, fmt.Errorf("data is too short")
+		}
+		length := binary.BigEndian.Uint32(data[:4])
+		data = data[4:]
+		if uint32(len(data)) < length {
+			return nil, fmt.Errorf("data is too short")
+		}
+		parts[i] = data[:length]
+		data = data[length:]
+	}
+	if len(data) != 0 {
+		return nil, fmt.Errorf("data is too long")
+	}
+	return parts, nil
+}
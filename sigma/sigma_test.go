@@ -0,0 +1,134 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package sigma
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/awsong/crypto/common"
+	"github.com/awsong/crypto/schnorr"
+	"github.com/stretchr/testify/assert"
+)
+
+// run executes the protocol between prover and verifier.
+func run(prover, verifier Protocol) bool {
+	err := verifier.SetProofRandomData(prover.GetProofRandomData())
+	if err != nil {
+		return false
+	}
+	challenge := verifier.GetChallenge()
+	return verifier.Verify(prover.GetProofData(challenge))
+}
+
+// getSchnorrInstances returns n pairs (x_i, y_i = g^x_i).
+func getSchnorrInstances(group *schnorr.Group, n int) ([]*big.Int, []*big.Int) {
+	secrets := make([]*big.Int, n)
+	ys := make([]*big.Int, n)
+	for i := range secrets {
+		secrets[i] = common.GetRandomInt(group.Q)
+		ys[i] = group.Exp(group.G, secrets[i])
+	}
+	return secrets, ys
+}
+
+func TestAND(t *testing.T) {
+	group, err := schnorr.NewGroup(256)
+	if err != nil {
+		t.Errorf("error when creating group: %v", err)
+	}
+	secrets, ys := getSchnorrInstances(group, 2)
+
+	p1, _ := NewSchnorrProver(group, secrets[0], ys[0])
+	p2, _ := NewSchnorrProver(group, secrets[1], ys[1])
+	prover := AND(p1, p2)
+	verifier := AND(NewSchnorrVerifier(group, ys[0]), NewSchnorrVerifier(group, ys[1]))
+	assert.Equal(t, true, run(prover, verifier), "AND proof failed")
+
+	// the prover does not know the second secret
+	p2, _ = NewSchnorrProver(group, secrets[0], ys[1])
+	prover = AND(p1, p2)
+	assert.Equal(t, false, run(prover, verifier), "AND proof should fail")
+}
+
+func TestOR(t *testing.T) {
+	group, err := schnorr.NewGroup(256)
+	if err != nil {
+		t.Errorf("error when creating group: %v", err)
+	}
+	secrets, ys := getSchnorrInstances(group, 3)
+
+	// prover knows only x_1
+	for known := 0; known < 3; known++ {
+		protos := make([]Protocol, 3)
+		for i := range protos {
+			if i == known {
+				protos[i], _ = NewSchnorrProver(group, secrets[i], ys[i])
+			} else {
+				protos[i] = NewSchnorrVerifier(group, ys[i])
+			}
+		}
+		prover := OR(known, protos...)
+		verifier := OR(0, NewSchnorrVerifier(group, ys[0]), NewSchnorrVerifier(group, ys[1]),
+			NewSchnorrVerifier(group, ys[2]))
+		assert.Equal(t, true, run(prover, verifier), "OR proof failed")
+	}
+
+	// the prover does not know any of the secrets
+	p, _ := NewSchnorrProver(group, secrets[2], ys[0])
+	prover := OR(0, p, NewSchnorrVerifier(group, ys[1]))
+	verifier := OR(0, NewSchnorrVerifier(group, ys[0]), NewSchnorrVerifier(group, ys[1]))
+	assert.Equal(t, false, run(prover, verifier), "OR proof should fail")
+}
+
+// TestNested demonstrates proof of x_0 OR (x_1 AND x_2) where the prover knows x_1 and x_2.
+func TestNested(t *testing.T) {
+	group, err := schnorr.NewGroup(256)
+	if err != nil {
+		t.Errorf("error when creating group: %v", err)
+	}
+	secrets, ys := getSchnorrInstances(group, 3)
+
+	p1, _ := NewSchnorrProver(group, secrets[1], ys[1])
+	p2, _ := NewSchnorrProver(group, secrets[2], ys[2])
+	prover := OR(1, NewSchnorrVerifier(group, ys[0]), AND(p1, p2))
+	verifier := OR(0, NewSchnorrVerifier(group, ys[0]),
+		AND(NewSchnorrVerifier(group, ys[1]), NewSchnorrVerifier(group, ys[2])))
+	assert.Equal(t, true, run(prover, verifier), "nested proof failed")
+
+	// (x_0 OR x_1) AND x_2 where the prover knows x_0 and x_2 - the OR is simulated inside AND
+	p0, _ := NewSchnorrProver(group, secrets[0], ys[0])
+	prover = AND(OR(0, p0, NewSchnorrVerifier(group, ys[1])), p2)
+	verifier = AND(OR(0, NewSchnorrVerifier(group, ys[0]), NewSchnorrVerifier(group, ys[1])),
+		NewSchnorrVerifier(group, ys[2]))
+	assert.Equal(t, true, run(prover, verifier), "nested proof failed")
+
+	// simulated transcript of a nested protocol is accepted
+	sim := OR(0, NewSchnorrVerifier(group, ys[0]),
+		AND(NewSchnorrVerifier(group, ys[1]), NewSchnorrVerifier(group, ys[2])))
+	challenge := verifier.GetChallenge()
+	proofRandomData, proofData := sim.Simulate(challenge)
+	verifier = OR(0, NewSchnorrVerifier(group, ys[0]),
+		AND(NewSchnorrVerifier(group, ys[1]), NewSchnorrVerifier(group, ys[2])))
+	err = verifier.SetProofRandomData(proofRandomData)
+	if err != nil {
+		t.Errorf("error when calling SetProofRandomData: %v", err)
+	}
+	verifier.SetChallenge(challenge)
+	assert.Equal(t, true, verifier.Verify(proofData), "simulated transcript was not accepted")
+}
@@ -0,0 +1,98 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package transcript
+
+import (
+	"crypto/sha3"
+	"encoding/binary"
+	"math/big"
+)
+
+// Transcript is a proof transcript (in the style of Merlin) which is used to derive
+// Fiat-Shamir challenges. Prover and verifier append all the protocol messages to the
+// transcript, each under a label, and derive challenges from it. Each message is framed as
+// len(label) || label || len(data) || data, thus messages of different protocol steps cannot
+// collide, and each challenge depends on all the messages (and challenges) before it.
+// The state is kept in SHAKE128, the first message is the label given in NewTranscript.
+type Transcript struct {
+	state *sha3.SHAKE
+}
+
+// NewTranscript returns a new Transcript for the protocol given by label (the label
+// should be unique for the protocol and its version).
+func NewTranscript(label string) *Transcript {
+	t := &Transcript{
+		state: sha3.NewSHAKE128(),
+	}
+	t.AppendMessage("protocol", []byte(label))
+	return t
+}
+
+// write appends len(data) || data to the state.
+func (t *Transcript) write(data []byte) {
+	var l [4]byte
+	binary.BigEndian.PutUint32(l[:], uint32(len(data)))
+	t.state.Write(l[:])
+	t.state.Write(data)
+}
+
+// AppendMessage appends data to the transcript under the given label.
+func (t *Transcript) AppendMessage(label string, data []byte) {
+	t.write([]byte(label))
+	t.write(data)
+}
+
+// AppendInts appends the numbers to the transcript under the given label (each
+// number is appended as a separate message, the sign is not encoded).
+func (t *Transcript) AppendInts(label string, numbers ...*big.Int) {
+	for _, n := range numbers {
+		t.AppendMessage(label, n.Bytes())
+	}
+}
+
+// ChallengeBytes returns length bytes derived from the transcript under the given label.
+// The returned bytes are appended to the transcript, thus the next challenge differs
+// even if no messages are appended in between.
+func (t *Transcript) ChallengeBytes(label string, length int) []byte {
+	// the state cannot be written to after it is read, thus the output is read from a copy
+	s, err := t.state.MarshalBinary()
+	if err != nil {
+		panic(err)
+	}
+	out := &Transcript{state: sha3.NewSHAKE128()}
+	if err := out.state.UnmarshalBinary(s); err != nil {
+		panic(err)
+	}
+	out.write([]byte(label))
+	var l [4]byte
+	binary.BigEndian.PutUint32(l[:], uint32(length))
+	out.write(l[:])
+	challenge := make([]byte, length)
+	out.state.Read(challenge)
+
+	t.AppendMessage(label, challenge)
+	return challenge
+}
+
+// ChallengeInt returns a challenge from [0, 2^bitLength) derived from the transcript
+// under the given label.
+func (t *Transcript) ChallengeInt(label string, bitLength int) *big.Int {
+	b := t.ChallengeBytes(label, (bitLength+7)/8)
+	c := new(big.Int).SetBytes(b)
+	return c.Rsh(c, uint(len(b)*8-bitLength))
+}
@@ -0,0 +1,59 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package transcript
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTranscript(t *testing.T) {
+	t1 := NewTranscript("test protocol")
+	t2 := NewTranscript("test protocol")
+	for _, tr := range []*Transcript{t1, t2} {
+		tr.AppendMessage("message", []byte("hello"))
+		tr.AppendInts("numbers", big.NewInt(12), big.NewInt(345))
+	}
+	c1 := t1.ChallengeBytes("challenge", 32)
+	c2 := t2.ChallengeBytes("challenge", 32)
+	assert.Equal(t, c1, c2, "the same transcripts should give the same challenge")
+	assert.Equal(t, 32, len(c1))
+
+	// each challenge is appended to the transcript
+	assert.NotEqual(t, c1, t1.ChallengeBytes("challenge", 32),
+		"subsequent challenges should differ")
+
+	// messages are separated by labels
+	t3 := NewTranscript("test protocol")
+	t3.AppendMessage("messag", []byte("ehello"))
+	t3.AppendInts("numbers", big.NewInt(12), big.NewInt(345))
+	assert.NotEqual(t, c1, t3.ChallengeBytes("challenge", 32),
+		"different framing of messages should give a different challenge")
+
+	// protocols are separated by the transcript label
+	t4 := NewTranscript("another protocol")
+	t4.AppendMessage("message", []byte("hello"))
+	t4.AppendInts("numbers", big.NewInt(12), big.NewInt(345))
+	assert.NotEqual(t, c1, t4.ChallengeBytes("challenge", 32),
+		"different protocols should give a different challenge")
+
+	c := NewTranscript("test protocol").ChallengeInt("challenge", 81)
+	assert.Equal(t, true, c.BitLen() <= 81, "challenge should have at most 81 bits")
+}
@@ -0,0 +1,94 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package vss
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/awsong/crypto/common"
+	"github.com/awsong/crypto/schnorr"
+)
+
+// Based on:
+// P. Feldman. A Practical Scheme for Non-interactive Verifiable Secret Sharing. FOCS 1987.
+//
+// SplitSecret splits the secret into n shares s_i = f(i), i = 1, ..., n, where f is a random
+// polynomial of degree t-1 over Z_q with f(0) = secret (q is the order of the group).
+// Any t shares reconstruct the secret. Besides the shares it returns Feldman commitments
+// C_j = g^a_j to the coefficients a_0, ..., a_(t-1) of f, which enable anybody to verify
+// the shares (see VerifyShare).
+func SplitSecret(secret *big.Int, t, n int, group *schnorr.Group) ([]*big.Int, []*big.Int,
+	error) {
+	if t < 1 || t > n {
+		return nil, nil, fmt.Errorf("threshold needs to be in [1, n]")
+	}
+	if secret.Sign() < 0 || secret.Cmp(group.Q) >= 0 {
+		return nil, nil, fmt.Errorf("secret needs to be in Z_q")
+	}
+	polynomial, err := common.NewRandomPolynomial(t-1, group.Q)
+	if err != nil {
+		return nil, nil, err
+	}
+	polynomial.SetCoefficient(0, secret)
+
+	shares := make([]*big.Int, n)
+	for i := 0; i < n; i++ {
+		shares[i] = polynomial.GetValue(big.NewInt(int64(i + 1)))
+	}
+	commitments := make([]*big.Int, t)
+	for j := 0; j < t; j++ {
+		commitments[j] = group.Exp(group.G, polynomial.GetCoefficient(j))
+	}
+	return shares, commitments, nil
+}
+
+// VerifyShare returns true if the share with index idx is consistent with the Feldman
+// commitments, that is g^share = C_0 * C_1^idx * ... * C_(t-1)^(idx^(t-1)).
+func VerifyShare(share *big.Int, idx int, commitments []*big.Int, group *schnorr.Group) bool {
+	if idx < 1 || len(commitments) == 0 {
+		return false
+	}
+	x := big.NewInt(int64(idx))
+	right := big.NewInt(1)
+	for j, c := range commitments {
+		e := new(big.Int).Exp(x, big.NewInt(int64(j)), group.Q)
+		right = group.Mul(right, group.Exp(c, e))
+	}
+	return common.ConstantTimeEq(group.Exp(group.G, share), right)
+}
+
+// ReconstructSecret returns f(0) computed by Lagrange interpolation from the shares
+// f(indices[i]). At least t shares need to be given, otherwise the returned value
+// is not the secret.
+func ReconstructSecret(shares []*big.Int, indices []int, group *schnorr.Group) (*big.Int,
+	error) {
+	if len(shares) == 0 || len(shares) != len(indices) {
+		return nil, fmt.Errorf("the number of shares and indices should be the same")
+	}
+	points := make(map[*big.Int]*big.Int)
+	seen := make(map[int]bool)
+	for i, idx := range indices {
+		if idx < 1 || seen[idx] {
+			return nil, fmt.Errorf("indices need to be positive and distinct")
+		}
+		seen[idx] = true
+		points[big.NewInt(int64(idx))] = shares[i]
+	}
+	return common.LagrangeInterpolation(big.NewInt(0), points, group.Q), nil
+}
@@ -0,0 +1,89 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package vss
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/awsong/crypto/common"
+	"github.com/awsong/crypto/schnorr"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFeldmanVSS(t *testing.T) {
+	group, err := schnorr.NewGroup(256)
+	if err != nil {
+		t.Errorf("error when creating Schnorr group: %v", err)
+	}
+
+	tests := []struct {
+		t int
+		n int
+	}{
+		{1, 1}, {1, 3}, {2, 3}, {3, 5}, {5, 5}, {4, 10},
+	}
+	for _, test := range tests {
+		secret := common.GetRandomInt(group.Q)
+		shares, commitments, err := SplitSecret(secret, test.t, test.n, group)
+		if err != nil {
+			t.Errorf("error in SplitSecret: %v", err)
+		}
+		assert.Equal(t, test.n, len(shares))
+		assert.Equal(t, test.t, len(commitments))
+
+		for i, share := range shares {
+			assert.Equal(t, true, VerifyShare(share, i+1, commitments, group),
+				"share %d should be valid for (%d, %d)", i+1, test.t, test.n)
+		}
+
+		// the last t shares reconstruct the secret
+		indices := make([]int, test.t)
+		for i := range indices {
+			indices[i] = test.n - test.t + i + 1
+		}
+		reconstructed, err := ReconstructSecret(shares[test.n-test.t:], indices, group)
+		if err != nil {
+			t.Errorf("error in ReconstructSecret: %v", err)
+		}
+		assert.Equal(t, secret, reconstructed,
+			"secret should be reconstructed for (%d, %d)", test.t, test.n)
+
+		// a modified share is detected
+		modified := new(big.Int).Add(shares[0], big.NewInt(1))
+		assert.Equal(t, false, VerifyShare(modified, 1, commitments, group),
+			"modified share should not be valid")
+	}
+
+	// t-1 shares do not reconstruct the secret
+	secret := common.GetRandomInt(group.Q)
+	shares, _, err := SplitSecret(secret, 3, 5, group)
+	if err != nil {
+		t.Errorf("error in SplitSecret: %v", err)
+	}
+	reconstructed, err := ReconstructSecret(shares[:2], []int{1, 2}, group)
+	if err != nil {
+		t.Errorf("error in ReconstructSecret: %v", err)
+	}
+	assert.NotEqual(t, secret, reconstructed, "t-1 shares should not reconstruct the secret")
+
+	_, err = ReconstructSecret(shares[:2], []int{1, 1}, group)
+	assert.NotNil(t, err, "duplicate indices should fail")
+	_, _, err = SplitSecret(secret, 6, 5, group)
+	assert.NotNil(t, err, "threshold larger than n should fail")
+}